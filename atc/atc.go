@@ -0,0 +1,157 @@
+package atc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Info is the version information the ATC reports
+type Info struct {
+	Version       string `json:"version"`
+	WorkerVersion string `json:"worker_version"`
+}
+
+// Worker is a worker registered with the ATC
+type Worker struct {
+	Name             string   `json:"name"`
+	State            string   `json:"state"`
+	ActiveContainers int      `json:"active_containers"`
+	ActiveVolumes    int      `json:"active_volumes"`
+	Platform         string   `json:"platform"`
+	Tags             []string `json:"tags"`
+	Team             string   `json:"team"`
+	Version          string   `json:"version"`
+}
+
+// Client talks to the Concourse API as a local user
+type Client struct {
+	url        string
+	username   string
+	password   string
+	httpClient *http.Client
+	token      string
+}
+
+// New returns a Concourse API client. The system's CAs are trusted as well as caCert,
+// which may be empty when Concourse has a certificate from a public CA
+func New(apiURL, caCert, username, password string) (*Client, error) {
+	if apiURL == "" {
+		return nil, errors.New("no Concourse URL found in config, has this deployment finished deploying?")
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if caCert != "" && !pool.AppendCertsFromPEM([]byte(caCert)) {
+		return nil, errors.New("could not parse Concourse CA certificate")
+	}
+
+	return &Client{
+		url:      strings.TrimSuffix(apiURL, "/"),
+		username: username,
+		password: password,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: pool},
+			},
+		},
+	}, nil
+}
+
+// Info fetches the ATC's version, which doesn't need a token
+func (c *Client) Info() (Info, error) {
+	var info Info
+	resp, err := c.httpClient.Get(c.url + "/api/v1/info")
+	if err != nil {
+		return info, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return info, responseError(resp)
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return info, fmt.Errorf("error decoding Concourse info: [%v]", err)
+	}
+	return info, nil
+}
+
+// Workers lists the workers registered with the ATC
+func (c *Client) Workers() ([]Worker, error) {
+	if c.token == "" {
+		err := c.authenticate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequest("GET", c.url+"/api/v1/workers", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	var workers []Worker
+	if err = json.NewDecoder(resp.Body).Decode(&workers); err != nil {
+		return nil, fmt.Errorf("error decoding Concourse workers: [%v]", err)
+	}
+	return workers, nil
+}
+
+// authenticate fetches a token for the local user in the same way fly login does
+func (c *Client) authenticate() error {
+	req, err := http.NewRequest("POST", c.url+"/sky/token", strings.NewReader(url.Values{
+		"grant_type": {"password"},
+		"username":   {c.username},
+		"password":   {c.password},
+		"scope":      {"openid profile email federated:id groups"},
+	}.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("fly", "Zmx5")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return fmt.Errorf("error decoding Concourse token: [%v]", err)
+	}
+	if token.AccessToken == "" {
+		return errors.New("Concourse did not return an access token")
+	}
+	c.token = token.AccessToken
+	return nil
+}
+
+func responseError(resp *http.Response) error {
+	body, _ := ioutil.ReadAll(resp.Body)
+	return fmt.Errorf("%s %s: unexpected status %s: %s", resp.Request.Method, resp.Request.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,85 @@
+package atc_test
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/EngineerBetter/concourse-up/atc"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeATC is a stand-in for the parts of the Concourse API used by the client
+func newFakeATC(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/info", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(atc.Info{Version: "5.0.0", WorkerVersion: "2.1"})
+	})
+	mux.HandleFunc("/sky/token", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		require.Equal(t, "fly", user)
+		require.Equal(t, "Zmx5", pass)
+		if r.FormValue("grant_type") != "password" || r.FormValue("username") != "admin" || r.FormValue("password") != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "token", "token_type": "Bearer"})
+	})
+	mux.HandleFunc("/api/v1/workers", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`[{"name": "worker-1", "state": "running", "active_containers": 12, "active_volumes": 40, "platform": "linux", "tags": ["gpu"], "team": "", "version": "2.1"}]`))
+	})
+	return httptest.NewTLSServer(mux)
+}
+
+func newClient(t *testing.T, server *httptest.Server, password string) *atc.Client {
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	c, err := atc.New(server.URL, string(caCert), "admin", password)
+	require.NoError(t, err)
+	return c
+}
+
+func TestClient_Info(t *testing.T) {
+	server := newFakeATC(t)
+	defer server.Close()
+
+	info, err := newClient(t, server, "s3cret").Info()
+	require.NoError(t, err)
+	require.Equal(t, atc.Info{Version: "5.0.0", WorkerVersion: "2.1"}, info)
+}
+
+func TestClient_Workers(t *testing.T) {
+	server := newFakeATC(t)
+	defer server.Close()
+
+	workers, err := newClient(t, server, "s3cret").Workers()
+	require.NoError(t, err)
+	require.Equal(t, []atc.Worker{{
+		Name:             "worker-1",
+		State:            "running",
+		ActiveContainers: 12,
+		ActiveVolumes:    40,
+		Platform:         "linux",
+		Tags:             []string{"gpu"},
+		Version:          "2.1",
+	}}, workers)
+}
+
+func TestClient_RejectsBadPassword(t *testing.T) {
+	server := newFakeATC(t)
+	defer server.Close()
+
+	_, err := newClient(t, server, "wrong").Workers()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "401")
+}
+
+func TestNew_RejectsBadCACert(t *testing.T) {
+	_, err := atc.New("https://ci.example.com", "not a cert", "admin", "s3cret")
+	require.EqualError(t, err, "could not parse Concourse CA certificate")
+}
@@ -10,27 +10,56 @@ import (
 )
 
 func (client *AWSClient) deployConcourse(creds []byte, detach bool) ([]byte, error) {
+	flagFiles, vmap, err := client.concourseDeployArgs(creds)
+	if err != nil {
+		return creds, err
+	}
+
+	vs := vars(vmap)
+
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return creds, fmt.Errorf("failed to retrieve director IP: [%v]", err)
+	}
 
+	err = client.boshCLI.RunAuthenticatedCommand(
+		"deploy",
+		directorPublicIP,
+		client.config.DirectorPassword,
+		client.config.DirectorCACert,
+		detach,
+		os.Stdout,
+		append(flagFiles, vs...)...)
+	if err != nil {
+		return creds, fmt.Errorf("failed to run bosh deploy with commands %+v: [%v]", flagFiles, err)
+	}
+
+	return ioutil.ReadFile(client.workingdir.PathInWorkingDir(credsFilename))
+}
+
+// concourseDeployArgs returns the manifest, ops files and vars files Concourse is deployed
+// with, having saved them to the working directory, along with the vars to pass alongside
+func (client *AWSClient) concourseDeployArgs(creds []byte) ([]string, map[string]interface{}, error) {
 	err := saveFilesToWorkingDir(client.workingdir, client.provider, creds)
 	if err != nil {
-		return creds, fmt.Errorf("failed saving files to working directory in deployConcourse: [%v]", err)
+		return nil, nil, fmt.Errorf("failed saving files to working directory in deployConcourse: [%v]", err)
 	}
 
 	boshDBAddress, err := client.outputs.Get("BoshDBAddress")
 	if err != nil {
-		return creds, err
+		return nil, nil, err
 	}
 	boshDBPort, err := client.outputs.Get("BoshDBPort")
 	if err != nil {
-		return creds, err
+		return nil, nil, err
 	}
 	atcPublicIP, err := client.outputs.Get("ATCPublicIP")
 	if err != nil {
-		return creds, err
+		return nil, nil, err
 	}
 
 	vmap := map[string]interface{}{
-		"deployment_name":          concourseDeploymentName,
+		"deployment_name":          concourseDeployment(client.config),
 		"domain":                   client.config.Domain,
 		"project":                  client.config.Project,
 		"web_network_name":         "public",
@@ -40,8 +69,8 @@ func (client *AWSClient) deployConcourse(creds []byte, detach bool) ([]byte, err
 		"postgres_role":            client.config.RDSUsername,
 		"postgres_password":        client.config.RDSPassword,
 		"postgres_ca_cert":         db.RDSRootCert,
-		"web_vm_type":              "concourse-web-" + client.config.ConcourseWebSize,
-		"worker_vm_type":           "concourse-" + client.config.ConcourseWorkerSize,
+		"web_vm_type":              webVMType(client.config),
+		"worker_vm_type":           workerVMType(client.config),
 		"worker_count":             client.config.ConcourseWorkerCount,
 		"atc_eip":                  atcPublicIP,
 		"external_tls.certificate": client.config.ConcourseCert,
@@ -63,6 +92,18 @@ func (client *AWSClient) deployConcourse(creds []byte, detach bool) ([]byte, err
 		client.workingdir.PathInWorkingDir(concourseGrafanaFilename),
 	}
 
+	concourseVersionFlags, err := concourseVersionOps(client.config, client.workingdir)
+	if err != nil {
+		return nil, nil, err
+	}
+	flagFiles = append(flagFiles, concourseVersionFlags...)
+
+	stemcellVersionFlags, err := stemcellVersionOps(client.config, client.provider.IAAS(), client.workingdir)
+	if err != nil {
+		return nil, nil, err
+	}
+	flagFiles = append(flagFiles, stemcellVersionFlags...)
+
 	if client.config.ConcoursePassword != "" {
 		vmap["atc_password"] = client.config.ConcoursePassword
 	}
@@ -73,33 +114,32 @@ func (client *AWSClient) deployConcourse(creds []byte, detach bool) ([]byte, err
 		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseGitHubAuthFilename))
 	}
 
-	t, err1 := client.buildTagsYaml(vmap["project"], "concourse")
-	if err1 != nil {
-		return creds, err
+	credentialManagerFlags, err := credentialManagerOps(client.config, client.workingdir, vmap)
+	if err != nil {
+		return nil, nil, err
 	}
-	vmap["tags"] = t
-	flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(extraTagsFilename))
-
-	vs := vars(vmap)
+	flagFiles = append(flagFiles, credentialManagerFlags...)
+	flagFiles = append(flagFiles, oldEncryptionKeyOps(client.config, client.workingdir, vmap)...)
+	flagFiles = append(flagFiles, workerPersistentDiskOps(client.config, client.workingdir)...)
 
-	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	workerPoolFlags, err := workerPoolOps(client.config, client.workingdir, vmap)
 	if err != nil {
-		return creds, fmt.Errorf("failed to retrieve director IP: [%v]", err)
+		return nil, nil, err
 	}
+	flagFiles = append(flagFiles, workerPoolFlags...)
 
-	err = client.boshCLI.RunAuthenticatedCommand(
-		"deploy",
-		directorPublicIP,
-		client.config.DirectorPassword,
-		client.config.DirectorCACert,
-		detach,
-		os.Stdout,
-		append(flagFiles, vs...)...)
-	if err != nil {
-		return creds, fmt.Errorf("failed to run bosh deploy with commands %+v: [%v]", flagFiles, err)
+	if len(client.config.WorkerIAMPolicies) > 0 || client.config.SelfUpdateRoleARN != "" {
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(workerVMExtensionFilename))
 	}
 
-	return ioutil.ReadFile(client.workingdir.PathInWorkingDir(credsFilename))
+	t, err1 := client.buildTagsYaml(vmap["project"], "concourse")
+	if err1 != nil {
+		return nil, nil, err1
+	}
+	vmap["tags"] = t
+	flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(extraTagsFilename))
+
+	return flagFiles, vmap, nil
 }
 
 func (client *AWSClient) buildTagsYaml(project interface{}, component string) (string, error) {
@@ -120,8 +120,7 @@ func (client *AWSClient) createDefaultDatabases() error {
 		return err
 	}
 	defer db.Close()
-	dbNames := []string{"concourse_atc", "uaa", "credhub"}
-	for _, dbName := range dbNames {
+	for _, dbName := range ConcourseDatabases {
 		_, err := db.Exec("CREATE DATABASE " + dbName)
 		if err != nil && !strings.Contains(err.Error(),
 			fmt.Sprintf(`pq: database "%s" already exists`, dbName)) {
@@ -130,3 +129,41 @@ func (client *AWSClient) createDefaultDatabases() error {
 	}
 	return nil
 }
+
+// setDBMaxStorage applies --db-max-storage to the RDS instance terraform created, which is
+// identified by the first label of its address
+func (client *AWSClient) setDBMaxStorage() error {
+	address, err := client.outputs.Get("BoshDBAddress")
+	if err != nil {
+		return err
+	}
+	return client.provider.SetDBMaxStorage(strings.SplitN(address, ".", 2)[0], client.config.DBMaxStorage)
+}
+
+// resetDirectorDatabase empties the director's database in an RDS instance restored from a
+// snapshot, as it refers to VMs that were deleted along with the old deployment. The director
+// database can't be dropped while connected to it, so this connects to concourse_atc instead
+func (client *AWSClient) resetDirectorDatabase() error {
+	db, err := client.db.Open("concourse_atc")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if _, err = db.Exec("DROP DATABASE IF EXISTS " + client.config.RDSDefaultDatabaseName); err != nil {
+		return err
+	}
+	_, err = db.Exec("CREATE DATABASE " + client.config.RDSDefaultDatabaseName)
+	return err
+}
+
+// DatabaseVersion connects to RDS through the director and returns the version of PostgreSQL it reports
+func (client *AWSClient) DatabaseVersion() (string, error) {
+	db, err := client.db.Open(client.config.RDSDefaultDatabaseName)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+	var version string
+	err = db.QueryRow("SHOW server_version").Scan(&version)
+	return version, err
+}
@@ -1,16 +1,24 @@
 package bosh
 
 import (
+	"fmt"
 	"net"
 
 	"github.com/EngineerBetter/concourse-up/bosh/internal/aws"
 	"github.com/EngineerBetter/concourse-up/bosh/internal/boshcli"
 	"github.com/EngineerBetter/concourse-up/db"
+	"github.com/EngineerBetter/concourse-up/iaas"
 	"github.com/apparentlymart/go-cidr/cidr"
 )
 
 // Deploy implements deploy for AWS client
 func (client *AWSClient) Deploy(state, creds []byte, detach bool) (newState, newCreds []byte, err error) {
+	if len(state) == 0 && client.config.RestoredFromSnapshot != "" {
+		if err = client.resetDirectorDatabase(); err != nil {
+			return state, creds, err
+		}
+	}
+
 	state, creds, err = client.createEnv(client.boshCLI, state, creds, "")
 	if err != nil {
 		return state, creds, err
@@ -25,6 +33,9 @@ func (client *AWSClient) Deploy(state, creds []byte, detach bool) (newState, new
 	if err = client.createDefaultDatabases(); err != nil {
 		return state, creds, err
 	}
+	if err = client.setDBMaxStorage(); err != nil {
+		return state, creds, err
+	}
 
 	creds, err = client.deployConcourse(creds, detach)
 	if err != nil {
@@ -63,93 +74,102 @@ func (client *AWSClient) Recreate() error {
 }
 
 func (client *AWSClient) createEnv(bosh boshcli.ICLI, state, creds []byte, customOps string) (newState, newCreds []byte, err error) {
-	tags, err := splitTags(client.config.Tags)
+	tags, err := directorTags(client.config)
 	if err != nil {
 		return state, creds, err
 	}
-	tags["concourse-up-project"] = client.config.Project
-	tags["concourse-up-component"] = "concourse"
 	//TODO(px): pull up this so that we use aws.Store
 	store := temporaryStore{
 		"vars.yaml":  creds,
 		"state.json": state,
 	}
 
-	boshUserAccessKeyID, err1 := client.outputs.Get("BoshUserAccessKeyID")
-	if err1 != nil {
-		return state, creds, err1
+	env, err := client.directorEnvironment(customOps)
+	if err != nil {
+		return state, creds, err
 	}
-	boshSecretAccessKey, err1 := client.outputs.Get("BoshSecretAccessKey")
-	if err1 != nil {
-		return state, creds, err1
+	err = bosh.CreateEnv(store, env, client.config.DirectorPassword, client.config.DirectorCert, client.config.DirectorKey, client.config.DirectorCACert, tags)
+	return store["state.json"], store["vars.yaml"], err
+}
+
+// directorEnvironment holds everything the director manifest is rendered from
+func (client *AWSClient) directorEnvironment(customOps string) (aws.Environment, error) {
+	boshUserAccessKeyID, err := client.outputs.Get("BoshUserAccessKeyID")
+	if err != nil {
+		return aws.Environment{}, err
 	}
-	publicSubnetID, err1 := client.outputs.Get("PublicSubnetID")
-	if err1 != nil {
-		return state, creds, err1
+	boshSecretAccessKey, err := client.outputs.Get("BoshSecretAccessKey")
+	if err != nil {
+		return aws.Environment{}, err
 	}
-	privateSubnetID, err1 := client.outputs.Get("PrivateSubnetID")
-	if err1 != nil {
-		return state, creds, err1
+	publicSubnetID, err := client.outputs.Get("PublicSubnetID")
+	if err != nil {
+		return aws.Environment{}, err
 	}
-	directorPublicIP, err1 := client.outputs.Get("DirectorPublicIP")
-	if err1 != nil {
-		return state, creds, err1
+	privateSubnetID, err := client.outputs.Get("PrivateSubnetID")
+	if err != nil {
+		return aws.Environment{}, err
 	}
-	atcSecurityGroupID, err1 := client.outputs.Get("ATCSecurityGroupID")
-	if err1 != nil {
-		return state, creds, err1
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return aws.Environment{}, err
+	}
+	atcSecurityGroupID, err := client.outputs.Get("ATCSecurityGroupID")
+	if err != nil {
+		return aws.Environment{}, err
 	}
-	vmSecurityGroupID, err1 := client.outputs.Get("VMsSecurityGroupID")
-	if err1 != nil {
-		return state, creds, err1
+	vmSecurityGroupID, err := client.outputs.Get("VMsSecurityGroupID")
+	if err != nil {
+		return aws.Environment{}, err
 	}
-	blobstoreBucket, err1 := client.outputs.Get("BlobstoreBucket")
-	if err1 != nil {
-		return state, creds, err1
+	blobstoreBucket, err := client.outputs.Get("BlobstoreBucket")
+	if err != nil {
+		return aws.Environment{}, err
 	}
-	boshDBAddress, err1 := client.outputs.Get("BoshDBAddress")
-	if err1 != nil {
-		return state, creds, err1
+	boshDBAddress, err := client.outputs.Get("BoshDBAddress")
+	if err != nil {
+		return aws.Environment{}, err
 	}
-	boshDbPort, err1 := client.outputs.Get("BoshDBPort")
-	if err1 != nil {
-		return state, creds, err1
+	boshDbPort, err := client.outputs.Get("BoshDBPort")
+	if err != nil {
+		return aws.Environment{}, err
 	}
-	blobstoreUserAccessKeyID, err1 := client.outputs.Get("BlobstoreUserAccessKeyID")
-	if err1 != nil {
-		return state, creds, err1
+	blobstoreUserAccessKeyID, err := client.outputs.Get("BlobstoreUserAccessKeyID")
+	if err != nil {
+		return aws.Environment{}, err
 	}
-	blobstoreSecretAccessKey, err1 := client.outputs.Get("BlobstoreSecretAccessKey")
-	if err1 != nil {
-		return state, creds, err1
+	blobstoreSecretAccessKey, err := client.outputs.Get("BlobstoreSecretAccessKey")
+	if err != nil {
+		return aws.Environment{}, err
 	}
-	directorKeyPair, err1 := client.outputs.Get("DirectorKeyPair")
-	if err1 != nil {
-		return state, creds, err1
+	directorKeyPair, err := client.outputs.Get("DirectorKeyPair")
+	if err != nil {
+		return aws.Environment{}, err
 	}
-	directorSecurityGroup, err1 := client.outputs.Get("DirectorSecurityGroupID")
-	if err1 != nil {
-		return state, creds, err1
+	directorSecurityGroup, err := client.outputs.Get("DirectorSecurityGroupID")
+	if err != nil {
+		return aws.Environment{}, err
 	}
 
 	publicCIDR := client.config.PublicCIDR
-	_, pubCIDR, err1 := net.ParseCIDR(publicCIDR)
-	if err1 != nil {
-		return state, creds, err1
+	_, pubCIDR, err := net.ParseCIDR(publicCIDR)
+	if err != nil {
+		return aws.Environment{}, err
 	}
-	internalGateway, err1 := cidr.Host(pubCIDR, 1)
-	if err1 != nil {
-		return state, creds, err1
+	internalGateway, err := cidr.Host(pubCIDR, 1)
+	if err != nil {
+		return aws.Environment{}, err
 	}
-	directorInternalIP, err1 := cidr.Host(pubCIDR, 6)
-	if err1 != nil {
-		return state, creds, err1
+	directorInternalIP, err := cidr.Host(pubCIDR, 6)
+	if err != nil {
+		return aws.Environment{}, err
 	}
 
-	err1 = bosh.CreateEnv(store, aws.Environment{
+	return aws.Environment{
 		InternalCIDR:    client.config.PublicCIDR,
 		InternalGateway: internalGateway.String(),
 		InternalIP:      directorInternalIP.String(),
+		LocalStores:     client.config.ImportedDirector,
 		AccessKeyID:     boshUserAccessKeyID,
 		SecretAccessKey: boshSecretAccessKey,
 		Region:          client.config.Region,
@@ -177,93 +197,174 @@ func (client *AWSClient) createEnv(bosh boshcli.ICLI, state, creds []byte, custo
 		Spot:                 client.config.Spot,
 		WorkerType:           client.config.WorkerType,
 		CustomOperations:     customOps,
-	}, client.config.DirectorPassword, client.config.DirectorCert, client.config.DirectorKey, client.config.DirectorCACert, tags)
-	if err1 != nil {
-		return store["state.json"], store["vars.yaml"], err1
-	}
-	return store["state.json"], store["vars.yaml"], err
+	}, nil
 }
 
-func (client *AWSClient) updateCloudConfig(bosh boshcli.ICLI) error {
+// cloudConfigEnvironment holds everything the cloud config is rendered from, apart from spot
+// bids which are passed in as looking them up is slow
+func (client *AWSClient) cloudConfigEnvironment(spotBidPrices map[string]float64) (aws.Environment, error) {
 	publicSubnetID, err := client.outputs.Get("PublicSubnetID")
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 	privateSubnetID, err := client.outputs.Get("PrivateSubnetID")
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 	aTCSecurityGroupID, err := client.outputs.Get("ATCSecurityGroupID")
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 	vMsSecurityGroupID, err := client.outputs.Get("VMsSecurityGroupID")
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
 	if err != nil {
-		return err
+		return aws.Environment{}, err
+	}
+	webInstanceProfile, err := client.outputs.Get("WebInstanceProfile")
+	if err != nil {
+		return aws.Environment{}, err
+	}
+	workerInstanceProfile, err := client.outputs.Get("WorkerInstanceProfile")
+	if err != nil {
+		return aws.Environment{}, err
 	}
 
 	publicCIDR := client.config.PublicCIDR
 	_, pubCIDR, err := net.ParseCIDR(publicCIDR)
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 	pubGateway, err := cidr.Host(pubCIDR, 1)
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 	publicCIDRGateway := pubGateway.String()
 	publicCIDRStatic, err := formatIPRange(publicCIDR, ", ", []int{6, 7})
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 	publicCIDRReserved, err := formatIPRange(publicCIDR, "-", []int{1, 5})
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 
 	privateCIDR := client.config.PrivateCIDR
 	_, privCIDR, err := net.ParseCIDR(privateCIDR)
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 	privGateway, err := cidr.Host(privCIDR, 1)
 	if err != nil {
-		return err
+		return aws.Environment{}, err
 	}
 	privateCIDRGateway := privGateway.String()
 	privateCIDRReserved, err := formatIPRange(privateCIDR, "-", []int{1, 5})
+	if err != nil {
+		return aws.Environment{}, err
+	}
+
+	return aws.Environment{
+		AZ:                    client.config.AvailabilityZone,
+		PublicSubnetID:        publicSubnetID,
+		PrivateSubnetID:       privateSubnetID,
+		ATCSecurityGroup:      aTCSecurityGroupID,
+		VMSecurityGroup:       vMsSecurityGroupID,
+		Spot:                  client.config.Spot,
+		ExternalIP:            directorPublicIP,
+		WorkerType:            client.config.WorkerType,
+		PublicCIDR:            publicCIDR,
+		PublicCIDRGateway:     publicCIDRGateway,
+		PublicCIDRStatic:      publicCIDRStatic,
+		PublicCIDRReserved:    publicCIDRReserved,
+		PrivateCIDR:           privateCIDR,
+		PrivateCIDRGateway:    privateCIDRGateway,
+		PrivateCIDRReserved:   privateCIDRReserved,
+		WebInstanceProfile:    webInstanceProfile,
+		WebInstanceType:       client.config.WebInstanceType,
+		WorkerDiskIOPS:        client.config.WorkerDiskIOPS,
+		WorkerDiskSize:        client.config.WorkerDiskSize,
+		WorkerDiskType:        client.config.WorkerDiskType,
+		WorkerInstanceProfile: workerInstanceProfile,
+		WorkerInstanceType:    client.config.WorkerInstanceType,
+		WorkerPersistentDisk:  client.config.WorkerPersistentDiskSize,
+		SpotBidPrices:         spotBidPrices,
+
+		ARMWorkerInstanceType:     ARMWorkerInstanceType(client.config),
+		WindowsWorkerInstanceType: WindowsWorkerInstanceType(client.config),
+	}, nil
+}
+
+func (client *AWSClient) updateCloudConfig(bosh boshcli.ICLI) error {
+	spotBidPrices, err := client.spotBidPrices()
 	if err != nil {
 		return err
 	}
+	env, err := client.cloudConfigEnvironment(spotBidPrices)
+	if err != nil {
+		return err
+	}
+	return bosh.UpdateCloudConfig(env, env.ExternalIP, client.config.DirectorPassword, client.config.DirectorCACert)
+}
 
-	return bosh.UpdateCloudConfig(aws.Environment{
-		AZ:                  client.config.AvailabilityZone,
-		PublicSubnetID:      publicSubnetID,
-		PrivateSubnetID:     privateSubnetID,
-		ATCSecurityGroup:    aTCSecurityGroupID,
-		VMSecurityGroup:     vMsSecurityGroupID,
-		Spot:                client.config.Spot,
-		ExternalIP:          directorPublicIP,
-		WorkerType:          client.config.WorkerType,
-		PublicCIDR:          publicCIDR,
-		PublicCIDRGateway:   publicCIDRGateway,
-		PublicCIDRStatic:    publicCIDRStatic,
-		PublicCIDRReserved:  publicCIDRReserved,
-		PrivateCIDR:         privateCIDR,
-		PrivateCIDRGateway:  privateCIDRGateway,
-		PrivateCIDRReserved: privateCIDRReserved,
-	}, directorPublicIP, client.config.DirectorPassword, client.config.DirectorCACert)
+// CloudConfigDrift lists the differences between the director's cloud config and the one
+// deploy would upload
+func (client *AWSClient) CloudConfigDrift() ([]string, error) {
+	env, err := client.cloudConfigEnvironment(nil)
+	if err != nil {
+		return nil, err
+	}
+	return cloudConfigDrift(client.boshCLI, env, env.ExternalIP, client.config.DirectorPassword, client.config.DirectorCACert)
+}
+
+// spotBidPrices looks up bids for the worker and compilation instance types. Where a price
+// can't be found the cloud config falls back to a built-in bid, which raw instance types
+// don't have
+func (client *AWSClient) spotBidPrices() (map[string]float64, error) {
+	bids := make(map[string]float64)
+	if !client.config.Spot {
+		return bids, nil
+	}
+	for _, instanceType := range []string{WorkerInstanceType(client.config, iaas.AWS), CompilationInstanceType(client.config, iaas.AWS)} {
+		bid, err := client.provider.SpotBidPrice(instanceType, client.config.AvailabilityZone)
+		if err != nil {
+			if _, ok := aws.FallbackSpotBidPrices[instanceType]; !ok {
+				return nil, err
+			}
+			fmt.Fprintf(client.stderr, "WARNING: using the built-in spot bid for %s: %v\n", instanceType, err)
+			continue
+		}
+		bids[instanceType] = bid
+	}
+	return bids, nil
 }
+
 func (client *AWSClient) uploadConcourseStemcell(bosh boshcli.ICLI) error {
 	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
 	if err != nil {
 		return err
 	}
-	return bosh.UploadConcourseStemcell(aws.Environment{
-		ExternalIP: directorPublicIP,
+	stemcellVersion, err := ConcourseStemcellVersion(client.config, client.provider.IAAS())
+	if err != nil {
+		return err
+	}
+	err = bosh.UploadConcourseStemcell(aws.Environment{
+		ExternalIP:      directorPublicIP,
+		StemcellVersion: stemcellVersion,
 	}, directorPublicIP, client.config.DirectorPassword, client.config.DirectorCACert)
+	if err != nil {
+		return err
+	}
+	for _, stemcellURL := range workerPoolStemcellURLs(client.config, iaas.AWS) {
+		err = bosh.UploadConcourseStemcell(aws.Environment{
+			ExternalIP:  directorPublicIP,
+			StemcellURL: stemcellURL,
+		}, directorPublicIP, client.config.DirectorPassword, client.config.DirectorCACert)
+		if err != nil {
+			return err
+		}
+	}
+	return uploadWorkerPoolReleases(bosh, client.config, directorPublicIP)
 }
@@ -0,0 +1,67 @@
+package bosh
+
+import (
+	"github.com/EngineerBetter/concourse-up/bosh/internal/aws"
+	"github.com/EngineerBetter/concourse-up/bosh/internal/boshcli"
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
+// Export writes the director manifest, cloud config and Concourse manifest, with everything
+// they are deployed with, to dir along with a script deploying them with the bosh CLI
+func (client *AWSClient) Export(dir string, state, creds []byte, redact bool) error {
+	tags, err := directorTags(client.config)
+	if err != nil {
+		return err
+	}
+	directorEnv, err := client.directorEnvironment("")
+	if err != nil {
+		return err
+	}
+	directorManifest, err := boshcli.DirectorManifest(directorEnv, client.config.DirectorPassword, client.config.DirectorCert, client.config.DirectorKey, client.config.DirectorCACert, tags)
+	if err != nil {
+		return err
+	}
+
+	spotBidPrices, err := client.spotBidPrices()
+	if err != nil {
+		return err
+	}
+	cloudConfigEnv, err := client.cloudConfigEnvironment(spotBidPrices)
+	if err != nil {
+		return err
+	}
+	cloudConfig, err := cloudConfigEnv.ConfigureDirectorCloudConfig()
+	if err != nil {
+		return err
+	}
+
+	stemcellVersion, err := ConcourseStemcellVersion(client.config, client.provider.IAAS())
+	if err != nil {
+		return err
+	}
+	stemcell, err := aws.Environment{StemcellVersion: stemcellVersion}.ConfigureConcourseStemcell()
+	if err != nil {
+		return err
+	}
+
+	concourseArgs, concourseVars, err := client.concourseDeployArgs(creds)
+	if err != nil {
+		return err
+	}
+
+	export := deploymentExport{
+		directorIP:       directorEnv.ExternalIP,
+		directorPassword: client.config.DirectorPassword,
+		directorCACert:   client.config.DirectorCACert,
+		directorManifest: directorManifest,
+		directorState:    state,
+		directorCreds:    creds,
+		cloudConfig:      cloudConfig,
+		stemcells:        append([]string{stemcell}, workerPoolStemcellURLs(client.config, iaas.AWS)...),
+		releases:         workerPoolReleaseURLs(client.config),
+		deployment:       concourseDeployment(client.config),
+		concourseArgs:    concourseArgs,
+		concourseVars:    concourseVars,
+	}
+	return export.write(dir, redact)
+}
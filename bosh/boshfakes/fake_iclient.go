@@ -18,6 +18,18 @@ type FakeIClient struct {
 	cleanupReturnsOnCall map[int]struct {
 		result1 error
 	}
+	CloudConfigDriftStub        func() ([]string, error)
+	cloudConfigDriftMutex       sync.RWMutex
+	cloudConfigDriftArgsForCall []struct {
+	}
+	cloudConfigDriftReturns struct {
+		result1 []string
+		result2 error
+	}
+	cloudConfigDriftReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	CreateEnvStub        func([]byte, []byte, string) ([]byte, []byte, error)
 	createEnvMutex       sync.RWMutex
 	createEnvArgsForCall []struct {
@@ -35,6 +47,18 @@ type FakeIClient struct {
 		result2 []byte
 		result3 error
 	}
+	DatabaseVersionStub        func() (string, error)
+	databaseVersionMutex       sync.RWMutex
+	databaseVersionArgsForCall []struct {
+	}
+	databaseVersionReturns struct {
+		result1 string
+		result2 error
+	}
+	databaseVersionReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
 	DeleteStub        func([]byte) ([]byte, error)
 	deleteMutex       sync.RWMutex
 	deleteArgsForCall []struct {
@@ -65,6 +89,20 @@ type FakeIClient struct {
 		result2 []byte
 		result3 error
 	}
+	ExportStub        func(string, []byte, []byte, bool) error
+	exportMutex       sync.RWMutex
+	exportArgsForCall []struct {
+		arg1 string
+		arg2 []byte
+		arg3 []byte
+		arg4 bool
+	}
+	exportReturns struct {
+		result1 error
+	}
+	exportReturnsOnCall map[int]struct {
+		result1 error
+	}
 	InstancesStub        func() ([]bosh.Instance, error)
 	instancesMutex       sync.RWMutex
 	instancesArgsForCall []struct {
@@ -99,6 +137,18 @@ type FakeIClient struct {
 	recreateReturnsOnCall map[int]struct {
 		result1 error
 	}
+	VitalsStub        func() ([]bosh.Instance, error)
+	vitalsMutex       sync.RWMutex
+	vitalsArgsForCall []struct {
+	}
+	vitalsReturns struct {
+		result1 []bosh.Instance
+		result2 error
+	}
+	vitalsReturnsOnCall map[int]struct {
+		result1 []bosh.Instance
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -155,6 +205,61 @@ func (fake *FakeIClient) CleanupReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeIClient) CloudConfigDrift() ([]string, error) {
+	fake.cloudConfigDriftMutex.Lock()
+	ret, specificReturn := fake.cloudConfigDriftReturnsOnCall[len(fake.cloudConfigDriftArgsForCall)]
+	fake.cloudConfigDriftArgsForCall = append(fake.cloudConfigDriftArgsForCall, struct {
+	}{})
+	fake.recordInvocation("CloudConfigDrift", []interface{}{})
+	fake.cloudConfigDriftMutex.Unlock()
+	if fake.CloudConfigDriftStub != nil {
+		return fake.CloudConfigDriftStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.cloudConfigDriftReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) CloudConfigDriftCallCount() int {
+	fake.cloudConfigDriftMutex.RLock()
+	defer fake.cloudConfigDriftMutex.RUnlock()
+	return len(fake.cloudConfigDriftArgsForCall)
+}
+
+func (fake *FakeIClient) CloudConfigDriftCalls(stub func() ([]string, error)) {
+	fake.cloudConfigDriftMutex.Lock()
+	defer fake.cloudConfigDriftMutex.Unlock()
+	fake.CloudConfigDriftStub = stub
+}
+
+func (fake *FakeIClient) CloudConfigDriftReturns(result1 []string, result2 error) {
+	fake.cloudConfigDriftMutex.Lock()
+	defer fake.cloudConfigDriftMutex.Unlock()
+	fake.CloudConfigDriftStub = nil
+	fake.cloudConfigDriftReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) CloudConfigDriftReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.cloudConfigDriftMutex.Lock()
+	defer fake.cloudConfigDriftMutex.Unlock()
+	fake.CloudConfigDriftStub = nil
+	if fake.cloudConfigDriftReturnsOnCall == nil {
+		fake.cloudConfigDriftReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.cloudConfigDriftReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIClient) CreateEnv(arg1 []byte, arg2 []byte, arg3 string) ([]byte, []byte, error) {
 	var arg1Copy []byte
 	if arg1 != nil {
@@ -233,6 +338,61 @@ func (fake *FakeIClient) CreateEnvReturnsOnCall(i int, result1 []byte, result2 [
 	}{result1, result2, result3}
 }
 
+func (fake *FakeIClient) DatabaseVersion() (string, error) {
+	fake.databaseVersionMutex.Lock()
+	ret, specificReturn := fake.databaseVersionReturnsOnCall[len(fake.databaseVersionArgsForCall)]
+	fake.databaseVersionArgsForCall = append(fake.databaseVersionArgsForCall, struct {
+	}{})
+	fake.recordInvocation("DatabaseVersion", []interface{}{})
+	fake.databaseVersionMutex.Unlock()
+	if fake.DatabaseVersionStub != nil {
+		return fake.DatabaseVersionStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.databaseVersionReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) DatabaseVersionCallCount() int {
+	fake.databaseVersionMutex.RLock()
+	defer fake.databaseVersionMutex.RUnlock()
+	return len(fake.databaseVersionArgsForCall)
+}
+
+func (fake *FakeIClient) DatabaseVersionCalls(stub func() (string, error)) {
+	fake.databaseVersionMutex.Lock()
+	defer fake.databaseVersionMutex.Unlock()
+	fake.DatabaseVersionStub = stub
+}
+
+func (fake *FakeIClient) DatabaseVersionReturns(result1 string, result2 error) {
+	fake.databaseVersionMutex.Lock()
+	defer fake.databaseVersionMutex.Unlock()
+	fake.DatabaseVersionStub = nil
+	fake.databaseVersionReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) DatabaseVersionReturnsOnCall(i int, result1 string, result2 error) {
+	fake.databaseVersionMutex.Lock()
+	defer fake.databaseVersionMutex.Unlock()
+	fake.DatabaseVersionStub = nil
+	if fake.databaseVersionReturnsOnCall == nil {
+		fake.databaseVersionReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.databaseVersionReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIClient) Delete(arg1 []byte) ([]byte, error) {
 	var arg1Copy []byte
 	if arg1 != nil {
@@ -379,6 +539,79 @@ func (fake *FakeIClient) DeployReturnsOnCall(i int, result1 []byte, result2 []by
 	}{result1, result2, result3}
 }
 
+func (fake *FakeIClient) Export(arg1 string, arg2 []byte, arg3 []byte, arg4 bool) error {
+	var arg2Copy []byte
+	if arg2 != nil {
+		arg2Copy = make([]byte, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	var arg3Copy []byte
+	if arg3 != nil {
+		arg3Copy = make([]byte, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.exportMutex.Lock()
+	ret, specificReturn := fake.exportReturnsOnCall[len(fake.exportArgsForCall)]
+	fake.exportArgsForCall = append(fake.exportArgsForCall, struct {
+		arg1 string
+		arg2 []byte
+		arg3 []byte
+		arg4 bool
+	}{arg1, arg2Copy, arg3Copy, arg4})
+	fake.recordInvocation("Export", []interface{}{arg1, arg2Copy, arg3Copy, arg4})
+	fake.exportMutex.Unlock()
+	if fake.ExportStub != nil {
+		return fake.ExportStub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.exportReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeIClient) ExportCallCount() int {
+	fake.exportMutex.RLock()
+	defer fake.exportMutex.RUnlock()
+	return len(fake.exportArgsForCall)
+}
+
+func (fake *FakeIClient) ExportCalls(stub func(string, []byte, []byte, bool) error) {
+	fake.exportMutex.Lock()
+	defer fake.exportMutex.Unlock()
+	fake.ExportStub = stub
+}
+
+func (fake *FakeIClient) ExportArgsForCall(i int) (string, []byte, []byte, bool) {
+	fake.exportMutex.RLock()
+	defer fake.exportMutex.RUnlock()
+	argsForCall := fake.exportArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeIClient) ExportReturns(result1 error) {
+	fake.exportMutex.Lock()
+	defer fake.exportMutex.Unlock()
+	fake.ExportStub = nil
+	fake.exportReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIClient) ExportReturnsOnCall(i int, result1 error) {
+	fake.exportMutex.Lock()
+	defer fake.exportMutex.Unlock()
+	fake.ExportStub = nil
+	if fake.exportReturnsOnCall == nil {
+		fake.exportReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.exportReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeIClient) Instances() ([]bosh.Instance, error) {
 	fake.instancesMutex.Lock()
 	ret, specificReturn := fake.instancesReturnsOnCall[len(fake.instancesArgsForCall)]
@@ -509,6 +742,8 @@ func (fake *FakeIClient) Recreate() error {
 func (fake *FakeIClient) RecreateCallCount() int {
 	fake.recreateMutex.RLock()
 	defer fake.recreateMutex.RUnlock()
+	fake.vitalsMutex.RLock()
+	defer fake.vitalsMutex.RUnlock()
 	return len(fake.recreateArgsForCall)
 }
 
@@ -541,23 +776,86 @@ func (fake *FakeIClient) RecreateReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeIClient) Vitals() ([]bosh.Instance, error) {
+	fake.vitalsMutex.Lock()
+	ret, specificReturn := fake.vitalsReturnsOnCall[len(fake.vitalsArgsForCall)]
+	fake.vitalsArgsForCall = append(fake.vitalsArgsForCall, struct {
+	}{})
+	fake.recordInvocation("Vitals", []interface{}{})
+	fake.vitalsMutex.Unlock()
+	if fake.VitalsStub != nil {
+		return fake.VitalsStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.vitalsReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIClient) VitalsCallCount() int {
+	fake.vitalsMutex.RLock()
+	defer fake.vitalsMutex.RUnlock()
+	return len(fake.vitalsArgsForCall)
+}
+
+func (fake *FakeIClient) VitalsCalls(stub func() ([]bosh.Instance, error)) {
+	fake.vitalsMutex.Lock()
+	defer fake.vitalsMutex.Unlock()
+	fake.VitalsStub = stub
+}
+
+func (fake *FakeIClient) VitalsReturns(result1 []bosh.Instance, result2 error) {
+	fake.vitalsMutex.Lock()
+	defer fake.vitalsMutex.Unlock()
+	fake.VitalsStub = nil
+	fake.vitalsReturns = struct {
+		result1 []bosh.Instance
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIClient) VitalsReturnsOnCall(i int, result1 []bosh.Instance, result2 error) {
+	fake.vitalsMutex.Lock()
+	defer fake.vitalsMutex.Unlock()
+	fake.VitalsStub = nil
+	if fake.vitalsReturnsOnCall == nil {
+		fake.vitalsReturnsOnCall = make(map[int]struct {
+			result1 []bosh.Instance
+			result2 error
+		})
+	}
+	fake.vitalsReturnsOnCall[i] = struct {
+		result1 []bosh.Instance
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.cleanupMutex.RLock()
 	defer fake.cleanupMutex.RUnlock()
+	fake.cloudConfigDriftMutex.RLock()
+	defer fake.cloudConfigDriftMutex.RUnlock()
 	fake.createEnvMutex.RLock()
 	defer fake.createEnvMutex.RUnlock()
+	fake.databaseVersionMutex.RLock()
+	defer fake.databaseVersionMutex.RUnlock()
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
 	fake.deployMutex.RLock()
 	defer fake.deployMutex.RUnlock()
+	fake.exportMutex.RLock()
+	defer fake.exportMutex.RUnlock()
 	fake.instancesMutex.RLock()
 	defer fake.instancesMutex.RUnlock()
 	fake.locksMutex.RLock()
 	defer fake.locksMutex.RUnlock()
 	fake.recreateMutex.RLock()
 	defer fake.recreateMutex.RUnlock()
+	fake.vitalsMutex.RLock()
+	defer fake.vitalsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,43 @@
+package bosh
+
+import (
+	"fmt"
+
+	"github.com/EngineerBetter/concourse-up/bosh/internal/aws"
+	"github.com/EngineerBetter/concourse-up/bosh/internal/boshcli"
+	"github.com/EngineerBetter/concourse-up/bosh/internal/gcp"
+	"github.com/EngineerBetter/concourse-up/bundle"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/resource"
+)
+
+// ArtifactURLs returns every release and stemcell the director and concourse deployments download on name
+func ArtifactURLs(name iaas.Name) ([]string, error) {
+	var (
+		env          boshcli.IAASEnvironment
+		versions     []byte
+		directorURLs []string
+	)
+	switch name {
+	case iaas.AWS:
+		env = aws.Environment{}
+		versions = awsConcourseVersions
+		directorURLs = []string{resource.Get(resource.AWSCPI).URL, resource.Get(resource.AWSStemcell).URL}
+	case iaas.GCP:
+		env = gcp.Environment{}
+		versions = gcpConcourseVersions
+		directorURLs = bundle.ArtifactURLs(resource.GCPCPIOps, resource.GCPJumpboxUserOps)
+	default:
+		return nil, fmt.Errorf("IAAS not supported: [%s]", name)
+	}
+
+	stemcell, err := env.ConfigureConcourseStemcell()
+	if err != nil {
+		return nil, err
+	}
+
+	urls := []string{resource.Get(resource.BOSHRelease).URL, resource.Get(resource.BPMRelease).URL}
+	urls = append(urls, directorURLs...)
+	urls = append(urls, stemcell)
+	return append(urls, bundle.ArtifactURLs(string(concourseManifestContents), string(versions))...), nil
+}
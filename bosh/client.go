@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/EngineerBetter/concourse-up/bundle"
 	"github.com/EngineerBetter/concourse-up/iaas"
 
 	"github.com/EngineerBetter/concourse-up/terraform"
@@ -21,6 +22,9 @@ const StateFilename = "director-state.json"
 // CredsFilename is default name for bosh-init creds file
 const CredsFilename = "director-creds.yml"
 
+// ConcourseDatabases are the databases, other than the director's, that Concourse keeps in RDS or Cloud SQL
+var ConcourseDatabases = []string{"concourse_atc", "uaa", "credhub"}
+
 //go:generate counterfeiter . IClient
 // IClient is a client for performing bosh-init commands
 type IClient interface {
@@ -31,13 +35,32 @@ type IClient interface {
 	CreateEnv([]byte, []byte, string) ([]byte, []byte, error)
 	Recreate() error
 	Locks() ([]byte, error)
+	Vitals() ([]Instance, error)
+	DatabaseVersion() (string, error)
+	CloudConfigDrift() ([]string, error)
+	Export(string, []byte, []byte, bool) error
 }
 
 // Instance represents a vm deployed by BOSH
 type Instance struct {
-	Name  string
-	IP    string
-	State string
+	Name     string
+	IP       string
+	State    string
+	Stemcell string
+	Vitals   *Vitals `json:",omitempty"`
+}
+
+// Vitals are the CPU, memory and disk usage BOSH reports for a vm
+type Vitals struct {
+	Load           string
+	CPUUser        string
+	CPUSys         string
+	CPUWait        string
+	Memory         string
+	Swap           string
+	SystemDisk     string
+	EphemeralDisk  string
+	PersistentDisk string
 }
 
 // ClientFactory creates a new IClient
@@ -50,7 +73,7 @@ func New(config config.Config, outputs terraform.Outputs, stdout, stderr io.Writ
 		return nil, err
 	}
 
-	boshCLI, err := boshcli.New(boshcli.DownloadBOSH())
+	boshCLI, err := boshcli.New(boshcli.DownloadBOSH(), boshcli.Deployment(concourseDeployment(config)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create boshCLI: [%v]", err)
 	}
@@ -64,19 +87,19 @@ func New(config config.Config, outputs terraform.Outputs, stdout, stderr io.Writ
 	return nil, fmt.Errorf("IAAS not supported: %s", provider.IAAS())
 }
 
-func instances(boshCLI boshcli.ICLI, ip, password, ca string) ([]Instance, error) {
+func instances(boshCLI boshcli.ICLI, ip, password, ca string, flags ...string) ([]Instance, error) {
 	output := new(bytes.Buffer)
 
 	if err := boshCLI.RunAuthenticatedCommand(
-		"instances",
+		"vms",
 		ip,
 		password,
 		ca,
 		false,
 		output,
-		"--json",
+		append([]string{"--json"}, flags...)...,
 	); err != nil {
-		return nil, fmt.Errorf("Error [%s] running `bosh instances`. stdout: [%s]", err, output.String())
+		return nil, fmt.Errorf("Error [%s] running `bosh vms`. stdout: [%s]", err, output.String())
 	}
 
 	jsonOutput := struct {
@@ -85,6 +108,17 @@ func instances(boshCLI boshcli.ICLI, ip, password, ca string) ([]Instance, error
 				Instance     string `json:"instance"`
 				IPs          string `json:"ips"`
 				ProcessState string `json:"process_state"`
+				Stemcell     string `json:"stemcell"`
+				// Only present with --vitals
+				Load           string `json:"load_1m_5m_15m"`
+				CPUUser        string `json:"cpu_user"`
+				CPUSys         string `json:"cpu_sys"`
+				CPUWait        string `json:"cpu_wait"`
+				Memory         string `json:"memory_usage"`
+				Swap           string `json:"swap_usage"`
+				SystemDisk     string `json:"system_disk_usage"`
+				EphemeralDisk  string `json:"ephemeral_disk_usage"`
+				PersistentDisk string `json:"persistent_disk_usage"`
 			} `json:"Rows"`
 		} `json:"Tables"`
 	}{}
@@ -97,11 +131,27 @@ func instances(boshCLI boshcli.ICLI, ip, password, ca string) ([]Instance, error
 
 	for _, table := range jsonOutput.Tables {
 		for _, row := range table.Rows {
-			instances = append(instances, Instance{
-				Name:  row.Instance,
-				IP:    row.IPs,
-				State: row.ProcessState,
-			})
+			instance := Instance{
+				Name:     row.Instance,
+				IP:       row.IPs,
+				State:    row.ProcessState,
+				Stemcell: row.Stemcell,
+			}
+			vitals := Vitals{
+				Load:           row.Load,
+				CPUUser:        row.CPUUser,
+				CPUSys:         row.CPUSys,
+				CPUWait:        row.CPUWait,
+				Memory:         row.Memory,
+				Swap:           row.Swap,
+				SystemDisk:     row.SystemDisk,
+				EphemeralDisk:  row.EphemeralDisk,
+				PersistentDisk: row.PersistentDisk,
+			}
+			if vitals != (Vitals{}) {
+				instance.Vitals = &vitals
+			}
+			instances = append(instances, instance)
 		}
 	}
 
@@ -118,15 +168,33 @@ func saveFilesToWorkingDir(workingdir workingdir.IClient, provider iaas.Provider
 		GCP: gcpConcourseSHAs,
 	}).([]byte)
 
+	versions, err := bundle.Rewrite(string(concourseVersionsContents))
+	if err != nil {
+		return err
+	}
+	manifest, err := bundle.Rewrite(string(concourseManifestContents))
+	if err != nil {
+		return err
+	}
+
 	filesToSave := map[string][]byte{
-		concourseVersionsFilename:      concourseVersionsContents,
+		concourseVersionsFilename:      []byte(versions),
 		concourseSHAsFilename:          concourseSHAsContents,
-		concourseManifestFilename:      concourseManifestContents,
+		concourseManifestFilename:      []byte(manifest),
 		concourseCompatibilityFilename: concourseCompatibility,
 		concourseGrafanaFilename:       concourseGrafana,
 		concourseGitHubAuthFilename:    concourseGitHubAuth,
 		credsFilename:                  creds,
 		extraTagsFilename:              extraTags,
+		removeCredhubFilename:          removeCredhub,
+		awsSecretsManagerFilename:      awsSecretsManager,
+		awsSSMFilename:                 awsSSM,
+		vaultFilename:                  vault,
+		workerVMExtensionFilename:      workerVMExtension,
+		oldEncryptionKeyFilename:       oldEncryptionKey,
+		workerPersistentDiskFilename:   workerPersistentDisk,
+		windowsWorkersFilename:         windowsWorkers,
+		armWorkersFilename:             armWorkers,
 	}
 
 	for filename, contents := range filesToSave {
@@ -99,15 +99,16 @@ sWbB3FCIsym1FXB+eRnVF3Y15RwBWWKA5RfwUNpEXFxtv24tQ8jrdA==
 			Context("When instances are found", func() {
 				JustBeforeEach(func() {
 					boshCLI.RunAuthenticatedCommandStub = func(action, ip, password, ca string, detach bool, stdout io.Writer, flags ...string) error {
-						stdout.Write([]byte("{\"Tables\":[{\"Rows\": [{\"instance\": \"foo\",\"ips\": \"1.2.3.4\", \"process_state\": \"bar\"}]}]}"))
+						stdout.Write([]byte("{\"Tables\":[{\"Rows\": [{\"instance\": \"foo\",\"ips\": \"1.2.3.4\", \"process_state\": \"bar\", \"stemcell\": \"bosh-aws-xen-hvm-ubuntu-xenial-go_agent/250.17\"}]}]}"))
 						return nil
 					}
 				})
 				It("returns them", func() {
 					expectedInstance := bosh.Instance{
-						Name:  "foo",
-						IP:    "1.2.3.4",
-						State: "bar",
+						Name:     "foo",
+						IP:       "1.2.3.4",
+						State:    "bar",
+						Stemcell: "bosh-aws-xen-hvm-ubuntu-xenial-go_agent/250.17",
 					}
 
 					client := buildClient()
@@ -117,6 +118,34 @@ sWbB3FCIsym1FXB+eRnVF3Y15RwBWWKA5RfwUNpEXFxtv24tQ8jrdA==
 					Expect(instances).To(Equal([]bosh.Instance{expectedInstance}))
 				})
 			})
+			Context("When vitals are requested", func() {
+				var flagsPassed []string
+				JustBeforeEach(func() {
+					boshCLI.RunAuthenticatedCommandStub = func(action, ip, password, ca string, detach bool, stdout io.Writer, flags ...string) error {
+						flagsPassed = flags
+						stdout.Write([]byte(`{"Tables":[{"Rows": [{"instance": "web/0", "ips": "10.0.0.7", "process_state": "running", "cpu_user": "1.2%", "cpu_sys": "0.4%", "cpu_wait": "0.0%", "memory_usage": "24% (934.7 MB)", "persistent_disk_usage": ""}]}]}`))
+						return nil
+					}
+				})
+				It("returns the instances with their vitals", func() {
+					client := buildClient()
+					instances, err := client.Vitals()
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(flagsPassed).To(Equal([]string{"--json", "--vitals"}))
+					Expect(instances).To(Equal([]bosh.Instance{{
+						Name:  "web/0",
+						IP:    "10.0.0.7",
+						State: "running",
+						Vitals: &bosh.Vitals{
+							CPUUser: "1.2%",
+							CPUSys:  "0.4%",
+							CPUWait: "0.0%",
+							Memory:  "24% (934.7 MB)",
+						},
+					}}))
+				})
+			})
 		})
 	})
 })
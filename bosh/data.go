@@ -2,7 +2,6 @@ package bosh
 
 const concourseManifestFilename = "concourse.yml"
 const credsFilename = "concourse-creds.yml"
-const concourseDeploymentName = "concourse"
 const concourseVersionsFilename = "versions.json"
 const concourseSHAsFilename = "shas.json"
 const concourseGrafanaFilename = "grafana_dashboard.yml"
@@ -10,15 +9,39 @@ const concourseCompatibilityFilename = "cup_compatibility.yml"
 const concourseGitHubAuthFilename = "github-auth.yml"
 const extraTagsFilename = "extra_tags.yml"
 const uaaCertFilename = "uaa-cert.yml"
+const removeCredhubFilename = "remove-credhub.yml"
+const awsSecretsManagerFilename = "aws-secretsmanager.yml"
+const awsSSMFilename = "aws-ssm.yml"
+const vaultFilename = "vault.yml"
+const workerVMExtensionFilename = "worker-vm-extension.yml"
+const concourseVersionFilename = "concourse-version.json"
+const oldEncryptionKeyFilename = "old-encryption-key.yml"
+const workerPersistentDiskFilename = "worker-persistent-disk.yml"
+const windowsWorkersFilename = "windows-workers.yml"
+const armWorkersFilename = "arm-workers.yml"
 
 //go:generate go-bindata -pkg $GOPACKAGE -ignore \.git assets/... ../../concourse-up-ops/... ../resource/assets/...
 var concourseGrafana = MustAsset("assets/grafana_dashboard.yml")
 var concourseCompatibility = MustAsset("assets/ops/cup_compatibility.yml")
 var concourseGitHubAuth = MustAsset("assets/ops/github-auth.yml")
 var extraTags = MustAsset("assets/ops/extra_tags.yml")
+var removeCredhub = MustAsset("assets/ops/remove-credhub.yml")
+var awsSecretsManager = MustAsset("assets/ops/aws-secretsmanager.yml")
+var awsSSM = MustAsset("assets/ops/aws-ssm.yml")
+var vault = MustAsset("assets/ops/vault.yml")
+var workerVMExtension = MustAsset("assets/ops/worker-vm-extension.yml")
+var oldEncryptionKey = MustAsset("assets/ops/old-encryption-key.yml")
+var workerPersistentDisk = MustAsset("assets/ops/worker-persistent-disk.yml")
+var windowsWorkers = MustAsset("assets/ops/windows-workers.yml")
+var armWorkers = MustAsset("assets/ops/arm-workers.yml")
 var concourseManifestContents = MustAsset("../../concourse-up-ops/manifest.yml")
 var awsConcourseVersions = MustAsset("../../concourse-up-ops/ops/versions-aws.json")
 var awsConcourseSHAs = MustAsset("../../concourse-up-ops/ops/shas-aws.json")
 var gcpConcourseVersions = MustAsset("../../concourse-up-ops/ops/versions-gcp.json")
 var gcpConcourseSHAs = MustAsset("../../concourse-up-ops/ops/shas-gcp.json")
 var uaaCert = MustAsset("../resource/assets/gcp/uaa-cert.yml")
+
+// compatibilityOps maps the compatibility_ops named in the Concourse catalogue to their contents
+var compatibilityOps = map[string][]byte{
+	concourseCompatibilityFilename: concourseCompatibility,
+}
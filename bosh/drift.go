@@ -0,0 +1,126 @@
+package bosh
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/EngineerBetter/concourse-up/bosh/internal/boshcli"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// cloudConfigDrift compares the cloud config the director has with the one deploy would
+// upload, returning a line for each difference. Spot bids are looked up on every deploy, so
+// aren't compared
+func cloudConfigDrift(boshCLI boshcli.ICLI, env boshcli.IAASEnvironment, ip, password, ca string) ([]string, error) {
+	expected, err := env.ConfigureDirectorCloudConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	output := new(bytes.Buffer)
+	if err = boshCLI.RunAuthenticatedCommand("cloud-config", ip, password, ca, false, output, "--json"); err != nil {
+		return nil, fmt.Errorf("Error [%s] running `bosh cloud-config`. stdout: [%s]", err, output.String())
+	}
+	var jsonOutput struct {
+		Blocks []string
+	}
+	if err = json.NewDecoder(output).Decode(&jsonOutput); err != nil {
+		return nil, err
+	}
+	if len(jsonOutput.Blocks) == 0 {
+		return []string{"the director has no cloud config"}, nil
+	}
+
+	var want, got interface{}
+	if err = yaml.Unmarshal([]byte(expected), &want); err != nil {
+		return nil, err
+	}
+	if err = yaml.Unmarshal([]byte(jsonOutput.Blocks[0]), &got); err != nil {
+		return nil, err
+	}
+	return diffYAML("", want, got), nil
+}
+
+// diffYAML describes how got differs from want. Lists of maps with names, such as vm_types,
+// are matched up by name rather than position
+func diffYAML(path string, want, got interface{}) []string {
+	switch w := want.(type) {
+	case map[interface{}]interface{}:
+		g, ok := got.(map[interface{}]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected %v, got %v", path, want, got)}
+		}
+		return diffYAMLMaps(path, w, g)
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if ok {
+			wNamed, wOK := byName(w)
+			gNamed, gOK := byName(g)
+			if wOK && gOK {
+				return diffYAMLMaps(path, wNamed, gNamed)
+			}
+		}
+	}
+	if !reflect.DeepEqual(want, got) {
+		return []string{fmt.Sprintf("%s: expected %v, got %v", path, want, got)}
+	}
+	return nil
+}
+
+func diffYAMLMaps(path string, want, got map[interface{}]interface{}) []string {
+	keys := map[string]interface{}{}
+	for k := range want {
+		keys[fmt.Sprint(k)] = k
+	}
+	for k := range got {
+		keys[fmt.Sprint(k)] = k
+	}
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var diffs []string
+	for _, name := range names {
+		if name == "spot_bid_price" {
+			continue
+		}
+		k := keys[name]
+		p := name
+		if path != "" {
+			p = path + "/" + name
+		}
+		w, inWant := want[k]
+		g, inGot := got[k]
+		switch {
+		case !inGot:
+			diffs = append(diffs, fmt.Sprintf("%s: missing from the director", p))
+		case !inWant:
+			diffs = append(diffs, fmt.Sprintf("%s: not expected, got %v", p, g))
+		default:
+			diffs = append(diffs, diffYAML(p, w, g)...)
+		}
+	}
+	return diffs
+}
+
+// byName indexes a list by the name of each element, failing if any element is unnamed
+func byName(list []interface{}) (map[interface{}]interface{}, bool) {
+	named := make(map[interface{}]interface{}, len(list))
+	for _, element := range list {
+		m, ok := element.(map[interface{}]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, ok := m["name"].(string)
+		if !ok {
+			return nil, false
+		}
+		named[name] = m
+	}
+	return named, true
+}
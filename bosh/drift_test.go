@@ -0,0 +1,110 @@
+package bosh
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/EngineerBetter/concourse-up/bosh/internal/boshcli/boshclifakes"
+	"github.com/EngineerBetter/concourse-up/iaas"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeCloudConfigEnvironment string
+
+func (e fakeCloudConfigEnvironment) ConfigureDirectorManifestCPI() (string, error) { return "", nil }
+func (e fakeCloudConfigEnvironment) ConfigureDirectorCloudConfig() (string, error) {
+	return string(e), nil
+}
+func (e fakeCloudConfigEnvironment) ConfigureConcourseStemcell() (string, error) { return "", nil }
+func (e fakeCloudConfigEnvironment) IAASCheck() iaas.Name                        { return iaas.AWS }
+
+var _ = Describe("Drift", func() {
+	Describe("cloudConfigDrift", func() {
+		const expected = `
+vm_types:
+- name: concourse-large
+  cloud_properties:
+    instance_type: m4.large
+    spot_bid_price: 0.13
+- name: compilation
+  cloud_properties:
+    instance_type: m4.large
+disk_types:
+- name: default
+  disk_size: 50_000
+`
+		var boshCLI *boshclifakes.FakeICLI
+
+		var directorCloudConfig = func(cloudConfig string) {
+			boshCLI.RunAuthenticatedCommandStub = func(action, ip, password, ca string, detach bool, stdout io.Writer, flags ...string) error {
+				Expect(action).To(Equal("cloud-config"))
+				Expect(flags).To(ConsistOf("--json"))
+				if cloudConfig == "" {
+					_, err := stdout.Write([]byte(`{"Tables":null,"Blocks":null,"Lines":["Succeeded"]}`))
+					return err
+				}
+				_, err := stdout.Write([]byte(`{"Tables":null,"Blocks":[` + jsonString(cloudConfig) + `],"Lines":["Succeeded"]}`))
+				return err
+			}
+		}
+
+		BeforeEach(func() {
+			boshCLI = &boshclifakes.FakeICLI{}
+		})
+
+		It("Finds nothing when the cloud configs match, apart from spot bids and order", func() {
+			directorCloudConfig(`
+disk_types:
+- name: default
+  disk_size: 50000
+vm_types:
+- name: compilation
+  cloud_properties:
+    instance_type: m4.large
+- name: concourse-large
+  cloud_properties:
+    instance_type: m4.large
+    spot_bid_price: 0.2
+`)
+			drift, err := cloudConfigDrift(boshCLI, fakeCloudConfigEnvironment(expected), "1.2.3.4", "password", "ca")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drift).To(BeEmpty())
+		})
+
+		It("Lists changed, missing and unexpected values", func() {
+			directorCloudConfig(`
+vm_types:
+- name: concourse-large
+  cloud_properties:
+    instance_type: m5.large
+- name: hand-made
+  cloud_properties:
+    instance_type: t2.nano
+disk_types:
+- name: default
+  disk_size: 50_000
+`)
+			drift, err := cloudConfigDrift(boshCLI, fakeCloudConfigEnvironment(expected), "1.2.3.4", "password", "ca")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drift).To(Equal([]string{
+				"vm_types/compilation: missing from the director",
+				"vm_types/concourse-large/cloud_properties/instance_type: expected m4.large, got m5.large",
+				"vm_types/hand-made: not expected, got map[cloud_properties:map[instance_type:t2.nano] name:hand-made]",
+			}))
+		})
+
+		It("Reports a director without a cloud config", func() {
+			directorCloudConfig("")
+			drift, err := cloudConfigDrift(boshCLI, fakeCloudConfigEnvironment(expected), "1.2.3.4", "password", "ca")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drift).To(ConsistOf("the director has no cloud config"))
+		})
+	})
+})
+
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,167 @@
+package bosh
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/EngineerBetter/concourse-up/util/yaml"
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+// DeployScriptFilename is the script Export writes to drive the director and Concourse with the bosh CLI
+const DeployScriptFilename = "deploy.sh"
+
+var secretKey = regexp.MustCompile(`(?i)(password|secret|private_key|encryption_key|token|json_key|credentials)`)
+
+// isSecret picks out the values in manifests and vars that are credentials
+func isSecret(key string) bool {
+	return secretKey.MatchString(key)
+}
+
+// isCredential picks out the values in a vars store that are credentials, which is everything
+// bosh generates apart from certificates and public keys
+func isCredential(key string) bool {
+	switch key {
+	case "ca", "certificate", "public_key", "public_key_fingerprint":
+		return false
+	}
+	return true
+}
+
+// deploymentExport is everything needed to deploy the director and Concourse with the bosh CLI
+type deploymentExport struct {
+	directorIP       string
+	directorPassword string
+	directorCACert   string
+	directorManifest string
+	directorState    []byte
+	directorCreds    []byte
+	cloudConfig      string
+	stemcells        []string
+	releases         []string
+	deployment       string
+	concourseArgs    []string
+	concourseVars    map[string]interface{}
+}
+
+// write lays out the export in dir, along with a script running the bosh commands deploy would
+func (e *deploymentExport) write(dir string, redact bool) error {
+	files := map[string][]byte{
+		"director/director.yml": []byte(e.directorManifest),
+		"director/creds.yml":    e.directorCreds,
+		"director/ca.pem":       []byte(e.directorCACert),
+		"cloud-config.yml":      []byte(e.cloudConfig),
+	}
+	if len(e.directorState) > 0 {
+		files["director/state.json"] = e.directorState
+	}
+
+	script := new(bytes.Buffer)
+	script.WriteString("#!/bin/sh\n")
+	script.WriteString("# Deploys the director and Concourse the way concourse-up does, once terraform has been applied\n")
+	script.WriteString("set -eu\n")
+	script.WriteString("cd \"$(dirname \"$0\")\"\n\n")
+	script.WriteString("bosh create-env director/director.yml --state director/state.json --vars-store director/creds.yml\n\n")
+	fmt.Fprintf(script, "export BOSH_ENVIRONMENT=https://%s\n", e.directorIP)
+	script.WriteString("export BOSH_CA_CERT=director/ca.pem\n")
+	script.WriteString("export BOSH_CLIENT=admin\n")
+	if redact {
+		script.WriteString(": \"${BOSH_CLIENT_SECRET:?set BOSH_CLIENT_SECRET to the director admin password}\"\n\n")
+	} else {
+		fmt.Fprintf(script, "export BOSH_CLIENT_SECRET=%s\n\n", shellQuote(e.directorPassword))
+	}
+	script.WriteString("bosh -n update-cloud-config cloud-config.yml\n")
+	for _, stemcell := range e.stemcells {
+		fmt.Fprintf(script, "bosh -n upload-stemcell %s\n", shellQuote(stemcell))
+	}
+	for _, release := range e.releases {
+		fmt.Fprintf(script, "bosh -n upload-release %s\n", shellQuote(release))
+	}
+
+	fmt.Fprintf(script, "bosh -n -d %s deploy", e.deployment)
+	for i, arg := range e.concourseArgs {
+		if strings.HasPrefix(arg, "--") {
+			fmt.Fprintf(script, " \\\n  %s", arg)
+			continue
+		}
+		contents, err := ioutil.ReadFile(arg)
+		if err != nil {
+			return err
+		}
+		name := "concourse/" + filepath.Base(arg)
+		if i > 0 && e.concourseArgs[i-1] == "--vars-store" {
+			name = "concourse/creds.yml"
+			if redact {
+				contents, err = yaml.Redact(contents, isCredential)
+				if err != nil {
+					return fmt.Errorf("failed to redact %s: [%v]", name, err)
+				}
+			}
+		}
+		files[name] = contents
+		fmt.Fprintf(script, " %s", name)
+	}
+	concourseVars, err := varsFile(e.concourseVars)
+	if err != nil {
+		return err
+	}
+	files["concourse/vars.yml"] = concourseVars
+	script.WriteString(" \\\n  --vars-file concourse/vars.yml\n")
+
+	if redact {
+		for name, isRedacted := range map[string]func(string) bool{
+			"director/director.yml": isSecret,
+			"director/creds.yml":    isCredential,
+			"concourse/vars.yml":    isSecret,
+		} {
+			files[name], err = yaml.Redact(files[name], isRedacted)
+			if err != nil {
+				return fmt.Errorf("failed to redact %s: [%v]", name, err)
+			}
+		}
+	}
+
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return err
+		}
+		if err = ioutil.WriteFile(path, contents, 0600); err != nil {
+			return err
+		}
+	}
+	return ioutil.WriteFile(filepath.Join(dir, DeployScriptFilename), script.Bytes(), 0700)
+}
+
+// varsFile renders vars as a file for --vars-file, nesting dotted names the way --var does
+func varsFile(vars map[string]interface{}) ([]byte, error) {
+	nested := map[string]interface{}{}
+	for name, value := range vars {
+		if name == "tags" {
+			var tags map[string]interface{}
+			if err := yamlv2.Unmarshal([]byte(fmt.Sprint(value)), &tags); err != nil {
+				return nil, err
+			}
+			value = tags
+		}
+		pieces := strings.Split(name, ".")
+		m := nested
+		for _, p := range pieces[:len(pieces)-1] {
+			if _, ok := m[p].(map[string]interface{}); !ok {
+				m[p] = map[string]interface{}{}
+			}
+			m = m[p].(map[string]interface{})
+		}
+		m[pieces[len(pieces)-1]] = value
+	}
+	return yamlv2.Marshal(nested)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,124 @@
+package bosh
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Export", func() {
+	Describe("deploymentExport", func() {
+		var dir, workingDir string
+		var export deploymentExport
+
+		var read = func(name string) string {
+			contents, err := ioutil.ReadFile(filepath.Join(dir, name))
+			Expect(err).ToNot(HaveOccurred())
+			return string(contents)
+		}
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "")
+			Expect(err).ToNot(HaveOccurred())
+			workingDir, err = ioutil.TempDir("", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			files := map[string]string{
+				"concourse.yml":       "name: ((deployment_name))\n",
+				"concourse-creds.yml": "atc_password: s3cret\nworker_key:\n  private_key: a-key\n  public_key: ssh-rsa\n",
+				"extra_tags.yml":      "- type: replace\n",
+			}
+			for name, contents := range files {
+				Expect(ioutil.WriteFile(filepath.Join(workingDir, name), []byte(contents), 0600)).To(Succeed())
+			}
+
+			export = deploymentExport{
+				directorIP:       "1.2.3.4",
+				directorPassword: "admin-password",
+				directorCACert:   "a-ca",
+				directorManifest: "name: bosh\ncloud_provider:\n  properties:\n    aws:\n      secret_access_key: a-secret\n      access_key_id: an-id\n    registry:\n      password: ((registry_password))\n",
+				directorState:    []byte(`{"director_id":"an-id"}`),
+				directorCreds:    []byte("registry_password: r3gistry\ndirector_ssl:\n  ca: a-ca\n  private_key: a-key\n"),
+				cloudConfig:      "vm_types: []\n",
+				stemcells:        []string{"https://example.com/stemcell.tgz"},
+				releases:         []string{"https://example.com/concourse-arm64.tgz"},
+				deployment:       "concourse",
+				concourseArgs: []string{
+					filepath.Join(workingDir, "concourse.yml"),
+					"--vars-store",
+					filepath.Join(workingDir, "concourse-creds.yml"),
+					"--ops-file",
+					filepath.Join(workingDir, "extra_tags.yml"),
+				},
+				concourseVars: map[string]interface{}{
+					"deployment_name":          "concourse",
+					"worker_count":             2,
+					"external_tls.private_key": "tls-key",
+					"tags":                     "{concourse-up-project: a-project,concourse-up-component: concourse}",
+				},
+			}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+			os.RemoveAll(workingDir)
+		})
+
+		It("Writes everything deploy uses, with a script running the bosh commands", func() {
+			Expect(export.write(dir, false)).To(Succeed())
+
+			Expect(read("director/director.yml")).To(Equal(export.directorManifest))
+			Expect(read("director/state.json")).To(Equal(`{"director_id":"an-id"}`))
+			Expect(read("director/creds.yml")).To(ContainSubstring("registry_password: r3gistry"))
+			Expect(read("director/ca.pem")).To(Equal("a-ca"))
+			Expect(read("cloud-config.yml")).To(Equal("vm_types: []\n"))
+			Expect(read("concourse/concourse.yml")).To(Equal("name: ((deployment_name))\n"))
+			Expect(read("concourse/creds.yml")).To(ContainSubstring("atc_password: s3cret"))
+			Expect(read("concourse/extra_tags.yml")).To(Equal("- type: replace\n"))
+			Expect(read("concourse/vars.yml")).To(Equal(`deployment_name: concourse
+external_tls:
+  private_key: tls-key
+tags:
+  concourse-up-component: concourse
+  concourse-up-project: a-project
+worker_count: 2
+`))
+
+			script := read(DeployScriptFilename)
+			Expect(script).To(ContainSubstring("bosh create-env director/director.yml --state director/state.json --vars-store director/creds.yml\n"))
+			Expect(script).To(ContainSubstring("export BOSH_ENVIRONMENT=https://1.2.3.4\n"))
+			Expect(script).To(ContainSubstring("export BOSH_CLIENT_SECRET='admin-password'\n"))
+			Expect(script).To(ContainSubstring("bosh -n upload-stemcell 'https://example.com/stemcell.tgz'\n"))
+			Expect(script).To(ContainSubstring("bosh -n upload-release 'https://example.com/concourse-arm64.tgz'\n"))
+			Expect(script).To(ContainSubstring(`bosh -n -d concourse deploy concourse/concourse.yml \
+  --vars-store concourse/creds.yml \
+  --ops-file concourse/extra_tags.yml \
+  --vars-file concourse/vars.yml
+`))
+			info, err := os.Stat(filepath.Join(dir, DeployScriptFilename))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0700)))
+		})
+
+		It("Redacts secrets, leaving certificates, public keys and variable references", func() {
+			Expect(export.write(dir, true)).To(Succeed())
+
+			director := read("director/director.yml")
+			Expect(director).To(ContainSubstring("secret_access_key: REDACTED"))
+			Expect(director).To(ContainSubstring("access_key_id: an-id"))
+			Expect(director).To(ContainSubstring("password: ((registry_password))"))
+			Expect(read("director/creds.yml")).To(Equal("registry_password: REDACTED\ndirector_ssl:\n  ca: a-ca\n  private_key: REDACTED\n"))
+			Expect(read("concourse/creds.yml")).To(Equal("atc_password: REDACTED\nworker_key:\n  private_key: REDACTED\n  public_key: ssh-rsa\n"))
+			Expect(read("concourse/vars.yml")).To(ContainSubstring("private_key: REDACTED"))
+			Expect(read("concourse/vars.yml")).To(ContainSubstring("deployment_name: concourse"))
+
+			script := read(DeployScriptFilename)
+			Expect(script).ToNot(ContainSubstring("admin-password"))
+			Expect(script).To(ContainSubstring(`"${BOSH_CLIENT_SECRET:?`))
+		})
+	})
+})
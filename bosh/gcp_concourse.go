@@ -8,38 +8,67 @@ import (
 )
 
 func (client *GCPClient) deployConcourse(creds []byte, detach bool) ([]byte, error) {
+	flagFiles, vmap, err := client.concourseDeployArgs(creds)
+	if err != nil {
+		return nil, err
+	}
+
+	vs := vars(vmap)
 
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve director IP: [%v]", err)
+	}
+
+	err = client.boshCLI.RunAuthenticatedCommand(
+		"deploy",
+		directorPublicIP,
+		client.config.DirectorPassword,
+		client.config.DirectorCACert,
+		detach,
+		os.Stdout,
+		append(flagFiles, vs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run bosh deploy with commands %+v: [%v]", flagFiles, err)
+	}
+
+	return ioutil.ReadFile(client.workingdir.PathInWorkingDir(credsFilename))
+}
+
+// concourseDeployArgs returns the manifest, ops files and vars files Concourse is deployed
+// with, having saved them to the working directory, along with the vars to pass alongside
+func (client *GCPClient) concourseDeployArgs(creds []byte) ([]string, map[string]interface{}, error) {
 	err := saveFilesToWorkingDir(client.workingdir, client.provider, creds)
 	if err != nil {
-		return nil, fmt.Errorf("failed saving files to working directory in deployConcourse: [%v]", err)
+		return nil, nil, fmt.Errorf("failed saving files to working directory in deployConcourse: [%v]", err)
 	}
 
 	uaaCertPath, err := client.workingdir.SaveFileToWorkingDir(uaaCertFilename, uaaCert)
 	if err != nil {
-		return []byte{}, err
+		return nil, nil, err
 	}
 
 	boshDBAddress, err := client.outputs.Get("BoshDBAddress")
 	if err != nil {
-		return []byte{}, err
+		return nil, nil, err
 	}
 	atcPublicIP, err := client.outputs.Get("ATCPublicIP")
 	if err != nil {
-		return []byte{}, err
+		return nil, nil, err
 	}
 
 	networkName, err := client.outputs.Get("Network")
 	if err != nil {
-		return []byte{}, err
+		return nil, nil, err
 	}
 
 	SQLServerCert, err := client.outputs.Get("SQLServerCert")
 	if err != nil {
-		return []byte{}, err
+		return nil, nil, err
 	}
 
 	vmap := map[string]interface{}{
-		"deployment_name":          concourseDeploymentName,
+		"deployment_name":          concourseDeployment(client.config),
 		"domain":                   client.config.Domain,
 		"project":                  client.config.Project,
 		"web_network_name":         "public",
@@ -49,8 +78,8 @@ func (client *GCPClient) deployConcourse(creds []byte, detach bool) ([]byte, err
 		"postgres_port":            "5432",
 		"postgres_password":        client.config.RDSPassword,
 		"postgres_ca_cert":         SQLServerCert,
-		"web_vm_type":              "concourse-web-" + client.config.ConcourseWebSize,
-		"worker_vm_type":           "concourse-" + client.config.ConcourseWorkerSize,
+		"web_vm_type":              webVMType(client.config),
+		"worker_vm_type":           workerVMType(client.config),
 		"worker_count":             client.config.ConcourseWorkerCount,
 		"atc_eip":                  atcPublicIP,
 		"external_tls.certificate": client.config.ConcourseCert,
@@ -75,6 +104,18 @@ func (client *GCPClient) deployConcourse(creds []byte, detach bool) ([]byte, err
 		client.workingdir.PathInWorkingDir(concourseGrafanaFilename),
 	}
 
+	concourseVersionFlags, err := concourseVersionOps(client.config, client.workingdir)
+	if err != nil {
+		return nil, nil, err
+	}
+	flagFiles = append(flagFiles, concourseVersionFlags...)
+
+	stemcellVersionFlags, err := stemcellVersionOps(client.config, client.provider.IAAS(), client.workingdir)
+	if err != nil {
+		return nil, nil, err
+	}
+	flagFiles = append(flagFiles, stemcellVersionFlags...)
+
 	if client.config.ConcoursePassword != "" {
 		vmap["atc_password"] = client.config.ConcoursePassword
 	}
@@ -85,33 +126,32 @@ func (client *GCPClient) deployConcourse(creds []byte, detach bool) ([]byte, err
 		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(concourseGitHubAuthFilename))
 	}
 
-	t, err1 := client.buildTagsYaml(vmap["project"], "concourse")
-	if err1 != nil {
-		return nil, err
+	credentialManagerFlags, err := credentialManagerOps(client.config, client.workingdir, vmap)
+	if err != nil {
+		return nil, nil, err
 	}
-	vmap["tags"] = t
-	flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(extraTagsFilename))
+	flagFiles = append(flagFiles, credentialManagerFlags...)
+	flagFiles = append(flagFiles, oldEncryptionKeyOps(client.config, client.workingdir, vmap)...)
+	flagFiles = append(flagFiles, workerPersistentDiskOps(client.config, client.workingdir)...)
 
-	vs := vars(vmap)
-
-	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	workerPoolFlags, err := workerPoolOps(client.config, client.workingdir, vmap)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve director IP: [%v]", err)
+		return nil, nil, err
 	}
+	flagFiles = append(flagFiles, workerPoolFlags...)
 
-	err = client.boshCLI.RunAuthenticatedCommand(
-		"deploy",
-		directorPublicIP,
-		client.config.DirectorPassword,
-		client.config.DirectorCACert,
-		detach,
-		os.Stdout,
-		append(flagFiles, vs...)...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to run bosh deploy with commands %+v: [%v]", flagFiles, err)
+	if len(client.config.WorkerServiceAccountRoles) > 0 {
+		flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(workerVMExtensionFilename))
 	}
 
-	return ioutil.ReadFile(client.workingdir.PathInWorkingDir(credsFilename))
+	t, err1 := client.buildTagsYaml(vmap["project"], "concourse")
+	if err1 != nil {
+		return nil, nil, err1
+	}
+	vmap["tags"] = t
+	flagFiles = append(flagFiles, "--ops-file", client.workingdir.PathInWorkingDir(extraTagsFilename))
+
+	return flagFiles, vmap, nil
 }
 
 func (client *GCPClient) buildTagsYaml(project interface{}, component string) (string, error) {
@@ -1,5 +1,22 @@
 package bosh
 
+import "github.com/EngineerBetter/concourse-up/config"
+
 func (client *GCPClient) createDefaultDatabases() error {
 	return client.provider.CreateDatabases(client.config.RDSDefaultDatabaseName, client.config.RDSUsername, client.config.RDSPassword)
 }
+
+// importDatabases loads the exports taken by destroy into a new Cloud SQL instance
+func (client *GCPClient) importDatabases() error {
+	return client.provider.ImportDatabases(client.config.RDSDefaultDatabaseName, client.config.RDSUsername, config.ArchiveBucket(client.config), client.config.RestoredFromSnapshot, ConcourseDatabases)
+}
+
+// setDBMaxStorage applies --db-max-storage to the Cloud SQL instance terraform created
+func (client *GCPClient) setDBMaxStorage() error {
+	return client.provider.SetDBMaxStorage(client.config.RDSDefaultDatabaseName, client.config.DBMaxStorage)
+}
+
+// DatabaseVersion returns the version of PostgreSQL Cloud SQL reports for the instance
+func (client *GCPClient) DatabaseVersion() (string, error) {
+	return client.provider.DatabaseVersion(client.config.RDSDefaultDatabaseName)
+}
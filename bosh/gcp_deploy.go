@@ -5,17 +5,20 @@ import (
 
 	"github.com/EngineerBetter/concourse-up/bosh/internal/boshcli"
 	"github.com/EngineerBetter/concourse-up/bosh/internal/gcp"
+	"github.com/EngineerBetter/concourse-up/iaas"
 	"github.com/apparentlymart/go-cidr/cidr"
 )
 
 // Deploy deploys a new Bosh director or converges an existing deployment
 // Returns new contents of bosh state file
 func (client *GCPClient) Deploy(state, creds []byte, detach bool) (newState, newCreds []byte, err error) {
-	boshCLI, err := boshcli.New(boshcli.DownloadBOSH())
+	boshCLI, err := boshcli.New(boshcli.DownloadBOSH(), boshcli.Deployment(concourseDeployment(client.config)))
 	if err != nil {
 		return state, creds, err
 	}
 
+	restore := len(state) == 0 && client.config.RestoredFromSnapshot != ""
+
 	state, creds, err = client.createEnv(boshCLI, state, creds, "")
 	if err != nil {
 		return state, creds, err
@@ -30,6 +33,14 @@ func (client *GCPClient) Deploy(state, creds []byte, detach bool) (newState, new
 	if err = client.createDefaultDatabases(); err != nil {
 		return state, creds, err
 	}
+	if err = client.setDBMaxStorage(); err != nil {
+		return state, creds, err
+	}
+	if restore {
+		if err = client.importDatabases(); err != nil {
+			return state, creds, err
+		}
+	}
 
 	creds, err = client.deployConcourse(creds, detach)
 	if err != nil {
@@ -56,57 +67,65 @@ func (client *GCPClient) Recreate() error {
 }
 
 func (client *GCPClient) createEnv(bosh boshcli.ICLI, state, creds []byte, customOps string) (newState, newCreds []byte, err error) {
-	tags, err := splitTags(client.config.Tags)
+	tags, err := directorTags(client.config)
 	if err != nil {
 		return state, creds, err
 	}
-	tags["concourse-up-project"] = client.config.Project
-	tags["concourse-up-component"] = "concourse"
 	//TODO(px): pull up this so that we use aws.Store
 	store := temporaryStore{
 		"vars.yaml":  creds,
 		"state.json": state,
 	}
 
-	network, err1 := client.outputs.Get("Network")
-	if err1 != nil {
-		return state, creds, err1
+	env, err := client.directorEnvironment(customOps)
+	if err != nil {
+		return state, creds, err
 	}
-	publicSubnetwork, err1 := client.outputs.Get("PublicSubnetworkName")
-	if err1 != nil {
-		return state, creds, err1
+	err = bosh.CreateEnv(store, env, client.config.DirectorPassword, client.config.DirectorCert, client.config.DirectorKey, client.config.DirectorCACert, tags)
+	return store["state.json"], store["vars.yaml"], err
+}
+
+// directorEnvironment holds everything the director manifest is rendered from
+func (client *GCPClient) directorEnvironment(customOps string) (gcp.Environment, error) {
+	network, err := client.outputs.Get("Network")
+	if err != nil {
+		return gcp.Environment{}, err
 	}
-	privateSubnetwork, err1 := client.outputs.Get("PrivateSubnetworkName")
-	if err1 != nil {
-		return state, creds, err1
+	publicSubnetwork, err := client.outputs.Get("PublicSubnetworkName")
+	if err != nil {
+		return gcp.Environment{}, err
+	}
+	privateSubnetwork, err := client.outputs.Get("PrivateSubnetworkName")
+	if err != nil {
+		return gcp.Environment{}, err
 	}
-	directorPublicIP, err1 := client.outputs.Get("DirectorPublicIP")
-	if err1 != nil {
-		return state, creds, err1
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return gcp.Environment{}, err
 	}
-	project, err1 := client.provider.Attr("project")
-	if err1 != nil {
-		return state, creds, err1
+	project, err := client.provider.Attr("project")
+	if err != nil {
+		return gcp.Environment{}, err
 	}
-	credentialsPath, err1 := client.provider.Attr("credentials_path")
-	if err1 != nil {
-		return state, creds, err1
+	credentialsPath, err := client.provider.Attr("credentials_path")
+	if err != nil {
+		return gcp.Environment{}, err
 	}
 
 	publicCIDR := client.config.PublicCIDR
-	_, pubCIDR, err1 := net.ParseCIDR(publicCIDR)
-	if err1 != nil {
-		return state, creds, err1
+	_, pubCIDR, err := net.ParseCIDR(publicCIDR)
+	if err != nil {
+		return gcp.Environment{}, err
 	}
-	internalGateway, err1 := cidr.Host(pubCIDR, 1)
-	if err1 != nil {
-		return state, creds, err1
+	internalGateway, err := cidr.Host(pubCIDR, 1)
+	if err != nil {
+		return gcp.Environment{}, err
 	}
-	directorInternalIP, err1 := cidr.Host(pubCIDR, 6)
-	if err1 != nil {
-		return state, creds, err1
+	directorInternalIP, err := cidr.Host(pubCIDR, 6)
+	if err != nil {
+		return gcp.Environment{}, err
 	}
-	err1 = bosh.CreateEnv(store, gcp.Environment{
+	return gcp.Environment{
 		InternalCIDR:       client.config.PublicCIDR,
 		InternalGW:         internalGateway.String(),
 		InternalIP:         directorInternalIP.String(),
@@ -122,11 +141,7 @@ func (client *GCPClient) createEnv(bosh boshcli.ICLI, state, creds []byte, custo
 		Spot:               client.config.Spot,
 		PublicKey:          client.config.PublicKey,
 		CustomOperations:   customOps,
-	}, client.config.DirectorPassword, client.config.DirectorCert, client.config.DirectorKey, client.config.DirectorCACert, tags)
-	if err1 != nil {
-		return store["state.json"], store["vars.yaml"], err1
-	}
-	return store["state.json"], store["vars.yaml"], err
+	}, nil
 }
 
 // Locks implements locks for GCP client
@@ -141,81 +156,131 @@ func (client *GCPClient) Locks() ([]byte, error) {
 
 }
 
-func (client *GCPClient) updateCloudConfig(bosh boshcli.ICLI) error {
-
+// cloudConfigEnvironment holds everything the cloud config is rendered from
+func (client *GCPClient) cloudConfigEnvironment() (gcp.Environment, error) {
 	privateSubnetwork, err := client.outputs.Get("PrivateSubnetworkName")
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
 	publicSubnetwork, err := client.outputs.Get("PublicSubnetworkName")
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
 	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
 	network, err := client.outputs.Get("Network")
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
+	}
+	workerServiceAccount, err := client.outputs.Get("WorkerServiceAccount")
+	if err != nil {
+		return gcp.Environment{}, err
 	}
 	zone := client.provider.Zone("")
 
 	publicCIDR := client.config.PublicCIDR
 	_, pubCIDR, err := net.ParseCIDR(publicCIDR)
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
 	pubGateway, err := cidr.Host(pubCIDR, 1)
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
 	publicCIDRGateway := pubGateway.String()
 
 	publicCIDRStatic, err := formatIPRange(publicCIDR, ", ", []int{6, 7})
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
 	publicCIDRReserved, err := formatIPRange(publicCIDR, "-", []int{1, 5})
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
 
 	privateCIDR := client.config.PrivateCIDR
 	_, privCIDR, err := net.ParseCIDR(privateCIDR)
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
 	privGateway, err := cidr.Host(privCIDR, 1)
 	if err != nil {
-		return err
+		return gcp.Environment{}, err
 	}
 	privateCIDRGateway := privGateway.String()
 	privateCIDRReserved, err := formatIPRange(privateCIDR, "-", []int{1, 5})
+	if err != nil {
+		return gcp.Environment{}, err
+	}
+	return gcp.Environment{
+		ExternalIP:           directorPublicIP,
+		PublicCIDR:           client.config.PublicCIDR,
+		PublicCIDRGateway:    publicCIDRGateway,
+		PublicCIDRStatic:     publicCIDRStatic,
+		PublicCIDRReserved:   publicCIDRReserved,
+		PrivateCIDRGateway:   privateCIDRGateway,
+		PrivateCIDRReserved:  privateCIDRReserved,
+		PrivateCIDR:          client.config.PrivateCIDR,
+		Spot:                 client.config.Spot,
+		PublicSubnetwork:     publicSubnetwork,
+		PrivateSubnetwork:    privateSubnetwork,
+		Zone:                 zone,
+		Network:              network,
+		WebInstanceType:      client.config.WebInstanceType,
+		WorkerDiskSize:       client.config.WorkerDiskSize,
+		WorkerDiskType:       client.config.WorkerDiskType,
+		WorkerInstanceType:   client.config.WorkerInstanceType,
+		WorkerPersistentDisk: client.config.WorkerPersistentDiskSize,
+		WorkerServiceAccount: workerServiceAccount,
+
+		ARMWorkerInstanceType:     ARMWorkerInstanceType(client.config),
+		WindowsWorkerInstanceType: WindowsWorkerInstanceType(client.config),
+	}, nil
+}
+
+func (client *GCPClient) updateCloudConfig(bosh boshcli.ICLI) error {
+	env, err := client.cloudConfigEnvironment()
 	if err != nil {
 		return err
 	}
-	return bosh.UpdateCloudConfig(gcp.Environment{
-		PublicCIDR:          client.config.PublicCIDR,
-		PublicCIDRGateway:   publicCIDRGateway,
-		PublicCIDRStatic:    publicCIDRStatic,
-		PublicCIDRReserved:  publicCIDRReserved,
-		PrivateCIDRGateway:  privateCIDRGateway,
-		PrivateCIDRReserved: privateCIDRReserved,
-		PrivateCIDR:         client.config.PrivateCIDR,
-		Spot:                client.config.Spot,
-		PublicSubnetwork:    publicSubnetwork,
-		PrivateSubnetwork:   privateSubnetwork,
-		Zone:                zone,
-		Network:             network,
-	}, directorPublicIP, client.config.DirectorPassword, client.config.DirectorCACert)
+	return bosh.UpdateCloudConfig(env, env.ExternalIP, client.config.DirectorPassword, client.config.DirectorCACert)
+}
+
+// CloudConfigDrift lists the differences between the director's cloud config and the one
+// deploy would upload
+func (client *GCPClient) CloudConfigDrift() ([]string, error) {
+	env, err := client.cloudConfigEnvironment()
+	if err != nil {
+		return nil, err
+	}
+	return cloudConfigDrift(client.boshCLI, env, env.ExternalIP, client.config.DirectorPassword, client.config.DirectorCACert)
 }
 func (client *GCPClient) uploadConcourseStemcell(bosh boshcli.ICLI) error {
 	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
 	if err != nil {
 		return err
 	}
-	return bosh.UploadConcourseStemcell(gcp.Environment{
-		ExternalIP: directorPublicIP,
+	stemcellVersion, err := ConcourseStemcellVersion(client.config, client.provider.IAAS())
+	if err != nil {
+		return err
+	}
+	err = bosh.UploadConcourseStemcell(gcp.Environment{
+		ExternalIP:      directorPublicIP,
+		StemcellVersion: stemcellVersion,
 	}, directorPublicIP, client.config.DirectorPassword, client.config.DirectorCACert)
+	if err != nil {
+		return err
+	}
+	for _, stemcellURL := range workerPoolStemcellURLs(client.config, iaas.GCP) {
+		err = bosh.UploadConcourseStemcell(gcp.Environment{
+			ExternalIP:  directorPublicIP,
+			StemcellURL: stemcellURL,
+		}, directorPublicIP, client.config.DirectorPassword, client.config.DirectorCACert)
+		if err != nil {
+			return err
+		}
+	}
+	return uploadWorkerPoolReleases(bosh, client.config, directorPublicIP)
 }
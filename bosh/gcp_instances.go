@@ -16,3 +16,19 @@ func (client *GCPClient) Instances() ([]Instance, error) {
 		client.config.DirectorCACert,
 	)
 }
+
+// Vitals returns the list of Concourse VMs with their CPU, memory and disk usage
+func (client *GCPClient) Vitals() ([]Instance, error) {
+	directorPublicIP, err := client.outputs.Get("DirectorPublicIP")
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve director IP: [%v]", err)
+	}
+
+	return instances(
+		client.boshCLI,
+		directorPublicIP,
+		client.config.DirectorPassword,
+		client.config.DirectorCACert,
+		"--vitals",
+	)
+}
@@ -1,10 +1,18 @@
 package bosh
 
 import (
+	"encoding/json"
 	"fmt"
-	"github.com/apparentlymart/go-cidr/cidr"
 	"net"
 	"strings"
+
+	"github.com/EngineerBetter/concourse-up/bosh/internal/boshcli"
+	"github.com/EngineerBetter/concourse-up/bosh/internal/workingdir"
+	"github.com/EngineerBetter/concourse-up/bundle"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/resource"
+	"github.com/apparentlymart/go-cidr/cidr"
 )
 
 func vars(vars map[string]interface{}) []string {
@@ -26,6 +34,15 @@ func vars(vars map[string]interface{}) []string {
 	return x
 }
 
+// concourseDeployment is the name of the Concourse deployment on the director, which only
+// imported deployments can have chosen
+func concourseDeployment(c config.Config) string {
+	if c.ConcourseDeployment == "" {
+		return "concourse"
+	}
+	return c.ConcourseDeployment
+}
+
 type temporaryStore map[string][]byte
 
 func (s temporaryStore) Set(key string, value []byte) error {
@@ -62,4 +79,172 @@ func formatIPRange(forCIDR, sep string, positions []int) (string, error) {
 	}
 	s := fmt.Sprintf(`[%s]`, strings.Join(ips, sep))
 	return s, nil
-}
\ No newline at end of file
+}
+
+// credentialManagerOps returns the ops files that swap CredHub for the configured
+// credential manager, adding the variables they need to vmap
+func credentialManagerOps(c config.Config, wd workingdir.IClient, vmap map[string]interface{}) ([]string, error) {
+	var opsFile string
+	switch c.CredentialManager {
+	case "", "credhub":
+		return nil, nil
+	case "aws-secretsmanager":
+		opsFile = awsSecretsManagerFilename
+		vmap["aws_region"] = c.Region
+	case "aws-ssm":
+		opsFile = awsSSMFilename
+		vmap["aws_region"] = c.Region
+	case "vault":
+		opsFile = vaultFilename
+		vmap["vault_url"] = c.VaultURL
+		vmap["vault_ca_cert"] = c.VaultCACert
+		vmap["vault_client_token"] = c.VaultToken
+		vmap["vault_path_prefix"] = c.VaultPathPrefix
+	default:
+		return nil, fmt.Errorf("unknown credential manager %q", c.CredentialManager)
+	}
+	return []string{
+		"--ops-file", wd.PathInWorkingDir(removeCredhubFilename),
+		"--ops-file", wd.PathInWorkingDir(opsFile),
+	}, nil
+}
+
+// oldEncryptionKeyOps lets the ATC decrypt data still encrypted with the previous key while
+// an encryption key rotation is in progress
+func oldEncryptionKeyOps(c config.Config, wd workingdir.IClient, vmap map[string]interface{}) []string {
+	if c.OldEncryptionKey == "" {
+		return nil
+	}
+	vmap["atc_old_encryption_key"] = c.OldEncryptionKey
+	return []string{"--ops-file", wd.PathInWorkingDir(oldEncryptionKeyFilename)}
+}
+
+// webVMType is the cloud config vm_type of the web node, concourse-web-custom when it has a
+// raw instance type rather than a size
+func webVMType(c config.Config) string {
+	if c.WebInstanceType != "" {
+		return "concourse-web-custom"
+	}
+	return "concourse-web-" + c.ConcourseWebSize
+}
+
+// workerVMType is the cloud config vm_type of the workers, concourse-worker-custom when they
+// have a raw instance type rather than a size
+func workerVMType(c config.Config) string {
+	if c.WorkerInstanceType != "" {
+		return "concourse-worker-custom"
+	}
+	return "concourse-" + c.ConcourseWorkerSize
+}
+
+// workerPersistentDiskOps gives each worker a persistent disk from the worker-cache disk type
+// in the cloud config and moves the worker's work dir onto it, so that caches survive the
+// worker being recreated
+func workerPersistentDiskOps(c config.Config, wd workingdir.IClient) []string {
+	if c.WorkerPersistentDiskSize == 0 {
+		return nil
+	}
+	return []string{"--ops-file", wd.PathInWorkingDir(workerPersistentDiskFilename)}
+}
+
+// uploadWorkerPoolReleases uploads the prebuilt releases of the arm64 workers, which the
+// director can't compile itself
+func uploadWorkerPoolReleases(bosh boshcli.ICLI, c config.Config, ip string) error {
+	for _, releaseURL := range workerPoolReleaseURLs(c) {
+		if err := bosh.UploadRelease(releaseURL, ip, c.DirectorPassword, c.DirectorCACert); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// workerPoolOps adds the optional Windows and arm64 worker instance groups, each on its own
+// stemcell and vm_type and tagged so that pipelines can send steps to them
+func workerPoolOps(c config.Config, wd workingdir.IClient, vmap map[string]interface{}) ([]string, error) {
+	var flags []string
+	if c.WindowsWorkerCount > 0 {
+		vmap["windows_worker_count"] = c.WindowsWorkerCount
+		flags = append(flags, "--ops-file", wd.PathInWorkingDir(windowsWorkersFilename))
+	}
+	if c.ARMWorkerCount > 0 {
+		stemcellOS, err := StemcellOS(c.ARMStemcellURL)
+		if err != nil {
+			return nil, err
+		}
+		vmap["arm_worker_count"] = c.ARMWorkerCount
+		vmap["arm_stemcell_os"] = stemcellOS
+		flags = append(flags, "--ops-file", wd.PathInWorkingDir(armWorkersFilename))
+	}
+	return flags, nil
+}
+
+// concourseVersionOps pins the Concourse release to the configured version from the catalogue,
+// swapping in the compatibility ops for that version. Nothing is returned when Concourse
+// follows the version shipped with concourse-up
+func concourseVersionOps(c config.Config, wd workingdir.IClient) ([]string, error) {
+	if c.ConcourseVersion == "" {
+		return nil, nil
+	}
+	release, err := resource.GetConcourseRelease(c.ConcourseVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	compatibility, ok := compatibilityOps[release.CompatibilityOps]
+	if !ok {
+		return nil, fmt.Errorf("unknown compatibility ops %q for Concourse %s", release.CompatibilityOps, release.Version)
+	}
+	if _, err = wd.SaveFileToWorkingDir(concourseCompatibilityFilename, compatibility); err != nil {
+		return nil, err
+	}
+
+	ops, err := json.Marshal([]map[string]string{
+		{"type": "replace", "path": "/releases/name=concourse/version", "value": release.Version},
+		{"type": "replace", "path": "/releases/name=concourse/url", "value": release.URL},
+		{"type": "replace", "path": "/releases/name=concourse/sha1", "value": release.SHA1},
+	})
+	if err != nil {
+		return nil, err
+	}
+	rewritten, err := bundle.Rewrite(string(ops))
+	if err != nil {
+		return nil, err
+	}
+	if _, err = wd.SaveFileToWorkingDir(concourseVersionFilename, []byte(rewritten)); err != nil {
+		return nil, err
+	}
+	return []string{"--ops-file", wd.PathInWorkingDir(concourseVersionFilename)}, nil
+}
+
+// stemcellVersionOps moves Concourse onto the stemcell chosen by maintain --upgrade-stemcell.
+// Nothing is returned when Concourse uses the stemcell shipped with concourse-up
+func stemcellVersionOps(c config.Config, name iaas.Name, wd workingdir.IClient) ([]string, error) {
+	if c.StemcellVersion == "" {
+		return nil, nil
+	}
+	version, err := ConcourseStemcellVersion(c, name)
+	if err != nil {
+		return nil, err
+	}
+	ops, err := json.Marshal([]map[string]string{
+		{"type": "replace", "path": "/stemcells/alias=xenial/version", "value": version},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err = wd.SaveFileToWorkingDir(stemcellVersionFilename, ops); err != nil {
+		return nil, err
+	}
+	return []string{"--ops-file", wd.PathInWorkingDir(stemcellVersionFilename)}, nil
+}
+
+// directorTags are the labels the director puts on the VMs and disks it creates
+func directorTags(c config.Config) (map[string]string, error) {
+	tags, err := splitTags(c.Tags)
+	if err != nil {
+		return nil, err
+	}
+	tags["concourse-up-project"] = c.Project
+	tags["concourse-up-component"] = "concourse"
+	return tags, nil
+}
@@ -0,0 +1,112 @@
+package bosh
+
+import (
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
+// These mirror the vm_types in the cloud config templates and the director manifests, and
+// need to be kept in step with them
+
+var awsWebInstanceTypes = map[string]string{
+	"small":   "t2.small",
+	"medium":  "t2.medium",
+	"large":   "t2.large",
+	"xlarge":  "t2.xlarge",
+	"2xlarge": "t2.2xlarge",
+}
+
+var gcpWebInstanceTypes = map[string]string{
+	"small":   "n1-standard-1",
+	"medium":  "n1-standard-2",
+	"large":   "n1-standard-4",
+	"xlarge":  "n1-standard-8",
+	"2xlarge": "n1-standard-16",
+}
+
+// awsWorkerInstanceTypes are the worker instance types of sizes that don't depend on the
+// worker type
+var awsWorkerInstanceTypes = map[string]string{
+	"medium":   "t2.medium",
+	"10xlarge": "m4.10xlarge",
+	"12xlarge": "m5.12xlarge",
+	"16xlarge": "m4.16xlarge",
+	"24xlarge": "m5.24xlarge",
+}
+
+var gcpWorkerInstanceTypes = map[string]string{
+	"medium":   "n1-standard-1",
+	"large":    "n1-standard-2",
+	"xlarge":   "n1-standard-4",
+	"2xlarge":  "n1-standard-8",
+	"4xlarge":  "n1-standard-16",
+	"10xlarge": "n1-standard-32",
+	"16xlarge": "n1-standard-64",
+}
+
+// WebInstanceType returns the instance type of the web VMs
+func WebInstanceType(c config.Config, name iaas.Name) string {
+	if c.WebInstanceType != "" {
+		return c.WebInstanceType
+	}
+	if name == iaas.GCP {
+		return gcpWebInstanceTypes[c.ConcourseWebSize]
+	}
+	return awsWebInstanceTypes[c.ConcourseWebSize]
+}
+
+// WorkerInstanceType returns the instance type of the workers
+func WorkerInstanceType(c config.Config, name iaas.Name) string {
+	if c.WorkerInstanceType != "" {
+		return c.WorkerInstanceType
+	}
+	if name == iaas.GCP {
+		return gcpWorkerInstanceTypes[c.ConcourseWorkerSize]
+	}
+	if t, ok := awsWorkerInstanceTypes[c.ConcourseWorkerSize]; ok {
+		return t
+	}
+	return awsWorkerFamily(c) + "." + c.ConcourseWorkerSize
+}
+
+// CompilationInstanceType returns the instance type BOSH compiles releases on
+func CompilationInstanceType(c config.Config, name iaas.Name) string {
+	if name == iaas.GCP {
+		return "n1-standard-2"
+	}
+	return awsWorkerFamily(c) + ".large"
+}
+
+// DirectorInstanceType returns the instance type of the BOSH director, as set by the director
+// ops files
+func DirectorInstanceType(name iaas.Name) string {
+	if name == iaas.GCP {
+		return "n1-standard-1"
+	}
+	return "t2.small"
+}
+
+// WindowsWorkerInstanceType returns the instance type of the Windows workers, or nothing when
+// there are none
+func WindowsWorkerInstanceType(c config.Config) string {
+	if c.WindowsWorkerCount == 0 {
+		return ""
+	}
+	return c.WindowsWorkerInstanceType
+}
+
+// ARMWorkerInstanceType returns the instance type of the arm64 workers, or nothing when there
+// are none
+func ARMWorkerInstanceType(c config.Config) string {
+	if c.ARMWorkerCount == 0 {
+		return ""
+	}
+	return c.ARMWorkerInstanceType
+}
+
+func awsWorkerFamily(c config.Config) string {
+	if c.WorkerType == "m5" {
+		return "m5"
+	}
+	return "m4"
+}
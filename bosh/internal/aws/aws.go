@@ -36,6 +36,9 @@ type Environment struct {
 	InternalCIDR          string
 	InternalGateway       string
 	InternalIP            string
+	// LocalStores keeps the director's database and blobstore on its persistent disk, where
+	// bosh create-env puts them by default, rather than on RDS and S3
+	LocalStores           bool
 	PrivateCIDR           string
 	PrivateCIDRGateway    string
 	PrivateCIDRReserved   string
@@ -51,19 +54,44 @@ type Environment struct {
 	S3AWSSecretAccessKey  string
 	SecretAccessKey       string
 	Spot                  bool
+	StemcellVersion       string
 	VMSecurityGroup       string
+	WebInstanceProfile    string
+	WebInstanceType       string
+	WorkerDiskIOPS        int
+	WorkerDiskSize        int
+	WorkerDiskType        string
+	WorkerInstanceProfile string
+	WorkerInstanceType    string
+	WorkerPersistentDisk  int
 	WorkerType            string
+	SpotBidPrices         map[string]float64
+
+	// Instance types of the extra worker pools, left empty for pools with no workers
+	ARMWorkerInstanceType     string
+	WindowsWorkerInstanceType string
+
+	// StemcellURL overrides the Concourse stemcell, so that the stemcells of the extra worker
+	// pools can be uploaded
+	StemcellURL string
 }
 
 var allOperations = resource.AWSCPIOps + resource.ExternalIPOps + resource.AWSDirectorCustomOps
 
+// externalStoreOperations move the director's database to RDS and its blobstore to S3
+var externalStoreOperations = resource.AWSExternalDBOps + resource.AWSS3BlobstoreOps
+
 // ConfigureDirectorManifestCPI interpolates all the Environment parameters and
 // required release versions into ready to use Director manifest
 func (e Environment) ConfigureDirectorManifestCPI() (string, error) {
 	cpiResource := resource.Get(resource.AWSCPI)
 	stemcellResource := resource.Get(resource.AWSStemcell)
 
-	return yaml.Interpolate(resource.DirectorManifest, allOperations+e.CustomOperations, map[string]interface{}{
+	ops := allOperations
+	if !e.LocalStores {
+		ops += externalStoreOperations
+	}
+	return yaml.Interpolate(resource.DirectorManifest, ops+e.CustomOperations, map[string]interface{}{
 		"cpi_url":                  cpiResource.URL,
 		"cpi_version":              cpiResource.Version,
 		"cpi_sha1":                 cpiResource.SHA1,
@@ -94,20 +122,32 @@ func (e Environment) ConfigureDirectorManifestCPI() (string, error) {
 }
 
 type awsCloudConfigParams struct {
-	ATCSecurityGroupID  string
-	AvailabilityZone    string
-	PrivateSubnetID     string
-	PublicSubnetID      string
-	Spot                bool
-	VMsSecurityGroupID  string
-	WorkerType          string
-	PublicCIDR          string
-	PublicCIDRStatic    string
-	PublicCIDRReserved  string
-	PublicCIDRGateway   string
-	PrivateCIDR         string
-	PrivateCIDRGateway  string
-	PrivateCIDRReserved string
+	ATCSecurityGroupID    string
+	AvailabilityZone      string
+	PrivateSubnetID       string
+	PublicSubnetID        string
+	Spot                  bool
+	VMsSecurityGroupID    string
+	WorkerType            string
+	PublicCIDR            string
+	PublicCIDRStatic      string
+	PublicCIDRReserved    string
+	PublicCIDRGateway     string
+	PrivateCIDR           string
+	PrivateCIDRGateway    string
+	PrivateCIDRReserved   string
+	WebInstanceProfile    string
+	WebInstanceType       string
+	WorkerDiskIOPS        int
+	WorkerDiskSize        int
+	WorkerDiskType        string
+	WorkerInstanceProfile string
+	WorkerInstanceType    string
+	WorkerPersistentDisk  int
+	SpotBidPrices         map[string]float64
+
+	ARMWorkerInstanceType     string
+	WindowsWorkerInstanceType string
 }
 
 // IAASCheck returns the IAAS provider
@@ -115,23 +155,74 @@ func (e Environment) IAASCheck() iaas.Name {
 	return iaas.AWS
 }
 
+const (
+	defaultWorkerDiskSize = 200
+	defaultWorkerDiskType = "gp2"
+)
+
+// FallbackSpotBidPrices are the spot bids used for instance types whose price couldn't be
+// looked up at deploy time. They are a little over the on-demand prices in us-east-1
+var FallbackSpotBidPrices = map[string]float64{
+	"t2.medium":   0.0567,
+	"m4.large":    0.13,
+	"m4.xlarge":   0.27,
+	"m4.2xlarge":  0.53,
+	"m4.4xlarge":  1.07,
+	"m4.10xlarge": 2.67,
+	"m4.16xlarge": 4.26,
+	"m5.large":    0.13,
+	"m5.xlarge":   0.26,
+	"m5.2xlarge":  0.51,
+	"m5.4xlarge":  1.03,
+	"m5.12xlarge": 3.08,
+	"m5.24xlarge": 6.17,
+}
+
 // ConfigureDirectorCloudConfig inserts values from the environment into the config template passed as argument
 func (e Environment) ConfigureDirectorCloudConfig() (string, error) {
+	workerDiskSize := e.WorkerDiskSize
+	if workerDiskSize == 0 {
+		workerDiskSize = defaultWorkerDiskSize
+	}
+	workerDiskType := e.WorkerDiskType
+	if workerDiskType == "" {
+		workerDiskType = defaultWorkerDiskType
+	}
+	spotBidPrices := make(map[string]float64, len(FallbackSpotBidPrices))
+	for instanceType, bid := range FallbackSpotBidPrices {
+		spotBidPrices[instanceType] = bid
+	}
+	for instanceType, bid := range e.SpotBidPrices {
+		spotBidPrices[instanceType] = bid
+	}
+
 	templateParams := awsCloudConfigParams{
-		AvailabilityZone:    e.AZ,
-		VMsSecurityGroupID:  e.VMSecurityGroup,
-		ATCSecurityGroupID:  e.ATCSecurityGroup,
-		PublicSubnetID:      e.PublicSubnetID,
-		PrivateSubnetID:     e.PrivateSubnetID,
-		Spot:                e.Spot,
-		WorkerType:          e.WorkerType,
-		PublicCIDR:          e.PublicCIDR,
-		PublicCIDRGateway:   e.PublicCIDRGateway,
-		PublicCIDRReserved:  e.PublicCIDRReserved,
-		PublicCIDRStatic:    e.PublicCIDRStatic,
-		PrivateCIDR:         e.PrivateCIDR,
-		PrivateCIDRGateway:  e.PrivateCIDRGateway,
-		PrivateCIDRReserved: e.PrivateCIDRReserved,
+		AvailabilityZone:      e.AZ,
+		VMsSecurityGroupID:    e.VMSecurityGroup,
+		ATCSecurityGroupID:    e.ATCSecurityGroup,
+		PublicSubnetID:        e.PublicSubnetID,
+		PrivateSubnetID:       e.PrivateSubnetID,
+		Spot:                  e.Spot,
+		WorkerType:            e.WorkerType,
+		PublicCIDR:            e.PublicCIDR,
+		PublicCIDRGateway:     e.PublicCIDRGateway,
+		PublicCIDRReserved:    e.PublicCIDRReserved,
+		PublicCIDRStatic:      e.PublicCIDRStatic,
+		PrivateCIDR:           e.PrivateCIDR,
+		PrivateCIDRGateway:    e.PrivateCIDRGateway,
+		PrivateCIDRReserved:   e.PrivateCIDRReserved,
+		WebInstanceProfile:    e.WebInstanceProfile,
+		WebInstanceType:       e.WebInstanceType,
+		WorkerDiskIOPS:        e.WorkerDiskIOPS,
+		WorkerDiskSize:        workerDiskSize,
+		WorkerDiskType:        workerDiskType,
+		WorkerInstanceProfile: e.WorkerInstanceProfile,
+		WorkerInstanceType:    e.WorkerInstanceType,
+		WorkerPersistentDisk:  e.WorkerPersistentDisk,
+		SpotBidPrices:         spotBidPrices,
+
+		ARMWorkerInstanceType:     e.ARMWorkerInstanceType,
+		WindowsWorkerInstanceType: e.WindowsWorkerInstanceType,
 	}
 
 	cc, err := util.RenderTemplate("cloud-config", resource.AWSDirectorCloudConfig, templateParams)
@@ -141,8 +232,17 @@ func (e Environment) ConfigureDirectorCloudConfig() (string, error) {
 	return string(cc), err
 }
 
+// concourseStemcellURL is where each version of the Concourse stemcell is downloaded from
+const concourseStemcellURL = "https://s3.amazonaws.com/bosh-aws-light-stemcells/light-bosh-stemcell-%s-aws-xen-hvm-ubuntu-xenial-go_agent.tgz"
+
 // ConfigureConcourseStemcell returns the stemcell location string for an AWS specific stemcell for the required concourse version
 func (e Environment) ConfigureConcourseStemcell() (string, error) {
+	if e.StemcellURL != "" {
+		return e.StemcellURL, nil
+	}
+	if e.StemcellVersion != "" {
+		return fmt.Sprintf(concourseStemcellURL, e.StemcellVersion), nil
+	}
 	var ops []struct {
 		Path  string
 		Value json.RawMessage
@@ -164,7 +264,7 @@ func (e Environment) ConfigureConcourseStemcell() (string, error) {
 	if version == "" {
 		return "", errors.New("did not find stemcell version in versions.json")
 	}
-	return fmt.Sprintf("https://s3.amazonaws.com/bosh-aws-light-stemcells/light-bosh-stemcell-%s-aws-xen-hvm-ubuntu-xenial-go_agent.tgz", version), nil
+	return fmt.Sprintf(concourseStemcellURL, version), nil
 }
 
 // Store holds the abstraction of a aws storage artifact
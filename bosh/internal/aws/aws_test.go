@@ -260,6 +260,98 @@ func TestEnvironment_ConfigureDirectorCloudConfig(t *testing.T) {
 				return a == b, fmt.Sprintf("m4 worker templating failed")
 			},
 		},
+		{
+			name:    "Success- web instance profile rendered",
+			fields:  fullTemplateParams,
+			want:    getFixture("../fixtures/aws_cloud_config_web_instance_profile.yml"),
+			wantErr: false,
+			init: func(e Environment) Environment {
+				n := e
+				n.WebInstanceProfile = "web_instance_profile"
+				return n
+			},
+			validate: func(a, b string) (bool, string) {
+				return a == b, fmt.Sprintf("web instance profile templating failed")
+			},
+		},
+		{
+			name:    "Success- worker instance profile rendered",
+			fields:  fullTemplateParams,
+			want:    getFixture("../fixtures/aws_cloud_config_worker_instance_profile.yml"),
+			wantErr: false,
+			init: func(e Environment) Environment {
+				n := e
+				n.WorkerInstanceProfile = "worker_instance_profile"
+				return n
+			},
+			validate: func(a, b string) (bool, string) {
+				return a == b, fmt.Sprintf("worker instance profile templating failed")
+			},
+		},
+		{
+			name:    "Success- worker disks rendered",
+			fields:  fullTemplateParams,
+			want:    getFixture("../fixtures/aws_cloud_config_worker_disks.yml"),
+			wantErr: false,
+			init: func(e Environment) Environment {
+				n := e
+				n.WorkerDiskSize = 500
+				n.WorkerDiskType = "io1"
+				n.WorkerDiskIOPS = 4000
+				n.WorkerPersistentDisk = 100
+				return n
+			},
+			validate: func(a, b string) (bool, string) {
+				return a == b, fmt.Sprintf("worker disk templating failed")
+			},
+		},
+		{
+			name:    "Success- custom instance types rendered",
+			fields:  fullTemplateParams,
+			want:    getFixture("../fixtures/aws_cloud_config_instance_types.yml"),
+			wantErr: false,
+			init: func(e Environment) Environment {
+				n := e
+				n.Spot = true
+				n.WebInstanceType = "t3.large"
+				n.WorkerInstanceType = "c5.4xlarge"
+				n.SpotBidPrices = map[string]float64{"c5.4xlarge": 0.55}
+				return n
+			},
+			validate: func(a, b string) (bool, string) {
+				return a == b, fmt.Sprintf("custom instance type templating failed")
+			},
+		},
+		{
+			name:    "Success- looked up spot bids rendered",
+			fields:  fullTemplateParams,
+			want:    getFixture("../fixtures/aws_cloud_config_spot_bids.yml"),
+			wantErr: false,
+			init: func(e Environment) Environment {
+				n := e
+				n.Spot = true
+				n.SpotBidPrices = map[string]float64{"m4.xlarge": 0.2, "m4.large": 0.1}
+				return n
+			},
+			validate: func(a, b string) (bool, string) {
+				return a == b, fmt.Sprintf("spot bid templating failed")
+			},
+		},
+		{
+			name:    "Success- Windows and arm64 worker pools rendered",
+			fields:  fullTemplateParams,
+			want:    getFixture("../fixtures/aws_cloud_config_worker_pools.yml"),
+			wantErr: false,
+			init: func(e Environment) Environment {
+				n := e
+				n.WindowsWorkerInstanceType = "m5.xlarge"
+				n.ARMWorkerInstanceType = "m6g.xlarge"
+				return n
+			},
+			validate: func(a, b string) (bool, string) {
+				return a == b, fmt.Sprintf("worker pool templating failed")
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -289,7 +381,7 @@ func listNodeFields(node parse.Node, res map[string]int) map[string]int {
 	}
 
 	if node.Type() == parse.NodeAction {
-		var re = regexp.MustCompile(`{{\.(.*)}}`)
+		var re = regexp.MustCompile(`{{(?:index )?\.(\w+)`)
 		res[re.FindStringSubmatch(node.String())[1]] = 1
 	}
 	if ln, ok := node.(*parse.ListNode); ok {
@@ -297,6 +389,12 @@ func listNodeFields(node parse.Node, res map[string]int) map[string]int {
 			res = listNodeFields(n, res)
 		}
 	}
+	if in, ok := node.(*parse.IfNode); ok {
+		res = listNodeFields(in.List, res)
+		if in.ElseList != nil {
+			res = listNodeFields(in.ElseList, res)
+		}
+	}
 	return res
 }
 
@@ -336,10 +434,12 @@ func getStemcellFixture(fixture string) string {
 
 func TestEnvironment_ConfigureConcourseStemcell(t *testing.T) {
 	tests := []struct {
-		name    string
-		want    string
-		wantErr bool
-		fixture string
+		name            string
+		want            string
+		wantErr         bool
+		fixture         string
+		stemcellVersion string
+		stemcellURL     string
 	}{
 		{
 			name:    "parse versions and provide a valid stemcell url",
@@ -353,10 +453,24 @@ func TestEnvironment_ConfigureConcourseStemcell(t *testing.T) {
 			wantErr: true,
 			fixture: "invalid_stemcell_version",
 		},
+		{
+			name:            "provide the stemcell url of a chosen version",
+			want:            "https://s3.amazonaws.com/bosh-aws-light-stemcells/light-bosh-stemcell-250.17-aws-xen-hvm-ubuntu-xenial-go_agent.tgz",
+			wantErr:         false,
+			fixture:         "invalid_stemcell_version",
+			stemcellVersion: "250.17",
+		},
+		{
+			name:        "provide the stemcell url of another worker pool",
+			want:        "https://bosh.io/d/stemcells/bosh-aws-xen-hvm-windows2016-go_agent",
+			wantErr:     false,
+			fixture:     "invalid_stemcell_version",
+			stemcellURL: "https://bosh.io/d/stemcells/bosh-aws-xen-hvm-windows2016-go_agent",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			e := Environment{}
+			e := Environment{StemcellVersion: tt.stemcellVersion, StemcellURL: tt.stemcellURL}
 			resource.AWSReleaseVersions = getStemcellFixture(tt.fixture)
 			got, err := e.ConfigureConcourseStemcell()
 			if (err != nil) != tt.wantErr {
@@ -370,3 +484,43 @@ func TestEnvironment_ConfigureConcourseStemcell(t *testing.T) {
 		})
 	}
 }
+
+func TestEnvironment_ConfigureDirectorManifestCPI(t *testing.T) {
+	tests := []struct {
+		name        string
+		localStores bool
+		want        []string
+		dontWant    []string
+	}{
+		{
+			name:     "database on RDS and blobstore on S3",
+			want:     []string{"host: rds.example.com", "provider: s3"},
+			dontWant: []string{"name: postgres-9.4\n", "name: blobstore\n", "provider: dav"},
+		},
+		{
+			name:        "database and blobstore kept on the director",
+			localStores: true,
+			want:        []string{"name: postgres-9.4\n", "name: blobstore\n", "provider: dav"},
+			dontWant:    []string{"host: rds.example.com", "provider: s3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Environment{DBHost: "rds.example.com", LocalStores: tt.localStores}
+			got, err := e.ConfigureDirectorManifestCPI()
+			if err != nil {
+				t.Fatalf("Environment.ConfigureDirectorManifestCPI() error = %v", err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("Environment.ConfigureDirectorManifestCPI() doesn't contain %q", s)
+				}
+			}
+			for _, s := range tt.dontWant {
+				if strings.Contains(got, s) {
+					t.Errorf("Environment.ConfigureDirectorManifestCPI() contains %q", s)
+				}
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/EngineerBetter/concourse-up/bundle"
 	"github.com/EngineerBetter/concourse-up/iaas"
 	"github.com/EngineerBetter/concourse-up/resource"
 	"github.com/EngineerBetter/concourse-up/util/yaml"
@@ -25,12 +26,14 @@ type ICLI interface {
 	Recreate(config IAASEnvironment, ip, password, ca string) error
 	UpdateCloudConfig(config IAASEnvironment, ip, password, ca string) error
 	UploadConcourseStemcell(config IAASEnvironment, ip, password, ca string) error
+	UploadRelease(url, ip, password, ca string) error
 }
 
 // CLI struct holds the abstraction of execCmd
 type CLI struct {
-	execCmd  func(string, ...string) *exec.Cmd
-	boshPath string
+	execCmd    func(string, ...string) *exec.Cmd
+	boshPath   string
+	deployment string
 }
 
 // Option defines the arbitary element of Options for New
@@ -53,11 +56,20 @@ func DownloadBOSH() Option {
 	}
 }
 
+// Deployment returns the name of the Concourse deployment commands act on as an Option
+func Deployment(name string) Option {
+	return func(c *CLI) error {
+		c.deployment = name
+		return nil
+	}
+}
+
 // New provides a new CLI
 func New(ops ...Option) (ICLI, error) {
 	c := &CLI{
-		execCmd:  exec.Command,
-		boshPath: "bosh",
+		execCmd:    exec.Command,
+		boshPath:   "bosh",
+		deployment: "concourse",
 	}
 	for _, op := range ops {
 		if err := op(c); err != nil {
@@ -82,13 +94,11 @@ type Store interface {
 	Get(string) ([]byte, error)
 }
 
-func (c *CLI) xEnv(action string, store Store, config IAASEnvironment, password, cert, key, ca string, tags map[string]string) error {
-	const stateFilename = "state.json"
-	const varsFilename = "vars.yaml"
-
+// DirectorManifest renders the manifest create-env and delete-env are run with
+func DirectorManifest(config IAASEnvironment, password, cert, key, ca string, tags map[string]string) (string, error) {
 	manifest, err := config.ConfigureDirectorManifestCPI()
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	boshResource := resource.Get(resource.BOSHRelease)
@@ -109,6 +119,17 @@ func (c *CLI) xEnv(action string, store Store, config IAASEnvironment, password,
 		"tags":                     tags,
 	}
 	manifest, err = yaml.Interpolate(manifest, "", vars)
+	if err != nil {
+		return "", err
+	}
+	return bundle.Rewrite(manifest)
+}
+
+func (c *CLI) xEnv(action string, store Store, config IAASEnvironment, password, cert, key, ca string, tags map[string]string) error {
+	const stateFilename = "state.json"
+	const varsFilename = "vars.yaml"
+
+	manifest, err := DirectorManifest(config, password, cert, key, ca, tags)
 	if err != nil {
 		return err
 	}
@@ -188,6 +209,11 @@ func (c *CLI) UploadConcourseStemcell(config IAASEnvironment, ip, password, ca s
 	if err != nil {
 		return err
 	}
+	stemcell, err = bundle.URL(stemcell)
+	if err != nil {
+		return err
+	}
+	stemcell = bundle.LocalPath(stemcell)
 
 	caPath, err := writeTempFile([]byte(ca))
 	if err != nil {
@@ -201,6 +227,26 @@ func (c *CLI) UploadConcourseStemcell(config IAASEnvironment, ip, password, ca s
 	return cmd.Run()
 }
 
+// UploadRelease uploads the release at url to the director
+func (c *CLI) UploadRelease(url, ip, password, ca string) error {
+	release, err := bundle.URL(url)
+	if err != nil {
+		return err
+	}
+	release = bundle.LocalPath(release)
+
+	caPath, err := writeTempFile([]byte(ca))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(caPath)
+	ip = fmt.Sprintf("https://%s", ip)
+	cmd := c.execCmd(c.boshPath, "--non-interactive", "--environment", ip, "--ca-cert", caPath, "--client", "admin", "--client-secret", password, "upload-release", release)
+	cmd.Stderr = os.Stderr
+	cmd.Stdout = os.Stdout
+	return cmd.Run()
+}
+
 // Recreate runs BOSH recreate
 func (c *CLI) Recreate(config IAASEnvironment, ip, password, ca string) error {
 	caPath, err := writeTempFile([]byte(ca))
@@ -209,7 +255,7 @@ func (c *CLI) Recreate(config IAASEnvironment, ip, password, ca string) error {
 	}
 	defer os.Remove(caPath)
 	ip = fmt.Sprintf("https://%s", ip)
-	cmd := c.execCmd(c.boshPath, "--non-interactive", "--environment", ip, "--ca-cert", caPath, "--client", "admin", "--client-secret", password, "--deployment", "concourse", "recreate")
+	cmd := c.execCmd(c.boshPath, "--non-interactive", "--environment", ip, "--ca-cert", caPath, "--client", "admin", "--client-secret", password, "--deployment", c.deployment, "recreate")
 	cmd.Stderr = os.Stderr
 	cmd.Stdout = os.Stdout
 	return cmd.Run()
@@ -235,7 +281,7 @@ func (c *CLI) RunAuthenticatedCommand(action, ip, password, ca string, detach bo
 	defer os.Remove(caPath)
 	ip = fmt.Sprintf("https://%s", ip)
 
-	authFlags := []string{"--non-interactive", "--environment", ip, "--ca-cert", caPath, "--client", "admin", "--client-secret", password, "--deployment", "concourse", action}
+	authFlags := []string{"--non-interactive", "--environment", ip, "--ca-cert", caPath, "--client", "admin", "--client-secret", password, "--deployment", c.deployment, action}
 	flags = append(authFlags, flags...)
 	if detach && action == "deploy" {
 		return c.detachedBoshCommand(stdout, flags...)
@@ -121,3 +121,36 @@ func TestCLI_UploadConcourseStemcell(t *testing.T) {
 	require.NoError(t, err)
 
 }
+
+func TestCLI_UploadRelease(t *testing.T) {
+	e := fakeexec.New(t)
+	defer e.Finish()
+	c, err := boshcli.New(boshcli.FakeExec(e.Cmd()))
+	require.NoError(t, err)
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "bosh", command)
+
+		require.Equal(t, "https://ip", args[2])
+		require.Equal(t, "upload-release", args[9])
+		require.Equal(t, "https://example.com/concourse-arm64.tgz", args[10])
+	})
+	err = c.UploadRelease("https://example.com/concourse-arm64.tgz", "ip", "password", "ca")
+	require.NoError(t, err)
+}
+
+func TestCLI_RecreateDeployment(t *testing.T) {
+	e := fakeexec.New(t)
+	defer e.Finish()
+	c, err := boshcli.New(boshcli.FakeExec(e.Cmd()), boshcli.Deployment("ci"))
+	require.NoError(t, err)
+	config := mockIAASConfig{}
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "bosh", command)
+
+		require.Equal(t, "--deployment", args[9])
+		require.Equal(t, "ci", args[10])
+		require.Equal(t, "recreate", args[11])
+	})
+	err = c.Recreate(config, "ip", "password", "ca")
+	require.NoError(t, err)
+}
@@ -118,6 +118,20 @@ type FakeICLI struct {
 	uploadConcourseStemcellReturnsOnCall map[int]struct {
 		result1 error
 	}
+	UploadReleaseStub        func(string, string, string, string) error
+	uploadReleaseMutex       sync.RWMutex
+	uploadReleaseArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+	}
+	uploadReleaseReturns struct {
+		result1 error
+	}
+	uploadReleaseReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -575,6 +589,69 @@ func (fake *FakeICLI) UploadConcourseStemcellReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
+func (fake *FakeICLI) UploadRelease(arg1 string, arg2 string, arg3 string, arg4 string) error {
+	fake.uploadReleaseMutex.Lock()
+	ret, specificReturn := fake.uploadReleaseReturnsOnCall[len(fake.uploadReleaseArgsForCall)]
+	fake.uploadReleaseArgsForCall = append(fake.uploadReleaseArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("UploadRelease", []interface{}{arg1, arg2, arg3, arg4})
+	fake.uploadReleaseMutex.Unlock()
+	if fake.UploadReleaseStub != nil {
+		return fake.UploadReleaseStub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.uploadReleaseReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeICLI) UploadReleaseCallCount() int {
+	fake.uploadReleaseMutex.RLock()
+	defer fake.uploadReleaseMutex.RUnlock()
+	return len(fake.uploadReleaseArgsForCall)
+}
+
+func (fake *FakeICLI) UploadReleaseCalls(stub func(string, string, string, string) error) {
+	fake.uploadReleaseMutex.Lock()
+	defer fake.uploadReleaseMutex.Unlock()
+	fake.UploadReleaseStub = stub
+}
+
+func (fake *FakeICLI) UploadReleaseArgsForCall(i int) (string, string, string, string) {
+	fake.uploadReleaseMutex.RLock()
+	defer fake.uploadReleaseMutex.RUnlock()
+	argsForCall := fake.uploadReleaseArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeICLI) UploadReleaseReturns(result1 error) {
+	fake.uploadReleaseMutex.Lock()
+	defer fake.uploadReleaseMutex.Unlock()
+	fake.UploadReleaseStub = nil
+	fake.uploadReleaseReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeICLI) UploadReleaseReturnsOnCall(i int, result1 error) {
+	fake.uploadReleaseMutex.Lock()
+	defer fake.uploadReleaseMutex.Unlock()
+	fake.UploadReleaseStub = nil
+	if fake.uploadReleaseReturnsOnCall == nil {
+		fake.uploadReleaseReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.uploadReleaseReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeICLI) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -592,6 +669,8 @@ func (fake *FakeICLI) Invocations() map[string][][]interface{} {
 	defer fake.updateCloudConfigMutex.RUnlock()
 	fake.uploadConcourseStemcellMutex.RLock()
 	defer fake.uploadConcourseStemcellMutex.RUnlock()
+	fake.uploadReleaseMutex.RLock()
+	defer fake.uploadReleaseMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -19,28 +19,43 @@ import (
 
 // Environment holds all the parameters GCP IAAS needs
 type Environment struct {
-	CustomOperations    string
-	DirectorName        string
-	ExternalIP          string
-	GcpCredentialsJSON  string
-	InternalCIDR        string
-	InternalGW          string
-	InternalIP          string
-	Network             string
-	PrivateCIDR         string
-	PrivateCIDRGateway  string
-	PrivateCIDRReserved string
-	PrivateSubnetwork   string
-	ProjectID           string
-	PublicCIDR          string
-	PublicCIDRGateway   string
-	PublicCIDRReserved  string
-	PublicCIDRStatic    string
-	PublicKey           string
-	PublicSubnetwork    string
-	Spot                bool
-	Tags                string
-	Zone                string
+	CustomOperations     string
+	DirectorName         string
+	ExternalIP           string
+	GcpCredentialsJSON   string
+	InternalCIDR         string
+	InternalGW           string
+	InternalIP           string
+	Network              string
+	PrivateCIDR          string
+	PrivateCIDRGateway   string
+	PrivateCIDRReserved  string
+	PrivateSubnetwork    string
+	ProjectID            string
+	PublicCIDR           string
+	PublicCIDRGateway    string
+	PublicCIDRReserved   string
+	PublicCIDRStatic     string
+	PublicKey            string
+	PublicSubnetwork     string
+	Spot                 bool
+	StemcellVersion      string
+	Tags                 string
+	WebInstanceType      string
+	WorkerDiskSize       int
+	WorkerDiskType       string
+	WorkerInstanceType   string
+	WorkerPersistentDisk int
+	WorkerServiceAccount string
+	Zone                 string
+
+	// Instance types of the extra worker pools, left empty for pools with no workers
+	ARMWorkerInstanceType     string
+	WindowsWorkerInstanceType string
+
+	// StemcellURL overrides the Concourse stemcell, so that the stemcells of the extra worker
+	// pools can be uploaded
+	StemcellURL string
 }
 
 var allOperations = resource.GCPCPIOps + resource.GCPExternalIPOps + resource.GCPDirectorCustomOps + resource.GCPJumpboxUserOps
@@ -70,18 +85,27 @@ func (e Environment) ConfigureDirectorManifestCPI() (string, error) {
 }
 
 type gcpCloudConfigParams struct {
-	Zone                string
-	Spot                bool
-	PublicSubnetwork    string
-	PrivateSubnetwork   string
-	Network             string
-	PublicCIDR          string
-	PublicCIDRGateway   string
-	PublicCIDRStatic    string
-	PublicCIDRReserved  string
-	PrivateCIDR         string
-	PrivateCIDRGateway  string
-	PrivateCIDRReserved string
+	Zone                 string
+	Spot                 bool
+	PublicSubnetwork     string
+	PrivateSubnetwork    string
+	Network              string
+	PublicCIDR           string
+	PublicCIDRGateway    string
+	PublicCIDRStatic     string
+	PublicCIDRReserved   string
+	PrivateCIDR          string
+	PrivateCIDRGateway   string
+	PrivateCIDRReserved  string
+	WebInstanceType      string
+	WorkerDiskSize       int
+	WorkerDiskType       string
+	WorkerInstanceType   string
+	WorkerPersistentDisk int
+	WorkerServiceAccount string
+
+	ARMWorkerInstanceType     string
+	WindowsWorkerInstanceType string
 }
 
 // IAASCheck returns the IAAS provider
@@ -89,21 +113,44 @@ func (e Environment) IAASCheck() iaas.Name {
 	return iaas.GCP
 }
 
+const (
+	defaultWorkerDiskSize = 200
+	defaultWorkerDiskType = "pd-ssd"
+)
+
 // ConfigureDirectorCloudConfig inserts values from the environment into the config template passed as argument
 func (e Environment) ConfigureDirectorCloudConfig() (string, error) {
+	workerDiskSize := e.WorkerDiskSize
+	if workerDiskSize == 0 {
+		workerDiskSize = defaultWorkerDiskSize
+	}
+	workerDiskType := e.WorkerDiskType
+	if workerDiskType == "" {
+		workerDiskType = defaultWorkerDiskType
+	}
+
 	templateParams := gcpCloudConfigParams{
-		Zone:                e.Zone,
-		PublicSubnetwork:    e.PublicSubnetwork,
-		PrivateSubnetwork:   e.PrivateSubnetwork,
-		Spot:                e.Spot,
-		Network:             e.Network,
-		PublicCIDR:          e.PublicCIDR,
-		PublicCIDRGateway:   e.PublicCIDRGateway,
-		PublicCIDRStatic:    e.PublicCIDRStatic,
-		PublicCIDRReserved:  e.PublicCIDRReserved,
-		PrivateCIDR:         e.PrivateCIDR,
-		PrivateCIDRGateway:  e.PrivateCIDRGateway,
-		PrivateCIDRReserved: e.PrivateCIDRReserved,
+		Zone:                 e.Zone,
+		PublicSubnetwork:     e.PublicSubnetwork,
+		PrivateSubnetwork:    e.PrivateSubnetwork,
+		Spot:                 e.Spot,
+		Network:              e.Network,
+		PublicCIDR:           e.PublicCIDR,
+		PublicCIDRGateway:    e.PublicCIDRGateway,
+		PublicCIDRStatic:     e.PublicCIDRStatic,
+		PublicCIDRReserved:   e.PublicCIDRReserved,
+		PrivateCIDR:          e.PrivateCIDR,
+		PrivateCIDRGateway:   e.PrivateCIDRGateway,
+		PrivateCIDRReserved:  e.PrivateCIDRReserved,
+		WebInstanceType:      e.WebInstanceType,
+		WorkerDiskSize:       workerDiskSize,
+		WorkerDiskType:       workerDiskType,
+		WorkerInstanceType:   e.WorkerInstanceType,
+		WorkerPersistentDisk: e.WorkerPersistentDisk,
+		WorkerServiceAccount: e.WorkerServiceAccount,
+
+		ARMWorkerInstanceType:     e.ARMWorkerInstanceType,
+		WindowsWorkerInstanceType: e.WindowsWorkerInstanceType,
 	}
 
 	cc, err := util.RenderTemplate("cloud-config", resource.GCPDirectorCloudConfig, templateParams)
@@ -113,8 +160,17 @@ func (e Environment) ConfigureDirectorCloudConfig() (string, error) {
 	return string(cc), err
 }
 
+// concourseStemcellURL is where each version of the Concourse stemcell is downloaded from
+const concourseStemcellURL = "https://s3.amazonaws.com/bosh-gce-light-stemcells/light-bosh-stemcell-%s-google-kvm-ubuntu-xenial-go_agent.tgz"
+
 // ConfigureConcourseStemcell returns the stemcell location string for an AWS specific stemcell for the required concourse version
 func (e Environment) ConfigureConcourseStemcell() (string, error) {
+	if e.StemcellURL != "" {
+		return e.StemcellURL, nil
+	}
+	if e.StemcellVersion != "" {
+		return fmt.Sprintf(concourseStemcellURL, e.StemcellVersion), nil
+	}
 	var ops []struct {
 		Path  string
 		Value json.RawMessage
@@ -136,7 +192,7 @@ func (e Environment) ConfigureConcourseStemcell() (string, error) {
 	if version == "" {
 		return "", errors.New("did not find stemcell version in versions.json")
 	}
-	return fmt.Sprintf("https://s3.amazonaws.com/bosh-gce-light-stemcells/light-bosh-stemcell-%s-google-kvm-ubuntu-xenial-go_agent.tgz", version), nil
+	return fmt.Sprintf(concourseStemcellURL, version), nil
 }
 
 // Store holds the abstraction of a aws storage artifact
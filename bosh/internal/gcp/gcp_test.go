@@ -230,6 +230,67 @@ func TestEnvironment_ConfigureDirectorCloudConfig(t *testing.T) {
 				return a == b, fmt.Sprintf("templating failed while rendering without spots")
 			},
 		},
+		{
+			name:    "Success- worker service account rendered",
+			fields:  fullTemplateParams,
+			want:    getFixture("../fixtures/gcp_cloud_config_worker_service_account.yml"),
+			wantErr: false,
+			init: func(e Environment) Environment {
+				n := e
+				n.WorkerServiceAccount = "worker@project.iam.gserviceaccount.com"
+				return n
+			},
+			validate: func(a, b string) (bool, string) {
+				return a == b, fmt.Sprintf("worker service account templating failed")
+			},
+		},
+		{
+			name:    "Success- worker disks rendered",
+			fields:  fullTemplateParams,
+			want:    getFixture("../fixtures/gcp_cloud_config_worker_disks.yml"),
+			wantErr: false,
+			init: func(e Environment) Environment {
+				n := e
+				n.WorkerDiskSize = 500
+				n.WorkerDiskType = "pd-standard"
+				n.WorkerPersistentDisk = 100
+				return n
+			},
+			validate: func(a, b string) (bool, string) {
+				return a == b, fmt.Sprintf("worker disk templating failed")
+			},
+		},
+		{
+			name:    "Success- custom instance types rendered",
+			fields:  fullTemplateParams,
+			want:    getFixture("../fixtures/gcp_cloud_config_instance_types.yml"),
+			wantErr: false,
+			init: func(e Environment) Environment {
+				n := e
+				n.Spot = true
+				n.WebInstanceType = "n2-standard-2"
+				n.WorkerInstanceType = "n2-standard-16"
+				return n
+			},
+			validate: func(a, b string) (bool, string) {
+				return a == b, fmt.Sprintf("custom instance type templating failed")
+			},
+		},
+		{
+			name:    "Success- Windows and arm64 worker pools rendered",
+			fields:  fullTemplateParams,
+			want:    getFixture("../fixtures/gcp_cloud_config_worker_pools.yml"),
+			wantErr: false,
+			init: func(e Environment) Environment {
+				n := e
+				n.WindowsWorkerInstanceType = "n1-standard-4"
+				n.ARMWorkerInstanceType = "t2a-standard-4"
+				return n
+			},
+			validate: func(a, b string) (bool, string) {
+				return a == b, fmt.Sprintf("worker pool templating failed")
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -257,10 +318,12 @@ func TestEnvironment_ConfigureConcourseStemcell(t *testing.T) {
 		versions string
 	}
 	tests := []struct {
-		name    string
-		want    string
-		wantErr bool
-		fixture string
+		name            string
+		want            string
+		wantErr         bool
+		fixture         string
+		stemcellVersion string
+		stemcellURL     string
 	}{
 		{
 			name:    "parse versions and provide a valid stemcell url",
@@ -274,10 +337,24 @@ func TestEnvironment_ConfigureConcourseStemcell(t *testing.T) {
 			wantErr: true,
 			fixture: "invalid_stemcell_version",
 		},
+		{
+			name:            "provide the stemcell url of a chosen version",
+			want:            "https://s3.amazonaws.com/bosh-gce-light-stemcells/light-bosh-stemcell-250.17-google-kvm-ubuntu-xenial-go_agent.tgz",
+			wantErr:         false,
+			fixture:         "invalid_stemcell_version",
+			stemcellVersion: "250.17",
+		},
+		{
+			name:        "provide the stemcell url of another worker pool",
+			want:        "https://bosh.io/d/stemcells/bosh-google-kvm-windows2016-go_agent",
+			wantErr:     false,
+			fixture:     "invalid_stemcell_version",
+			stemcellURL: "https://bosh.io/d/stemcells/bosh-google-kvm-windows2016-go_agent",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			e := Environment{}
+			e := Environment{StemcellVersion: tt.stemcellVersion, StemcellURL: tt.stemcellURL}
 			resource.GCPReleaseVersions = getStemcellFixture(tt.fixture)
 			got, err := e.ConfigureConcourseStemcell()
 			if (err != nil) != tt.wantErr {
@@ -0,0 +1,52 @@
+package bosh
+
+import (
+	"github.com/EngineerBetter/concourse-up/util/yaml"
+	yamlv2 "gopkg.in/yaml.v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Ops files", func() {
+	// The worker instance group as the Concourse 4.2.2 release lays it out
+	const workerManifest = `name: concourse
+instance_groups:
+- name: worker
+  jobs:
+  - name: worker
+    release: concourse
+  - name: baggageclaim
+    release: concourse
+    properties: {}
+  - name: garden
+    release: garden-runc
+`
+
+	Describe("worker-persistent-disk.yml", func() {
+		It("Puts the baggageclaim volumes on the persistent disk", func() {
+			manifest, err := yaml.Interpolate(workerManifest, string(workerPersistentDisk), nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			var rendered struct {
+				InstanceGroups []struct {
+					Name               string `yaml:"name"`
+					PersistentDiskType string `yaml:"persistent_disk_type"`
+					Jobs               []struct {
+						Name       string                 `yaml:"name"`
+						Properties map[string]interface{} `yaml:"properties"`
+					} `yaml:"jobs"`
+				} `yaml:"instance_groups"`
+			}
+			Expect(yamlv2.Unmarshal([]byte(manifest), &rendered)).To(Succeed())
+			Expect(rendered.InstanceGroups).To(HaveLen(1))
+
+			worker := rendered.InstanceGroups[0]
+			Expect(worker.PersistentDiskType).To(Equal("worker-cache"))
+			Expect(worker.Jobs).To(HaveLen(3))
+			Expect(worker.Jobs[0].Properties).To(BeEmpty())
+			Expect(worker.Jobs[1].Name).To(Equal("baggageclaim"))
+			Expect(worker.Jobs[1].Properties).To(Equal(map[string]interface{}{"volumes": "/var/vcap/store/baggageclaim/volumes"}))
+		})
+	})
+})
@@ -0,0 +1,165 @@
+package bosh
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EngineerBetter/concourse-up/bundle"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
+// stemcellIndexURL lists the published versions of a stemcell on bosh.io
+var stemcellIndexURL = "https://bosh.io/api/v1/stemcells/"
+
+// concourseStemcells are the stemcells Concourse is deployed onto for each IAAS
+var concourseStemcells = map[iaas.Name]string{
+	iaas.AWS: "bosh-aws-xen-hvm-ubuntu-xenial-go_agent",
+	iaas.GCP: "bosh-google-kvm-ubuntu-xenial-go_agent",
+}
+
+// windowsStemcellURLs download the latest Windows stemcell from bosh.io for each IAAS
+var windowsStemcellURLs = map[iaas.Name]string{
+	iaas.AWS: "https://bosh.io/d/stemcells/bosh-aws-xen-hvm-windows2016-go_agent",
+	iaas.GCP: "https://bosh.io/d/stemcells/bosh-google-kvm-windows2016-go_agent",
+}
+
+const stemcellVersionFilename = "stemcell-version.json"
+
+var stemcellOSRegex = regexp.MustCompile(`-(ubuntu-[a-z]+|windows\d+)-go_agent`)
+
+// StemcellOS returns the OS of a stemcell from its file name, e.g. ubuntu-xenial for
+// light-bosh-stemcell-97.12-aws-xen-hvm-ubuntu-xenial-go_agent.tgz
+func StemcellOS(url string) (string, error) {
+	m := stemcellOSRegex.FindStringSubmatch(url)
+	if m == nil {
+		return "", fmt.Errorf("can't tell the OS of the stemcell at %s, expected a file name like light-bosh-stemcell-<version>-<infrastructure>-<os>-go_agent.tgz", url)
+	}
+	return m[1], nil
+}
+
+// workerPoolStemcellURLs are the stemcells of the Windows and arm64 workers, when there are any
+func workerPoolStemcellURLs(c config.Config, name iaas.Name) []string {
+	var urls []string
+	if c.WindowsWorkerCount > 0 {
+		urls = append(urls, windowsStemcellURLs[name])
+	}
+	if c.ARMWorkerCount > 0 {
+		urls = append(urls, c.ARMStemcellURL)
+	}
+	return urls
+}
+
+// workerPoolReleaseURLs are the prebuilt arm64 Concourse and garden-runc releases of the arm64
+// workers, when there are any
+func workerPoolReleaseURLs(c config.Config) []string {
+	if c.ARMWorkerCount == 0 {
+		return nil
+	}
+	return []string{c.ARMConcourseReleaseURL, c.ARMGardenRunCReleaseURL}
+}
+
+// ConcourseStemcellVersion returns the stemcell version Concourse is deployed onto, which is the
+// version chosen by maintain --upgrade-stemcell unless this release of concourse-up ships a newer one
+func ConcourseStemcellVersion(c config.Config, name iaas.Name) (string, error) {
+	var versions []byte
+	switch name {
+	case iaas.AWS:
+		versions = awsConcourseVersions
+	case iaas.GCP:
+		versions = gcpConcourseVersions
+	default:
+		return "", fmt.Errorf("IAAS not supported: [%s]", name)
+	}
+
+	var ops []struct {
+		Path  string
+		Value json.RawMessage
+	}
+	if err := json.Unmarshal(versions, &ops); err != nil {
+		return "", err
+	}
+	var version string
+	for _, op := range ops {
+		if op.Path != "/stemcells/alias=xenial/version" {
+			continue
+		}
+		if err := json.Unmarshal(op.Value, &version); err != nil {
+			return "", err
+		}
+	}
+	if version == "" {
+		return "", errors.New("did not find stemcell version in versions.json")
+	}
+
+	if CompareStemcellVersions(c.StemcellVersion, version) > 0 {
+		return c.StemcellVersion, nil
+	}
+	return version, nil
+}
+
+// LatestStemcellVersion returns the newest version of the Concourse stemcell published on bosh.io
+// in the same line, i.e. with the same major version, as current. bosh.io isn't asked when a bundle
+// is in use, as a stemcell found there couldn't be downloaded anyway
+func LatestStemcellVersion(name iaas.Name, current string) (string, error) {
+	stemcell, ok := concourseStemcells[name]
+	if !ok {
+		return "", fmt.Errorf("IAAS not supported: [%s]", name)
+	}
+	if bundle.Active() {
+		return "", errors.New("cannot look up the latest stemcell when using a bundle, pass the version of a stemcell in the bundle with --stemcell-version")
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(stemcellIndexURL + stemcell)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to list %s versions: unexpected status %s", stemcell, resp.Status)
+	}
+	var published []struct {
+		Version string `json:"version"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&published); err != nil {
+		return "", fmt.Errorf("failed to list %s versions: [%v]", stemcell, err)
+	}
+
+	line := strings.SplitN(current, ".", 2)[0]
+	latest := current
+	for _, p := range published {
+		if strings.SplitN(p.Version, ".", 2)[0] == line && CompareStemcellVersions(p.Version, latest) > 0 {
+			latest = p.Version
+		}
+	}
+	return latest, nil
+}
+
+// CompareStemcellVersions returns a negative number when a is older than b, a positive number when
+// it is newer and 0 when they are the same. An empty version is older than any other
+func CompareStemcellVersions(a, b string) int {
+	if a == "" || b == "" {
+		return len(a) - len(b)
+	}
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return 0
+}
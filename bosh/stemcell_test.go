@@ -0,0 +1,100 @@
+package bosh
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/EngineerBetter/concourse-up/bundle"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stemcells", func() {
+	Describe("CompareStemcellVersions", func() {
+		It("Compares each part of the version as a number", func() {
+			Expect(CompareStemcellVersions("250.17", "250.9")).To(BeNumerically(">", 0))
+			Expect(CompareStemcellVersions("170.9", "250.1")).To(BeNumerically("<", 0))
+			Expect(CompareStemcellVersions("250", "250.0")).To(Equal(0))
+			Expect(CompareStemcellVersions("", "1.0")).To(BeNumerically("<", 0))
+		})
+	})
+
+	Describe("LatestStemcellVersion", func() {
+		var server *httptest.Server
+		var originalURL string
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.URL.Path).To(Equal("/bosh-aws-xen-hvm-ubuntu-xenial-go_agent"))
+				w.Write([]byte(`[{"version":"315.1"},{"version":"250.25"},{"version":"250.9"},{"version":"170.30"}]`))
+			}))
+			originalURL = stemcellIndexURL
+			stemcellIndexURL = server.URL + "/"
+		})
+
+		AfterEach(func() {
+			stemcellIndexURL = originalURL
+			server.Close()
+		})
+
+		It("Returns the newest version with the same major version", func() {
+			latest, err := LatestStemcellVersion(iaas.AWS, "250.17")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(latest).To(Equal("250.25"))
+		})
+
+		It("Returns the current version when there is nothing newer", func() {
+			latest, err := LatestStemcellVersion(iaas.AWS, "315.1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(latest).To(Equal("315.1"))
+		})
+
+		It("Does not look on bosh.io when a bundle is in use", func() {
+			mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"artifacts":[]}`))
+			}))
+			defer mirror.Close()
+			b, err := bundle.Open(mirror.URL)
+			Expect(err).ToNot(HaveOccurred())
+			bundle.Use(b)
+			defer bundle.Use(nil)
+
+			_, err = LatestStemcellVersion(iaas.AWS, "250.17")
+			Expect(err).To(MatchError(ContainSubstring("--stemcell-version")))
+		})
+	})
+
+	Describe("ConcourseStemcellVersion", func() {
+		It("Prefers the version in the config when it is newer than the embedded one", func() {
+			version, err := ConcourseStemcellVersion(config.Config{StemcellVersion: "99999.1"}, iaas.GCP)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(version).To(Equal("99999.1"))
+		})
+
+		It("Never goes back to an older stemcell than the embedded one", func() {
+			version, err := ConcourseStemcellVersion(config.Config{StemcellVersion: "1.0"}, iaas.AWS)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(version).ToNot(Equal("1.0"))
+		})
+	})
+
+	Describe("StemcellOS", func() {
+		It("Reads the OS from a stemcell's file name", func() {
+			os, err := StemcellOS("https://example.com/light-bosh-stemcell-1.0-aws-xen-hvm-ubuntu-bionic-go_agent.tgz")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os).To(Equal("ubuntu-bionic"))
+
+			os, err = StemcellOS(windowsStemcellURLs[iaas.GCP])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os).To(Equal("windows2016"))
+		})
+
+		It("Errors when the file name doesn't say", func() {
+			_, err := StemcellOS("https://example.com/arm64.tgz")
+			Expect(err).To(MatchError(ContainSubstring("can't tell the OS of the stemcell")))
+		})
+	})
+})
@@ -0,0 +1,317 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const indexFilename = "index.json"
+
+// Artifact is a single file concourse-up would otherwise download from the internet
+type Artifact struct {
+	URL  string `json:"url"`
+	Path string `json:"path"`
+}
+
+// Index lists the artifacts in a bundle
+type Index struct {
+	ConcourseUpVersion string     `json:"concourse_up_version"`
+	Artifacts          []Artifact `json:"artifacts"`
+}
+
+// Bundle serves artifacts from an extracted archive on local disk or from a mirror
+type Bundle struct {
+	location string
+	base     string
+	urls     map[string]string
+}
+
+var active *Bundle
+
+var client = func() *http.Client {
+	t := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	t.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
+	return &http.Client{Transport: t}
+}()
+
+// Create downloads every URL and writes them, along with an index, to w as a gzipped tarball
+func Create(w io.Writer, concourseUpVersion string, urls []string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	index := Index{ConcourseUpVersion: concourseUpVersion}
+	seen := map[string]bool{}
+	for _, u := range urls {
+		if seen[u] {
+			continue
+		}
+		seen[u] = true
+
+		artifact := Artifact{URL: u, Path: artifactPath(u)}
+		if err := addArtifact(tw, artifact); err != nil {
+			return fmt.Errorf("error adding %s to bundle: [%v]", u, err)
+		}
+		index.Artifacts = append(index.Artifacts, artifact)
+	}
+
+	indexBytes, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = tw.WriteHeader(&tar.Header{Name: indexFilename, Mode: 0644, Size: int64(len(indexBytes))})
+	if err != nil {
+		return err
+	}
+	if _, err = tw.Write(indexBytes); err != nil {
+		return err
+	}
+
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func addArtifact(tw *tar.Writer, artifact Artifact) error {
+	resp, err := client.Get(artifact.URL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	// tar needs the size up front and not every server sends a Content-Length
+	tmp, err := ioutil.TempFile("", "concourse-up-bundle")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	size, err := io.Copy(tmp, resp.Body)
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	err = tw.WriteHeader(&tar.Header{Name: artifact.Path, Mode: 0644, Size: size})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, tmp)
+	return err
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// artifactPath names an artifact after its URL, keeping the extension so zips are still recognised
+func artifactPath(u string) string {
+	sum := sha256.Sum256([]byte(u))
+	name := "artifact"
+	if parsed, err := url.Parse(u); err == nil && path.Base(parsed.Path) != "/" && path.Base(parsed.Path) != "." {
+		name = unsafeChars.ReplaceAllString(path.Base(parsed.Path), "_")
+	}
+	return path.Join("artifacts", hex.EncodeToString(sum[:8])+"-"+name)
+}
+
+// Open loads a bundle from a local archive, extracting it into the user's cache directory,
+// or from the http(s) URL of a mirror holding the extracted contents of one
+func Open(location string) (*Bundle, error) {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		base := strings.TrimSuffix(location, "/")
+		resp, err := client.Get(base + "/" + indexFilename)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("error fetching %s/%s: unexpected status %s", base, indexFilename, resp.Status)
+		}
+		return newBundle(location, base, resp.Body)
+	}
+
+	dir, err := extract(location)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Join(dir, indexFilename))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return newBundle(location, "file://"+filepath.ToSlash(dir), f)
+}
+
+func newBundle(location, base string, indexReader io.Reader) (*Bundle, error) {
+	var index Index
+	if err := json.NewDecoder(indexReader).Decode(&index); err != nil {
+		return nil, fmt.Errorf("error reading bundle index: [%v]", err)
+	}
+	b := &Bundle{location: location, base: base, urls: map[string]string{}}
+	for _, a := range index.Artifacts {
+		b.urls[a.URL] = base + "/" + a.Path
+	}
+	return b, nil
+}
+
+// extract unpacks an archive once, keyed on its path, size and modification time
+func extract(archive string) (string, error) {
+	abs, err := filepath.Abs(archive)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", err
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d", abs, info.Size(), info.ModTime().UnixNano())))
+	dir := filepath.Join(cacheDir, "concourse-up", "bundles", hex.EncodeToString(sum[:]))
+	if _, err = os.Stat(filepath.Join(dir, indexFilename)); err == nil {
+		return dir, nil
+	}
+
+	f, err := os.Open(abs)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return "", fmt.Errorf("%s is not a concourse-up bundle: [%v]", archive, err)
+	}
+	tmpDir := dir + ".tmp"
+	os.RemoveAll(tmpDir)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if strings.HasPrefix(name, "..") || filepath.IsAbs(name) {
+			return "", fmt.Errorf("%s contains an unsafe path %s", archive, hdr.Name)
+		}
+		if err = writeFile(filepath.Join(tmpDir, name), tr); err != nil {
+			return "", err
+		}
+	}
+	if err = os.Rename(tmpDir, dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+func writeFile(name string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Use makes b the source of every artifact download for the rest of the run
+func Use(b *Bundle) {
+	active = b
+}
+
+// Active reports whether a bundle is in use
+func Active() bool {
+	return active != nil
+}
+
+// URL returns where to fetch u from: its copy in the active bundle, or u itself if there isn't one.
+// Artifacts missing from the bundle are an error rather than a silent trip to the internet
+func URL(u string) (string, error) {
+	if active == nil {
+		return u, nil
+	}
+	mapped, ok := active.urls[u]
+	if !ok {
+		return "", fmt.Errorf("%s is not in bundle %s, was it created by a different version of concourse-up?", u, active.location)
+	}
+	return mapped, nil
+}
+
+// Get fetches u, from the active bundle if there is one
+func Get(u string) (*http.Response, error) {
+	mapped, err := URL(u)
+	if err != nil {
+		return nil, err
+	}
+	return client.Get(mapped)
+}
+
+// LocalPath strips the file scheme from URLs that point into an extracted bundle,
+// for tools such as `bosh upload-stemcell` that expect a path
+func LocalPath(u string) string {
+	if strings.HasPrefix(u, "file://") {
+		return filepath.FromSlash(strings.TrimPrefix(u, "file://"))
+	}
+	return u
+}
+
+// Rewrite replaces every release and stemcell URL in a manifest or ops file with its copy in the active bundle
+func Rewrite(contents string) (string, error) {
+	if active == nil {
+		return contents, nil
+	}
+	var rewritten strings.Builder
+	last := 0
+	for _, m := range artifactURLPattern.FindAllStringSubmatchIndex(contents, -1) {
+		mapped, err := URL(contents[m[2]:m[3]])
+		if err != nil {
+			return "", err
+		}
+		rewritten.WriteString(contents[last:m[2]])
+		rewritten.WriteString(mapped)
+		last = m[3]
+	}
+	rewritten.WriteString(contents[last:])
+	return rewritten.String(), nil
+}
+
+// matches both `url: <url>` in manifests and `"path": ".../url", "value": "<url>"` in ops files
+var artifactURLPattern = regexp.MustCompile(`(?m)(?:^|[\s"/])url"?\s*(?::|,\s*"value"\s*:)\s*"?(https?://[^\s"']+)`)
+
+// ArtifactURLs finds the release and stemcell URLs in manifests and ops files
+func ArtifactURLs(contents ...string) []string {
+	var urls []string
+	for _, c := range contents {
+		for _, m := range artifactURLPattern.FindAllStringSubmatch(c, -1) {
+			urls = append(urls, m[1])
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
@@ -0,0 +1,168 @@
+package bundle
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func setup(t *testing.T) (string, *httptest.Server, func()) {
+	dir, err := ioutil.TempDir("", "bundle-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheDir := os.Getenv("XDG_CACHE_HOME")
+	os.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, "contents of %s", r.URL.Path)
+	}))
+
+	return dir, upstream, func() {
+		Use(nil)
+		upstream.Close()
+		os.Setenv("XDG_CACHE_HOME", cacheDir)
+		os.RemoveAll(dir)
+	}
+}
+
+func get(t *testing.T, u string) string {
+	resp, err := Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestCreateAndOpen(t *testing.T) {
+	dir, upstream, teardown := setup(t)
+	defer teardown()
+
+	cli := upstream.URL + "/bosh-cli-linux-amd64"
+	release := upstream.URL + "/d/github.com/concourse/concourse?v=4.2.2"
+	archive := filepath.Join(dir, "bundle.tgz")
+	var buf bytes.Buffer
+	if err := Create(&buf, "1.2.3", []string{cli, release, cli}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(archive, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := Open(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Use(b)
+	upstream.Close()
+
+	if got := get(t, cli); got != "contents of /bosh-cli-linux-amd64" {
+		t.Errorf("Get(%s) = %q", cli, got)
+	}
+	releaseURL, err := URL(release)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(releaseURL, "file://") {
+		t.Errorf("URL(%s) = %s, want a file URL", release, releaseURL)
+	}
+	if _, err = os.Stat(LocalPath(releaseURL)); err != nil {
+		t.Errorf("LocalPath(URL(%s)) does not exist: %v", release, err)
+	}
+	if _, err = URL("https://example.com/not-bundled"); err == nil {
+		t.Errorf("expected an error looking up an artifact missing from the bundle")
+	}
+	if _, err = Get("https://example.com/not-bundled"); err == nil {
+		t.Errorf("expected an error fetching an artifact missing from the bundle")
+	}
+
+	manifest := fmt.Sprintf("releases:\n- name: concourse\n  url: %s\nexternal_url: https://example.com\n", release)
+	want := fmt.Sprintf("releases:\n- name: concourse\n  url: %s\nexternal_url: https://example.com\n", releaseURL)
+	got, err := Rewrite(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("Rewrite() = %q, want %q", got, want)
+	}
+	if _, err = Rewrite(manifest + "- name: other\n  url: https://example.com/other\n"); err == nil {
+		t.Errorf("expected an error rewriting a release missing from the bundle")
+	}
+}
+
+func TestOpenMirror(t *testing.T) {
+	dir, upstream, teardown := setup(t)
+	defer teardown()
+
+	cli := upstream.URL + "/terraform.zip"
+	archive := filepath.Join(dir, "bundle.tgz")
+	var buf bytes.Buffer
+	if err := Create(&buf, "1.2.3", []string{cli}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(archive, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	extracted, err := extract(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mirror := httptest.NewServer(http.FileServer(http.Dir(extracted)))
+	defer mirror.Close()
+
+	b, err := Open(mirror.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	Use(b)
+
+	cliURL, err := URL(cli)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(cliURL, mirror.URL+"/artifacts/") || !strings.HasSuffix(cliURL, "-terraform.zip") {
+		t.Errorf("URL(%s) = %s, want an artifact on the mirror", cli, cliURL)
+	}
+	if got := get(t, cli); got != "contents of /terraform.zip" {
+		t.Errorf("Get(%s) = %q", cli, got)
+	}
+}
+
+func TestCreate_MissingArtifact(t *testing.T) {
+	_, upstream, teardown := setup(t)
+	defer teardown()
+
+	if err := Create(ioutil.Discard, "1.2.3", []string{upstream.URL + "/missing"}); err == nil {
+		t.Errorf("expected an error bundling a missing artifact")
+	}
+}
+
+func TestArtifactURLs(t *testing.T) {
+	manifest := "releases:\n- name: bpm\n  url: https://bosh.io/d/github.com/cloudfoundry-incubator/bpm-release?v=1.0.0\n  sha1: abc\nexternal_url: https://((domain))\n"
+	ops := `[{"type": "replace", "path": "/releases/name=concourse/url", "value": "https://bosh.io/d/github.com/concourse/concourse?v=4.2.2"}]`
+
+	got := ArtifactURLs(manifest, ops)
+	want := []string{
+		"https://bosh.io/d/github.com/cloudfoundry-incubator/bpm-release?v=1.0.0",
+		"https://bosh.io/d/github.com/concourse/concourse?v=4.2.2",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ArtifactURLs() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,124 @@
+package certs
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Certificate describes a certificate found in a document by Inspect
+type Certificate struct {
+	Source        string    `json:"source"`
+	Name          string    `json:"name"`
+	Subject       string    `json:"subject"`
+	SANs          []string  `json:"sans"`
+	Issuer        string    `json:"issuer"`
+	NotAfter      time.Time `json:"not_after"`
+	DaysRemaining int       `json:"days_remaining"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// Inspect finds every PEM encoded certificate in the string values of a JSON or YAML document.
+// Each is named by its path in the document, with an index appended when a value holds a chain.
+// A value that can't be parsed is reported with its Error set rather than failing the rest.
+// Certificates are returned soonest to expire first, after any that can't be read
+func Inspect(source string, document []byte, now time.Time) ([]Certificate, error) {
+	var doc interface{}
+	if err := json.Unmarshal(document, &doc); err != nil {
+		if err = yaml.Unmarshal(document, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: [%v]", source, err)
+		}
+	}
+
+	var found []Certificate
+	var walk func(path string, value interface{})
+	walk = func(path string, value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key, child := range v {
+				walk(join(path, key), child)
+			}
+		case map[interface{}]interface{}:
+			for key, child := range v {
+				walk(join(path, fmt.Sprint(key)), child)
+			}
+		case []interface{}:
+			for i, child := range v {
+				walk(join(path, strconv.Itoa(i)), child)
+			}
+		case string:
+			chain, err := parseCertificates(v)
+			if err != nil {
+				found = append(found, Certificate{Source: source, Name: path, Error: fmt.Sprintf("unreadable: %v", err)})
+				return
+			}
+			for i, c := range chain {
+				name := path
+				if len(chain) > 1 {
+					name = fmt.Sprintf("%s[%d]", path, i)
+				}
+				found = append(found, describe(source, name, c, now))
+			}
+		}
+	}
+	walk("", doc)
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].NotAfter.Equal(found[j].NotAfter) {
+			return found[i].Name < found[j].Name
+		}
+		return found[i].NotAfter.Before(found[j].NotAfter)
+	})
+	return found, nil
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "/" + key
+}
+
+// parseCertificates returns every certificate PEM block in s
+func parseCertificates(s string) ([]*x509.Certificate, error) {
+	var chain []*x509.Certificate
+	rest := []byte(s)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return chain, nil
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, c)
+	}
+}
+
+func describe(source, name string, c *x509.Certificate, now time.Time) Certificate {
+	sans := append([]string{}, c.DNSNames...)
+	for _, ip := range c.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return Certificate{
+		Source:        source,
+		Name:          name,
+		Subject:       c.Subject.String(),
+		SANs:          sans,
+		Issuer:        c.Issuer.String(),
+		NotAfter:      c.NotAfter,
+		DaysRemaining: int(math.Floor(c.NotAfter.Sub(now).Hours() / 24)),
+	}
+}
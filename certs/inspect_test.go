@@ -0,0 +1,92 @@
+package certs_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+
+	. "github.com/EngineerBetter/concourse-up/certs"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Inspect", func() {
+	var now = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	selfSigned := func(cn string, notAfter time.Time) string {
+		key, err := rsa.GenerateKey(rand.Reader, 1024)
+		Expect(err).ToNot(HaveOccurred())
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: cn, Organization: []string{"EngineerBetter"}},
+			DNSNames:     []string{cn},
+			IPAddresses:  []net.IP{net.ParseIP("10.0.0.6")},
+			NotBefore:    now.Add(-time.Hour),
+			NotAfter:     notAfter,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		Expect(err).ToNot(HaveOccurred())
+		return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	}
+
+	indent := func(s string) string {
+		return strings.Replace(strings.TrimSpace(s), "\n", "\n    ", -1)
+	}
+
+	It("Describes every certificate in a YAML document, soonest to expire first", func() {
+		ca := selfSigned("ca.example.com", now.Add(30*24*time.Hour))
+		leaf := selfSigned("leaf.example.com", now.Add(10*24*time.Hour+time.Hour))
+		doc := "nats_server_tls:\n  certificate: |\n    " + indent(leaf) + "\n  ca: |\n    " + indent(ca) + "\n  private_key: not a certificate\n"
+
+		certificates, err := Inspect("director-creds.yml", []byte(doc), now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(certificates).To(HaveLen(2))
+
+		Expect(certificates[0]).To(Equal(Certificate{
+			Source:        "director-creds.yml",
+			Name:          "nats_server_tls/certificate",
+			Subject:       "CN=leaf.example.com,O=EngineerBetter",
+			SANs:          []string{"leaf.example.com", "10.0.0.6"},
+			Issuer:        "CN=leaf.example.com,O=EngineerBetter",
+			NotAfter:      certificates[0].NotAfter,
+			DaysRemaining: 10,
+		}))
+		Expect(certificates[0].NotAfter).To(BeTemporally("~", now.Add(10*24*time.Hour+time.Hour), time.Second))
+		Expect(certificates[1].Name).To(Equal("nats_server_tls/ca"))
+		Expect(certificates[1].DaysRemaining).To(Equal(30))
+	})
+
+	It("Names each certificate in a chain in a JSON document", func() {
+		chain := selfSigned("a.example.com", now.Add(-24*time.Hour)) + selfSigned("b.example.com", now.Add(24*time.Hour))
+		doc, err := json.Marshal(map[string]string{"concourse_cert": chain, "domain": "ci.example.com"})
+		Expect(err).ToNot(HaveOccurred())
+
+		certificates, err := Inspect("config.json", doc, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(certificates).To(HaveLen(2))
+		Expect(certificates[0].Name).To(Equal("concourse_cert[0]"))
+		Expect(certificates[0].DaysRemaining).To(Equal(-1))
+		Expect(certificates[1].Name).To(Equal("concourse_cert[1]"))
+	})
+
+	It("Reports a certificate that can't be parsed and carries on", func() {
+		ca := selfSigned("ca.example.com", now.Add(30*24*time.Hour))
+		doc := "director_cert: |\n  -----BEGIN CERTIFICATE-----\n  bm90IGEgY2VydGlmaWNhdGU=\n  -----END CERTIFICATE-----\ndirector_ca_cert: |\n  " + strings.Replace(strings.TrimSpace(ca), "\n", "\n  ", -1) + "\n"
+		certificates, err := Inspect("config.json", []byte(doc), now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(certificates).To(HaveLen(2))
+		Expect(certificates[0].Name).To(Equal("director_cert"))
+		Expect(certificates[0].Error).To(HavePrefix("unreadable"))
+		Expect(certificates[1].Name).To(Equal("director_ca_cert"))
+		Expect(certificates[1].Error).To(BeEmpty())
+		Expect(certificates[1].DaysRemaining).To(Equal(30))
+	})
+})
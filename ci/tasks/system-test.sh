@@ -120,6 +120,8 @@ after_timestamp="$(date -d "$after" +"%s")"
 
 [[ $before_timestamp -lt $after_timestamp ]]
 
+./cup info "$deployment" --cert-expiry --json | jq -e 'map(select(.name == "nats_server_tls/ca")) | length == 1'
+
 sleep 60
 
 assertPipelinesCanReadFromCredhub
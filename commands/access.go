@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/certs"
+	"github.com/EngineerBetter/concourse-up/commands/access"
+	"github.com/EngineerBetter/concourse-up/concourse"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/fly"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	"github.com/EngineerBetter/concourse-up/util"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var initialAccessArgs access.Args
+
+var accessFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialAccessArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS, can be AWS or GCP",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialAccessArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialAccessArgs.Namespace,
+	},
+}
+
+func accessAddAction(c *cli.Context, accessArgs access.Args, provider iaas.Provider) error {
+	name, cidr := c.Args().Get(0), c.Args().Get(1)
+	if name == "" || cidr == "" {
+		return errors.New("Usage is `concourse-up access add <name> <cidr>`")
+	}
+
+	client, err := accessClientFromContext(c, name, accessArgs, provider)
+	if err != nil {
+		return err
+	}
+	return client.AddAccess(cidr)
+}
+
+func accessRemoveAction(c *cli.Context, accessArgs access.Args, provider iaas.Provider) error {
+	name, cidr := c.Args().Get(0), c.Args().Get(1)
+	if name == "" || cidr == "" {
+		return errors.New("Usage is `concourse-up access remove <name> <cidr>`")
+	}
+
+	client, err := accessClientFromContext(c, name, accessArgs, provider)
+	if err != nil {
+		return err
+	}
+	return client.RemoveAccess(cidr)
+}
+
+func accessListAction(c *cli.Context, accessArgs access.Args, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return errors.New("Usage is `concourse-up access list <name>`")
+	}
+
+	client, err := accessClientFromContext(c, name, accessArgs, provider)
+	if err != nil {
+		return err
+	}
+	cidrs, err := client.ListAccess()
+	if err != nil {
+		return err
+	}
+	for _, cidr := range cidrs {
+		fmt.Fprintln(os.Stdout, cidr)
+	}
+	return nil
+}
+
+func accessClientFromContext(c *cli.Context, name string, accessArgs access.Args, provider iaas.Provider) (*concourse.Client, error) {
+	err := accessArgs.MarkSetFlags(c)
+	if err != nil {
+		return nil, err
+	}
+	return buildAccessClient(name, c.App.Version, accessArgs, provider)
+}
+
+func buildAccessClient(name, version string, accessArgs access.Args, provider iaas.Provider) (*concourse.Client, error) {
+	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform())
+	if err != nil {
+		return nil, err
+	}
+
+	tfInputVarsFactory, err := concourse.NewTFInputVarsFactory(provider)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating TFInputVarsFactory [%v]", err)
+	}
+
+	client := concourse.NewClient(
+		provider,
+		terraformClient,
+		tfInputVarsFactory,
+		bosh.New,
+		fly.New,
+		certs.Generate,
+		config.New(provider, name, accessArgs.Namespace),
+		nil,
+		os.Stdout,
+		os.Stderr,
+		util.FindUserIP,
+		certs.NewAcmeClient,
+		util.GeneratePasswordWithLength,
+		util.EightRandomLetters,
+		util.GenerateSSHKeyPair,
+		version,
+	)
+
+	return client, nil
+}
+
+// withAccessProvider builds the IAAS provider from the access flags before running the action
+func withAccessProvider(action func(*cli.Context, access.Args, iaas.Provider) error) func(*cli.Context) error {
+	return func(c *cli.Context) error {
+		iaasName, err := iaas.Assosiate(initialAccessArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, initialAccessArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on access: [%v]", err)
+		}
+		return action(c, initialAccessArgs, provider)
+	}
+}
+
+var accessCmd = cli.Command{
+	Name:  "access",
+	Usage: "Manages the list of CIDRs allowed to reach the BOSH director",
+	Subcommands: []cli.Command{
+		{
+			Name:      "add",
+			Usage:     "Allows a CIDR to reach the BOSH director",
+			ArgsUsage: "<name> <cidr>",
+			Flags:     accessFlags,
+			Action:    withAccessProvider(accessAddAction),
+		},
+		{
+			Name:      "remove",
+			Usage:     "Stops a CIDR from reaching the BOSH director",
+			ArgsUsage: "<name> <cidr>",
+			Flags:     accessFlags,
+			Action:    withAccessProvider(accessRemoveAction),
+		},
+		{
+			Name:      "list",
+			Usage:     "Lists the CIDRs allowed to reach the BOSH director",
+			ArgsUsage: "<name>",
+			Flags:     accessFlags,
+			Action:    withAccessProvider(accessListAction),
+		},
+	},
+}
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/EngineerBetter/concourse-up/bundle"
+	bundleargs "github.com/EngineerBetter/concourse-up/commands/bundle"
+	"github.com/EngineerBetter/concourse-up/concourse"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var initialBundleArgs bundleargs.Args
+
+var bundleFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS the bundle will be deployed to, can be AWS or GCP",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialBundleArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "os",
+		Usage:       "(optional) OS concourse-up will run on when using the bundle, can be linux or darwin",
+		Value:       runtime.GOOS,
+		Destination: &initialBundleArgs.OS,
+	},
+	cli.StringFlag{
+		Name:        "concourse-version",
+		Usage:       "(optional) Also bundle this version of Concourse, for deployments pinned with --concourse-version",
+		EnvVar:      "CONCOURSE_VERSION",
+		Destination: &initialBundleArgs.ConcourseVersion,
+	},
+}
+
+// bundleFlag is shared by every command that downloads binaries, releases or stemcells
+func bundleFlag(destination *string) cli.Flag {
+	return cli.StringFlag{
+		Name:        "bundle",
+		Usage:       "(optional) Archive created by the bundle command, or the URL of a mirror of its contents, to fetch binaries, releases and stemcells from",
+		EnvVar:      "CONCOURSE_UP_BUNDLE",
+		Destination: destination,
+	}
+}
+
+func useBundle(location string) error {
+	if location == "" {
+		return nil
+	}
+	b, err := bundle.Open(location)
+	if err != nil {
+		return fmt.Errorf("Error opening bundle %s: [%v]", location, err)
+	}
+	bundle.Use(b)
+	return nil
+}
+
+func bundleAction(c *cli.Context, bundleArgs bundleargs.Args) error {
+	filename := c.Args().Get(0)
+	if filename == "" {
+		return errors.New("Usage is `concourse-up bundle <file>`")
+	}
+
+	if err := bundleArgs.MarkSetFlags(c); err != nil {
+		return err
+	}
+	if err := bundleArgs.Validate(); err != nil {
+		return err
+	}
+
+	iaasName, err := iaas.Assosiate(bundleArgs.IAAS)
+	if err != nil {
+		return err
+	}
+	urls, err := concourse.BundleURLs(iaasName, bundleArgs.OS, bundleArgs.ConcourseVersion)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fmt.Fprintf(os.Stderr, "Bundling %d artifacts for %s on %s into %s\n", len(urls), iaasName, bundleArgs.OS, filename)
+	if err = bundle.Create(f, c.App.Version, urls); err != nil {
+		os.Remove(filename)
+		return err
+	}
+	return f.Close()
+}
+
+var bundleCmd = cli.Command{
+	Name:      "bundle",
+	Usage:     "Downloads every binary, release and stemcell needed to deploy into a single archive for use with --bundle",
+	ArgsUsage: "<file>",
+	Flags:     bundleFlags,
+	Action: func(c *cli.Context) error {
+		return bundleAction(c, initialBundleArgs)
+	},
+}
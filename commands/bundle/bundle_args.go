@@ -0,0 +1,60 @@
+package bundle
+
+import (
+	"fmt"
+
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+// Args are arguments passed to the bundle command
+type Args struct {
+	IAAS             string
+	OS               string
+	ConcourseVersion string
+}
+
+// MarkSetFlags is marking which bundle Args have been set
+func (a *Args) MarkSetFlags(c FlagSetChecker) error {
+	for _, f := range c.FlagNames() {
+		if c.IsSet(f) {
+			switch f {
+			case "iaas", "os", "concourse-version":
+				//do nothing
+			default:
+				return fmt.Errorf("flag %q is not supported by bundle flags", f)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks the bundle can be used from the requested OS
+func (a Args) Validate() error {
+	switch a.OS {
+	case "linux", "darwin":
+		return nil
+	default:
+		return fmt.Errorf("--os must be linux or darwin, not %q", a.OS)
+	}
+}
+
+// FlagSetChecker allows us to find out if flags were set, adn what the names of all flags are
+type FlagSetChecker interface {
+	IsSet(name string) bool
+	FlagNames() (names []string)
+}
+
+// ContextWrapper wraps a CLI context for testing
+type ContextWrapper struct {
+	c *cli.Context
+}
+
+// IsSet tells you if a user provided a flag
+func (t *ContextWrapper) IsSet(name string) bool {
+	return t.c.IsSet(name)
+}
+
+// FlagNames lists all flags it's possible for a user to provide
+func (t *ContextWrapper) FlagNames() (names []string) {
+	return t.c.FlagNames()
+}
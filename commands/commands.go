@@ -1,18 +1,29 @@
 package commands
 
 import (
+	"errors"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
 	cli "gopkg.in/urfave/cli.v1"
 )
 
 // Commands is a list of all supported CLI commands
 var Commands = []cli.Command{
+	accessCmd,
+	bundleCmd,
 	deployCmd,
 	destroyCmd,
+	driftCmd,
+	exportCmd,
+	importCmd,
 	infoCmd,
 	maintainCmd,
+	migrateCmd,
+	secretsCmd,
 }
 
 var nonInteractive bool
+var awsAuth iaas.AWSAuth
 
 // GlobalFlags are the global CLIflags
 var GlobalFlags = []cli.Flag{
@@ -22,6 +33,45 @@ var GlobalFlags = []cli.Flag{
 		Usage:       "Non interactive",
 		Destination: &nonInteractive,
 	},
+	cli.StringFlag{
+		Name:        "aws-profile",
+		EnvVar:      "AWS_PROFILE",
+		Usage:       "(optional) AWS named profile to load credentials from",
+		Destination: &awsAuth.Profile,
+	},
+	cli.StringFlag{
+		Name:        "assume-role-arn",
+		EnvVar:      "ASSUME_ROLE_ARN",
+		Usage:       "(optional) ARN of an IAM role to assume before talking to AWS, e.g. in a member account",
+		Destination: &awsAuth.RoleARN,
+	},
+	cli.StringFlag{
+		Name:        "external-id",
+		EnvVar:      "EXTERNAL_ID",
+		Usage:       "(optional) External ID required by the trust policy of --assume-role-arn",
+		Destination: &awsAuth.ExternalID,
+	},
+	cli.StringFlag{
+		Name:        "mfa-serial",
+		EnvVar:      "AWS_MFA_SERIAL",
+		Usage:       "(optional) Serial number or ARN of the MFA device to authenticate to AWS with",
+		Destination: &awsAuth.MFASerial,
+	},
+	cli.StringFlag{
+		Name:        "mfa-token",
+		EnvVar:      "AWS_MFA_TOKEN",
+		Usage:       "(optional) Current code from --mfa-serial, prompted for if not provided",
+		Destination: &awsAuth.MFAToken,
+	},
+}
+
+// Before applies the global flags before any command runs
+func Before(c *cli.Context) error {
+	if awsAuth.MFASerial != "" && awsAuth.MFAToken == "" && nonInteractive {
+		return errors.New("--mfa-serial requires --mfa-token to also be provided in non-interactive mode")
+	}
+	iaas.UseAWSAuth(awsAuth)
+	return nil
 }
 
 // NonInteractiveModeEnabled returns true if --non-interactive true has been passed in
@@ -42,6 +42,7 @@ var _ = Describe("commands", func() {
 				Expect(session.Out).To(Say("--private-subnet-range value\\s+\\(optional\\) private network CIDR \\(if IAAS is AWS must be within --vpc-network-range\\)"))
 				Expect(session.Out).To(Say("--rds-subnet-range1 value\\s+\\(optional\\) first rds network CIDR \\(if IAAS is AWS must be within --vpc-network-range\\)"))
 				Expect(session.Out).To(Say("--rds-subnet-range2 value\\s+\\(optional\\) second rds network CIDR \\(if IAAS is AWS must be within --vpc-network-range\\)"))
+				Expect(session.Out).To(Say("--bundle value"))
 			})
 		})
 
@@ -148,6 +149,72 @@ var _ = Describe("commands", func() {
 		})
 	})
 
+	Describe("drift", func() {
+		Context("When using --help", func() {
+			It("should display usage details", func() {
+				command := exec.Command(cliPath, "drift", "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("concourse-up drift - Reports changes made to a deployment's infrastructure"))
+			})
+		})
+
+		Context("When no name is passed in", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "drift")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up drift <name>`"))
+			})
+		})
+	})
+
+	Describe("export", func() {
+		Context("When using --help", func() {
+			It("should display usage details", func() {
+				command := exec.Command(cliPath, "export", "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("concourse-up export - Writes the terraform config and bosh manifests"))
+			})
+		})
+
+		Context("When no name is passed in", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "export")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up export <name>`"))
+			})
+		})
+	})
+
+	Describe("import", func() {
+		Context("When using --help", func() {
+			It("should display usage details", func() {
+				command := exec.Command(cliPath, "import", "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("concourse-up import - Adopts a Concourse deployed with bosh create-env and terraform"))
+			})
+		})
+
+		Context("When no name is passed in", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "import")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up import <name>`"))
+			})
+		})
+	})
+
 	Describe("info", func() {
 		Context("When using --help", func() {
 			It("should display usage details", func() {
@@ -169,4 +236,146 @@ var _ = Describe("commands", func() {
 			})
 		})
 	})
+
+	Describe("migrate", func() {
+		Context("When using --help", func() {
+			It("should display usage details", func() {
+				command := exec.Command(cliPath, "migrate", "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("concourse-up migrate - Moves a deployment and its databases to another region, namespace or name"))
+			})
+		})
+
+		Context("When no name is passed in", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "migrate")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up migrate <name>`"))
+			})
+		})
+	})
+
+	Describe("access", func() {
+		Context("When using --help", func() {
+			It("should display the subcommands", func() {
+				command := exec.Command(cliPath, "access", "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("add"))
+				Expect(session.Out).To(Say("remove"))
+				Expect(session.Out).To(Say("list"))
+			})
+		})
+
+		Context("When no CIDR is passed to add", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "access", "add", "abc")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up access add <name> <cidr>`"))
+			})
+		})
+
+		Context("When no name is passed to list", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "access", "list")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up access list <name>`"))
+			})
+		})
+	})
+
+	Describe("secrets", func() {
+		Context("When using --help", func() {
+			It("should display the subcommands", func() {
+				command := exec.Command(cliPath, "secrets", "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("set"))
+				Expect(session.Out).To(Say("get"))
+				Expect(session.Out).To(Say("list"))
+				Expect(session.Out).To(Say("delete"))
+				Expect(session.Out).To(Say("import"))
+			})
+		})
+
+		Context("When no name is passed in", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "secrets", "set")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up secrets set <name> <key> <value>`"))
+			})
+		})
+	})
+
+	Describe("bundle", func() {
+		Context("When using --help", func() {
+			It("should display usage details", func() {
+				command := exec.Command(cliPath, "bundle", "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("concourse-up bundle - Downloads every binary, release and stemcell needed to deploy into a single archive for use with --bundle"))
+				Expect(session.Out).To(Say("--iaas value"))
+				Expect(session.Out).To(Say("--os value"))
+			})
+		})
+
+		Context("When no file is passed in", func() {
+			It("should display correct usage", func() {
+				command := exec.Command(cliPath, "bundle")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("Usage is `concourse-up bundle <file>`"))
+			})
+		})
+
+		Context("When an unsupported OS is passed in", func() {
+			It("Should show a meaningful error", func() {
+				command := exec.Command(cliPath, "bundle", "--os", "windows", "bundle.tgz")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("--os must be linux or darwin, not \"windows\""))
+			})
+		})
+	})
+
+	Describe("global AWS credential flags", func() {
+		Context("When using --help", func() {
+			It("should list them", func() {
+				command := exec.Command(cliPath, "--help")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred(), "Error running CLI: "+cliPath)
+				Eventually(session).Should(Exit(0))
+				Expect(session.Out).To(Say("--aws-profile value"))
+				Expect(session.Out).To(Say("--assume-role-arn value"))
+				Expect(session.Out).To(Say("--external-id value"))
+				Expect(session.Out).To(Say("--mfa-serial value"))
+				Expect(session.Out).To(Say("--mfa-token value"))
+			})
+		})
+
+		Context("When an MFA device is given without a token in non-interactive mode", func() {
+			It("Should show a meaningful error", func() {
+				command := exec.Command(cliPath, "--non-interactive", "--mfa-serial", "arn:aws:iam::123456789012:mfa/user", "info", "abc")
+				session, err := Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(session).Should(Exit(1))
+				Expect(session.Err).To(Say("--mfa-serial requires --mfa-token to also be provided in non-interactive mode"))
+			})
+		})
+	})
 })
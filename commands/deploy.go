@@ -18,6 +18,7 @@ import (
 	"github.com/EngineerBetter/concourse-up/config"
 	"github.com/EngineerBetter/concourse-up/fly"
 	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/resource"
 	"github.com/EngineerBetter/concourse-up/util"
 
 	cli "gopkg.in/urfave/cli.v1"
@@ -66,6 +67,54 @@ var deployFlags = []cli.Flag{
 		Value:       "xlarge",
 		Destination: &initialDeployArgs.WorkerSize,
 	},
+	cli.StringFlag{
+		Name:        "worker-instance-type",
+		Usage:       "(optional) Instance type of Concourse workers, e.g. c5.4xlarge or n2-standard-16, used instead of --worker-size",
+		EnvVar:      "WORKER_INSTANCE_TYPE",
+		Destination: &initialDeployArgs.WorkerInstanceType,
+	},
+	cli.IntFlag{
+		Name:        "windows-workers",
+		Usage:       "(optional) Number of Windows workers to deploy alongside the Linux ones, tagged windows",
+		EnvVar:      "WINDOWS_WORKERS",
+		Destination: &initialDeployArgs.WindowsWorkerCount,
+	},
+	cli.StringFlag{
+		Name:        "windows-worker-instance-type",
+		Usage:       "(optional) Instance type of the Windows workers (default: m5.xlarge on AWS, n1-standard-4 on GCP)",
+		EnvVar:      "WINDOWS_WORKER_INSTANCE_TYPE",
+		Destination: &initialDeployArgs.WindowsWorkerInstanceType,
+	},
+	cli.IntFlag{
+		Name:        "arm-workers",
+		Usage:       "(optional) Number of arm64 workers to deploy alongside the Linux ones, tagged arm64. Requires --arm-stemcell-url, --arm-concourse-release-url and --arm-garden-runc-release-url",
+		EnvVar:      "ARM_WORKERS",
+		Destination: &initialDeployArgs.ARMWorkerCount,
+	},
+	cli.StringFlag{
+		Name:        "arm-worker-instance-type",
+		Usage:       "(optional) Instance type of the arm64 workers (default: m6g.xlarge on AWS, t2a-standard-4 on GCP)",
+		EnvVar:      "ARM_WORKER_INSTANCE_TYPE",
+		Destination: &initialDeployArgs.ARMWorkerInstanceType,
+	},
+	cli.StringFlag{
+		Name:        "arm-stemcell-url",
+		Usage:       "(optional) URL of the arm64 stemcell for the arm64 workers, which bosh.io doesn't publish",
+		EnvVar:      "ARM_STEMCELL_URL",
+		Destination: &initialDeployArgs.ARMStemcellURL,
+	},
+	cli.StringFlag{
+		Name:        "arm-concourse-release-url",
+		Usage:       "(optional) URL of a Concourse release named concourse-arm64 with packages compiled for the arm64 stemcell",
+		EnvVar:      "ARM_CONCOURSE_RELEASE_URL",
+		Destination: &initialDeployArgs.ARMConcourseReleaseURL,
+	},
+	cli.StringFlag{
+		Name:        "arm-garden-runc-release-url",
+		Usage:       "(optional) URL of a garden-runc release named garden-runc-arm64 with packages compiled for the arm64 stemcell",
+		EnvVar:      "ARM_GARDEN_RUNC_RELEASE_URL",
+		Destination: &initialDeployArgs.ARMGardenRunCReleaseURL,
+	},
 	cli.StringFlag{
 		Name:        "worker-type",
 		Usage:       "(optional) Specify a worker type for aws (m5 or m4)",
@@ -73,6 +122,31 @@ var deployFlags = []cli.Flag{
 		Value:       "m4",
 		Destination: &initialDeployArgs.WorkerType,
 	},
+	cli.IntFlag{
+		Name:        "worker-disk-size",
+		Usage:       "(optional) Size in GB of each worker's ephemeral disk",
+		EnvVar:      "WORKER_DISK_SIZE",
+		Value:       200,
+		Destination: &initialDeployArgs.WorkerDiskSize,
+	},
+	cli.StringFlag{
+		Name:        "worker-disk-type",
+		Usage:       "(optional) Volume type of the workers' disks. Can be gp2, gp3 or io1 on AWS (default: gp2) and pd-standard or pd-ssd on GCP (default: pd-ssd)",
+		EnvVar:      "WORKER_DISK_TYPE",
+		Destination: &initialDeployArgs.WorkerDiskType,
+	},
+	cli.IntFlag{
+		Name:        "worker-disk-iops",
+		Usage:       "(optional) Provisioned IOPS of the workers' disks. Only for io1 and gp3, and required for io1",
+		EnvVar:      "WORKER_DISK_IOPS",
+		Destination: &initialDeployArgs.WorkerDiskIOPS,
+	},
+	cli.IntFlag{
+		Name:        "worker-persistent-disk",
+		Usage:       "(optional) Size in GB of a persistent disk for each worker to keep its caches on when it is recreated. 0 for none",
+		EnvVar:      "WORKER_PERSISTENT_DISK",
+		Destination: &initialDeployArgs.WorkerPersistentDisk,
+	},
 	cli.StringFlag{
 		Name:        "web-size",
 		Usage:       "(optional) Size of Concourse web node. Can be small, medium, large, xlarge, 2xlarge",
@@ -80,6 +154,12 @@ var deployFlags = []cli.Flag{
 		Value:       "small",
 		Destination: &initialDeployArgs.WebSize,
 	},
+	cli.StringFlag{
+		Name:        "web-instance-type",
+		Usage:       "(optional) Instance type of the Concourse web node, e.g. t3.large or n2-standard-2, used instead of --web-size",
+		EnvVar:      "WEB_INSTANCE_TYPE",
+		Destination: &initialDeployArgs.WebInstanceType,
+	},
 	cli.StringFlag{
 		Name:        "iaas",
 		Usage:       "(optional) IAAS, can be AWS or GCP",
@@ -101,6 +181,25 @@ var deployFlags = []cli.Flag{
 		Value:       "small",
 		Destination: &initialDeployArgs.DBSize,
 	},
+	cli.StringFlag{
+		Name:        "db-instance-type",
+		Usage:       "(optional) RDS instance class or Cloud SQL tier of the Concourse database, e.g. db.r5.large or db-custom-2-8192, used instead of --db-size",
+		EnvVar:      "DB_INSTANCE_TYPE",
+		Destination: &initialDeployArgs.DBInstanceType,
+	},
+	cli.IntFlag{
+		Name:        "db-storage",
+		Usage:       "(optional) Storage in GB to create the database with. Can't be changed after the first deploy",
+		EnvVar:      "DB_STORAGE",
+		Value:       10,
+		Destination: &initialDeployArgs.DBStorage,
+	},
+	cli.IntFlag{
+		Name:        "db-max-storage",
+		Usage:       "(optional) Maximum storage in GB the database may grow to by itself. 0 turns off RDS storage autoscaling, and lets Cloud SQL grow without a limit",
+		EnvVar:      "DB_MAX_STORAGE",
+		Destination: &initialDeployArgs.DBMaxStorage,
+	},
 	cli.BoolTFlag{
 		Name:        "spot",
 		Usage:       "(optional) Use spot instances for workers. Can be true/false (default: true)",
@@ -179,6 +278,121 @@ var deployFlags = []cli.Flag{
 		EnvVar:      "RDS_SUBNET_RANGE2",
 		Destination: &initialDeployArgs.RDS2CIDR,
 	},
+	cli.StringFlag{
+		Name:        "credential-manager",
+		Usage:       "(optional) Credential manager for Concourse to use. Can be credhub, aws-secretsmanager, aws-ssm or vault. Concourse can't read GCP Secret Manager, so on GCP use credhub, or vault with the secrets copied from Secret Manager",
+		EnvVar:      "CREDENTIAL_MANAGER",
+		Value:       "credhub",
+		Destination: &initialDeployArgs.CredentialManager,
+	},
+	cli.StringFlag{
+		Name:        "vault-url",
+		Usage:       "(optional) URL of an external Vault, required if --credential-manager is vault",
+		EnvVar:      "CONCOURSE_VAULT_URL",
+		Destination: &initialDeployArgs.VaultURL,
+	},
+	cli.StringFlag{
+		Name:        "vault-ca-cert",
+		Usage:       "(optional) CA certificate of an external Vault, if not signed by a public CA",
+		EnvVar:      "CONCOURSE_VAULT_CA_CERT",
+		Destination: &initialDeployArgs.VaultCACert,
+	},
+	cli.StringFlag{
+		Name:        "vault-token",
+		Usage:       "(optional) Periodic token Concourse uses to authenticate to an external Vault",
+		EnvVar:      "CONCOURSE_VAULT_TOKEN",
+		Destination: &initialDeployArgs.VaultToken,
+	},
+	cli.StringFlag{
+		Name:        "vault-path-prefix",
+		Usage:       "(optional) Path under which Concourse looks up secrets in Vault",
+		EnvVar:      "CONCOURSE_VAULT_PATH_PREFIX",
+		Value:       "/concourse",
+		Destination: &initialDeployArgs.VaultPathPrefix,
+	},
+	cli.StringSliceFlag{
+		Name:  "worker-iam-policy",
+		Usage: "(optional) ARN of an IAM policy to attach to the workers' instance profile - Multiple policies can be attached with multiple uses of this flag (AWS only)",
+		Value: &initialDeployArgs.WorkerIAMPolicies,
+	},
+	cli.StringSliceFlag{
+		Name:  "worker-service-account-role",
+		Usage: "(optional) Role to grant the workers' service account - Multiple roles can be granted with multiple uses of this flag (GCP only)",
+		Value: &initialDeployArgs.WorkerServiceAccountRoles,
+	},
+	cli.StringFlag{
+		Name:        "concourse-version",
+		Usage:       "(optional) Version of Concourse to deploy and stay on across self-updates. Can be " + strings.Join(resource.ConcourseVersions(), ", "),
+		EnvVar:      "CONCOURSE_VERSION",
+		Destination: &initialDeployArgs.ConcourseVersion,
+	},
+	cli.StringFlag{
+		Name:        "self-update-channel",
+		Usage:       "(optional) Releases of concourse-up the self-update pipeline follows. Can be stable or pre-release",
+		EnvVar:      "SELF_UPDATE_CHANNEL",
+		Value:       "pre-release",
+		Destination: &initialDeployArgs.SelfUpdateChannel,
+	},
+	cli.StringFlag{
+		Name:        "self-update-version-range",
+		Usage:       "(optional) Semver range the self-update pipeline's releases must satisfy, e.g. \">= 0.20.0, < 1.0.0\"",
+		EnvVar:      "SELF_UPDATE_VERSION_RANGE",
+		Destination: &initialDeployArgs.SelfUpdateVersionRange,
+	},
+	cli.BoolFlag{
+		Name:        "self-update-approval",
+		Usage:       "(optional) Only self-update after the approve-self-update job has been triggered manually",
+		EnvVar:      "SELF_UPDATE_APPROVAL",
+		Destination: &initialDeployArgs.SelfUpdateApproval,
+	},
+	cli.StringFlag{
+		Name:        "maintenance-window",
+		Usage:       "(optional) Daily window, as HH:MM-HH:MM in UTC, in which the self-update pipeline may renew HTTPS certificates",
+		EnvVar:      "MAINTENANCE_WINDOW",
+		Destination: &initialDeployArgs.MaintenanceWindow,
+	},
+	cli.StringFlag{
+		Name:        "notify-slack-webhook",
+		Usage:       "(optional) Slack incoming webhook to tell when deploy, destroy, maintain, self-update or cert renewal succeed or fail",
+		EnvVar:      "NOTIFY_SLACK_WEBHOOK",
+		Destination: &initialDeployArgs.NotifySlackWebhook,
+	},
+	cli.StringFlag{
+		Name:        "notify-webhook",
+		Usage:       "(optional) URL to POST the same outcomes to as JSON",
+		EnvVar:      "NOTIFY_WEBHOOK",
+		Destination: &initialDeployArgs.NotifyWebhook,
+	},
+	cli.StringFlag{
+		Name:        "notify-email",
+		Usage:       "(optional) Address to email the same outcomes to, and from. Requires --notify-smtp-server",
+		EnvVar:      "NOTIFY_EMAIL",
+		Destination: &initialDeployArgs.NotifyEmail,
+	},
+	cli.StringFlag{
+		Name:        "notify-smtp-server",
+		Usage:       "(optional) SMTP server, as host:port, to send notification emails through",
+		EnvVar:      "NOTIFY_SMTP_SERVER",
+		Destination: &initialDeployArgs.NotifySMTPServer,
+	},
+	cli.StringFlag{
+		Name:        "notify-smtp-username",
+		Usage:       "(optional) Username to authenticate to the SMTP server with",
+		EnvVar:      "NOTIFY_SMTP_USERNAME",
+		Destination: &initialDeployArgs.NotifySMTPUsername,
+	},
+	cli.StringFlag{
+		Name:        "notify-smtp-password",
+		Usage:       "(optional) Password to authenticate to the SMTP server with",
+		EnvVar:      "NOTIFY_SMTP_PASSWORD",
+		Destination: &initialDeployArgs.NotifySMTPPassword,
+	},
+	cli.StringFlag{
+		Name:        "restore-from-snapshot",
+		Usage:       "(optional) Create a new deployment with the config and database from a final snapshot taken by destroy",
+		Destination: &initialDeployArgs.RestoreFromSnapshot,
+	},
+	bundleFlag(&initialDeployArgs.Bundle),
 }
 
 func deployAction(c *cli.Context, deployArgs deploy.Args, provider iaas.Provider) error {
@@ -382,6 +596,11 @@ func validateRDSSubnetSize(cidr *net.IPNet) bool {
 }
 
 func buildClient(name, version string, deployArgs deploy.Args, provider iaas.Provider) (*concourse.Client, error) {
+	if provider.IAAS() == iaas.AWS {
+		deployArgs.SelfUpdateRoleARN, deployArgs.SelfUpdateExternalID = iaas.AWSAssumedRole()
+		deployArgs.SelfUpdateRoleARNIsSet = deployArgs.SelfUpdateRoleARN != ""
+	}
+
 	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform())
 	if err != nil {
 		return nil, err
@@ -421,6 +640,9 @@ var deployCmd = cli.Command{
 	ArgsUsage: "<name>",
 	Flags:     deployFlags,
 	Action: func(c *cli.Context) error {
+		if err := useBundle(initialDeployArgs.Bundle); err != nil {
+			return err
+		}
 		iaasName, err := iaas.Assosiate(initialDeployArgs.IAAS)
 		if err != nil {
 			return err
@@ -3,13 +3,19 @@ package deploy
 import (
 	"errors"
 	"fmt"
+	"net"
 	"regexp"
+	"strings"
 
+	"github.com/EngineerBetter/concourse-up/resource"
 	"gopkg.in/urfave/cli.v1"
 )
 
 // Args are arguments passed to the deploy command
 type Args struct {
+	// Bundle is the archive or mirror created by `concourse-up bundle` to fetch artifacts from
+	Bundle           string
+	BundleIsSet      bool
 	IAAS             string
 	IAASIsSet        bool
 	Region           string
@@ -31,6 +37,10 @@ type Args struct {
 	DBSize           string
 	// DBSizeIsSet is true if the user has manually specified the db-size (ie, it's not the default)
 	DBSizeIsSet                 bool
+	DBStorage                   int
+	DBStorageIsSet              bool
+	DBMaxStorage                int
+	DBMaxStorageIsSet           bool
 	Namespace                   string
 	NamespaceIsSet              bool
 	AllowIPs                    string
@@ -61,6 +71,103 @@ type Args struct {
 	RDS1CIDRIsSet    bool
 	RDS2CIDR         string
 	RDS2CIDRIsSet    bool
+	// CredentialManager is the secrets backend the ATC is configured to use
+	CredentialManager      string
+	CredentialManagerIsSet bool
+	VaultURL               string
+	VaultURLIsSet          bool
+	VaultCACert            string
+	VaultCACertIsSet       bool
+	VaultToken             string
+	VaultTokenIsSet        bool
+	VaultPathPrefix        string
+	VaultPathPrefixIsSet   bool
+	// WorkerIAMPolicies are the ARNs of managed policies attached to the workers' instance profile
+	WorkerIAMPolicies      cli.StringSlice
+	WorkerIAMPoliciesIsSet bool
+	// WorkerServiceAccountRoles are the project roles granted to the workers' service account
+	WorkerServiceAccountRoles      cli.StringSlice
+	WorkerServiceAccountRolesIsSet bool
+	// ConcourseVersion pins the deployment to a version of Concourse from the catalogue
+	ConcourseVersion      string
+	ConcourseVersionIsSet bool
+	// SelfUpdateChannel is stable or pre-release, the releases of concourse-up the self-update pipeline follows
+	SelfUpdateChannel      string
+	SelfUpdateChannelIsSet bool
+	// SelfUpdateVersionRange is a semver constraint the self-update pipeline's releases must satisfy
+	SelfUpdateVersionRange      string
+	SelfUpdateVersionRangeIsSet bool
+	// SelfUpdateApproval adds a manually triggered job that must pass before self-update runs
+	SelfUpdateApproval      bool
+	SelfUpdateApprovalIsSet bool
+	// SelfUpdateRoleARN and SelfUpdateExternalID are the role the deploy assumed, which the
+	// self-update pipeline assumes from the workers' instance profile. They come from the global flags
+	SelfUpdateRoleARN      string
+	SelfUpdateRoleARNIsSet bool
+	SelfUpdateExternalID   string
+	// MaintenanceWindow is the daily HH:MM-HH:MM window, in UTC, in which HTTPS certs are renewed
+	MaintenanceWindow      string
+	MaintenanceWindowIsSet bool
+	// NotifySlackWebhook is a Slack incoming webhook told the outcome of deploys, destroys, maintenance and self-updates
+	NotifySlackWebhook      string
+	NotifySlackWebhookIsSet bool
+	// NotifyWebhook is a URL the outcomes are POSTed to as JSON
+	NotifyWebhook      string
+	NotifyWebhookIsSet bool
+	// NotifyEmail is an address the outcomes are emailed to, and from, through NotifySMTPServer
+	NotifyEmail             string
+	NotifyEmailIsSet        bool
+	NotifySMTPServer        string
+	NotifySMTPServerIsSet   bool
+	NotifySMTPUsername      string
+	NotifySMTPUsernameIsSet bool
+	NotifySMTPPassword      string
+	NotifySMTPPasswordIsSet bool
+	// RestoreFromSnapshot is the name of a final snapshot taken by destroy to create a new deployment from
+	RestoreFromSnapshot      string
+	RestoreFromSnapshotIsSet bool
+	// WorkerDiskSize is the size in GB of each worker's ephemeral disk
+	WorkerDiskSize      int
+	WorkerDiskSizeIsSet bool
+	// WorkerDiskType is the volume type of the workers' disks, gp2 on AWS and pd-ssd on GCP when empty
+	WorkerDiskType      string
+	WorkerDiskTypeIsSet bool
+	// WorkerDiskIOPS is the provisioned IOPS of the workers' disks, for io1 and gp3 volumes
+	WorkerDiskIOPS      int
+	WorkerDiskIOPSIsSet bool
+	// WorkerPersistentDisk is the size in GB of a persistent disk the workers keep their caches on, none when 0
+	WorkerPersistentDisk      int
+	WorkerPersistentDiskIsSet bool
+	// WorkerInstanceType is a raw instance type for the workers, used instead of WorkerSize
+	WorkerInstanceType      string
+	WorkerInstanceTypeIsSet bool
+	// WebInstanceType is a raw instance type for the web node, used instead of WebSize
+	WebInstanceType      string
+	WebInstanceTypeIsSet bool
+	// DBInstanceType is a raw RDS instance class or Cloud SQL tier, used instead of DBSize
+	DBInstanceType      string
+	DBInstanceTypeIsSet bool
+	// WindowsWorkerCount is the number of Windows workers, deployed alongside the Linux ones
+	WindowsWorkerCount      int
+	WindowsWorkerCountIsSet bool
+	// WindowsWorkerInstanceType is the instance type of the Windows workers
+	WindowsWorkerInstanceType      string
+	WindowsWorkerInstanceTypeIsSet bool
+	// ARMWorkerCount is the number of arm64 workers, deployed alongside the Linux ones
+	ARMWorkerCount      int
+	ARMWorkerCountIsSet bool
+	// ARMWorkerInstanceType is the instance type of the arm64 workers
+	ARMWorkerInstanceType      string
+	ARMWorkerInstanceTypeIsSet bool
+	// ARMStemcellURL is where the arm64 workers' stemcell is uploaded from
+	ARMStemcellURL      string
+	ARMStemcellURLIsSet bool
+	// ARMConcourseReleaseURL is where the Concourse release compiled for arm64 is uploaded from
+	ARMConcourseReleaseURL      string
+	ARMConcourseReleaseURLIsSet bool
+	// ARMGardenRunCReleaseURL is where the garden-runc release compiled for arm64 is uploaded from
+	ARMGardenRunCReleaseURL      string
+	ARMGardenRunCReleaseURLIsSet bool
 }
 
 // MarkSetFlags is marking the IsSet DeployArgs
@@ -88,6 +195,10 @@ func (a *Args) MarkSetFlags(c FlagSetChecker) error {
 				a.SelfUpdateIsSet = true
 			case "db-size":
 				a.DBSizeIsSet = true
+			case "db-storage":
+				a.DBStorageIsSet = true
+			case "db-max-storage":
+				a.DBMaxStorageIsSet = true
 			case "spot", "preemptible":
 				a.SpotIsSet = true
 			case "allow-ips":
@@ -104,6 +215,34 @@ func (a *Args) MarkSetFlags(c FlagSetChecker) error {
 				a.ZoneIsSet = true
 			case "worker-type":
 				a.WorkerTypeIsSet = true
+			case "worker-disk-size":
+				a.WorkerDiskSizeIsSet = true
+			case "worker-disk-type":
+				a.WorkerDiskTypeIsSet = true
+			case "worker-disk-iops":
+				a.WorkerDiskIOPSIsSet = true
+			case "worker-persistent-disk":
+				a.WorkerPersistentDiskIsSet = true
+			case "worker-instance-type":
+				a.WorkerInstanceTypeIsSet = true
+			case "web-instance-type":
+				a.WebInstanceTypeIsSet = true
+			case "db-instance-type":
+				a.DBInstanceTypeIsSet = true
+			case "windows-workers":
+				a.WindowsWorkerCountIsSet = true
+			case "windows-worker-instance-type":
+				a.WindowsWorkerInstanceTypeIsSet = true
+			case "arm-workers":
+				a.ARMWorkerCountIsSet = true
+			case "arm-worker-instance-type":
+				a.ARMWorkerInstanceTypeIsSet = true
+			case "arm-stemcell-url":
+				a.ARMStemcellURLIsSet = true
+			case "arm-concourse-release-url":
+				a.ARMConcourseReleaseURLIsSet = true
+			case "arm-garden-runc-release-url":
+				a.ARMGardenRunCReleaseURLIsSet = true
 			case "vpc-network-range":
 				a.NetworkCIDRIsSet = true
 			case "public-subnet-range":
@@ -114,6 +253,46 @@ func (a *Args) MarkSetFlags(c FlagSetChecker) error {
 				a.RDS1CIDRIsSet = true
 			case "rds-subnet-range2":
 				a.RDS2CIDRIsSet = true
+			case "credential-manager":
+				a.CredentialManagerIsSet = true
+			case "worker-iam-policy":
+				a.WorkerIAMPoliciesIsSet = true
+			case "worker-service-account-role":
+				a.WorkerServiceAccountRolesIsSet = true
+			case "vault-url":
+				a.VaultURLIsSet = true
+			case "vault-ca-cert":
+				a.VaultCACertIsSet = true
+			case "vault-token":
+				a.VaultTokenIsSet = true
+			case "vault-path-prefix":
+				a.VaultPathPrefixIsSet = true
+			case "bundle":
+				a.BundleIsSet = true
+			case "concourse-version":
+				a.ConcourseVersionIsSet = true
+			case "self-update-channel":
+				a.SelfUpdateChannelIsSet = true
+			case "self-update-version-range":
+				a.SelfUpdateVersionRangeIsSet = true
+			case "self-update-approval":
+				a.SelfUpdateApprovalIsSet = true
+			case "maintenance-window":
+				a.MaintenanceWindowIsSet = true
+			case "notify-slack-webhook":
+				a.NotifySlackWebhookIsSet = true
+			case "notify-webhook":
+				a.NotifyWebhookIsSet = true
+			case "notify-email":
+				a.NotifyEmailIsSet = true
+			case "notify-smtp-server":
+				a.NotifySMTPServerIsSet = true
+			case "notify-smtp-username":
+				a.NotifySMTPUsernameIsSet = true
+			case "notify-smtp-password":
+				a.NotifySMTPPasswordIsSet = true
+			case "restore-from-snapshot":
+				a.RestoreFromSnapshotIsSet = true
 			default:
 				return fmt.Errorf("flag %q is not supported by deployment flags", f)
 			}
@@ -133,6 +312,18 @@ var WebSizes = []string{"small", "medium", "large", "xlarge", "2xlarge"}
 // AllowedDBSizes contains the valid values for --db-size flag
 var AllowedDBSizes = []string{"small", "medium", "large", "xlarge", "2xlarge", "4xlarge"}
 
+// AWSWorkerDiskTypes are the permitted values for --worker-disk-type on AWS
+var AWSWorkerDiskTypes = []string{"gp2", "gp3", "io1"}
+
+// GCPWorkerDiskTypes are the permitted values for --worker-disk-type on GCP
+var GCPWorkerDiskTypes = []string{"pd-standard", "pd-ssd"}
+
+// SelfUpdateChannels are the permitted values for --self-update-channel
+var SelfUpdateChannels = []string{"stable", "pre-release"}
+
+// CredentialManagers are the permitted values for --credential-manager
+var CredentialManagers = []string{"credhub", "aws-secretsmanager", "aws-ssm", "vault"}
+
 // ModifyGithub allows mutation of github related fields
 func (a *Args) ModifyGithub(GithubAuthClientID, GithubAuthClientSecret string, GithubAuthIsSet bool) {
 	a.GithubAuthClientID = GithubAuthClientID
@@ -150,6 +341,10 @@ func (a Args) Validate() error {
 		return err
 	}
 
+	if err := a.validateWorkerDiskFields(); err != nil {
+		return err
+	}
+
 	if err := a.validateWebFields(); err != nil {
 		return err
 	}
@@ -158,6 +353,14 @@ func (a Args) Validate() error {
 		return err
 	}
 
+	if err := a.validateInstanceTypes(); err != nil {
+		return err
+	}
+
+	if err := a.validateWorkerPools(); err != nil {
+		return err
+	}
+
 	if err := a.validateGithubFields(); err != nil {
 		return err
 	}
@@ -170,9 +373,111 @@ func (a Args) Validate() error {
 		return err
 	}
 
+	if err := a.validateCredentialManagerFields(); err != nil {
+		return err
+	}
+
+	if err := a.validateWorkerIdentityFields(); err != nil {
+		return err
+	}
+
+	if err := a.validateConcourseVersion(); err != nil {
+		return err
+	}
+
+	if err := a.validateSelfUpdateFields(); err != nil {
+		return err
+	}
+
+	if err := a.validateNotifyFields(); err != nil {
+		return err
+	}
+
+	if err := a.validateRestoreFields(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var snapshotNameRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]*$`)
+
+func (a Args) validateRestoreFields() error {
+	if !a.RestoreFromSnapshotIsSet {
+		return nil
+	}
+	if !snapshotNameRegex.MatchString(a.RestoreFromSnapshot) {
+		return fmt.Errorf("`%s` is not a snapshot name, as printed by destroy", a.RestoreFromSnapshot)
+	}
+	if a.SelfUpdate {
+		return errors.New("--restore-from-snapshot cannot be used with --self-update")
+	}
+	return nil
+}
+
+func (a Args) validateNotifyFields() error {
+	if a.NotifySlackWebhook != "" && !isHTTPURL(a.NotifySlackWebhook) {
+		return errors.New("--notify-slack-webhook must be an http or https URL")
+	}
+	if a.NotifyWebhook != "" && !isHTTPURL(a.NotifyWebhook) {
+		return errors.New("--notify-webhook must be an http or https URL")
+	}
+	if a.NotifyEmail != "" && a.NotifySMTPServer == "" {
+		return errors.New("--notify-email requires --notify-smtp-server to also be provided")
+	}
+	if a.NotifySMTPServer != "" {
+		if a.NotifyEmail == "" {
+			return errors.New("--notify-smtp-server requires --notify-email to also be provided")
+		}
+		if _, _, err := net.SplitHostPort(a.NotifySMTPServer); err != nil {
+			return fmt.Errorf("`%s` is not in the format `host:port`", a.NotifySMTPServer)
+		}
+	}
+	if a.NotifySMTPUsername != "" && a.NotifySMTPPassword == "" {
+		return errors.New("--notify-smtp-username requires --notify-smtp-password to also be provided")
+	}
+
+	return nil
+}
+
+var (
+	semverRangeRegex       = regexp.MustCompile(`^[\dxX*<>=!~^|,. v-]*\d[\dxX*<>=!~^|,. v-]*$`)
+	maintenanceWindowRegex = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d-([01]\d|2[0-3]):[0-5]\d$`)
+)
+
+func (a Args) validateSelfUpdateFields() error {
+	if a.SelfUpdateChannel != "" {
+		valid := false
+		for _, channel := range SelfUpdateChannels {
+			if a.SelfUpdateChannel == channel {
+				valid = true
+			}
+		}
+		if !valid {
+			return fmt.Errorf("unknown self-update channel: `%s`. Valid channels are: %v", a.SelfUpdateChannel, SelfUpdateChannels)
+		}
+	}
+	if a.SelfUpdateVersionRange != "" && !semverRangeRegex.MatchString(a.SelfUpdateVersionRange) {
+		return fmt.Errorf("`%s` is not a semver range, e.g. \">= 0.20.0, < 1.0.0\"", a.SelfUpdateVersionRange)
+	}
+	if a.MaintenanceWindow != "" && !maintenanceWindowRegex.MatchString(a.MaintenanceWindow) {
+		return fmt.Errorf("`%s` is not a maintenance window, use HH:MM-HH:MM in UTC, e.g. 02:00-04:00", a.MaintenanceWindow)
+	}
 	return nil
 }
 
+func isHTTPURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
+
+func (a Args) validateConcourseVersion() error {
+	if a.ConcourseVersion == "" {
+		return nil
+	}
+	_, err := resource.GetConcourseRelease(a.ConcourseVersion)
+	return err
+}
+
 func (a Args) validateCertFields() error {
 	if a.TLSKey != "" && a.TLSCert == "" {
 		return errors.New("--tls-key requires --tls-cert to also be provided")
@@ -200,6 +505,44 @@ func (a Args) validateWorkerFields() error {
 	return fmt.Errorf("unknown worker size: `%s`. Valid sizes are: %v", a.WorkerSize, WorkerSizes)
 }
 
+func (a Args) validateWorkerDiskFields() error {
+	if a.WorkerDiskSizeIsSet && (a.WorkerDiskSize < 20 || a.WorkerDiskSize > 16384) {
+		return fmt.Errorf("worker disk size must be between 20 and 16384 GB, not %d", a.WorkerDiskSize)
+	}
+	if a.WorkerPersistentDisk != 0 && (a.WorkerPersistentDisk < 10 || a.WorkerPersistentDisk > 16384) {
+		return fmt.Errorf("worker persistent disk must be between 10 and 16384 GB, not %d", a.WorkerPersistentDisk)
+	}
+
+	if a.WorkerDiskType != "" {
+		diskTypes := AWSWorkerDiskTypes
+		if strings.EqualFold(a.IAAS, "GCP") {
+			diskTypes = GCPWorkerDiskTypes
+		}
+		valid := false
+		for _, diskType := range diskTypes {
+			if a.WorkerDiskType == diskType {
+				valid = true
+			}
+		}
+		if !valid {
+			return fmt.Errorf("unknown worker disk type on %s: `%s`. Valid types are: %v", strings.ToUpper(a.IAAS), a.WorkerDiskType, diskTypes)
+		}
+	}
+
+	provisioned := a.WorkerDiskType == "io1" || a.WorkerDiskType == "gp3"
+	if a.WorkerDiskIOPS != 0 && !provisioned {
+		return errors.New("--worker-disk-iops can only be used with --worker-disk-type io1 or gp3")
+	}
+	if a.WorkerDiskType == "io1" && a.WorkerDiskIOPS == 0 {
+		return errors.New("--worker-disk-type io1 requires --worker-disk-iops to also be provided")
+	}
+	if a.WorkerDiskIOPS < 0 {
+		return fmt.Errorf("worker disk IOPS must be positive, not %d", a.WorkerDiskIOPS)
+	}
+
+	return nil
+}
+
 func (a Args) validateWebFields() error {
 	for _, size := range WebSizes {
 		if size == a.WebSize {
@@ -210,6 +553,12 @@ func (a Args) validateWebFields() error {
 }
 
 func (a Args) validateDBFields() error {
+	if a.DBStorageIsSet && (a.DBStorage < 10 || a.DBStorage > 16384) {
+		return fmt.Errorf("database storage must be between 10 and 16384 GB, not %d", a.DBStorage)
+	}
+	if a.DBMaxStorageIsSet && a.DBMaxStorage != 0 && (a.DBMaxStorage < a.DBStorage || a.DBMaxStorage > 16384) {
+		return fmt.Errorf("maximum database storage must be between the database storage of %d GB and 16384 GB, not %d", a.DBStorage, a.DBMaxStorage)
+	}
 	for _, size := range AllowedDBSizes {
 		if size == a.DBSize {
 			return nil
@@ -218,6 +567,45 @@ func (a Args) validateDBFields() error {
 	return fmt.Errorf("unknown DB size: `%s`. Valid sizes are: %v", a.DBSize, AllowedDBSizes)
 }
 
+var instanceTypeRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]*$`)
+
+func (a Args) validateInstanceTypes() error {
+	for _, t := range []struct {
+		flag, instanceType, sizeFlag string
+		sizeIsSet                    bool
+	}{
+		{"--worker-instance-type", a.WorkerInstanceType, "--worker-size", a.WorkerSizeIsSet},
+		{"--web-instance-type", a.WebInstanceType, "--web-size", a.WebSizeIsSet},
+		{"--db-instance-type", a.DBInstanceType, "--db-size", a.DBSizeIsSet},
+	} {
+		if t.instanceType == "" {
+			continue
+		}
+		if t.sizeIsSet {
+			return fmt.Errorf("%s cannot be used with %s", t.flag, t.sizeFlag)
+		}
+		if !instanceTypeRegex.MatchString(t.instanceType) {
+			return fmt.Errorf("`%s` is not an instance type", t.instanceType)
+		}
+	}
+	return nil
+}
+
+func (a Args) validateWorkerPools() error {
+	if a.WindowsWorkerCount < 0 {
+		return errors.New("--windows-workers cannot be negative")
+	}
+	if a.ARMWorkerCount < 0 {
+		return errors.New("--arm-workers cannot be negative")
+	}
+	for _, instanceType := range []string{a.WindowsWorkerInstanceType, a.ARMWorkerInstanceType} {
+		if instanceType != "" && !instanceTypeRegex.MatchString(instanceType) {
+			return fmt.Errorf("`%s` is not an instance type", instanceType)
+		}
+	}
+	return nil
+}
+
 func (a Args) validateGithubFields() error {
 	if a.GithubAuthClientID != "" && a.GithubAuthClientSecret == "" {
 		return errors.New("--github-auth-client-id requires --github-auth-client-secret to also be provided")
@@ -252,6 +640,47 @@ func (a Args) validateTags() error {
 	return nil
 }
 
+func (a Args) validateCredentialManagerFields() error {
+	switch a.CredentialManager {
+	case "", "credhub":
+	case "aws-secretsmanager", "aws-ssm":
+		if !strings.EqualFold(a.IAAS, "AWS") {
+			return fmt.Errorf("--credential-manager %s is only available when IAAS is AWS", a.CredentialManager)
+		}
+	case "vault":
+		if a.VaultURL == "" || a.VaultToken == "" {
+			return errors.New("--credential-manager vault requires --vault-url and --vault-token to also be provided")
+		}
+	case "gcp-secretmanager":
+		return errors.New("Concourse has no GCP Secret Manager backend, so concourse-up can't point it at one. Use --credential-manager credhub and load the secrets with `concourse-up secrets import`, or --credential-manager vault with the secrets copied from Secret Manager into Vault")
+	default:
+		return fmt.Errorf("unknown credential manager: `%s`. Valid credential managers are: %v", a.CredentialManager, CredentialManagers)
+	}
+
+	return nil
+}
+
+func (a Args) validateWorkerIdentityFields() error {
+	if len(a.WorkerIAMPolicies) > 0 && !strings.EqualFold(a.IAAS, "AWS") {
+		return errors.New("--worker-iam-policy is only available when IAAS is AWS, use --worker-service-account-role on GCP")
+	}
+	if len(a.WorkerServiceAccountRoles) > 0 && !strings.EqualFold(a.IAAS, "GCP") {
+		return errors.New("--worker-service-account-role is only available when IAAS is GCP, use --worker-iam-policy on AWS")
+	}
+	for _, policy := range a.WorkerIAMPolicies {
+		if !strings.HasPrefix(policy, "arn:") {
+			return fmt.Errorf("`%s` is not a policy ARN, e.g. arn:aws:iam::aws:policy/AmazonS3ReadOnlyAccess", policy)
+		}
+	}
+	for _, role := range a.WorkerServiceAccountRoles {
+		if !strings.HasPrefix(role, "roles/") && !strings.HasPrefix(role, "projects/") {
+			return fmt.Errorf("`%s` is not a GCP role, e.g. roles/storage.objectViewer", role)
+		}
+	}
+
+	return nil
+}
+
 // FlagSetChecker allows us to find out if flags were set, adn what the names of all flags are
 type FlagSetChecker interface {
 	IsSet(name string) bool
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	. "github.com/EngineerBetter/concourse-up/commands/deploy"
+	"github.com/EngineerBetter/concourse-up/resource"
 )
 
 func TestDeployArgs_Validate(t *testing.T) {
@@ -171,6 +172,377 @@ func TestDeployArgs_Validate(t *testing.T) {
 			},
 			wantErr:     true,
 			expectedErr: "both --public-subnet-range and --private-subnet-range are required when either is provided",
+		},
+		{
+			name: "Credential manager must be a known value",
+			modification: func() Args {
+				args := defaultFields
+				args.CredentialManager = "bananas"
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "unknown credential manager: `bananas`",
+		},
+		{
+			name: "AWS credential managers are only available on AWS",
+			modification: func() Args {
+				args := defaultFields
+				args.IAAS = "GCP"
+				args.CredentialManager = "aws-ssm"
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--credential-manager aws-ssm is only available when IAAS is AWS",
+		},
+		{
+			name: "AWS Secrets Manager is accepted on AWS",
+			modification: func() Args {
+				args := defaultFields
+				args.CredentialManager = "aws-secretsmanager"
+				return args
+			},
+			wantErr: false,
+		},
+		{
+			name: "Vault requires a URL and token",
+			modification: func() Args {
+				args := defaultFields
+				args.CredentialManager = "vault"
+				args.VaultURL = "https://vault.example.com:8200"
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--credential-manager vault requires --vault-url and --vault-token to also be provided",
+		},
+		{
+			name: "GCP Secret Manager is rejected with an explanation and a workaround",
+			modification: func() Args {
+				args := defaultFields
+				args.IAAS = "GCP"
+				args.CredentialManager = "gcp-secretmanager"
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "or --credential-manager vault with the secrets copied from Secret Manager into Vault",
+		},
+		{
+			name: "Worker IAM policies are only available on AWS",
+			modification: func() Args {
+				args := defaultFields
+				args.IAAS = "GCP"
+				args.WorkerIAMPolicies = []string{"arn:aws:iam::aws:policy/AmazonS3ReadOnlyAccess"}
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--worker-iam-policy is only available when IAAS is AWS",
+		},
+		{
+			name: "Worker IAM policies must be ARNs",
+			modification: func() Args {
+				args := defaultFields
+				args.WorkerIAMPolicies = []string{"AmazonS3ReadOnlyAccess"}
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "`AmazonS3ReadOnlyAccess` is not a policy ARN",
+		},
+		{
+			name: "Worker service account roles are accepted on GCP",
+			modification: func() Args {
+				args := defaultFields
+				args.IAAS = "GCP"
+				args.WorkerServiceAccountRoles = []string{"roles/storage.objectViewer"}
+				return args
+			},
+			wantErr: false,
+		},
+		{
+			name: "Worker service account roles are only available on GCP",
+			modification: func() Args {
+				args := defaultFields
+				args.WorkerServiceAccountRoles = []string{"roles/storage.objectViewer"}
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--worker-service-account-role is only available when IAAS is GCP",
+		},
+		{
+			name: "Concourse version from the catalogue is accepted",
+			modification: func() Args {
+				args := defaultFields
+				args.ConcourseVersion = resource.ConcourseVersions()[0]
+				return args
+			},
+			wantErr: false,
+		},
+		{
+			name: "Concourse version must be in the catalogue",
+			modification: func() Args {
+				args := defaultFields
+				args.ConcourseVersion = "0.0.1"
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "Concourse 0.0.1 is not supported by this version of concourse-up",
+		},
+		{
+			name: "Self-update controls are accepted when valid",
+			modification: func() Args {
+				args := defaultFields
+				args.SelfUpdateChannel = "stable"
+				args.SelfUpdateVersionRange = ">= 0.20.0, < 1.0.0"
+				args.MaintenanceWindow = "22:30-23:30"
+				return args
+			},
+			wantErr: false,
+		},
+		{
+			name: "Self-update channel must be a known value",
+			modification: func() Args {
+				args := defaultFields
+				args.SelfUpdateChannel = "nightly"
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "unknown self-update channel: `nightly`",
+		},
+		{
+			name: "Self-update version range must be a semver range",
+			modification: func() Args {
+				args := defaultFields
+				args.SelfUpdateVersionRange = "latest"
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "`latest` is not a semver range",
+		},
+		{
+			name: "Maintenance window must be HH:MM-HH:MM",
+			modification: func() Args {
+				args := defaultFields
+				args.MaintenanceWindow = "2am-4am"
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "`2am-4am` is not a maintenance window",
+		},
+		{
+			name: "Notification targets are accepted when complete",
+			modification: func() Args {
+				args := defaultFields
+				args.NotifySlackWebhook = "https://hooks.slack.com/services/T0/B0/x"
+				args.NotifyWebhook = "http://ops.example.com/hook"
+				args.NotifyEmail = "ops@example.com"
+				args.NotifySMTPServer = "smtp.example.com:587"
+				args.NotifySMTPUsername = "ops"
+				args.NotifySMTPPassword = "s3cret"
+				return args
+			},
+			wantErr: false,
+		},
+		{
+			name: "Notification webhooks must be URLs",
+			modification: func() Args {
+				args := defaultFields
+				args.NotifyWebhook = "ops.example.com/hook"
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--notify-webhook must be an http or https URL",
+		},
+		{
+			name: "Notification email requires an SMTP server",
+			modification: func() Args {
+				args := defaultFields
+				args.NotifyEmail = "ops@example.com"
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--notify-email requires --notify-smtp-server to also be provided",
+		},
+		{
+			name: "Notification SMTP server must include a port",
+			modification: func() Args {
+				args := defaultFields
+				args.NotifyEmail = "ops@example.com"
+				args.NotifySMTPServer = "smtp.example.com"
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "`smtp.example.com` is not in the format `host:port`",
+		},
+		{
+			name: "Database storage must be between 10 and 16384 GB",
+			modification: func() Args {
+				args := defaultFields
+				args.DBStorage = 5
+				args.DBStorageIsSet = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "database storage must be between 10 and 16384 GB, not 5",
+		},
+		{
+			name: "Maximum database storage must be at least the database storage",
+			modification: func() Args {
+				args := defaultFields
+				args.DBStorage = 50
+				args.DBStorageIsSet = true
+				args.DBMaxStorage = 20
+				args.DBMaxStorageIsSet = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "maximum database storage must be between the database storage of 50 GB and 16384 GB, not 20",
+		},
+		{
+			name: "Worker disk size must be between 20 and 16384 GB",
+			modification: func() Args {
+				args := defaultFields
+				args.WorkerDiskSize = 10
+				args.WorkerDiskSizeIsSet = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "worker disk size must be between 20 and 16384 GB, not 10",
+		},
+		{
+			name: "Worker disk type must be valid for the IAAS",
+			modification: func() Args {
+				args := defaultFields
+				args.WorkerDiskType = "pd-ssd"
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "unknown worker disk type on AWS: `pd-ssd`. Valid types are: [gp2 gp3 io1]",
+		},
+		{
+			name: "Worker disk type pd-ssd is valid on GCP",
+			modification: func() Args {
+				args := defaultFields
+				args.IAAS = "GCP"
+				args.WorkerDiskType = "pd-ssd"
+				args.WorkerPersistentDisk = 100
+				return args
+			},
+			wantErr: false,
+		},
+		{
+			name: "Worker disk type io1 requires IOPS",
+			modification: func() Args {
+				args := defaultFields
+				args.WorkerDiskType = "io1"
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--worker-disk-type io1 requires --worker-disk-iops to also be provided",
+		},
+		{
+			name: "Worker disk IOPS can only be used with io1 or gp3",
+			modification: func() Args {
+				args := defaultFields
+				args.WorkerDiskIOPS = 3000
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--worker-disk-iops can only be used with --worker-disk-type io1 or gp3",
+		},
+		{
+			name: "Worker disk IOPS with gp3",
+			modification: func() Args {
+				args := defaultFields
+				args.WorkerDiskType = "gp3"
+				args.WorkerDiskIOPS = 6000
+				return args
+			},
+			wantErr: false,
+		},
+		{
+			name: "Raw instance types",
+			modification: func() Args {
+				args := defaultFields
+				args.WorkerInstanceType = "c5.4xlarge"
+				args.WebInstanceType = "t3.large"
+				args.DBInstanceType = "db.r5.large"
+				return args
+			},
+			wantErr: false,
+		},
+		{
+			name: "Worker instance type cannot be used with worker size",
+			modification: func() Args {
+				args := defaultFields
+				args.WorkerInstanceType = "c5.4xlarge"
+				args.WorkerSizeIsSet = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--worker-instance-type cannot be used with --worker-size",
+		},
+		{
+			name: "DB instance type must look like an instance type",
+			modification: func() Args {
+				args := defaultFields
+				args.DBInstanceType = "DB R5 Large"
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "`DB R5 Large` is not an instance type",
+		},
+		{
+			name: "Windows and arm64 workers",
+			modification: func() Args {
+				args := defaultFields
+				args.WindowsWorkerCount = 2
+				args.WindowsWorkerInstanceType = "m5.2xlarge"
+				args.ARMWorkerCount = 1
+				args.ARMWorkerInstanceType = "m6g.xlarge"
+				return args
+			},
+			wantErr: false,
+		},
+		{
+			name: "Windows worker count cannot be negative",
+			modification: func() Args {
+				args := defaultFields
+				args.WindowsWorkerCount = -1
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--windows-workers cannot be negative",
+		},
+		{
+			name: "arm64 worker instance type must look like an instance type",
+			modification: func() Args {
+				args := defaultFields
+				args.ARMWorkerInstanceType = "graviton please"
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "`graviton please` is not an instance type",
+		},
+		{
+			name: "Restore snapshot must be a name printed by destroy",
+			modification: func() Args {
+				args := defaultFields
+				args.RestoreFromSnapshot = "../other-deployment"
+				args.RestoreFromSnapshotIsSet = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "`../other-deployment` is not a snapshot name, as printed by destroy",
+		},
+		{
+			name: "Restoring from a snapshot can't be done by a self-update",
+			modification: func() Args {
+				args := defaultFields
+				args.RestoreFromSnapshot = "concourse-up-happymeal-final-20190301120000"
+				args.RestoreFromSnapshotIsSet = true
+				args.SelfUpdate = true
+				return args
+			},
+			wantErr:     true,
+			expectedErr: "--restore-from-snapshot cannot be used with --self-update",
 		}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
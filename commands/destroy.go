@@ -41,6 +41,12 @@ var destroyFlags = []cli.Flag{
 		EnvVar:      "NAMESPACE",
 		Destination: &initialDestroyArgs.Namespace,
 	},
+	cli.BoolFlag{
+		Name:        "skip-final-snapshot",
+		Usage:       "(optional) Don't snapshot the database or archive the config before destroying them",
+		Destination: &initialDestroyArgs.SkipFinalSnapshot,
+	},
+	bundleFlag(&initialDestroyArgs.Bundle),
 }
 
 func destroyAction(c *cli.Context, destroyArgs destroy.Args, provider iaas.Provider) error {
@@ -71,7 +77,7 @@ func destroyAction(c *cli.Context, destroyArgs destroy.Args, provider iaas.Provi
 	if err != nil {
 		return err
 	}
-	return client.Destroy()
+	return client.Destroy(destroyArgs)
 }
 func markSetFlags(c *cli.Context, destroyArgs destroy.Args) (destroy.Args, error) {
 	err := destroyArgs.MarkSetFlags(c)
@@ -121,6 +127,9 @@ var destroyCmd = cli.Command{
 	ArgsUsage: "<name>",
 	Flags:     destroyFlags,
 	Action: func(c *cli.Context) error {
+		if err := useBundle(initialDestroyArgs.Bundle); err != nil {
+			return err
+		}
 		iaasName, err := iaas.Assosiate(initialDestroyArgs.IAAS)
 		if err != nil {
 			return err
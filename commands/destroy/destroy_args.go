@@ -14,6 +14,9 @@ type Args struct {
 	Namespace      string
 	NamespaceIsSet bool
 	IAASIsSet      bool
+	Bundle         string
+	// SkipFinalSnapshot destroys the database without taking a final snapshot or archiving the config
+	SkipFinalSnapshot bool
 }
 
 //MarkSetFlags is marking which destroy Args have been set
@@ -27,6 +30,8 @@ func (a *Args) MarkSetFlags(c FlagSetChecker) error {
 				a.NamespaceIsSet = true
 			case "iaas":
 				a.IAASIsSet = true
+			case "bundle", "skip-final-snapshot":
+				//do nothing
 			default:
 				return fmt.Errorf("flag %q is not supported by deployment flags", f)
 			}
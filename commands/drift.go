@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/certs"
+	"github.com/EngineerBetter/concourse-up/commands/drift"
+	"github.com/EngineerBetter/concourse-up/concourse"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/fly"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	"github.com/EngineerBetter/concourse-up/util"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var initialDriftArgs drift.Args
+
+var driftFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialDriftArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS, can be AWS or GCP",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialDriftArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialDriftArgs.Namespace,
+	},
+	bundleFlag(&initialDriftArgs.Bundle),
+}
+
+func driftAction(c *cli.Context, driftArgs drift.Args, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return errors.New("Usage is `concourse-up drift <name>`")
+	}
+
+	err := driftArgs.MarkSetFlags(c)
+	if err != nil {
+		return err
+	}
+
+	client, err := buildDriftClient(name, c.App.Version, driftArgs, provider)
+	if err != nil {
+		return err
+	}
+	d, err := client.FetchDrift()
+	if err != nil {
+		return err
+	}
+	if _, err = os.Stdout.WriteString(d.String()); err != nil {
+		return err
+	}
+	if d.Found() {
+		return errors.New("deployment has drifted, the next deploy will revert these changes")
+	}
+	return nil
+}
+
+func buildDriftClient(name, version string, driftArgs drift.Args, provider iaas.Provider) (*concourse.Client, error) {
+	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform())
+	if err != nil {
+		return nil, err
+	}
+
+	tfInputVarsFactory, err := concourse.NewTFInputVarsFactory(provider)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating TFInputVarsFactory [%v]", err)
+	}
+
+	client := concourse.NewClient(
+		provider,
+		terraformClient,
+		tfInputVarsFactory,
+		bosh.New,
+		fly.New,
+		certs.Generate,
+		config.New(provider, name, driftArgs.Namespace),
+		nil,
+		os.Stdout,
+		os.Stderr,
+		util.FindUserIP,
+		certs.NewAcmeClient,
+		util.GeneratePasswordWithLength,
+		util.EightRandomLetters,
+		util.GenerateSSHKeyPair,
+		version,
+	)
+
+	return client, nil
+}
+
+var driftCmd = cli.Command{
+	Name:      "drift",
+	Usage:     "Reports changes made to a deployment's infrastructure and cloud config outside of concourse-up, exiting non-zero when there are any",
+	ArgsUsage: "<name>",
+	Flags:     driftFlags,
+	Action: func(c *cli.Context) error {
+		if err := useBundle(initialDriftArgs.Bundle); err != nil {
+			return err
+		}
+		iaasName, err := iaas.Assosiate(initialDriftArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, initialDriftArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on drift: [%v]", err)
+		}
+		return driftAction(c, initialDriftArgs, provider)
+	},
+}
@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/certs"
+	"github.com/EngineerBetter/concourse-up/commands/export"
+	"github.com/EngineerBetter/concourse-up/concourse"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/fly"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	"github.com/EngineerBetter/concourse-up/util"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var initialExportArgs export.Args
+
+var exportFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialExportArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS, can be AWS or GCP",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialExportArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialExportArgs.Namespace,
+	},
+	cli.StringFlag{
+		Name:        "dir",
+		Usage:       "(optional) Directory to write the export to, which must be empty or not exist (default: ./<name>)",
+		Destination: &initialExportArgs.Dir,
+	},
+	cli.BoolFlag{
+		Name:        "redact-secrets",
+		Usage:       "(optional) Replace passwords, keys and other secrets with REDACTED, for sharing the export with auditors",
+		Destination: &initialExportArgs.RedactSecrets,
+	},
+	bundleFlag(&initialExportArgs.Bundle),
+}
+
+func exportAction(c *cli.Context, exportArgs export.Args, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return errors.New("Usage is `concourse-up export <name>`")
+	}
+
+	err := exportArgs.MarkSetFlags(c)
+	if err != nil {
+		return err
+	}
+
+	dir := exportArgs.Dir
+	if dir == "" {
+		dir = name
+	}
+	if files, err := ioutil.ReadDir(dir); err == nil && len(files) > 0 {
+		return fmt.Errorf("%s already has files in it, export needs an empty directory", dir)
+	}
+
+	client, err := buildExportClient(name, c.App.Version, exportArgs, provider)
+	if err != nil {
+		return err
+	}
+	if err = client.Export(dir, exportArgs.RedactSecrets); err != nil {
+		return err
+	}
+	_, err = fmt.Printf("Exported %s to %s, run terraform in %s and then %s\n", name, dir, filepath.Join(dir, filepath.Dir(concourse.TerraformConfigFilename)), filepath.Join(dir, bosh.DeployScriptFilename))
+	return err
+}
+
+func buildExportClient(name, version string, exportArgs export.Args, provider iaas.Provider) (*concourse.Client, error) {
+	terraformClient, err := terraform.New(provider.IAAS(), terraform.DownloadTerraform())
+	if err != nil {
+		return nil, err
+	}
+
+	tfInputVarsFactory, err := concourse.NewTFInputVarsFactory(provider)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating TFInputVarsFactory [%v]", err)
+	}
+
+	client := concourse.NewClient(
+		provider,
+		terraformClient,
+		tfInputVarsFactory,
+		bosh.New,
+		fly.New,
+		certs.Generate,
+		config.New(provider, name, exportArgs.Namespace),
+		nil,
+		os.Stdout,
+		os.Stderr,
+		util.FindUserIP,
+		certs.NewAcmeClient,
+		util.GeneratePasswordWithLength,
+		util.EightRandomLetters,
+		util.GenerateSSHKeyPair,
+		version,
+	)
+
+	return client, nil
+}
+
+var exportCmd = cli.Command{
+	Name:      "export",
+	Usage:     "Writes the terraform config and bosh manifests, vars and state of a deployment to a directory, so it can be operated with terraform and bosh alone",
+	ArgsUsage: "<name>",
+	Flags:     exportFlags,
+	Action: func(c *cli.Context) error {
+		if err := useBundle(initialExportArgs.Bundle); err != nil {
+			return err
+		}
+		iaasName, err := iaas.Assosiate(initialExportArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, initialExportArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on export: [%v]", err)
+		}
+		return exportAction(c, initialExportArgs, provider)
+	},
+}
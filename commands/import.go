@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/EngineerBetter/concourse-up/commands/deploy"
+	"github.com/EngineerBetter/concourse-up/commands/imports"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var initialImportArgs imports.Args
+
+var importFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialImportArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS, can be AWS or GCP",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialImportArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialImportArgs.Namespace,
+	},
+	cli.StringFlag{
+		Name:        "director-state",
+		Usage:       "Path to the director's bosh create-env state, such as vars/bosh-state.json in a bbl state directory",
+		Destination: &initialImportArgs.DirectorState,
+	},
+	cli.StringFlag{
+		Name:        "director-creds",
+		Usage:       "Path to the director's bosh create-env vars store, such as vars/director-vars-store.yml in a bbl state directory",
+		Destination: &initialImportArgs.DirectorCreds,
+	},
+	cli.StringFlag{
+		Name:        "terraform-state",
+		Usage:       "Path to the terraform state of the director's infrastructure, such as vars/terraform.tfstate in a bbl state directory",
+		Destination: &initialImportArgs.TerraformState,
+	},
+	cli.StringFlag{
+		Name:        "deployment",
+		Usage:       "(optional) Name of the Concourse deployment on the director",
+		Value:       "concourse",
+		Destination: &initialImportArgs.Deployment,
+	},
+	bundleFlag(&initialImportArgs.Bundle),
+}
+
+func importAction(c *cli.Context, importArgs imports.Args, provider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return errors.New("Usage is `concourse-up import <name>`")
+	}
+
+	err := importArgs.MarkSetFlags(c)
+	if err != nil {
+		return err
+	}
+	if importArgs.DirectorState == "" || importArgs.DirectorCreds == "" || importArgs.TerraformState == "" {
+		return errors.New("--director-state, --director-creds and --terraform-state are required")
+	}
+	if err = validateNameLength(name, provider.IAAS()); err != nil {
+		return err
+	}
+
+	state, err := ioutil.ReadFile(importArgs.DirectorState)
+	if err != nil {
+		return err
+	}
+	creds, err := ioutil.ReadFile(importArgs.DirectorCreds)
+	if err != nil {
+		return err
+	}
+	tfState, err := ioutil.ReadFile(importArgs.TerraformState)
+	if err != nil {
+		return err
+	}
+
+	deployArgs := defaultDeployArgs()
+	deployArgs.IAAS = importArgs.IAAS
+	deployArgs.Region = provider.Region()
+	deployArgs.Namespace = importArgs.Namespace
+	client, err := buildClient(name, c.App.Version, deployArgs, provider)
+	if err != nil {
+		return err
+	}
+	if err = client.Import(state, creds, tfState, importArgs.Deployment); err != nil {
+		return err
+	}
+	_, err = fmt.Printf("Imported %s, run `concourse-up deploy %s` with any flags it needs to take it over\n", name, name)
+	return err
+}
+
+// defaultDeployArgs returns the args deploy has when given no flags, which an imported
+// deployment's config is generated from
+func defaultDeployArgs() deploy.Args {
+	set := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	for _, f := range deployFlags {
+		f.Apply(set)
+	}
+	return initialDeployArgs
+}
+
+var importCmd = cli.Command{
+	Name:      "import",
+	Usage:     "Adopts a Concourse deployed with bosh create-env and terraform, such as by bosh-bootloader, so concourse-up can manage it",
+	ArgsUsage: "<name>",
+	Flags:     importFlags,
+	Action: func(c *cli.Context) error {
+		if err := useBundle(initialImportArgs.Bundle); err != nil {
+			return err
+		}
+		iaasName, err := iaas.Assosiate(initialImportArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, initialImportArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on import: [%v]", err)
+		}
+		return importAction(c, initialImportArgs, provider)
+	},
+}
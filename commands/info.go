@@ -39,9 +39,24 @@ var infoFlags = []cli.Flag{
 	},
 	cli.BoolFlag{
 		Name:        "cert-expiry",
-		Usage:       "(optional) Output only the expiration date of the director nats certificate",
+		Usage:       "(optional) Output only the expiration date of the director nats certificate, or with --json every certificate managed by concourse-up and when it expires",
 		Destination: &initialInfoArgs.CertExpiry,
 	},
+	cli.BoolFlag{
+		Name:        "certificates",
+		Usage:       "(optional) Output only the certificates managed by concourse-up and when they expire, as a table or with --json",
+		Destination: &initialInfoArgs.Certificates,
+	},
+	cli.BoolFlag{
+		Name:        "health",
+		Usage:       "(optional) Output a health report of Concourse, its workers, CredHub, the database and VMs as json, exiting non-zero when unhealthy",
+		Destination: &initialInfoArgs.Health,
+	},
+	cli.BoolFlag{
+		Name:        "cost",
+		Usage:       "(optional) Output an estimate of the deployment's monthly cost at current prices, as a table or with --json",
+		Destination: &initialInfoArgs.Cost,
+	},
 	cli.StringFlag{
 		Name:        "iaas",
 		Usage:       "(optional) IAAS, can be AWS or GCP",
@@ -55,6 +70,7 @@ var infoFlags = []cli.Flag{
 		EnvVar:      "NAMESPACE",
 		Destination: &initialInfoArgs.Namespace,
 	},
+	bundleFlag(&initialInfoArgs.Bundle),
 }
 
 func infoAction(c *cli.Context, infoArgs info.Args, provider iaas.Provider) error {
@@ -74,6 +90,48 @@ func infoAction(c *cli.Context, infoArgs info.Args, provider iaas.Provider) erro
 	if err != nil {
 		return err
 	}
+	if infoArgs.Health {
+		h, err := client.FetchHealth()
+		if err != nil {
+			return err
+		}
+		err = json.NewEncoder(os.Stdout).Encode(h)
+		if err != nil {
+			return err
+		}
+		if !h.Healthy {
+			return errors.New("deployment is unhealthy")
+		}
+		return nil
+	}
+	if infoArgs.Cost {
+		cost, err := client.FetchCost()
+		if err != nil {
+			return err
+		}
+		if infoArgs.JSON {
+			return json.NewEncoder(os.Stdout).Encode(cost)
+		}
+		_, err = os.Stdout.WriteString(cost.String())
+		return err
+	}
+
+	if infoArgs.CertExpiry || infoArgs.Certificates {
+		certificates, err := client.FetchCertificates()
+		if err != nil {
+			return err
+		}
+		switch {
+		case infoArgs.JSON:
+			return json.NewEncoder(os.Stdout).Encode(certificates)
+		case infoArgs.Certificates:
+			_, err = os.Stdout.WriteString(concourse.CertificatesTable(certificates))
+		default:
+			_, err = os.Stdout.WriteString(concourse.NATSCertExpiry(certificates))
+		}
+		return err
+	}
+
 	i, err := client.FetchInfo()
 	if err != nil {
 		return err
@@ -88,15 +146,10 @@ func infoAction(c *cli.Context, infoArgs info.Args, provider iaas.Provider) erro
 		}
 		_, err = os.Stdout.WriteString(env)
 		return err
-	case infoArgs.CertExpiry:
-		os.Stdout.WriteString(i.CertExpiry)
-		return nil
 	default:
 		_, err := fmt.Fprint(os.Stdout, i)
 		return err
 	}
-	//this will never run
-	return nil
 }
 
 func buildInfoClient(name, version string, infoArgs info.Args, provider iaas.Provider) (*concourse.Client, error) {
@@ -139,6 +192,9 @@ var infoCmd = cli.Command{
 	ArgsUsage: "<name>",
 	Flags:     infoFlags,
 	Action: func(c *cli.Context) error {
+		if err := useBundle(initialInfoArgs.Bundle); err != nil {
+			return err
+		}
 		iaasName, err := iaas.Assosiate(initialInfoArgs.IAAS)
 		if err != nil {
 			return err
@@ -16,6 +16,10 @@ type Args struct {
 	NamespaceIsSet bool
 	IAAS           string
 	CertExpiry     bool
+	Certificates   bool
+	Health         bool
+	Cost           bool
+	Bundle         string
 }
 
 //MarkSetFlags is marking which info Args have been set
@@ -27,7 +31,7 @@ func (a *Args) MarkSetFlags(c FlagSetChecker) error {
 				a.RegionIsSet = true
 			case "namespace":
 				a.NamespaceIsSet = true
-			case "iaas", "json", "env", "cert-expiry":
+			case "iaas", "json", "env", "cert-expiry", "certificates", "health", "cost", "bundle":
 				//do nothing
 			default:
 				return fmt.Errorf("flag %q is not supported by info flags", f)
@@ -32,6 +32,36 @@ var maintainFlags = []cli.Flag{
 		Usage:       "(optional) Rotate nats certificate",
 		Destination: &initialMaintainArgs.RenewNatsCert,
 	},
+	cli.BoolFlag{
+		Name:        "rotate-credentials",
+		Usage:       "(optional) Rotate generated passwords, the encryption key and the SSH keypair",
+		Destination: &initialMaintainArgs.RotateCredentials,
+	},
+	cli.BoolFlag{
+		Name:        "rotate-director-certs",
+		Usage:       "(optional) Rotate the director TLS certificate, director CAs and Concourse internal CA",
+		Destination: &initialMaintainArgs.RotateDirectorCerts,
+	},
+	cli.BoolFlag{
+		Name:        "upgrade-stemcell",
+		Usage:       "(optional) Upgrade Concourse to the latest stemcell in its current line",
+		Destination: &initialMaintainArgs.UpgradeStemcell,
+	},
+	cli.StringFlag{
+		Name:        "stemcell-version",
+		Usage:       "(optional) Stemcell version to upgrade to with --upgrade-stemcell, defaults to the latest",
+		Destination: &initialMaintainArgs.StemcellVersion,
+	},
+	cli.BoolFlag{
+		Name:        "upgrade-db",
+		Usage:       "(optional) Snapshot the database and upgrade it to a newer major version of PostgreSQL",
+		Destination: &initialMaintainArgs.UpgradeDB,
+	},
+	cli.StringFlag{
+		Name:        "db-version",
+		Usage:       "(optional) PostgreSQL major version to upgrade to with --upgrade-db, defaults to the latest supported",
+		Destination: &initialMaintainArgs.DBVersion,
+	},
 	cli.StringFlag{
 		Name:        "iaas",
 		Usage:       "(optional) IAAS, can be AWS or GCP",
@@ -47,10 +77,11 @@ var maintainFlags = []cli.Flag{
 	},
 	cli.IntFlag{
 		Name:        "stage",
-		Usage:       "(optional) Set the desired stage for nats rotation tasks",
+		Usage:       "(optional) Set the desired stage of the maintenance operation to start from",
 		EnvVar:      "STAGE",
 		Destination: &initialMaintainArgs.Stage,
 	},
+	bundleFlag(&initialMaintainArgs.Bundle),
 }
 
 func maintainAction(c *cli.Context, maintainArgs maintain.Args, provider iaas.Provider) error {
@@ -65,6 +96,9 @@ func maintainAction(c *cli.Context, maintainArgs maintain.Args, provider iaas.Pr
 	if err != nil {
 		return err
 	}
+	if err = maintainArgs.Validate(); err != nil {
+		return err
+	}
 
 	client, err := buildMaintainClient(name, version, maintainArgs, provider)
 	if err != nil {
@@ -118,6 +152,9 @@ var maintainCmd = cli.Command{
 	ArgsUsage: "<name>",
 	Flags:     maintainFlags,
 	Action: func(c *cli.Context) error {
+		if err := useBundle(initialMaintainArgs.Bundle); err != nil {
+			return err
+		}
 		iaasName, err := iaas.Assosiate(initialMaintainArgs.IAAS)
 		if err != nil {
 			return err
@@ -1,22 +1,37 @@
 package maintain
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
 
 	cli "gopkg.in/urfave/cli.v1"
 )
 
 // Args are arguments passed to the info command
 type Args struct {
-	Region             string
-	RegionIsSet        bool
-	RenewNatsCert      bool
-	RenewNatsCertIsSet bool
-	Namespace          string
-	NamespaceIsSet     bool
-	IAAS               string
-	Stage              int
-	StageIsSet         bool
+	Region                   string
+	RegionIsSet              bool
+	RenewNatsCert            bool
+	RenewNatsCertIsSet       bool
+	RotateCredentials        bool
+	RotateCredentialsIsSet   bool
+	RotateDirectorCerts      bool
+	RotateDirectorCertsIsSet bool
+	UpgradeStemcell          bool
+	UpgradeStemcellIsSet     bool
+	StemcellVersion          string
+	StemcellVersionIsSet     bool
+	UpgradeDB                bool
+	UpgradeDBIsSet           bool
+	DBVersion                string
+	DBVersionIsSet           bool
+	Namespace                string
+	NamespaceIsSet           bool
+	IAAS                     string
+	Stage                    int
+	StageIsSet               bool
+	Bundle                   string
 }
 
 //MarkSetFlags is marking which info Args have been set
@@ -30,9 +45,21 @@ func (a *Args) MarkSetFlags(c FlagSetChecker) error {
 				a.NamespaceIsSet = true
 			case "renew-nats-cert":
 				a.RenewNatsCertIsSet = true
+			case "rotate-credentials":
+				a.RotateCredentialsIsSet = true
+			case "rotate-director-certs":
+				a.RotateDirectorCertsIsSet = true
+			case "upgrade-stemcell":
+				a.UpgradeStemcellIsSet = true
+			case "stemcell-version":
+				a.StemcellVersionIsSet = true
+			case "upgrade-db":
+				a.UpgradeDBIsSet = true
+			case "db-version":
+				a.DBVersionIsSet = true
 			case "stage":
 				a.StageIsSet = true
-			case "iaas":
+			case "iaas", "bundle":
 				//do nothing
 			default:
 				return fmt.Errorf("flag %q is not supported by maintain flags", f)
@@ -42,6 +69,33 @@ func (a *Args) MarkSetFlags(c FlagSetChecker) error {
 	return nil
 }
 
+var stemcellVersionPattern = regexp.MustCompile(`^\d+(\.\d+)*$`)
+
+// Validate checks that only one maintenance operation has been requested
+func (a Args) Validate() error {
+	var operations int
+	for _, isSet := range []bool{a.RenewNatsCertIsSet, a.RotateCredentialsIsSet, a.RotateDirectorCertsIsSet, a.UpgradeStemcellIsSet, a.UpgradeDBIsSet} {
+		if isSet {
+			operations++
+		}
+	}
+	if operations > 1 {
+		return errors.New("only one of --renew-nats-cert, --rotate-credentials, --rotate-director-certs, --upgrade-stemcell and --upgrade-db can be used at a time")
+	}
+	if a.StemcellVersionIsSet {
+		if !a.UpgradeStemcellIsSet {
+			return errors.New("--stemcell-version can only be used with --upgrade-stemcell")
+		}
+		if !stemcellVersionPattern.MatchString(a.StemcellVersion) {
+			return fmt.Errorf("stemcell version %q is not valid, expected a version such as 250.17", a.StemcellVersion)
+		}
+	}
+	if a.DBVersionIsSet && !a.UpgradeDBIsSet {
+		return errors.New("--db-version can only be used with --upgrade-db")
+	}
+	return nil
+}
+
 // FlagSetChecker allows us to find out if flags were set, adn what the names of all flags are
 type FlagSetChecker interface {
 	IsSet(name string) bool
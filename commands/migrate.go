@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/EngineerBetter/concourse-up/commands/migrate"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var initialMigrateArgs migrate.Args
+
+var migrateFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialMigrateArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS, can be AWS or GCP",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialMigrateArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialMigrateArgs.Namespace,
+	},
+	cli.StringFlag{
+		Name:        "to-region",
+		Usage:       "(optional) Region to move the deployment to",
+		Destination: &initialMigrateArgs.ToRegion,
+	},
+	cli.StringFlag{
+		Name:        "to-namespace",
+		Usage:       "(optional) Namespace to move the deployment to, defaults to --namespace",
+		Destination: &initialMigrateArgs.ToNamespace,
+	},
+	cli.StringFlag{
+		Name:        "to-name",
+		Usage:       "(optional) Name to give the deployment, which is required on GCP",
+		Destination: &initialMigrateArgs.ToName,
+	},
+	bundleFlag(&initialMigrateArgs.Bundle),
+}
+
+func migrateAction(c *cli.Context, migrateArgs migrate.Args, provider, targetProvider iaas.Provider) error {
+	name := c.Args().Get(0)
+	if name == "" {
+		return errors.New("Usage is `concourse-up migrate <name>`")
+	}
+
+	err := migrateArgs.MarkSetFlags(c)
+	if err != nil {
+		return err
+	}
+	if !migrateArgs.ToRegionIsSet && !migrateArgs.ToNamespaceIsSet && !migrateArgs.ToNameIsSet {
+		return errors.New("at least one of --to-region, --to-namespace and --to-name is required")
+	}
+
+	targetName := name
+	if migrateArgs.ToNameIsSet {
+		targetName = migrateArgs.ToName
+	}
+	if err = validateNameLength(targetName, provider.IAAS()); err != nil {
+		return err
+	}
+	targetNamespace := migrateArgs.Namespace
+	if migrateArgs.ToNamespaceIsSet {
+		targetNamespace = migrateArgs.ToNamespace
+	}
+
+	deployArgs := defaultDeployArgs()
+	deployArgs.IAAS = migrateArgs.IAAS
+	deployArgs.Region = provider.Region()
+	deployArgs.Namespace = migrateArgs.Namespace
+	client, err := buildClient(name, c.App.Version, deployArgs, provider)
+	if err != nil {
+		return err
+	}
+
+	targetDeployArgs := defaultDeployArgs()
+	targetDeployArgs.IAAS = migrateArgs.IAAS
+	targetDeployArgs.Region = targetProvider.Region()
+	targetDeployArgs.Namespace = targetNamespace
+	target, err := buildClient(targetName, c.App.Version, targetDeployArgs, targetProvider)
+	if err != nil {
+		return err
+	}
+
+	return client.Migrate(target)
+}
+
+var migrateCmd = cli.Command{
+	Name:      "migrate",
+	Usage:     "Moves a deployment and its databases to another region, namespace or name, then destroys the original",
+	ArgsUsage: "<name>",
+	Flags:     migrateFlags,
+	Action: func(c *cli.Context) error {
+		if err := useBundle(initialMigrateArgs.Bundle); err != nil {
+			return err
+		}
+		iaasName, err := iaas.Assosiate(initialMigrateArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, initialMigrateArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on migrate: [%v]", err)
+		}
+		targetRegion := provider.Region()
+		if initialMigrateArgs.ToRegion != "" {
+			targetRegion = initialMigrateArgs.ToRegion
+		}
+		targetProvider, err := iaas.New(iaasName, targetRegion)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on migrate: [%v]", err)
+		}
+		return migrateAction(c, initialMigrateArgs, provider, targetProvider)
+	},
+}
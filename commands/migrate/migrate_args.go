@@ -0,0 +1,69 @@
+package migrate
+
+import (
+	"fmt"
+
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+// Args are arguments passed to the migrate command
+type Args struct {
+	Region           string
+	RegionIsSet      bool
+	Namespace        string
+	NamespaceIsSet   bool
+	IAAS             string
+	Bundle           string
+	ToRegion         string
+	ToRegionIsSet    bool
+	ToNamespace      string
+	ToNamespaceIsSet bool
+	ToName           string
+	ToNameIsSet      bool
+}
+
+//MarkSetFlags is marking which migrate Args have been set
+func (a *Args) MarkSetFlags(c FlagSetChecker) error {
+	for _, f := range c.FlagNames() {
+		if c.IsSet(f) {
+			switch f {
+			case "region":
+				a.RegionIsSet = true
+			case "namespace":
+				a.NamespaceIsSet = true
+			case "to-region":
+				a.ToRegionIsSet = true
+			case "to-namespace":
+				a.ToNamespaceIsSet = true
+			case "to-name":
+				a.ToNameIsSet = true
+			case "iaas", "bundle":
+				//do nothing
+			default:
+				return fmt.Errorf("flag %q is not supported by migrate flags", f)
+			}
+		}
+	}
+	return nil
+}
+
+// FlagSetChecker allows us to find out if flags were set, adn what the names of all flags are
+type FlagSetChecker interface {
+	IsSet(name string) bool
+	FlagNames() (names []string)
+}
+
+// ContextWrapper wraps a CLI context for testing
+type ContextWrapper struct {
+	c *cli.Context
+}
+
+// IsSet tells you if a user provided a flag
+func (t *ContextWrapper) IsSet(name string) bool {
+	return t.c.IsSet(name)
+}
+
+// FlagNames lists all flags it's possible for a user to provide
+func (t *ContextWrapper) FlagNames() (names []string) {
+	return t.c.FlagNames()
+}
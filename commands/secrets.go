@@ -0,0 +1,232 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/EngineerBetter/concourse-up/commands/secrets"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/credhub"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var initialSecretsArgs secrets.Args
+
+var secretsFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:        "region",
+		Usage:       "(optional) AWS region",
+		EnvVar:      "AWS_REGION",
+		Destination: &initialSecretsArgs.Region,
+	},
+	cli.StringFlag{
+		Name:        "iaas",
+		Usage:       "(optional) IAAS, can be AWS or GCP",
+		EnvVar:      "IAAS",
+		Value:       "AWS",
+		Destination: &initialSecretsArgs.IAAS,
+	},
+	cli.StringFlag{
+		Name:        "namespace",
+		Usage:       "(optional) Specify a namespace for deployments in order to group them in a meaningful way",
+		EnvVar:      "NAMESPACE",
+		Destination: &initialSecretsArgs.Namespace,
+	},
+	cli.StringFlag{
+		Name:        "team",
+		Usage:       "(optional) Concourse team the secrets belong to",
+		EnvVar:      "CONCOURSE_TEAM",
+		Value:       "main",
+		Destination: &initialSecretsArgs.Team,
+	},
+	cli.StringFlag{
+		Name:        "pipeline",
+		Usage:       "(optional) Scope secrets to a single pipeline rather than the whole team",
+		EnvVar:      "CONCOURSE_PIPELINE",
+		Destination: &initialSecretsArgs.Pipeline,
+	},
+}
+
+var secretsSetFlags = append([]cli.Flag{
+	cli.StringFlag{
+		Name:        "type",
+		Usage:       "(optional) CredHub credential type, can be value, password or json",
+		Value:       "value",
+		Destination: &initialSecretsArgs.Type,
+	},
+}, secretsFlags...)
+
+func secretsSetAction(c *cli.Context, secretsArgs secrets.Args, client *credhub.Client) error {
+	key, value := c.Args().Get(1), c.Args().Get(2)
+	if key == "" || value == "" {
+		return errors.New("Usage is `concourse-up secrets set <name> <key> <value>`")
+	}
+	if err := secretsArgs.Validate(); err != nil {
+		return err
+	}
+
+	var credValue interface{} = value
+	if secretsArgs.Type == "json" {
+		if err := json.Unmarshal([]byte(value), &credValue); err != nil {
+			return fmt.Errorf("value is not valid JSON: [%v]", err)
+		}
+	}
+
+	name := credhub.Path(secretsArgs.Team, secretsArgs.Pipeline, key)
+	if err := client.Set(name, secretsArgs.Type, credValue); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(os.Stdout, "Set %s\n", name)
+	return err
+}
+
+func secretsGetAction(c *cli.Context, secretsArgs secrets.Args, client *credhub.Client) error {
+	key := c.Args().Get(1)
+	if key == "" {
+		return errors.New("Usage is `concourse-up secrets get <name> <key>`")
+	}
+
+	cred, err := client.Get(credhub.Path(secretsArgs.Team, secretsArgs.Pipeline, key))
+	if err != nil {
+		return err
+	}
+
+	var s string
+	if json.Unmarshal(cred.Value, &s) == nil {
+		_, err = fmt.Fprintln(os.Stdout, s)
+		return err
+	}
+	_, err = fmt.Fprintln(os.Stdout, string(cred.Value))
+	return err
+}
+
+func secretsListAction(c *cli.Context, secretsArgs secrets.Args, client *credhub.Client) error {
+	names, err := client.List(credhub.Path(secretsArgs.Team, secretsArgs.Pipeline, ""))
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		fmt.Fprintln(os.Stdout, name)
+	}
+	return nil
+}
+
+func secretsDeleteAction(c *cli.Context, secretsArgs secrets.Args, client *credhub.Client) error {
+	key := c.Args().Get(1)
+	if key == "" {
+		return errors.New("Usage is `concourse-up secrets delete <name> <key>`")
+	}
+
+	name := credhub.Path(secretsArgs.Team, secretsArgs.Pipeline, key)
+	if err := client.Delete(name); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(os.Stdout, "Deleted %s\n", name)
+	return err
+}
+
+func secretsImportAction(c *cli.Context, secretsArgs secrets.Args, client *credhub.Client) error {
+	file := c.Args().Get(1)
+	if file == "" {
+		return errors.New("Usage is `concourse-up secrets import <name> <file>`")
+	}
+
+	contents, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	names, err := client.Import(secretsArgs.Team, secretsArgs.Pipeline, contents)
+	for _, name := range names {
+		fmt.Fprintf(os.Stdout, "Set %s\n", name)
+	}
+	return err
+}
+
+func buildCredhubClient(name string, secretsArgs secrets.Args, provider iaas.Provider) (*credhub.Client, error) {
+	conf, err := config.New(provider, name, secretsArgs.Namespace).Load()
+	if err != nil {
+		return nil, err
+	}
+	if conf.CredentialManager != "" && conf.CredentialManager != "credhub" {
+		return nil, fmt.Errorf("deployment %s uses %s for credentials rather than CredHub, manage secrets there instead", name, conf.CredentialManager)
+	}
+	return credhub.New(conf.CredhubURL, conf.CredhubCACert, credhub.AdminClient, conf.CredhubAdminClientSecret)
+}
+
+// withCredhubClient parses the common secrets flags and builds a CredHub client for the named deployment
+func withCredhubClient(usage string, action func(*cli.Context, secrets.Args, *credhub.Client) error) func(*cli.Context) error {
+	return func(c *cli.Context) error {
+		name := c.Args().Get(0)
+		if name == "" {
+			return fmt.Errorf("Usage is `concourse-up secrets %s`", usage)
+		}
+
+		secretsArgs := initialSecretsArgs
+		err := secretsArgs.MarkSetFlags(c)
+		if err != nil {
+			return err
+		}
+
+		iaasName, err := iaas.Assosiate(secretsArgs.IAAS)
+		if err != nil {
+			return err
+		}
+		provider, err := iaas.New(iaasName, secretsArgs.Region)
+		if err != nil {
+			return fmt.Errorf("Error creating IAAS provider on secrets: [%v]", err)
+		}
+
+		client, err := buildCredhubClient(name, secretsArgs, provider)
+		if err != nil {
+			return err
+		}
+		return action(c, secretsArgs, client)
+	}
+}
+
+var secretsCmd = cli.Command{
+	Name:  "secrets",
+	Usage: "Manages pipeline secrets in the CredHub deployed alongside Concourse",
+	Subcommands: []cli.Command{
+		{
+			Name:      "set",
+			Usage:     "Sets a secret for a team or pipeline",
+			ArgsUsage: "<name> <key> <value>",
+			Flags:     secretsSetFlags,
+			Action:    withCredhubClient("set <name> <key> <value>", secretsSetAction),
+		},
+		{
+			Name:      "get",
+			Usage:     "Prints the current value of a secret",
+			ArgsUsage: "<name> <key>",
+			Flags:     secretsFlags,
+			Action:    withCredhubClient("get <name> <key>", secretsGetAction),
+		},
+		{
+			Name:      "list",
+			Usage:     "Lists the secrets for a team or pipeline",
+			ArgsUsage: "<name>",
+			Flags:     secretsFlags,
+			Action:    withCredhubClient("list <name>", secretsListAction),
+		},
+		{
+			Name:      "delete",
+			Usage:     "Deletes a secret",
+			ArgsUsage: "<name> <key>",
+			Flags:     secretsFlags,
+			Action:    withCredhubClient("delete <name> <key>", secretsDeleteAction),
+		},
+		{
+			Name:      "import",
+			Usage:     "Sets every top-level key of a YAML file as a secret",
+			ArgsUsage: "<name> <file>",
+			Flags:     secretsFlags,
+			Action:    withCredhubClient("import <name> <file>", secretsImportAction),
+		},
+	},
+}
@@ -0,0 +1,72 @@
+package secrets
+
+import (
+	"fmt"
+
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+// Args are arguments passed to the secrets command
+type Args struct {
+	Region         string
+	RegionIsSet    bool
+	Namespace      string
+	NamespaceIsSet bool
+	IAAS           string
+	Team           string
+	Pipeline       string
+	PipelineIsSet  bool
+	Type           string
+}
+
+//MarkSetFlags is marking which secrets Args have been set
+func (a *Args) MarkSetFlags(c FlagSetChecker) error {
+	for _, f := range c.FlagNames() {
+		if c.IsSet(f) {
+			switch f {
+			case "region":
+				a.RegionIsSet = true
+			case "namespace":
+				a.NamespaceIsSet = true
+			case "pipeline":
+				a.PipelineIsSet = true
+			case "iaas", "team", "type":
+				//do nothing
+			default:
+				return fmt.Errorf("flag %q is not supported by secrets flags", f)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks that the credential type is one the secrets command knows how to send
+func (a Args) Validate() error {
+	switch a.Type {
+	case "value", "password", "json":
+		return nil
+	default:
+		return fmt.Errorf("unsupported credential type %q, must be one of value, password or json", a.Type)
+	}
+}
+
+// FlagSetChecker allows us to find out if flags were set, adn what the names of all flags are
+type FlagSetChecker interface {
+	IsSet(name string) bool
+	FlagNames() (names []string)
+}
+
+// ContextWrapper wraps a CLI context for testing
+type ContextWrapper struct {
+	c *cli.Context
+}
+
+// IsSet tells you if a user provided a flag
+func (t *ContextWrapper) IsSet(name string) bool {
+	return t.c.IsSet(name)
+}
+
+// FlagNames lists all flags it's possible for a user to provide
+func (t *ContextWrapper) FlagNames() (names []string) {
+	return t.c.FlagNames()
+}
@@ -0,0 +1,105 @@
+package concourse
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AccessEntry is an operator CIDR on the managed director access list
+type AccessEntry struct {
+	CIDR        string `json:"cidr"`
+	Whitelisted bool   `json:"whitelisted"`
+}
+
+// ListAccess returns the managed director access list
+func (client *Client) ListAccess() ([]string, error) {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return nil, err
+	}
+	return conf.AccessCIDRs, nil
+}
+
+// AddAccess adds a CIDR to the managed director access list and applies it to the director firewall
+func (client *Client) AddAccess(cidr string) error {
+	normalised, err := normaliseAccessCIDR(cidr)
+	if err != nil {
+		return err
+	}
+
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range conf.AccessCIDRs {
+		if existing == normalised {
+			_, err = fmt.Fprintf(client.stdout, "%s is already on the access list\n", normalised)
+			return err
+		}
+	}
+
+	conf.AccessCIDRs = append(conf.AccessCIDRs, normalised)
+
+	err = client.tfCLI.Apply(client.tfInputVarsFactory.NewInputVars(conf))
+	if err != nil {
+		return err
+	}
+
+	err = client.configClient.Update(conf)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(client.stdout, "Added %s to the access list\n", normalised)
+	return err
+}
+
+// RemoveAccess removes a CIDR from the managed director access list and applies it to the director firewall
+func (client *Client) RemoveAccess(cidr string) error {
+	normalised, err := normaliseAccessCIDR(cidr)
+	if err != nil {
+		return err
+	}
+
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+
+	var remaining []string
+	for _, existing := range conf.AccessCIDRs {
+		if existing != normalised {
+			remaining = append(remaining, existing)
+		}
+	}
+	if len(remaining) == len(conf.AccessCIDRs) {
+		return fmt.Errorf("%s is not on the access list", normalised)
+	}
+
+	conf.AccessCIDRs = remaining
+
+	err = client.tfCLI.Apply(client.tfInputVarsFactory.NewInputVars(conf))
+	if err != nil {
+		return err
+	}
+
+	err = client.configClient.Update(conf)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(client.stdout, "Removed %s from the access list\n", normalised)
+	return err
+}
+
+func normaliseAccessCIDR(s string) (string, error) {
+	if strings.Contains(s, ",") {
+		return "", fmt.Errorf("expected a single IP address or CIDR range, got %q", s)
+	}
+	blocks, err := parseAllowedIPsCIDRs(s)
+	if err != nil {
+		return "", err
+	}
+	return blocks[0].String(), nil
+}
@@ -0,0 +1,137 @@
+package concourse
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
+const archivedConfigFilename = "config.json"
+
+// finalSnapshotName names both the database snapshot destroy takes and the archive of the config
+func finalSnapshotName(deployment string) string {
+	return fmt.Sprintf("%s-final-%s", deployment, time.Now().UTC().Format("20060102150405"))
+}
+
+// takeFinalSnapshot archives the config and director creds, then arranges for the databases to
+// be kept. RDS takes a final snapshot when terraform deletes the instance, whereas Cloud SQL
+// backups are deleted along with the instance, so the databases are exported to the archive
+func (client *Client) takeFinalSnapshot(conf config.Config) (config.Config, error) {
+	name := finalSnapshotName(conf.Deployment)
+	if err := client.archiveConfig(conf, name); err != nil {
+		return conf, fmt.Errorf("error archiving config: [%v]", err)
+	}
+
+	switch client.provider.IAAS() {
+	case iaas.AWS: // nolint
+		conf.FinalSnapshot = name
+		if err := client.tfCLI.Apply(client.tfInputVarsFactory.NewInputVars(conf)); err != nil {
+			return conf, fmt.Errorf("error enabling the final RDS snapshot: [%v]", err)
+		}
+	case iaas.GCP: // nolint
+		if err := client.provider.ExportDatabases(conf.RDSDefaultDatabaseName, config.ArchiveBucket(conf), name, bosh.ConcourseDatabases); err != nil {
+			return conf, fmt.Errorf("error exporting databases: [%v]", err)
+		}
+	}
+
+	fmt.Fprintf(client.stdout, "Kept final snapshot %s in %s\nRun deploy with --restore-from-snapshot %s to recreate this deployment\n", name, config.ArchiveBucket(conf), name)
+	return conf, nil
+}
+
+// archiveConfig copies the config and director creds to the archive bucket, which destroy leaves in place
+func (client *Client) archiveConfig(conf config.Config, name string) error {
+	directorCreds, err := loadDirectorCreds(client.configClient)
+	if err != nil {
+		return err
+	}
+	return client.writeArchive(conf, name, directorCreds)
+}
+
+// writeArchive writes a config and director creds to the config's archive bucket for restoreConfig to find
+func (client *Client) writeArchive(conf config.Config, name string, directorCreds []byte) error {
+	bucket := config.ArchiveBucket(conf)
+	err := client.ensureArchiveBucket(conf)
+	if err != nil {
+		return err
+	}
+
+	configBytes, err := json.Marshal(conf)
+	if err != nil {
+		return err
+	}
+	if err = client.provider.WriteFile(bucket, path.Join(name, archivedConfigFilename), configBytes); err != nil {
+		return err
+	}
+	return client.provider.WriteFile(bucket, path.Join(name, bosh.CredsFilename), directorCreds)
+}
+
+// ensureArchiveBucket creates the archive bucket if it doesn't exist yet
+func (client *Client) ensureArchiveBucket(conf config.Config) error {
+	bucket := config.ArchiveBucket(conf)
+	exists, err := client.provider.BucketExists(bucket)
+	if err != nil || exists {
+		return err
+	}
+	return client.provider.CreateBucket(bucket)
+}
+
+// restoreConfig makes a config for a new deployment from one archived by destroy. The director is
+// recreated, so only its creds are kept and not its state. It also gets new addresses, so the
+// certificates and domain that named the old ones are dropped
+func (client *Client) restoreConfig(name string) (config.Config, error) {
+	newConf := client.configClient.NewConfig()
+	bucket := config.ArchiveBucket(newConf)
+
+	configBytes, err := client.provider.LoadFile(bucket, path.Join(name, archivedConfigFilename))
+	if err != nil {
+		return config.Config{}, fmt.Errorf("error loading archived config for snapshot %s from %s: [%v]", name, bucket, err)
+	}
+	var conf config.Config
+	if err = json.Unmarshal(configBytes, &conf); err != nil {
+		return config.Config{}, err
+	}
+	if conf.Region != newConf.Region {
+		return config.Config{}, fmt.Errorf("snapshot %s was taken in %s and can only be restored there", name, conf.Region)
+	}
+
+	directorCreds, err := client.provider.LoadFile(bucket, path.Join(name, bosh.CredsFilename))
+	if err != nil {
+		return config.Config{}, fmt.Errorf("error loading archived director creds for snapshot %s from %s: [%v]", name, bucket, err)
+	}
+	if err = client.configClient.StoreAsset(bosh.CredsFilename, directorCreds); err != nil {
+		return config.Config{}, err
+	}
+
+	conf = withoutAddresses(conf)
+	conf.ConfigBucket = newConf.ConfigBucket
+	conf.DBUpgradeSnapshot = ""
+	conf.FinalSnapshot = ""
+	conf.RestoredFromSnapshot = name
+	// Cloud SQL instance names can't be reused for a week after the instance is deleted
+	if client.provider.IAAS() == iaas.GCP {
+		conf.RDSDefaultDatabaseName = fmt.Sprintf("bosh-%s", client.eightRandomLetters())
+	}
+	return conf, nil
+}
+
+// withoutAddresses clears the certificates and domain naming the director's and Concourse's
+// addresses, so that a deployment recreated from conf generates certificates for its own
+func withoutAddresses(conf config.Config) config.Config {
+	conf.DirectorPublicIP = ""
+	conf.DirectorCACert = ""
+	conf.DirectorCert = ""
+	conf.DirectorKey = ""
+	conf.ConcourseCert = ""
+	conf.ConcourseKey = ""
+	conf.ConcourseCACert = ""
+	conf.ConcourseUserProvidedCert = false
+	conf.Domain = ""
+	conf.HostedZoneID = ""
+	conf.HostedZoneRecordPrefix = ""
+	return conf
+}
@@ -0,0 +1,59 @@
+package concourse
+
+import (
+	"fmt"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/fly"
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/resource"
+	"github.com/EngineerBetter/concourse-up/terraform"
+)
+
+// BundleURLs returns every binary, terraform provider, release and stemcell a deployment to name from goos downloads,
+// including those for concourseVersion if it is pinned to one from the catalogue
+func BundleURLs(name iaas.Name, goos, concourseVersion string) ([]string, error) {
+	var versionFile []byte
+	switch name {
+	case iaas.AWS:
+		versionFile = awsVersionFile
+	case iaas.GCP:
+		versionFile = gcpVersionFile
+	default:
+		return nil, fmt.Errorf("IAAS not supported: [%s]", name)
+	}
+
+	flyURL, err := fly.FlyURL(versionFile, goos)
+	if err != nil {
+		return nil, err
+	}
+	artifactURLs, err := bosh.ArtifactURLs(name)
+	if err != nil {
+		return nil, err
+	}
+
+	providerURL, err := terraform.ProviderURL(name, goos)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := append(resource.BinaryURLs(goos), flyURL, providerURL)
+	urls = append(urls, artifactURLs...)
+
+	if concourseVersion != "" {
+		release, err := resource.GetConcourseRelease(concourseVersion)
+		if err != nil {
+			return nil, err
+		}
+		flyVersionFile, err := release.FlyVersionFile()
+		if err != nil {
+			return nil, err
+		}
+		pinnedFlyURL, err := fly.FlyURL(flyVersionFile, goos)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, release.URL, pinnedFlyURL)
+	}
+	return urls, nil
+}
@@ -8,9 +8,11 @@ import (
 	"github.com/EngineerBetter/concourse-up/bosh"
 	"github.com/EngineerBetter/concourse-up/certs"
 	"github.com/EngineerBetter/concourse-up/commands/deploy"
+	"github.com/EngineerBetter/concourse-up/commands/destroy"
 	"github.com/EngineerBetter/concourse-up/config"
 	"github.com/EngineerBetter/concourse-up/fly"
 	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/resource"
 	"github.com/EngineerBetter/concourse-up/terraform"
 
 	"github.com/xenolf/lego/lego"
@@ -39,10 +41,19 @@ type Client struct {
 
 // IClient represents a concourse-up client
 type IClient interface {
+	AddAccess(cidr string) error
 	Deploy() error
-	Destroy() error
+	Destroy(destroy.Args) error
+	Export(dir string, redact bool) error
+	FetchCertificates() ([]certs.Certificate, error)
+	FetchCost() (*Cost, error)
+	FetchDrift() (*Drift, error)
+	FetchHealth() (*Health, error)
 	FetchInfo() (*Info, error)
+	Import(state, creds, tfState []byte, deployment string) error
+	ListAccess() ([]string, error)
 	Maintain(maintain.Args) error
+	RemoveAccess(cidr string) error
 }
 
 //go:generate go-bindata -pkg $GOPACKAGE ../../concourse-up-ops/director-versions-aws.json ../../concourse-up-ops/director-versions-gcp.json
@@ -91,6 +102,18 @@ func NewClient(
 	}
 }
 
+// flyVersionFile returns the fly download URLs matching the Concourse version c is pinned to
+func (client *Client) flyVersionFile(c config.Config) ([]byte, error) {
+	if c.ConcourseVersion == "" {
+		return client.versionFile, nil
+	}
+	release, err := resource.GetConcourseRelease(c.ConcourseVersion)
+	if err != nil {
+		return nil, err
+	}
+	return release.FlyVersionFile()
+}
+
 func (client *Client) buildBoshClient(config config.Config, tfOutputs terraform.Outputs) (bosh.IClient, error) {
 
 	return client.boshClientFactory(
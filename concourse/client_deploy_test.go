@@ -1,6 +1,7 @@
 package concourse_test
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -19,6 +20,7 @@ import (
 	"github.com/EngineerBetter/concourse-up/fly/flyfakes"
 	"github.com/EngineerBetter/concourse-up/iaas"
 	"github.com/EngineerBetter/concourse-up/iaas/iaasfakes"
+	"github.com/EngineerBetter/concourse-up/resource"
 	"github.com/EngineerBetter/concourse-up/terraform"
 	"github.com/EngineerBetter/concourse-up/terraform/terraformfakes"
 	. "github.com/onsi/ginkgo"
@@ -44,9 +46,12 @@ var _ = Describe("client", func() {
 	var ipChecker func() (string, error)
 	var tfInputVarsFactory *concoursefakes.FakeTFInputVarsFactory
 	var flyClient *flyfakes.FakeIClient
+	var flyVersionFile []byte
 	var terraformCLI *terraformfakes.FakeCLIInterface
 	var configClient *configfakes.FakeIClient
 	var boshClient *boshfakes.FakeIClient
+	var awsClient *iaasfakes.FakeProvider
+	var archivedFiles map[string][]byte
 
 	var setupFakeAwsProvider = func() *iaasfakes.FakeProvider {
 		provider := &iaasfakes.FakeProvider{}
@@ -68,6 +73,19 @@ var _ = Describe("client", func() {
 
 			return "", "", errors.New("hosted zone not found")
 		}
+		provider.ValidateInstanceTypeStub = func(instanceType, zone string) error {
+			if instanceType == "x1e.32xlarge" {
+				return fmt.Errorf("instance type %s is not offered in %s", instanceType, zone)
+			}
+			return nil
+		}
+		provider.LoadFileStub = func(bucket, path string) ([]byte, error) {
+			contents, ok := archivedFiles[bucket+"/"+path]
+			if !ok {
+				return nil, fmt.Errorf("%s/%s not found", bucket, path)
+			}
+			return contents, nil
+		}
 		return provider
 	}
 
@@ -147,6 +165,7 @@ var _ = Describe("client", func() {
 
 		deleteBoshDirectorError = nil
 		certGenerationActions = []string{}
+		archivedFiles = map[string][]byte{}
 
 		// Initial config in bucket from an existing deployment
 		configInBucket = config.Config{
@@ -206,7 +225,7 @@ sWbB3FCIsym1FXB+eRnVF3Y15RwBWWKA5RfwUNpEXFxtv24tQ8jrdA==
 		}
 
 		flyClient = &flyfakes.FakeIClient{}
-		awsClient := setupFakeAwsProvider()
+		awsClient = setupFakeAwsProvider()
 		otherRegionClient := setupFakeOtherRegionProvider()
 		tfInputVarsFactory = setupFakeTfInputVarsFactory()
 		configClient = &configfakes.FakeIClient{}
@@ -232,7 +251,8 @@ sWbB3FCIsym1FXB+eRnVF3Y15RwBWWKA5RfwUNpEXFxtv24tQ8jrdA==
 				terraformCLI,
 				tfInputVarsFactory,
 				boshClientFactory,
-				func(iaas.Provider, fly.Credentials, io.Writer, io.Writer, []byte) (fly.IClient, error) {
+				func(_ iaas.Provider, _ fly.Credentials, _, _ io.Writer, versionFile []byte) (fly.IClient, error) {
+					flyVersionFile = versionFile
 					return flyClient, nil
 				},
 				certGenerator,
@@ -294,6 +314,8 @@ sWbB3FCIsym1FXB+eRnVF3Y15RwBWWKA5RfwUNpEXFxtv24tQ8jrdA==
 						AllowIPs:               configAfterLoad.AllowIPs,
 						AvailabilityZone:       configAfterLoad.AvailabilityZone,
 						ConfigBucket:           configAfterLoad.ConfigBucket,
+						DBEngineVersion:        "9.6.11",
+						DBStorage:              10,
 						Deployment:             configAfterLoad.Deployment,
 						HostedZoneID:           configAfterLoad.HostedZoneID,
 						HostedZoneRecordPrefix: configAfterLoad.HostedZoneRecordPrefix,
@@ -486,9 +508,12 @@ wEW5QkylaPEkbVDhJWeR1I8=
 					args.WorkerSizeIsSet = true
 					args.WorkerType = "m5"
 					args.WorkerTypeIsSet = true
+					args.ConcourseVersion = resource.ConcourseVersions()[0]
+					args.ConcourseVersionIsSet = true
 
 					configAfterLoad = configInBucket
 					configAfterLoad.AllowIPs = "\"88.98.225.40/32\""
+					configAfterLoad.ConcourseVersion = args.ConcourseVersion
 					configAfterLoad.ConcourseWebSize = args.WebSize
 					configAfterLoad.ConcourseWorkerCount = args.WorkerCount
 					configAfterLoad.ConcourseWorkerSize = args.WorkerSize
@@ -513,6 +538,8 @@ wEW5QkylaPEkbVDhJWeR1I8=
 						AllowIPs:               configAfterLoad.AllowIPs,
 						AvailabilityZone:       configAfterLoad.AvailabilityZone,
 						ConfigBucket:           configAfterLoad.ConfigBucket,
+						DBEngineVersion:        "9.6.11",
+						DBStorage:              10,
 						Deployment:             configAfterLoad.Deployment,
 						HostedZoneID:           configAfterLoad.HostedZoneID,
 						HostedZoneRecordPrefix: configAfterLoad.HostedZoneRecordPrefix,
@@ -584,6 +611,18 @@ wEW5QkylaPEkbVDhJWeR1I8=
 					Expect(flyClient).To(HaveReceived("SetDefaultPipeline").With(configAfterCreateEnv, false))
 					Expect(configClient).To(HaveReceived("Update").With(configAfterConcourseDeploy))
 				})
+
+				It("uses the fly matching the pinned Concourse version", func() {
+					client := buildClient()
+					err := client.Deploy()
+					Expect(err).ToNot(HaveOccurred())
+
+					release, err := resource.GetConcourseRelease(args.ConcourseVersion)
+					Expect(err).ToNot(HaveOccurred())
+					expected, err := release.FlyVersionFile()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(flyVersionFile).To(MatchJSON(expected))
+				})
 			})
 		})
 
@@ -703,6 +742,8 @@ wEW5QkylaPEkbVDhJWeR1I8=
 					AllowIPs:               defaultGeneratedConfig.AllowIPs,
 					AvailabilityZone:       defaultGeneratedConfig.AvailabilityZone,
 					ConfigBucket:           defaultGeneratedConfig.ConfigBucket,
+					DBEngineVersion:        "9.6.11",
+					DBStorage:              10,
 					Deployment:             defaultGeneratedConfig.Deployment,
 					HostedZoneID:           defaultGeneratedConfig.HostedZoneID,
 					HostedZoneRecordPrefix: defaultGeneratedConfig.HostedZoneRecordPrefix,
@@ -843,6 +884,246 @@ wEW5QkylaPEkbVDhJWeR1I8=
 			})
 		})
 
+		Context("When raw instance types are provided", func() {
+			BeforeEach(func() {
+				args.WorkerInstanceType = "c5.4xlarge"
+				args.WorkerInstanceTypeIsSet = true
+				args.WebInstanceType = "t3.large"
+				args.WebInstanceTypeIsSet = true
+				args.DBInstanceType = "db.r5.large"
+				args.DBInstanceTypeIsSet = true
+			})
+
+			JustBeforeEach(func() {
+				configClient.LoadReturns(configInBucket, nil)
+				configClient.ConfigExistsReturns(true, nil)
+			})
+
+			It("Persists them in the config and uses the DB instance class", func() {
+				client := buildClient()
+				err := client.Deploy()
+				Expect(err).ToNot(HaveOccurred())
+
+				updated := configClient.UpdateArgsForCall(0)
+				Expect(updated.WorkerInstanceType).To(Equal("c5.4xlarge"))
+				Expect(updated.WebInstanceType).To(Equal("t3.large"))
+				Expect(updated.RDSInstanceClass).To(Equal("db.r5.large"))
+
+				inputVars := terraformCLI.ApplyArgsForCall(0).(*terraform.AWSInputVars)
+				Expect(inputVars.RDSInstanceClass).To(Equal("db.r5.large"))
+			})
+
+			It("Checks RDS offers the DB instance class for the version of PostgreSQL", func() {
+				client := buildClient()
+				err := client.Deploy()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(awsClient.ValidateDBInstanceTypeCallCount()).To(Equal(1))
+				instanceClass, engineVersion := awsClient.ValidateDBInstanceTypeArgsForCall(0)
+				Expect(instanceClass).To(Equal("db.r5.large"))
+				Expect(engineVersion).To(Equal("9.6.11"))
+			})
+
+			It("Refuses an instance type the zone doesn't offer", func() {
+				args.WorkerInstanceType = "x1e.32xlarge"
+				client := buildClient()
+				err := client.Deploy()
+				Expect(err).To(MatchError(ContainSubstring("instance type x1e.32xlarge is not offered in")))
+				Expect(terraformCLI).ToNot(HaveReceived("Apply"))
+			})
+		})
+
+		Context("When a maximum database storage is provided", func() {
+			BeforeEach(func() {
+				args.DBMaxStorage = 100
+				args.DBMaxStorageIsSet = true
+			})
+
+			JustBeforeEach(func() {
+				configClient.LoadReturns(configInBucket, nil)
+				configClient.ConfigExistsReturns(true, nil)
+			})
+
+			It("Persists it in the config and passes it to terraform", func() {
+				client := buildClient()
+				err := client.Deploy()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(configClient.UpdateArgsForCall(0).DBMaxStorage).To(Equal(100))
+			})
+
+			It("Refuses a maximum below the storage the database already has", func() {
+				configInBucket.DBStorage = 200
+				configClient.LoadReturns(configInBucket, nil)
+				client := buildClient()
+				err := client.Deploy()
+				Expect(err).To(MatchError(ContainSubstring("Maximum database storage of 100GB is less than the 200GB the database has")))
+				Expect(terraformCLI).ToNot(HaveReceived("Apply"))
+			})
+		})
+
+		Context("When the config has a self-update role", func() {
+			BeforeEach(func() {
+				configInBucket.SelfUpdateRoleARN = "arn:aws:iam::123456789012:role/concourse-up"
+				configInBucket.SelfUpdateExternalID = "an-external-id"
+			})
+
+			JustBeforeEach(func() {
+				configClient.LoadReturns(configInBucket, nil)
+				configClient.ConfigExistsReturns(true, nil)
+			})
+
+			It("Keeps the role when the deploy doesn't assume one", func() {
+				client := buildClient()
+				err := client.Deploy()
+				Expect(err).ToNot(HaveOccurred())
+
+				updated := configClient.UpdateArgsForCall(0)
+				Expect(updated.SelfUpdateRoleARN).To(Equal("arn:aws:iam::123456789012:role/concourse-up"))
+				Expect(updated.SelfUpdateExternalID).To(Equal("an-external-id"))
+			})
+
+			It("Replaces the role with the one the deploy assumed", func() {
+				args.SelfUpdateRoleARN = "arn:aws:iam::123456789012:role/deployer"
+				args.SelfUpdateRoleARNIsSet = true
+				client := buildClient()
+				err := client.Deploy()
+				Expect(err).ToNot(HaveOccurred())
+
+				updated := configClient.UpdateArgsForCall(0)
+				Expect(updated.SelfUpdateRoleARN).To(Equal("arn:aws:iam::123456789012:role/deployer"))
+				Expect(updated.SelfUpdateExternalID).To(BeEmpty())
+			})
+		})
+
+		Context("When Windows and arm64 workers are requested", func() {
+			BeforeEach(func() {
+				args.WindowsWorkerCount = 2
+				args.WindowsWorkerCountIsSet = true
+				args.ARMWorkerCount = 1
+				args.ARMWorkerCountIsSet = true
+				args.ARMStemcellURL = "https://example.com/light-bosh-stemcell-1.0-aws-xen-hvm-ubuntu-bionic-go_agent.tgz"
+				args.ARMStemcellURLIsSet = true
+				args.ARMConcourseReleaseURL = "https://example.com/concourse-arm64-5.0.0.tgz"
+				args.ARMConcourseReleaseURLIsSet = true
+				args.ARMGardenRunCReleaseURL = "https://example.com/garden-runc-arm64-1.19.0.tgz"
+				args.ARMGardenRunCReleaseURLIsSet = true
+			})
+
+			JustBeforeEach(func() {
+				configClient.LoadReturns(configInBucket, nil)
+				configClient.ConfigExistsReturns(true, nil)
+			})
+
+			It("Persists the worker pools with default instance types", func() {
+				client := buildClient()
+				err := client.Deploy()
+				Expect(err).ToNot(HaveOccurred())
+
+				updated := configClient.UpdateArgsForCall(0)
+				Expect(updated.WindowsWorkerCount).To(Equal(2))
+				Expect(updated.WindowsWorkerInstanceType).To(Equal("m5.xlarge"))
+				Expect(updated.ARMWorkerCount).To(Equal(1))
+				Expect(updated.ARMWorkerInstanceType).To(Equal("m6g.xlarge"))
+				Expect(updated.ARMStemcellURL).To(Equal(args.ARMStemcellURL))
+				Expect(updated.ARMConcourseReleaseURL).To(Equal(args.ARMConcourseReleaseURL))
+				Expect(updated.ARMGardenRunCReleaseURL).To(Equal(args.ARMGardenRunCReleaseURL))
+			})
+
+			It("Refuses arm64 workers without a stemcell", func() {
+				args.ARMStemcellURL = ""
+				args.ARMStemcellURLIsSet = false
+				client := buildClient()
+				err := client.Deploy()
+				Expect(err).To(MatchError(ContainSubstring("--arm-workers requires --arm-stemcell-url")))
+				Expect(terraformCLI).ToNot(HaveReceived("Apply"))
+			})
+
+			It("Refuses arm64 workers without prebuilt arm64 releases", func() {
+				args.ARMGardenRunCReleaseURL = ""
+				args.ARMGardenRunCReleaseURLIsSet = false
+				client := buildClient()
+				err := client.Deploy()
+				Expect(err).To(MatchError(ContainSubstring("--arm-workers requires --arm-concourse-release-url and --arm-garden-runc-release-url")))
+				Expect(terraformCLI).ToNot(HaveReceived("Apply"))
+			})
+		})
+
+		Context("When restoring from a final snapshot", func() {
+			const snapshot = "concourse-up-happymeal-final-20190301120000"
+
+			BeforeEach(func() {
+				args.RestoreFromSnapshot = snapshot
+				args.RestoreFromSnapshotIsSet = true
+
+				archivedConfig := configInBucket
+				archivedConfig.ConfigBucket = "concourse-up-happymeal-eu-west-1-config"
+				archivedConfig.EncryptionKey = "archived-encryption-key"
+				archivedConfig.RDSDefaultDatabaseName = "bosh_archived"
+				archivedConfig.DirectorPublicIP = "11.11.11.11"
+				archivedConfig.DirectorCACert = "archived-director-ca"
+				archivedConfig.DirectorCert = "archived-director-cert"
+				archivedConfig.DirectorKey = "archived-director-key"
+				archivedConfig.Domain = "11.11.11.12"
+				configBytes, err := json.Marshal(archivedConfig)
+				Expect(err).ToNot(HaveOccurred())
+				archivedFiles["concourse-up-happymeal-eu-west-1-archive/"+snapshot+"/config.json"] = configBytes
+				archivedFiles["concourse-up-happymeal-eu-west-1-archive/"+snapshot+"/director-creds.yml"] = directorCredsFixture
+			})
+
+			JustBeforeEach(func() {
+				configClient.NewConfigReturns(config.Config{
+					ConfigBucket: "concourse-up-happymeal-eu-west-1-config",
+					Deployment:   "concourse-up-happymeal",
+					Project:      "happymeal",
+					Region:       "eu-west-1",
+					TFStatePath:  "terraform.tfstate",
+				})
+			})
+
+			It("Recreates the deployment from the archived config and director creds", func() {
+				client := buildClient()
+				err := client.Deploy()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(configClient).ToNot(HaveReceived("Load"))
+				Expect(configClient).To(HaveReceived("StoreAsset").With("director-creds.yml", directorCredsFixture))
+
+				restored := configClient.UpdateArgsForCall(0)
+				Expect(restored.EncryptionKey).To(Equal("archived-encryption-key"))
+				Expect(restored.RDSDefaultDatabaseName).To(Equal("bosh_archived"))
+				Expect(restored.RestoredFromSnapshot).To(Equal(snapshot))
+
+				inputVars := terraformCLI.ApplyArgsForCall(0).(*terraform.AWSInputVars)
+				Expect(inputVars.RestoreSnapshot).To(Equal(snapshot))
+				Expect(inputVars.FinalSnapshot).To(BeEmpty())
+			})
+
+			It("Generates director and Concourse certs for the new addresses", func() {
+				client := buildClient()
+				err := client.Deploy()
+				Expect(err).ToNot(HaveOccurred())
+
+				restored := configClient.UpdateArgsForCall(0)
+				Expect(restored.DirectorCACert).To(BeEmpty())
+				Expect(restored.Domain).To(BeEmpty())
+				Expect(certGenerationActions).To(Equal([]string{
+					"generating cert ca: concourse-up-happymeal, cn: [99.99.99.99 10.0.0.6]",
+					"generating cert ca: concourse-up-happymeal, cn: [77.77.77.77]",
+				}))
+				deployed := configClient.UpdateArgsForCall(configClient.UpdateCallCount() - 1)
+				Expect(deployed.DirectorCACert).ToNot(Equal("archived-director-ca"))
+				Expect(deployed.DirectorPublicIP).To(Equal("99.99.99.99"))
+			})
+
+			It("Refuses to restore over an existing deployment", func() {
+				configClient.ConfigExistsReturns(true, nil)
+				client := buildClient()
+				err := client.Deploy()
+				Expect(err).To(MatchError(ContainSubstring("cannot restore from snapshot " + snapshot + " as the deployment already exists")))
+			})
+		})
+
 		Context("When running in self-update mode and the concourse is already deployed", func() {
 			It("Sets the default pipeline, before deploying the bosh director", func() {
 				flyClient.CanConnectStub = func() (bool, error) {
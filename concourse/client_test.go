@@ -1,16 +1,26 @@
 package concourse_test
 
 import (
+	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/EngineerBetter/concourse-up/bosh"
 	"github.com/EngineerBetter/concourse-up/bosh/boshfakes"
 	"github.com/EngineerBetter/concourse-up/certs"
 	"github.com/EngineerBetter/concourse-up/certs/certsfakes"
 	"github.com/EngineerBetter/concourse-up/commands/deploy"
+	"github.com/EngineerBetter/concourse-up/commands/destroy"
+	"github.com/EngineerBetter/concourse-up/commands/maintain"
 	"github.com/EngineerBetter/concourse-up/concourse"
 	"github.com/EngineerBetter/concourse-up/concourse/concoursefakes"
 	"github.com/EngineerBetter/concourse-up/config"
@@ -26,10 +36,23 @@ import (
 	"github.com/onsi/gomega/gbytes"
 	. "github.com/tjarratt/gcounterfeiter"
 	"github.com/xenolf/lego/lego"
+	"gopkg.in/yaml.v2"
 )
 
+// natsCAFixture returns the NATS CA from director-creds.yml
+func natsCAFixture(directorCreds []byte) string {
+	var creds struct {
+		NatsCA struct {
+			Certificate string `yaml:"certificate"`
+		} `yaml:"nats_ca"`
+	}
+	Expect(yaml.Unmarshal(directorCreds, &creds)).To(Succeed())
+	return creds.NatsCA.Certificate
+}
+
 var _ = Describe("client", func() {
 	var buildClient func() concourse.IClient
+	var newClient func(iaas.Provider, terraform.CLIInterface, config.IClient, *deploy.Args) *concourse.Client
 	var actions []string
 	var stdout *gbytes.Buffer
 	var stderr *gbytes.Buffer
@@ -43,6 +66,10 @@ var _ = Describe("client", func() {
 	var terraformCLI *terraformfakes.FakeCLIInterface
 	var configClient *configfakes.FakeIClient
 	var boshClient *boshfakes.FakeIClient
+	var awsClient *iaasfakes.FakeProvider
+	var instancesFor func(config.Config) []bosh.Instance
+	var natGatewayPriceErr error
+	var cloudConfigDrift []string
 
 	var setupFakeAwsProvider = func() *iaasfakes.FakeProvider {
 		provider := &iaasfakes.FakeProvider{}
@@ -51,7 +78,7 @@ var _ = Describe("client", func() {
 		provider.IAASReturns(iaas.AWS)
 		provider.CheckForWhitelistedIPStub = func(ip, securityGroup string) (bool, error) {
 			actions = append(actions, "checking security group for IP")
-			if ip == "1.2.3.4" {
+			if ip == "1.2.3.4" || ip == "1.2.3.4/32" {
 				return false, nil
 			}
 			return true, nil
@@ -60,6 +87,14 @@ var _ = Describe("client", func() {
 			actions = append(actions, fmt.Sprintf("deleting vms in %s", vpcID))
 			return nil, nil
 		}
+		provider.CreateBucketStub = func(name string) error {
+			actions = append(actions, fmt.Sprintf("creating bucket %s", name))
+			return nil
+		}
+		provider.WriteFileStub = func(bucket, path string, contents []byte) error {
+			actions = append(actions, fmt.Sprintf("writing %s/%s", bucket, path))
+			return nil
+		}
 		provider.FindLongestMatchingHostedZoneStub = func(subdomain string) (string, string, error) {
 			if subdomain == "ci.google.com" {
 				return "google.com", "ABC123", nil
@@ -67,6 +102,19 @@ var _ = Describe("client", func() {
 
 			return "", "", errors.New("hosted zone not found")
 		}
+		provider.InstancePriceStub = func(instanceType, zone string, spot bool) (float64, error) {
+			prices := map[string]float64{"t2.small": 0.025, "m4.xlarge": 0.2, "m5.xlarge": 0.2}
+			if spot {
+				return prices[instanceType] * 0.4, nil
+			}
+			return prices[instanceType], nil
+		}
+		provider.DiskPriceReturns(0.1, nil)
+		provider.DBInstancePriceReturns(0.1, nil)
+		provider.DBStoragePriceReturns(0.2, nil)
+		provider.NATGatewayPriceStub = func() (float64, error) {
+			return 0.05, natGatewayPriceErr
+		}
 		return provider
 	}
 
@@ -144,7 +192,7 @@ var _ = Describe("client", func() {
 			}, nil
 		}
 
-		awsClient := setupFakeAwsProvider()
+		awsClient = setupFakeAwsProvider()
 		tfInputVarsFactory = setupFakeTfInputVarsFactory()
 		configClient = setupFakeConfigClient()
 
@@ -246,6 +294,7 @@ sWbB3FCIsym1FXB+eRnVF3Y15RwBWWKA5RfwUNpEXFxtv24tQ8jrdA==
 		configAfterCreateEnv.Version = "some version"
 
 		terraformCLI = setupFakeTerraformCLI(terraformOutputs)
+		instancesFor = nil
 
 		boshClientFactory := func(config config.Config, outputs terraform.Outputs, stdout, stderr io.Writer, provider iaas.Provider, versionFile []byte) (bosh.IClient, error) {
 			boshClient = &boshfakes.FakeIClient{}
@@ -267,8 +316,36 @@ sWbB3FCIsym1FXB+eRnVF3Y15RwBWWKA5RfwUNpEXFxtv24tQ8jrdA==
 			}
 			boshClient.InstancesStub = func() ([]bosh.Instance, error) {
 				actions = append(actions, "listing bosh instances")
+				if instancesFor != nil {
+					return instancesFor(config), nil
+				}
+				return nil, nil
+			}
+			boshClient.VitalsStub = func() ([]bosh.Instance, error) {
+				actions = append(actions, "listing bosh vitals")
+				if instancesFor != nil {
+					return instancesFor(config), nil
+				}
 				return nil, nil
 			}
+			boshClient.CreateEnvStub = func(stateFileBytes, credsFileBytes []byte, customOps string) ([]byte, []byte, error) {
+				actions = append(actions, "creating director env")
+				return stateFileBytes, credsFileBytes, nil
+			}
+			boshClient.RecreateStub = func() error {
+				actions = append(actions, "recreating vms")
+				return nil
+			}
+			boshClient.DatabaseVersionStub = func() (string, error) {
+				actions = append(actions, "checking database version")
+				if config.DBVersion == "" {
+					return "9.6.11", nil
+				}
+				return config.DBVersion + ".1", nil
+			}
+			boshClient.CloudConfigDriftStub = func() ([]string, error) {
+				return cloudConfigDrift, nil
+			}
 
 			return boshClient, nil
 		}
@@ -280,10 +357,10 @@ sWbB3FCIsym1FXB+eRnVF3Y15RwBWWKA5RfwUNpEXFxtv24tQ8jrdA==
 		stdout = gbytes.NewBuffer()
 		stderr = gbytes.NewBuffer()
 
-		buildClient = func() concourse.IClient {
+		newClient = func(provider iaas.Provider, tfCLI terraform.CLIInterface, configClient config.IClient, deployArgs *deploy.Args) *concourse.Client {
 			return concourse.NewClient(
-				awsClient,
-				terraformCLI,
+				provider,
+				tfCLI,
 				tfInputVarsFactory,
 				boshClientFactory,
 				func(iaas.Provider, fly.Credentials, io.Writer, io.Writer, []byte) (fly.IClient, error) {
@@ -291,7 +368,7 @@ sWbB3FCIsym1FXB+eRnVF3Y15RwBWWKA5RfwUNpEXFxtv24tQ8jrdA==
 				},
 				certGenerator,
 				configClient,
-				args,
+				deployArgs,
 				stdout,
 				stderr,
 				ipChecker,
@@ -302,32 +379,35 @@ sWbB3FCIsym1FXB+eRnVF3Y15RwBWWKA5RfwUNpEXFxtv24tQ8jrdA==
 				"some version",
 			)
 		}
+		buildClient = func() concourse.IClient {
+			return newClient(awsClient, terraformCLI, configClient, args)
+		}
 	})
 
 	Describe("Destroy", func() {
 		It("Loads the config file", func() {
 			client := buildClient()
-			err := client.Destroy()
+			err := client.Destroy(destroy.Args{})
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(actions).To(ContainElement("loading config file"))
 		})
 		It("Builds IAAS environment", func() {
 			client := buildClient()
-			err := client.Destroy()
+			err := client.Destroy(destroy.Args{SkipFinalSnapshot: true})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(tfInputVarsFactory).To(HaveReceived("NewInputVars").With(configInBucket))
 		})
 		It("Loads terraform output", func() {
 			client := buildClient()
-			err := client.Destroy()
+			err := client.Destroy(destroy.Args{})
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(actions).To(ContainElement("initializing terraform outputs"))
 		})
 		It("Deletes the vms in the vpcs", func() {
 			client := buildClient()
-			err := client.Destroy()
+			err := client.Destroy(destroy.Args{})
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(actions).To(ContainElement("deleting vms in vpc-112233"))
@@ -335,7 +415,7 @@ sWbB3FCIsym1FXB+eRnVF3Y15RwBWWKA5RfwUNpEXFxtv24tQ8jrdA==
 
 		It("Destroys the terraform infrastructure", func() {
 			client := buildClient()
-			err := client.Destroy()
+			err := client.Destroy(destroy.Args{})
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(actions).To(ContainElement("destroying terraform"))
@@ -343,7 +423,7 @@ sWbB3FCIsym1FXB+eRnVF3Y15RwBWWKA5RfwUNpEXFxtv24tQ8jrdA==
 
 		It("Deletes the config", func() {
 			client := buildClient()
-			err := client.Destroy()
+			err := client.Destroy(destroy.Args{})
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(actions).To(ContainElement("deleting config"))
@@ -351,12 +431,53 @@ sWbB3FCIsym1FXB+eRnVF3Y15RwBWWKA5RfwUNpEXFxtv24tQ8jrdA==
 
 		It("Prints a destroy success message", func() {
 			client := buildClient()
-			err := client.Destroy()
+			err := client.Destroy(destroy.Args{})
 			Expect(err).ToNot(HaveOccurred())
 
 			Eventually(stdout).Should(gbytes.Say("DESTROY SUCCESSFUL"))
 		})
 
+		Context("When taking a final snapshot", func() {
+			BeforeEach(func() {
+				configInBucket.ConfigBucket = "concourse-up-happymeal-eu-west-1-config"
+			})
+
+			It("Archives the config and has terraform snapshot the database before destroying it", func() {
+				client := buildClient()
+				err := client.Destroy(destroy.Args{})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(actions).To(ContainElement("creating bucket concourse-up-happymeal-eu-west-1-archive"))
+				inputVars := terraformCLI.ApplyArgsForCall(0).(*terraform.AWSInputVars)
+				Expect(inputVars.FinalSnapshot).To(HavePrefix("concourse-up-happymeal-final-"))
+				archive := "concourse-up-happymeal-eu-west-1-archive/" + inputVars.FinalSnapshot
+
+				var steps []string
+				for _, action := range actions {
+					switch {
+					case strings.HasPrefix(action, "writing"), action == "applying terraform", action == "destroying terraform", action == "deleting config":
+						steps = append(steps, action)
+					}
+				}
+				Expect(steps).To(Equal([]string{
+					"writing " + archive + "/config.json",
+					"writing " + archive + "/director-creds.yml",
+					"applying terraform",
+					"destroying terraform",
+					"deleting config",
+				}))
+			})
+		})
+
+		It("Doesn't snapshot or archive anything when asked not to", func() {
+			client := buildClient()
+			err := client.Destroy(destroy.Args{SkipFinalSnapshot: true})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(terraformCLI.ApplyCallCount()).To(Equal(0))
+			Expect(actions).ToNot(ContainElement(HavePrefix("writing")))
+		})
+
 		Context("When there is an error deleting the bosh director", func() {
 			BeforeEach(func() {
 				deleteBoshDirectorError = errors.New("some error")
@@ -364,12 +485,817 @@ sWbB3FCIsym1FXB+eRnVF3Y15RwBWWKA5RfwUNpEXFxtv24tQ8jrdA==
 
 			It("Continues the error", func() {
 				client := buildClient()
-				err := client.Destroy()
+				err := client.Destroy(destroy.Args{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("When a notification webhook is configured", func() {
+			var webhook *httptest.Server
+			var payloads chan map[string]string
+
+			BeforeEach(func() {
+				payloads = make(chan map[string]string, 1)
+				webhook = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					var payload map[string]string
+					Expect(json.NewDecoder(r.Body).Decode(&payload)).To(Succeed())
+					payloads <- payload
+				}))
+				configInBucket.Deployment = "concourse-up-happymeal"
+				configInBucket.NotifyWebhook = webhook.URL
+			})
+
+			AfterEach(func() {
+				webhook.Close()
+			})
+
+			It("Reports success", func() {
+				client := buildClient()
+				err := client.Destroy(destroy.Args{})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(<-payloads).To(Equal(map[string]string{
+					"text":       "concourse-up destroy of happymeal succeeded",
+					"deployment": "happymeal",
+					"action":     "destroy",
+					"outcome":    "succeeded",
+				}))
+			})
+
+			It("Reports failure and still returns the original error", func() {
+				terraformCLI.DestroyReturns(errors.New("terraform exploded"))
+				client := buildClient()
+				err := client.Destroy(destroy.Args{})
+				Expect(err).To(MatchError("terraform exploded"))
+
+				payload := <-payloads
+				Expect(payload["outcome"]).To(Equal("failed"))
+				Expect(payload["error"]).To(Equal("terraform exploded"))
+			})
+
+			It("Only warns when the notification can't be sent", func() {
+				webhook.Close()
+				client := buildClient()
+				err := client.Destroy(destroy.Args{})
 				Expect(err).ToNot(HaveOccurred())
+
+				Expect(stderr).To(gbytes.Say("WARNING: failed to send notifications"))
 			})
 		})
 	})
 
+	Describe("AddAccess", func() {
+		It("Applies terraform with the new entry and persists it", func() {
+			client := buildClient()
+			err := client.AddAccess("203.0.113.0/24")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(actions).To(Equal([]string{
+				"loading config file",
+				"converting config.Config to TFInputVars",
+				"applying terraform",
+				"updating config file",
+			}))
+			updated := configClient.UpdateArgsForCall(0)
+			Expect(updated.AccessCIDRs).To(Equal([]string{"203.0.113.0/24"}))
+			inputVars := terraformCLI.ApplyArgsForCall(0).(*terraform.AWSInputVars)
+			Expect(inputVars.AccessCIDRs).To(Equal(`"203.0.113.0/24"`))
+		})
+
+		It("Normalises a bare IP to a /32", func() {
+			client := buildClient()
+			err := client.AddAccess("198.51.100.7")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(configClient.UpdateArgsForCall(0).AccessCIDRs).To(Equal([]string{"198.51.100.7/32"}))
+		})
+
+		It("Does nothing when the entry is already present", func() {
+			configInBucket.AccessCIDRs = []string{"203.0.113.0/24"}
+			client := buildClient()
+			err := client.AddAccess("203.0.113.0/24")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actions).ToNot(ContainElement("applying terraform"))
+			Eventually(stdout).Should(gbytes.Say("already on the access list"))
+		})
+
+		It("Rejects input that is not an IP or CIDR", func() {
+			client := buildClient()
+			err := client.AddAccess("not-a-cidr")
+			Expect(err).To(HaveOccurred())
+			Expect(actions).To(BeEmpty())
+		})
+	})
+
+	Describe("RemoveAccess", func() {
+		BeforeEach(func() {
+			configInBucket.AccessCIDRs = []string{"203.0.113.0/24", "198.51.100.7/32"}
+		})
+
+		It("Applies terraform without the entry and persists the list", func() {
+			client := buildClient()
+			err := client.RemoveAccess("198.51.100.7")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(actions).To(ContainElement("applying terraform"))
+			Expect(configClient.UpdateArgsForCall(0).AccessCIDRs).To(Equal([]string{"203.0.113.0/24"}))
+		})
+
+		It("Returns an error when the entry is not present", func() {
+			client := buildClient()
+			err := client.RemoveAccess("192.0.2.0/24")
+			Expect(err).To(MatchError("192.0.2.0/24 is not on the access list"))
+			Expect(actions).ToNot(ContainElement("applying terraform"))
+		})
+	})
+
+	Describe("ListAccess", func() {
+		It("Returns the entries from config", func() {
+			configInBucket.AccessCIDRs = []string{"203.0.113.0/24"}
+			client := buildClient()
+			cidrs, err := client.ListAccess()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cidrs).To(Equal([]string{"203.0.113.0/24"}))
+		})
+	})
+
+	Describe("Maintain", func() {
+		var assets map[string][]byte
+		var storedCreds [][]byte
+
+		BeforeEach(func() {
+			storedCreds = nil
+			configInBucket.EncryptionKey = "old-encryption-key"
+			assets = map[string][]byte{
+				bosh.StateFilename: directorStateFixture,
+				bosh.CredsFilename: directorCredsFixture,
+			}
+			configClient.HasAssetStub = func(filename string) (bool, error) {
+				_, ok := assets[filename]
+				return ok, nil
+			}
+			configClient.LoadAssetStub = func(filename string) ([]byte, error) {
+				return assets[filename], nil
+			}
+			configClient.StoreAssetStub = func(filename string, contents []byte) error {
+				if filename == bosh.CredsFilename {
+					storedCreds = append(storedCreds, contents)
+				}
+				assets[filename] = contents
+				return nil
+			}
+			configClient.UpdateStub = func(c config.Config) error {
+				actions = append(actions, "updating config file")
+				configInBucket = c
+				return nil
+			}
+		})
+
+		Context("When rotating credentials", func() {
+			It("Generates new credentials and rolls them out in stages", func() {
+				client := buildClient()
+				err := client.Maintain(maintain.Args{RotateCredentials: true, RotateCredentialsIsSet: true})
+				Expect(err).ToNot(HaveOccurred())
+
+				generated := configClient.UpdateArgsForCall(0)
+				Expect(generated.EncryptionKey).To(Equal("generatedPassword32"))
+				Expect(generated.OldEncryptionKey).To(Equal("old-encryption-key"))
+				Expect(generated.DirectorPassword).To(Equal("generatedPassword20"))
+				Expect(generated.RDSPassword).To(Equal("generatedPassword20"))
+				Expect(generated.PublicKey).To(Equal("public"))
+				Expect(generated.PrivateKey).To(Equal("private"))
+				Expect(generated.ConcoursePassword).To(BeEmpty())
+
+				Expect(string(assets["director-creds-backup.yml"])).To(Equal(string(directorCredsFixture)))
+				Expect(string(storedCreds[0])).ToNot(ContainSubstring("hm_password"))
+				Expect(string(storedCreds[0])).ToNot(ContainSubstring("credhub_admin_client_secret"))
+				Expect(string(storedCreds[0])).To(ContainSubstring("mbus_bootstrap_password"))
+
+				var stages []string
+				for _, action := range actions {
+					switch action {
+					case "applying terraform", "creating director env", "deploying director", "recreating vms":
+						stages = append(stages, action)
+					}
+				}
+				Expect(stages).To(Equal([]string{
+					"applying terraform",
+					"creating director env",
+					"deploying director",
+					"recreating vms",
+					"deploying director",
+				}))
+
+				Expect(configInBucket.OldEncryptionKey).To(BeEmpty())
+				Expect(configInBucket.EncryptionKey).To(Equal("generatedPassword32"))
+				Expect(string(assets["maintenance.json"])).To(MatchJSON(`{"operation": "rotate-credentials", "status_index": -1}`))
+			})
+
+			It("Keeps the original encryption key when resuming", func() {
+				configInBucket.OldEncryptionKey = "original-encryption-key"
+				assets["maintenance.json"] = []byte(`{"operation": "rotate-credentials", "status_index": 2}`)
+				client := buildClient()
+				err := client.Maintain(maintain.Args{RotateCredentials: true, RotateCredentialsIsSet: true, Stage: 0, StageIsSet: true})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(configClient.UpdateArgsForCall(0).OldEncryptionKey).To(Equal("original-encryption-key"))
+			})
+
+			It("Refuses to start while the NATS certificate renewal is unfinished", func() {
+				assets["maintenance.json"] = []byte(`{"status_index": 1}`)
+				client := buildClient()
+				err := client.Maintain(maintain.Args{RotateCredentials: true, RotateCredentialsIsSet: true})
+				Expect(err).To(MatchError(ContainSubstring("renew-nats-cert has not finished")))
+				Expect(actions).ToNot(ContainElement("applying terraform"))
+			})
+		})
+
+		Context("When rotating director certificates", func() {
+			It("Swaps in the new CAs and regenerates the Concourse CA", func() {
+				assets[bosh.CredsFilename] = []byte(`
+default_ca: {certificate: old-default-ca}
+default_ca_2: {certificate: new-default-ca}
+mbus_bootstrap_ssl: {ca: old-default-ca, certificate: old-mbus}
+mbus_bootstrap_ssl_2: {ca: new-default-ca, certificate: new-mbus}
+blobstore_ca: {certificate: old-blobstore-ca}
+blobstore_ca_2: {certificate: new-blobstore-ca}
+blobstore_server_tls: {ca: old-blobstore-ca, certificate: old-blobstore}
+blobstore_server_tls_2: {ca: new-blobstore-ca, certificate: new-blobstore}
+ca: {ca: concourse-ca, certificate: concourse-ca}
+internal_tls: {ca: concourse-ca, certificate: internal}
+nats_ca: {ca: nats-ca, certificate: nats-ca}
+`)
+				client := buildClient()
+				err := client.Maintain(maintain.Args{RotateDirectorCerts: true, RotateDirectorCertsIsSet: true})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(actions).To(ContainElement("generating cert ca: concourse-up-happymeal, cn: [99.99.99.99 192.168.0.6]"))
+				Expect(configClient.UpdateArgsForCall(0).DirectorCACert).To(Equal("----EXAMPLE CERT----"))
+				var createEnvs int
+				for _, action := range actions {
+					if action == "creating director env" {
+						createEnvs++
+					}
+				}
+				Expect(createEnvs).To(Equal(2))
+
+				var promoted map[string]interface{}
+				Expect(yaml.Unmarshal(storedCreds[2], &promoted)).To(Succeed())
+				Expect(promoted).To(Equal(map[string]interface{}{
+					"default_ca":           map[interface{}]interface{}{"certificate": "new-default-ca"},
+					"mbus_bootstrap_ssl":   map[interface{}]interface{}{"ca": "new-default-ca", "certificate": "new-mbus"},
+					"blobstore_ca":         map[interface{}]interface{}{"certificate": "new-blobstore-ca"},
+					"blobstore_server_tls": map[interface{}]interface{}{"ca": "new-blobstore-ca", "certificate": "new-blobstore"},
+					"nats_ca":              map[interface{}]interface{}{"ca": "nats-ca", "certificate": "nats-ca"},
+				}))
+				Expect(actions[len(actions)-3:]).To(ContainElement("deploying director"))
+				Expect(string(assets["maintenance.json"])).To(MatchJSON(`{"operation": "rotate-director-certs", "status_index": -1}`))
+			})
+		})
+
+		Context("When upgrading the database", func() {
+			It("Snapshots RDS, upgrades it and checks the version through the director", func() {
+				client := buildClient()
+				err := client.Maintain(maintain.Args{UpgradeDB: true, UpgradeDBIsSet: true})
+				Expect(err).ToNot(HaveOccurred())
+
+				snapshot := terraformCLI.ApplyArgsForCall(0).(*terraform.AWSInputVars)
+				Expect(snapshot.DBUpgradeSnapshot).To(HavePrefix("concourse-up-happymeal-pre-upgrade-"))
+				Expect(snapshot.DBEngineVersion).To(Equal("9.6.11"))
+				upgrade := terraformCLI.ApplyArgsForCall(1).(*terraform.AWSInputVars)
+				Expect(upgrade.DBEngineVersion).To(Equal("11.1"))
+				Expect(upgrade.DBUpgradeSnapshot).To(Equal(snapshot.DBUpgradeSnapshot))
+
+				Expect(configInBucket.DBVersion).To(Equal("11"))
+				Expect(actions).To(ContainElement("checking database version"))
+				Expect(stdout).To(gbytes.Say("The database is on PostgreSQL 11.1"))
+				Expect(string(assets["maintenance.json"])).To(MatchJSON(`{"operation": "upgrade-db", "status_index": -1}`))
+			})
+
+			It("Does nothing when the database is already on the version", func() {
+				configInBucket.DBVersion = "11"
+				client := buildClient()
+				err := client.Maintain(maintain.Args{UpgradeDB: true, UpgradeDBIsSet: true})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(terraformCLI.ApplyCallCount()).To(Equal(0))
+				Expect(stdout).To(gbytes.Say("The database is already on PostgreSQL 11"))
+			})
+
+			It("Refuses to downgrade the database", func() {
+				configInBucket.DBVersion = "11"
+				client := buildClient()
+				err := client.Maintain(maintain.Args{UpgradeDB: true, UpgradeDBIsSet: true, DBVersion: "10", DBVersionIsSet: true})
+				Expect(err).To(MatchError("refusing to downgrade the database from PostgreSQL 11 to 10"))
+			})
+		})
+
+		Context("When upgrading the stemcell", func() {
+			BeforeEach(func() {
+				instancesFor = func(c config.Config) []bosh.Instance {
+					stemcell := "bosh-aws-xen-hvm-ubuntu-xenial-go_agent/"
+					if c.StemcellVersion == "" {
+						stemcell += "170.9"
+					} else {
+						stemcell += c.StemcellVersion
+					}
+					return []bosh.Instance{{Name: "web/0", Stemcell: stemcell}, {Name: "worker/0", Stemcell: stemcell}}
+				}
+			})
+
+			It("Redeploys onto the requested stemcell and reports each instance's stemcell", func() {
+				client := buildClient()
+				err := client.Maintain(maintain.Args{UpgradeStemcell: true, UpgradeStemcellIsSet: true, StemcellVersion: "99999.1", StemcellVersionIsSet: true})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(actions).To(ContainElement("deploying director"))
+				Expect(configClient.UpdateArgsForCall(0).StemcellVersion).To(Equal("99999.1"))
+				Expect(stdout).To(gbytes.Say("web/0: bosh-aws-xen-hvm-ubuntu-xenial-go_agent/170.9 -> bosh-aws-xen-hvm-ubuntu-xenial-go_agent/99999.1"))
+				Expect(stdout).To(gbytes.Say("worker/0: bosh-aws-xen-hvm-ubuntu-xenial-go_agent/170.9 -> bosh-aws-xen-hvm-ubuntu-xenial-go_agent/99999.1"))
+			})
+
+			It("Refuses to downgrade the stemcell", func() {
+				client := buildClient()
+				err := client.Maintain(maintain.Args{UpgradeStemcell: true, UpgradeStemcellIsSet: true, StemcellVersion: "1.0", StemcellVersionIsSet: true})
+				Expect(err).To(MatchError(ContainSubstring("refusing to downgrade the stemcell")))
+				Expect(actions).ToNot(ContainElement("deploying director"))
+			})
+		})
+	})
+
+	Describe("FetchHealth", func() {
+		var server *httptest.Server
+		var workerState string
+
+		BeforeEach(func() {
+			workerState = "running"
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/v1/info", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"version": "5.0.0", "worker_version": "2.1"}`))
+			})
+			mux.HandleFunc("/sky/token", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"access_token": "token"}`))
+			})
+			mux.HandleFunc("/api/v1/workers", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `[{"name": "worker-1", "state": %q, "active_containers": 3, "platform": "linux"}]`, workerState)
+			})
+			mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"status": "UP"}`))
+			})
+			server = httptest.NewTLSServer(mux)
+
+			u, err := url.Parse(server.URL)
+			Expect(err).ToNot(HaveOccurred())
+			caCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+			configInBucket.Domain = u.Host
+			configInBucket.ConcourseCACert = caCert
+			configInBucket.CredhubURL = server.URL
+			configInBucket.CredhubCACert = caCert
+			instancesFor = func(config.Config) []bosh.Instance {
+				return []bosh.Instance{{Name: "web/0", State: "running", Vitals: &bosh.Vitals{CPUUser: "1.2%"}}}
+			}
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("Reports Concourse, its workers and VMs as healthy", func() {
+			client := buildClient()
+			health, err := client.FetchHealth()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(health.Healthy).To(BeTrue())
+			Expect(health.Concourse.Reachable).To(BeTrue())
+			Expect(health.Concourse.Version).To(Equal("5.0.0"))
+			Expect(health.Workers).To(HaveLen(1))
+			Expect(health.Workers[0].ActiveContainers).To(Equal(3))
+			Expect(health.DB.Reachable).To(BeTrue())
+			Expect(health.CredHub.Reachable).To(BeTrue())
+			Expect(health.Instances[0].Vitals.CPUUser).To(Equal("1.2%"))
+			Expect(actions).To(ContainElement("listing bosh vitals"))
+		})
+
+		It("Is unhealthy when a worker isn't running", func() {
+			workerState = "stalled"
+			client := buildClient()
+			health, err := client.FetchHealth()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(health.Healthy).To(BeFalse())
+		})
+
+		It("Does not check CredHub when another credential manager is used", func() {
+			configInBucket.CredentialManager = "vault"
+			configInBucket.CredhubURL = "https://credhub.invalid:8844"
+			client := buildClient()
+			health, err := client.FetchHealth()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(health.CredHub).To(Equal(concourse.ServiceHealth{NotUsed: true}))
+			Expect(health.Healthy).To(BeTrue())
+		})
+
+		It("Is unhealthy when Concourse can't be reached", func() {
+			server.Close()
+			client := buildClient()
+			health, err := client.FetchHealth()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(health.Healthy).To(BeFalse())
+			Expect(health.Concourse.Error).ToNot(BeEmpty())
+			Expect(health.DB.Reachable).To(BeFalse())
+			Expect(health.CredHub.Reachable).To(BeFalse())
+		})
+	})
+
+	Describe("FetchCost", func() {
+		BeforeEach(func() {
+			natGatewayPriceErr = nil
+			configInBucket.AvailabilityZone = "eu-west-1a"
+			configInBucket.ConcourseWebSize = "small"
+			configInBucket.ConcourseWorkerCount = 2
+			configInBucket.ConcourseWorkerSize = "xlarge"
+			configInBucket.WorkerType = "m5"
+			configInBucket.Spot = true
+			configInBucket.DBStorage = 10
+		})
+
+		It("Prices each component for a month", func() {
+			client := buildClient()
+			cost, err := client.FetchCost()
+			Expect(err).ToNot(HaveOccurred())
+
+			monthly := map[string]float64{}
+			for _, c := range cost.Components {
+				Expect(c.Error).To(BeEmpty())
+				monthly[c.Name] = c.Monthly
+			}
+			Expect(monthly["web"]).To(BeNumerically("~", 0.025*730+20*0.1, 0.001))
+			Expect(monthly["workers"]).To(BeNumerically("~", 2*(0.08*730+200*0.1), 0.001))
+			Expect(monthly["director"]).To(BeNumerically("~", 0.025*730, 0.001))
+			Expect(monthly["database"]).To(BeNumerically("~", 0.1*730+10*0.2, 0.001))
+			Expect(monthly["NAT"]).To(BeNumerically("~", 0.05*730, 0.001))
+			Expect(monthly["public IPs"]).To(BeNumerically("~", 3*0.005*730, 0.001))
+			Expect(cost.Total).To(BeNumerically("~", 317.75, 0.001))
+			Expect(cost.String()).To(ContainSubstring("2 x m5.xlarge spot"))
+		})
+
+		It("Leaves components that can't be priced out of the total", func() {
+			natGatewayPriceErr = errors.New("User is not authorized to perform: pricing:GetProducts")
+			client := buildClient()
+			cost, err := client.FetchCost()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cost.Total).To(BeNumerically("~", 317.75-0.05*730, 0.001))
+			Expect(cost.String()).To(ContainSubstring("unknown: User is not authorized"))
+		})
+
+		It("Prices the storage databases created before it could be set were given", func() {
+			configInBucket.DBStorage = 0
+			client := buildClient()
+			cost, err := client.FetchCost()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cost.Total).To(BeNumerically("~", 317.75, 0.001))
+			Expect(cost.String()).To(ContainSubstring("db.t2.medium, 10 GB"))
+		})
+
+		It("Prices Windows workers on demand", func() {
+			configInBucket.WindowsWorkerCount = 3
+			configInBucket.WindowsWorkerInstanceType = "m5.xlarge"
+			client := buildClient()
+			cost, err := client.FetchCost()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cost.Total).To(BeNumerically("~", 317.75+3*(0.2*730+200*0.1), 0.001))
+			Expect(cost.String()).To(ContainSubstring("3 x m5.xlarge excluding licences"))
+		})
+	})
+
+	Describe("FetchDrift", func() {
+		BeforeEach(func() {
+			cloudConfigDrift = nil
+		})
+
+		It("Finds nothing when terraform and the cloud config have no changes", func() {
+			client := buildClient()
+			drift, err := client.FetchDrift()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drift.Found()).To(BeFalse())
+			Expect(terraformCLI.PlanCallCount()).To(Equal(1))
+			Expect(boshClient.CleanupCallCount()).To(Equal(1))
+		})
+
+		It("Reports infrastructure and cloud config drift", func() {
+			terraformCLI.PlanReturns(true, nil)
+			cloudConfigDrift = []string{"vm_types/compilation: missing from the director"}
+			client := buildClient()
+			drift, err := client.FetchDrift()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drift.Found()).To(BeTrue())
+			Expect(drift.Infrastructure).To(BeTrue())
+			Expect(drift.String()).To(ContainSubstring("  vm_types/compilation: missing from the director"))
+		})
+
+		It("Returns terraform errors", func() {
+			terraformCLI.PlanReturns(false, errors.New("terraform exploded"))
+			client := buildClient()
+			_, err := client.FetchDrift()
+			Expect(err).To(MatchError("terraform exploded"))
+			Expect(terraformCLI.BuildOutputCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("Export", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			terraformCLI.ConfigReturns("variable \"rds_instance_password\" {\n\tdefault = \"s3cret\"\n}\n", nil)
+			assets := map[string][]byte{
+				bosh.StateFilename: directorStateFixture,
+				bosh.CredsFilename: directorCredsFixture,
+			}
+			configClient.HasAssetStub = func(filename string) (bool, error) {
+				_, ok := assets[filename]
+				return ok, nil
+			}
+			configClient.LoadAssetStub = func(filename string) ([]byte, error) {
+				return assets[filename], nil
+			}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("Writes the terraform config and has bosh export the director and Concourse", func() {
+			client := buildClient()
+			Expect(client.Export(dir, false)).To(Succeed())
+
+			tfConfig, err := ioutil.ReadFile(filepath.Join(dir, concourse.TerraformConfigFilename))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(tfConfig)).To(ContainSubstring(`default = "s3cret"`))
+
+			Expect(boshClient.ExportCallCount()).To(Equal(1))
+			exportDir, state, creds, redact := boshClient.ExportArgsForCall(0)
+			Expect(exportDir).To(Equal(dir))
+			Expect(state).To(Equal(directorStateFixture))
+			Expect(creds).To(Equal(directorCredsFixture))
+			Expect(redact).To(BeFalse())
+			Expect(boshClient.CleanupCallCount()).To(Equal(1))
+		})
+
+		It("Redacts secrets when asked to", func() {
+			client := buildClient()
+			Expect(client.Export(dir, true)).To(Succeed())
+
+			tfConfig, err := ioutil.ReadFile(filepath.Join(dir, concourse.TerraformConfigFilename))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(tfConfig)).To(ContainSubstring(`default = "REDACTED"`))
+
+			_, _, _, redact := boshClient.ExportArgsForCall(0)
+			Expect(redact).To(BeTrue())
+		})
+	})
+
+	Describe("Import", func() {
+		const tfState = `{"version":3,"modules":[{"path":["root"],"resources":{"aws_vpc.default":{}}}]}`
+		var storedAssets map[string][]byte
+		var bblCreds []byte
+
+		BeforeEach(func() {
+			bblCreds = append([]byte("admin_password: bbl-admin-password\n"), directorCredsFixture...)
+			storedAssets = map[string][]byte{}
+			configClient.ConfigExistsStub = nil
+			configClient.ConfigExistsReturns(false, nil)
+			configClient.NewConfigReturns(config.Config{
+				ConfigBucket: "concourse-up-happymeal-eu-west-1-config",
+				Deployment:   "concourse-up-happymeal",
+				Project:      "happymeal",
+				Region:       "eu-west-1",
+				TFStatePath:  "terraform.tfstate",
+			})
+			configClient.StoreAssetStub = func(filename string, contents []byte) error {
+				storedAssets[filename] = contents
+				return nil
+			}
+		})
+
+		It("Stores the director and terraform state with a new config using the director's password", func() {
+			client := buildClient()
+			err := client.Import(directorStateFixture, bblCreds, []byte(tfState), "concourse")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(storedAssets).To(Equal(map[string][]byte{
+				"terraform.tfstate": []byte(tfState),
+				bosh.StateFilename:  directorStateFixture,
+				bosh.CredsFilename:  bblCreds,
+			}))
+			Expect(configClient.UpdateCallCount()).To(Equal(1))
+			imported := configClient.UpdateArgsForCall(0)
+			Expect(imported.Project).To(Equal("happymeal"))
+			Expect(imported.DirectorPassword).To(Equal("bbl-admin-password"))
+			Expect(imported.DirectorCACert).To(BeEmpty())
+			Expect(imported.RDSPassword).ToNot(BeEmpty())
+			Expect(imported.ConcourseDeployment).To(Equal("concourse"))
+			Expect(imported.ImportedDirector).To(BeTrue())
+		})
+
+		It("Keeps the name of the Concourse deployment", func() {
+			client := buildClient()
+			err := client.Import(directorStateFixture, bblCreds, []byte(tfState), "ci")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(configClient.UpdateArgsForCall(0).ConcourseDeployment).To(Equal("ci"))
+		})
+
+		It("Moves resources bosh-bootloader created to concourse-up's addresses", func() {
+			client := buildClient()
+			err := client.Import(directorStateFixture, bblCreds, []byte(`{"version":3,"serial":2,"modules":[{"path":["root"],"resources":{"aws_vpc.vpc":{},"aws_eip.bosh_eip":{}}}]}`), "concourse")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(storedAssets["terraform.tfstate"])).To(ContainSubstring(`"aws_vpc.default"`))
+			Expect(string(storedAssets["terraform.tfstate"])).To(ContainSubstring(`"aws_eip.director"`))
+			Expect(string(storedAssets["terraform.tfstate"])).ToNot(ContainSubstring(`"aws_vpc.vpc"`))
+		})
+
+		It("Refuses to import over a deployment concourse-up already manages", func() {
+			configClient.ConfigExistsReturns(true, nil)
+			client := buildClient()
+			err := client.Import(directorStateFixture, bblCreds, []byte(tfState), "concourse")
+			Expect(err).To(MatchError("this deployment is already managed by concourse-up"))
+			Expect(configClient.StoreAssetCallCount()).To(Equal(0))
+		})
+
+		It("Refuses terraform state with resources deploy would destroy", func() {
+			client := buildClient()
+			err := client.Import(directorStateFixture, bblCreds, []byte(`{"version":3,"modules":[{"path":["root"],"resources":{"aws_vpc.default":{},"aws_instance.nat":{}}}]}`), "concourse")
+			Expect(err).To(MatchError(ContainSubstring("terraform state mv")))
+			Expect(err).To(MatchError(ContainSubstring("\n  aws_instance.nat")))
+			Expect(configClient.StoreAssetCallCount()).To(Equal(0))
+			Expect(configClient.UpdateCallCount()).To(Equal(0))
+		})
+
+		It("Refuses director creds without an admin password", func() {
+			client := buildClient()
+			err := client.Import(directorStateFixture, []byte("director_ssl: {}\n"), []byte(tfState), "concourse")
+			Expect(err).To(MatchError(ContainSubstring("admin_password")))
+			Expect(configClient.UpdateCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("Migrate", func() {
+		var targetProvider *iaasfakes.FakeProvider
+		var targetConfigClient *configfakes.FakeIClient
+		var targetTerraformCLI *terraformfakes.FakeCLIInterface
+		var targetConfig config.Config
+		var sourceAssets, targetAssets, archive map[string][]byte
+
+		storeAssetsIn := func(client *configfakes.FakeIClient, assets map[string][]byte) {
+			client.HasAssetStub = func(filename string) (bool, error) {
+				_, ok := assets[filename]
+				return ok, nil
+			}
+			client.LoadAssetStub = func(filename string) ([]byte, error) {
+				return assets[filename], nil
+			}
+			client.StoreAssetStub = func(filename string, contents []byte) error {
+				assets[filename] = contents
+				return nil
+			}
+		}
+
+		// steps lists the terraform runs of both deployments, and whether they set the DNS record
+		steps := func() []string {
+			var steps []string
+			for _, action := range actions {
+				switch {
+				case strings.Contains(action, "terraform with"), action == "destroying terraform", action == "copying snapshot", action == "deleting config":
+					steps = append(steps, action)
+				}
+			}
+			return steps
+		}
+
+		BeforeEach(func() {
+			configInBucket.AllowIPs = `"0.0.0.0/0"`
+			configInBucket.ConfigBucket = "concourse-up-happymeal-eu-west-1-config"
+			configInBucket.Domain = "ci.google.com"
+			configInBucket.HostedZoneID = "ABC123"
+			configInBucket.HostedZoneRecordPrefix = "ci"
+			configClient.UpdateStub = func(conf config.Config) error {
+				configInBucket = conf
+				return nil
+			}
+			sourceAssets = map[string][]byte{bosh.StateFilename: directorStateFixture, bosh.CredsFilename: directorCredsFixture}
+			storeAssetsIn(configClient, sourceAssets)
+			terraformCLI.ApplyStub = func(inputVars terraform.InputVars) error {
+				actions = append(actions, fmt.Sprintf("applying terraform with hosted zone %q", inputVars.(*terraform.AWSInputVars).HostedZoneID))
+				return nil
+			}
+
+			archive = map[string][]byte{}
+			targetProvider = setupFakeAwsProvider()
+			targetProvider.RegionReturns("eu-central-1")
+			targetProvider.ZoneReturns("eu-central-1a")
+			targetProvider.WriteFileStub = func(bucket, path string, contents []byte) error {
+				archive[bucket+"/"+path] = contents
+				return nil
+			}
+			targetProvider.LoadFileStub = func(bucket, path string) ([]byte, error) {
+				return archive[bucket+"/"+path], nil
+			}
+			targetProvider.CopyDBSnapshotStub = func(sourceRegion, snapshot string) error {
+				actions = append(actions, "copying snapshot")
+				return nil
+			}
+
+			targetConfig = config.Config{}
+			targetAssets = map[string][]byte{}
+			targetConfigClient = &configfakes.FakeIClient{}
+			targetConfigClient.NewConfigReturns(config.Config{
+				ConfigBucket: "concourse-up-happymeal-eu-central-1-config",
+				Deployment:   "concourse-up-happymeal-lunch",
+				Project:      "happymeal",
+				Region:       "eu-central-1",
+				TFStatePath:  "terraform.tfstate",
+			})
+			targetConfigClient.ConfigExistsStub = func() (bool, error) {
+				return targetConfig.ConfigBucket != "", nil
+			}
+			targetConfigClient.LoadStub = func() (config.Config, error) {
+				return targetConfig, nil
+			}
+			targetConfigClient.UpdateStub = func(conf config.Config) error {
+				targetConfig = conf
+				return nil
+			}
+			storeAssetsIn(targetConfigClient, targetAssets)
+
+			targetTerraformCLI = &terraformfakes.FakeCLIInterface{}
+			targetTerraformCLI.BuildOutputReturns(&terraform.AWSOutputs{
+				ATCPublicIP:      terraform.MetadataStringValue{Value: "66.66.66.66"},
+				DirectorPublicIP: terraform.MetadataStringValue{Value: "55.55.55.55"},
+				VPCID:            terraform.MetadataStringValue{Value: "vpc-445566"},
+			}, nil)
+			targetTerraformCLI.ApplyStub = func(inputVars terraform.InputVars) error {
+				actions = append(actions, fmt.Sprintf("applying target terraform with hosted zone %q", inputVars.(*terraform.AWSInputVars).HostedZoneID))
+				return nil
+			}
+		})
+
+		migrate := func() error {
+			source := newClient(awsClient, terraformCLI, configClient, args)
+			targetArgs := *args
+			target := newClient(targetProvider, targetTerraformCLI, targetConfigClient, &targetArgs)
+			return source.Migrate(target)
+		}
+
+		It("Moves the DNS record to the new deployment before destroying this one", func() {
+			Expect(migrate()).To(Succeed())
+
+			Expect(steps()).To(Equal([]string{
+				`applying terraform with hosted zone "ABC123"`,
+				"copying snapshot",
+				`applying target terraform with hosted zone ""`,
+				`applying terraform with hosted zone ""`,
+				`applying target terraform with hosted zone "ABC123"`,
+				`applying terraform with hosted zone ""`,
+				"destroying terraform",
+				"deleting config",
+			}))
+			Expect(targetConfig.Domain).To(Equal("ci.google.com"))
+			Expect(targetConfig.RestoredFromSnapshot).To(HavePrefix("concourse-up-happymeal-migrate-"))
+		})
+
+		It("Resumes from the stage after the last one to complete", func() {
+			sourceAssets["maintenance.json"] = []byte(`{"operation":"migrate","status_index":2}`)
+			targetConfig = targetConfigClient.NewConfig()
+
+			Expect(migrate()).To(Succeed())
+
+			Expect(steps()).To(Equal([]string{
+				`applying terraform with hosted zone ""`,
+				`applying target terraform with hosted zone "ABC123"`,
+				`applying terraform with hosted zone ""`,
+				"destroying terraform",
+				"deleting config",
+			}))
+		})
+
+		It("Only destroys this deployment once the new one has been restored from its snapshot", func() {
+			configInBucket.DBUpgradeSnapshot = "concourse-up-happymeal-migrate-20191019120000"
+			targetConfig = targetConfigClient.NewConfig()
+			targetConfig.RestoredFromSnapshot = configInBucket.DBUpgradeSnapshot
+
+			Expect(migrate()).To(Succeed())
+
+			Expect(steps()).To(Equal([]string{
+				`applying terraform with hosted zone "ABC123"`,
+				"destroying terraform",
+				"deleting config",
+			}))
+		})
+	})
+
 	Describe("FetchInfo", func() {
 		BeforeEach(func() {
 			configClient.HasAssetReturnsOnCall(0, true, nil)
@@ -413,6 +1339,55 @@ sWbB3FCIsym1FXB+eRnVF3Y15RwBWWKA5RfwUNpEXFxtv24tQ8jrdA==
 			Expect(actions).To(ContainElement("listing bosh instances"))
 		})
 
+		It("Describes every certificate in the config and director-creds.yml", func() {
+			configInBucket.DirectorCACert = natsCAFixture(directorCredsFixture)
+			client := buildClient()
+			info, err := client.FetchInfo()
+			Expect(err).ToNot(HaveOccurred())
+
+			var names []string
+			for _, c := range info.Certificates {
+				names = append(names, c.Source+" "+c.Name)
+				Expect(c.NotAfter).ToNot(BeZero())
+			}
+			Expect(names).To(ContainElement("config.json director_ca_cert"))
+			Expect(names).To(ContainElement("director-creds.yml nats_server_tls/certificate"))
+			Expect(names).To(ContainElement("director-creds.yml nats_server_tls/ca"))
+			Expect(names).To(ContainElement("director-creds.yml default_ca/certificate"))
+			Expect(info.CertExpiry).To(MatchRegexp(`^\w{3} [ \d]\d \d{2}:\d{2}:\d{2} \d{4} GMT\n$`))
+		})
+
+		It("Reports a certificate that can't be parsed without failing", func() {
+			configInBucket.DirectorCACert = "-----BEGIN CERTIFICATE-----\nbm90IGEgY2VydGlmaWNhdGU=\n-----END CERTIFICATE-----\n"
+			client := buildClient()
+			certificates, err := client.FetchCertificates()
+			Expect(err).ToNot(HaveOccurred())
+
+			var unreadable []string
+			for _, c := range certificates {
+				if c.Error != "" {
+					unreadable = append(unreadable, c.Source+" "+c.Name)
+				}
+			}
+			Expect(unreadable).To(Equal([]string{"config.json director_ca_cert"}))
+			Expect(concourse.NATSCertExpiry(certificates)).ToNot(BeEmpty())
+			Expect(concourse.CertificatesTable(certificates)).To(HavePrefix("SOURCE"))
+			Expect(concourse.CertificatesTable(certificates)).To(ContainSubstring("nats_server_tls/ca"))
+			Expect(concourse.CertificatesTable(certificates)).To(MatchRegexp(`config.json +director_ca_cert +\S`))
+		})
+
+		It("Reports whether each access list entry is in the director firewall", func() {
+			configInBucket.AccessCIDRs = []string{"203.0.113.0/24", "1.2.3.4/32"}
+			client := buildClient()
+			info, err := client.FetchInfo()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(info.Access).To(Equal([]concourse.AccessEntry{
+				{CIDR: "203.0.113.0/24", Whitelisted: true},
+				{CIDR: "1.2.3.4/32", Whitelisted: false},
+			}))
+		})
+
 		Context("When the IP address isn't properly whitelisted", func() {
 			BeforeEach(func() {
 				ipChecker = func() (string, error) {
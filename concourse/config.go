@@ -2,10 +2,12 @@ package concourse
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"net"
 	"strings"
 
+	"github.com/EngineerBetter/concourse-up/bosh"
 	"github.com/EngineerBetter/concourse-up/commands/deploy"
 	"github.com/EngineerBetter/concourse-up/config"
 	"github.com/EngineerBetter/concourse-up/iaas"
@@ -20,7 +22,33 @@ func (client *Client) getInitialConfig() (config.Config, bool, error) {
 
 	var isDomainUpdated bool
 	var conf config.Config
-	if priorConfigExists {
+	if priorConfigExists && client.deployArgs.RestoreFromSnapshotIsSet {
+		return config.Config{}, false, fmt.Errorf("cannot restore from snapshot %s as the deployment already exists", client.deployArgs.RestoreFromSnapshot)
+	}
+
+	if client.deployArgs.RestoreFromSnapshotIsSet {
+		conf, err = client.restoreConfig(client.deployArgs.RestoreFromSnapshot)
+		if err != nil {
+			return config.Config{}, false, err
+		}
+
+		conf, _, err = populateConfigWithDefaultsOrProvidedArguments(conf, false, client.deployArgs, client.provider)
+		if err != nil {
+			return config.Config{}, false, fmt.Errorf("error merging new options with restored config: [%v]", err)
+		}
+
+		err = validateInstanceTypes(conf, client.deployArgs, client.provider)
+		if err != nil {
+			return config.Config{}, false, err
+		}
+
+		err = client.configClient.Update(conf)
+		if err != nil {
+			return config.Config{}, false, fmt.Errorf("error persisting restored config [%v]", err)
+		}
+
+		isDomainUpdated = true
+	} else if priorConfigExists {
 		if client.deployArgs.NetworkCIDRIsSet || client.deployArgs.PrivateCIDRIsSet || client.deployArgs.PublicCIDRIsSet {
 			return config.Config{}, false, fmt.Errorf("custom CIDRs cannot be applied after intial deploy")
 		}
@@ -38,6 +66,11 @@ func (client *Client) getInitialConfig() (config.Config, bool, error) {
 			return config.Config{}, false, fmt.Errorf("error merging new options with existing config: [%v]", err)
 		}
 
+		err = validateInstanceTypes(conf, client.deployArgs, client.provider)
+		if err != nil {
+			return config.Config{}, false, err
+		}
+
 	} else {
 		conf, err = newConfig(client.configClient, client.deployArgs, client.provider, client.passwordGenerator, client.eightRandomLetters, client.sshGenerator)
 		if err != nil {
@@ -76,16 +109,25 @@ func newConfig(configClient config.IClient, deployArgs *deploy.Args, provider ia
 	}
 
 	// Why do we do this here?
-	provider.WorkerType(conf.ConcourseWorkerSize)
+	if conf.WorkerInstanceType != "" {
+		provider.WorkerType(conf.WorkerInstanceType)
+	} else {
+		provider.WorkerType(conf.ConcourseWorkerSize)
+	}
 	conf.AvailabilityZone = provider.Zone(deployArgs.Zone)
 	// End stuff from concourse.Deploy()
 
+	err = validateInstanceTypes(conf, deployArgs, provider)
+	if err != nil {
+		return config.Config{}, err
+	}
+
 	return conf, nil
 }
 
-func populateConfigWithDefaults(conf config.Config, provider iaas.Provider, passwordGenerator func(int) string, sshGenerator func() ([]byte, []byte, string, error)) (config.Config, error) {
-	const defaultPasswordLength = 20
+const defaultPasswordLength = 20
 
+func populateConfigWithDefaults(conf config.Config, provider iaas.Provider, passwordGenerator func(int) string, sshGenerator func() ([]byte, []byte, string, error)) (config.Config, error) {
 	privateKey, publicKey, _, err := sshGenerator()
 	if err != nil {
 		return config.Config{}, fmt.Errorf("error generating SSH keypair for new config: [%v]", err)
@@ -142,13 +184,36 @@ func populateConfigWithDefaultsOrProvidedArguments(conf config.Config, newConfig
 	}
 	if newConfigCreated || deployArgs.WorkerSizeIsSet {
 		conf.ConcourseWorkerSize = deployArgs.WorkerSize
+		conf.WorkerInstanceType = ""
+	}
+	if deployArgs.WorkerInstanceTypeIsSet {
+		conf.WorkerInstanceType = deployArgs.WorkerInstanceType
 	}
 	if newConfigCreated || deployArgs.WebSizeIsSet {
 		conf.ConcourseWebSize = deployArgs.WebSize
+		conf.WebInstanceType = ""
+	}
+	if deployArgs.WebInstanceTypeIsSet {
+		conf.WebInstanceType = deployArgs.WebInstanceType
 	}
 	if newConfigCreated || deployArgs.DBSizeIsSet {
 		conf.RDSInstanceClass = provider.DBType(deployArgs.DBSize)
 	}
+	if deployArgs.DBInstanceTypeIsSet {
+		conf.RDSInstanceClass = deployArgs.DBInstanceType
+	}
+	if deployArgs.DBStorageIsSet {
+		if !newConfigCreated && deployArgs.DBStorage != dbStorage(conf) {
+			return config.Config{}, false, fmt.Errorf("Existing deployment has %dGB of database storage and cannot change to %dGB", dbStorage(conf), deployArgs.DBStorage)
+		}
+		conf.DBStorage = deployArgs.DBStorage
+	}
+	if deployArgs.DBMaxStorageIsSet {
+		if deployArgs.DBMaxStorage != 0 && deployArgs.DBMaxStorage < dbStorage(conf) {
+			return config.Config{}, false, fmt.Errorf("Maximum database storage of %dGB is less than the %dGB the database has", deployArgs.DBMaxStorage, dbStorage(conf))
+		}
+		conf.DBMaxStorage = deployArgs.DBMaxStorage
+	}
 	if newConfigCreated || deployArgs.GithubAuthIsSet {
 		conf.GithubClientID = deployArgs.GithubAuthClientID
 		conf.GithubClientSecret = deployArgs.GithubAuthClientSecret
@@ -163,6 +228,69 @@ func populateConfigWithDefaultsOrProvidedArguments(conf config.Config, newConfig
 	if newConfigCreated || deployArgs.WorkerTypeIsSet {
 		conf.WorkerType = deployArgs.WorkerType
 	}
+	if newConfigCreated || deployArgs.WorkerDiskSizeIsSet {
+		conf.WorkerDiskSize = deployArgs.WorkerDiskSize
+	}
+	if newConfigCreated || deployArgs.WorkerDiskTypeIsSet {
+		conf.WorkerDiskType = deployArgs.WorkerDiskType
+	}
+	if newConfigCreated || deployArgs.WorkerDiskIOPSIsSet || deployArgs.WorkerDiskTypeIsSet {
+		conf.WorkerDiskIOPS = deployArgs.WorkerDiskIOPS
+	}
+	if newConfigCreated || deployArgs.WorkerPersistentDiskIsSet {
+		conf.WorkerPersistentDiskSize = deployArgs.WorkerPersistentDisk
+	}
+	conf, err = populateConfigWithWorkerPools(conf, newConfigCreated, deployArgs, provider)
+	if err != nil {
+		return config.Config{}, false, err
+	}
+	if newConfigCreated || deployArgs.CredentialManagerIsSet {
+		conf.CredentialManager = deployArgs.CredentialManager
+	}
+	conf = populateConfigWithVaultArgs(conf, newConfigCreated, deployArgs)
+	if newConfigCreated || deployArgs.WorkerIAMPoliciesIsSet {
+		conf.WorkerIAMPolicies = deployArgs.WorkerIAMPolicies
+	}
+	if newConfigCreated || deployArgs.SelfUpdateRoleARNIsSet {
+		conf.SelfUpdateRoleARN = deployArgs.SelfUpdateRoleARN
+		conf.SelfUpdateExternalID = deployArgs.SelfUpdateExternalID
+	}
+	if newConfigCreated || deployArgs.WorkerServiceAccountRolesIsSet {
+		conf.WorkerServiceAccountRoles = deployArgs.WorkerServiceAccountRoles
+	}
+	if newConfigCreated || deployArgs.ConcourseVersionIsSet {
+		conf.ConcourseVersion = deployArgs.ConcourseVersion
+	}
+	if newConfigCreated || deployArgs.SelfUpdateChannelIsSet {
+		conf.SelfUpdateChannel = deployArgs.SelfUpdateChannel
+	}
+	if newConfigCreated || deployArgs.SelfUpdateVersionRangeIsSet {
+		conf.SelfUpdateVersionRange = deployArgs.SelfUpdateVersionRange
+	}
+	if newConfigCreated || deployArgs.SelfUpdateApprovalIsSet {
+		conf.SelfUpdateApproval = deployArgs.SelfUpdateApproval
+	}
+	if newConfigCreated || deployArgs.MaintenanceWindowIsSet {
+		conf.MaintenanceWindow = deployArgs.MaintenanceWindow
+	}
+	if newConfigCreated || deployArgs.NotifySlackWebhookIsSet {
+		conf.NotifySlackWebhook = deployArgs.NotifySlackWebhook
+	}
+	if newConfigCreated || deployArgs.NotifyWebhookIsSet {
+		conf.NotifyWebhook = deployArgs.NotifyWebhook
+	}
+	if newConfigCreated || deployArgs.NotifyEmailIsSet {
+		conf.NotifyEmail = deployArgs.NotifyEmail
+	}
+	if newConfigCreated || deployArgs.NotifySMTPServerIsSet {
+		conf.NotifySMTPServer = deployArgs.NotifySMTPServer
+	}
+	if newConfigCreated || deployArgs.NotifySMTPUsernameIsSet {
+		conf.NotifySMTPUsername = deployArgs.NotifySMTPUsername
+	}
+	if newConfigCreated || deployArgs.NotifySMTPPasswordIsSet {
+		conf.NotifySMTPPassword = deployArgs.NotifySMTPPassword
+	}
 
 	if newConfigCreated {
 		if hasCIDRFlagsSet(deployArgs, provider) {
@@ -190,6 +318,117 @@ func populateConfigWithDefaultsOrProvidedArguments(conf config.Config, newConfig
 	return conf, isDomainUpdated, nil
 }
 
+// The default instance types of the Windows and arm64 workers, comparable to an xlarge worker
+var (
+	defaultWindowsWorkerInstanceTypes = map[iaas.Name]string{
+		iaas.AWS: "m5.xlarge",
+		iaas.GCP: "n1-standard-4",
+	}
+	defaultARMWorkerInstanceTypes = map[iaas.Name]string{
+		iaas.AWS: "m6g.xlarge",
+		iaas.GCP: "t2a-standard-4",
+	}
+)
+
+func populateConfigWithWorkerPools(conf config.Config, newConfigCreated bool, deployArgs *deploy.Args, provider iaas.Provider) (config.Config, error) {
+	if newConfigCreated || deployArgs.WindowsWorkerCountIsSet {
+		conf.WindowsWorkerCount = deployArgs.WindowsWorkerCount
+	}
+	if deployArgs.WindowsWorkerInstanceTypeIsSet {
+		conf.WindowsWorkerInstanceType = deployArgs.WindowsWorkerInstanceType
+	}
+	if conf.WindowsWorkerCount > 0 && conf.WindowsWorkerInstanceType == "" {
+		conf.WindowsWorkerInstanceType = defaultWindowsWorkerInstanceTypes[provider.IAAS()]
+	}
+
+	if newConfigCreated || deployArgs.ARMWorkerCountIsSet {
+		conf.ARMWorkerCount = deployArgs.ARMWorkerCount
+	}
+	if deployArgs.ARMWorkerInstanceTypeIsSet {
+		conf.ARMWorkerInstanceType = deployArgs.ARMWorkerInstanceType
+	}
+	if deployArgs.ARMStemcellURLIsSet {
+		conf.ARMStemcellURL = deployArgs.ARMStemcellURL
+	}
+	if deployArgs.ARMConcourseReleaseURLIsSet {
+		conf.ARMConcourseReleaseURL = deployArgs.ARMConcourseReleaseURL
+	}
+	if deployArgs.ARMGardenRunCReleaseURLIsSet {
+		conf.ARMGardenRunCReleaseURL = deployArgs.ARMGardenRunCReleaseURL
+	}
+	if conf.ARMWorkerCount > 0 {
+		if conf.ARMWorkerInstanceType == "" {
+			conf.ARMWorkerInstanceType = defaultARMWorkerInstanceTypes[provider.IAAS()]
+		}
+		if conf.ARMStemcellURL == "" {
+			return config.Config{}, errors.New("--arm-workers requires --arm-stemcell-url, as bosh.io doesn't publish arm64 stemcells")
+		}
+		if _, err := bosh.StemcellOS(conf.ARMStemcellURL); err != nil {
+			return config.Config{}, err
+		}
+		// BOSH compiles packages on the amd64 compilation VMs, so the arm64 workers need releases
+		// that were compiled against the arm64 stemcell beforehand
+		if conf.ARMConcourseReleaseURL == "" || conf.ARMGardenRunCReleaseURL == "" {
+			return config.Config{}, errors.New("--arm-workers requires --arm-concourse-release-url and --arm-garden-runc-release-url, as BOSH can't compile arm64 packages")
+		}
+	}
+	return conf, nil
+}
+
+// validateInstanceTypes checks the provider offers the raw instance types passed to deploy.
+// This needs the zone, so can only happen once the config has one
+func validateInstanceTypes(conf config.Config, deployArgs *deploy.Args, provider iaas.Provider) error {
+	if deployArgs.WorkerInstanceTypeIsSet {
+		if err := provider.ValidateInstanceType(deployArgs.WorkerInstanceType, conf.AvailabilityZone); err != nil {
+			return err
+		}
+	}
+	if deployArgs.WebInstanceTypeIsSet {
+		if err := provider.ValidateInstanceType(deployArgs.WebInstanceType, conf.AvailabilityZone); err != nil {
+			return err
+		}
+	}
+	if deployArgs.WindowsWorkerInstanceTypeIsSet {
+		if err := provider.ValidateInstanceType(deployArgs.WindowsWorkerInstanceType, conf.AvailabilityZone); err != nil {
+			return err
+		}
+	}
+	if deployArgs.ARMWorkerInstanceTypeIsSet {
+		if err := provider.ValidateInstanceType(deployArgs.ARMWorkerInstanceType, conf.AvailabilityZone); err != nil {
+			return err
+		}
+	}
+	if deployArgs.DBInstanceTypeIsSet {
+		return provider.ValidateDBInstanceType(deployArgs.DBInstanceType, dbEngineVersions[provider.IAAS()][dbVersion(conf)])
+	}
+	return nil
+}
+
+func populateConfigWithVaultArgs(conf config.Config, newConfigCreated bool, deployArgs *deploy.Args) config.Config {
+	if conf.CredentialManager != "vault" {
+		conf.VaultURL = ""
+		conf.VaultCACert = ""
+		conf.VaultToken = ""
+		conf.VaultPathPrefix = ""
+		return conf
+	}
+	// Switching an existing deployment to vault is treated like a new one so that defaults are picked up
+	fresh := newConfigCreated || deployArgs.CredentialManagerIsSet
+	if fresh || deployArgs.VaultURLIsSet {
+		conf.VaultURL = deployArgs.VaultURL
+	}
+	if fresh || deployArgs.VaultCACertIsSet {
+		conf.VaultCACert = deployArgs.VaultCACert
+	}
+	if fresh || deployArgs.VaultTokenIsSet {
+		conf.VaultToken = deployArgs.VaultToken
+	}
+	if fresh || deployArgs.VaultPathPrefixIsSet {
+		conf.VaultPathPrefix = deployArgs.VaultPathPrefix
+	}
+	return conf
+}
+
 func hasCIDRFlagsSet(deployArgs *deploy.Args, provider iaas.Provider) bool {
 	switch provider.IAAS() {
 	case iaas.AWS:
@@ -289,3 +528,8 @@ func (b cidrBlocks) String() (string, error) {
 	}
 	return buf.String(), nil
 }
+
+// usesCredhub reports whether Concourse is wired to the CredHub colocated on the web VM
+func usesCredhub(c config.Config) bool {
+	return c.CredentialManager == "" || c.CredentialManager == "credhub"
+}
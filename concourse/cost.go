@@ -0,0 +1,203 @@
+package concourse
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
+// hoursPerMonth is the average number of hours in a month, as used by both clouds' calculators
+const hoursPerMonth = 730
+
+// publicIPHourlyPrice is what AWS and GCP charge for a public IPv4 address. Neither publishes
+// it in a form that can be looked up
+const publicIPHourlyPrice = 0.005
+
+// webRootDiskSize is the GB of disk each web VM is given by the cloud config
+const webRootDiskSize = 20
+
+// Cost is an estimate of what a deployment costs each month in USD, ignoring data transfer,
+// storage buckets and the director's disks
+type Cost struct {
+	Components []ComponentCost `json:"components"`
+	Total      float64         `json:"total"`
+}
+
+// ComponentCost is the monthly cost of one part of a deployment or, if it couldn't be
+// priced, why
+type ComponentCost struct {
+	Name    string  `json:"name"`
+	Detail  string  `json:"detail"`
+	Monthly float64 `json:"monthly"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// FetchCost prices every VM, disk, database and network resource the deployment uses with
+// the provider's current prices
+func (client *Client) FetchCost() (*Cost, error) {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return nil, err
+	}
+	p := client.provider
+	name := p.IAAS()
+
+	var cost Cost
+	webType := bosh.WebInstanceType(conf, name)
+	cost.add(ComponentCost{Name: "web", Detail: webType}, func() (float64, error) {
+		return vmMonthlyPrice(p, webType, conf.AvailabilityZone, false, webRootDiskSize, defaultDiskType(name))
+	})
+
+	workerType := bosh.WorkerInstanceType(conf, name)
+	workerDetail := fmt.Sprintf("%d x %s", conf.ConcourseWorkerCount, workerType)
+	if conf.Spot {
+		workerDetail += " spot"
+	}
+	cost.add(ComponentCost{Name: "workers", Detail: workerDetail}, func() (float64, error) {
+		monthly, err := vmMonthlyPrice(p, workerType, conf.AvailabilityZone, conf.Spot, workerDiskSize(conf), workerDiskType(conf, name))
+		if err != nil {
+			return 0, err
+		}
+		if conf.WorkerPersistentDiskSize > 0 {
+			diskPrice, err := p.DiskPrice(defaultDiskType(name))
+			if err != nil {
+				return 0, err
+			}
+			monthly += float64(conf.WorkerPersistentDiskSize) * diskPrice
+		}
+		return monthly * float64(conf.ConcourseWorkerCount), nil
+	})
+
+	// Windows workers are priced as Linux ones, as neither price list makes licensing easy to
+	// look up
+	pools := []struct {
+		name, detail, instanceType string
+		count                      int
+	}{
+		{"Windows workers", " excluding licences", bosh.WindowsWorkerInstanceType(conf), conf.WindowsWorkerCount},
+		{"arm64 workers", "", bosh.ARMWorkerInstanceType(conf), conf.ARMWorkerCount},
+	}
+	for _, pool := range pools {
+		if pool.count == 0 {
+			continue
+		}
+		cost.add(ComponentCost{Name: pool.name, Detail: fmt.Sprintf("%d x %s%s", pool.count, pool.instanceType, pool.detail)}, func() (float64, error) {
+			monthly, err := vmMonthlyPrice(p, pool.instanceType, conf.AvailabilityZone, false, workerDiskSize(conf), workerDiskType(conf, name))
+			return monthly * float64(pool.count), err
+		})
+	}
+
+	directorType := bosh.DirectorInstanceType(name)
+	cost.add(ComponentCost{Name: "director", Detail: directorType}, func() (float64, error) {
+		price, err := p.InstancePrice(directorType, conf.AvailabilityZone, false)
+		return price * hoursPerMonth, err
+	})
+
+	storage := dbStorage(conf)
+	cost.add(ComponentCost{Name: "database", Detail: fmt.Sprintf("%s, %d GB", conf.RDSInstanceClass, storage)}, func() (float64, error) {
+		price, err := p.DBInstancePrice(conf.RDSInstanceClass)
+		if err != nil {
+			return 0, err
+		}
+		storagePrice, err := p.DBStoragePrice()
+		if err != nil {
+			return 0, err
+		}
+		return price*hoursPerMonth + float64(storage)*storagePrice, nil
+	})
+
+	if name == iaas.GCP {
+		cost.add(ComponentCost{Name: "NAT", Detail: "n1-standard-1 instance"}, func() (float64, error) {
+			price, err := p.InstancePrice("n1-standard-1", conf.AvailabilityZone, false)
+			return price * hoursPerMonth, err
+		})
+	} else {
+		cost.add(ComponentCost{Name: "NAT", Detail: "NAT gateway, excluding data processed"}, func() (float64, error) {
+			price, err := p.NATGatewayPrice()
+			return price * hoursPerMonth, err
+		})
+	}
+
+	// The director, ATC and NAT each have a public IP: an elastic IP for the NAT gateway on AWS
+	// and an external IP for the NAT instance on GCP
+	publicIPs := 3
+	cost.add(ComponentCost{Name: "public IPs", Detail: fmt.Sprintf("%d addresses", publicIPs)}, func() (float64, error) {
+		return float64(publicIPs) * publicIPHourlyPrice * hoursPerMonth, nil
+	})
+
+	return &cost, nil
+}
+
+// add prices a component, recording rather than returning any error so that the rest can
+// still be estimated
+func (c *Cost) add(component ComponentCost, price func() (float64, error)) {
+	monthly, err := price()
+	if err != nil {
+		component.Error = err.Error()
+	} else {
+		component.Monthly = monthly
+		c.Total += monthly
+	}
+	c.Components = append(c.Components, component)
+}
+
+func vmMonthlyPrice(p iaas.Provider, instanceType, zone string, spot bool, diskSize int, diskType string) (float64, error) {
+	price, err := p.InstancePrice(instanceType, zone, spot)
+	if err != nil {
+		return 0, err
+	}
+	diskPrice, err := p.DiskPrice(diskType)
+	if err != nil {
+		return 0, err
+	}
+	return price*hoursPerMonth + float64(diskSize)*diskPrice, nil
+}
+
+func defaultDiskType(name iaas.Name) string {
+	if name == iaas.GCP {
+		return "pd-ssd"
+	}
+	return "gp2"
+}
+
+// workerDiskSize defaults the worker disk size for deployments from before it could be set
+func workerDiskSize(conf config.Config) int {
+	if conf.WorkerDiskSize == 0 {
+		return 200
+	}
+	return conf.WorkerDiskSize
+}
+
+func workerDiskType(conf config.Config, name iaas.Name) string {
+	if conf.WorkerDiskType != "" {
+		return conf.WorkerDiskType
+	}
+	return defaultDiskType(name)
+}
+
+// String lists the cost of each component in a table
+func (c *Cost) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "COMPONENT\tDETAIL\tMONTHLY (USD)")
+	incomplete := false
+	for _, component := range c.Components {
+		monthly := fmt.Sprintf("%.2f", component.Monthly)
+		if component.Error != "" {
+			monthly = "unknown: " + component.Error
+			incomplete = true
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", component.Name, component.Detail, monthly)
+	}
+	fmt.Fprintf(w, "total\t\t%.2f\n", c.Total)
+	w.Flush()
+	if incomplete {
+		buf.WriteString("\nThe total leaves out components that couldn't be priced\n")
+	}
+	buf.WriteString("\nEstimates exclude data transfer, storage buckets and the director's disks\n")
+	return buf.String()
+}
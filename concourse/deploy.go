@@ -37,6 +37,22 @@ type BoshParams struct {
 	DirectorCACert           string
 }
 
+// applyTo copies the values produced by a BOSH deploy into c
+func (bp BoshParams) applyTo(c config.Config) config.Config {
+	c.CredhubPassword = bp.CredhubPassword
+	c.CredhubAdminClientSecret = bp.CredhubAdminClientSecret
+	c.CredhubCACert = bp.CredhubCACert
+	c.CredhubURL = bp.CredhubURL
+	c.CredhubUsername = bp.CredhubUsername
+	c.ConcourseUsername = bp.ConcourseUsername
+	c.ConcoursePassword = bp.ConcoursePassword
+	c.GrafanaPassword = bp.GrafanaPassword
+	c.DirectorUsername = bp.DirectorUsername
+	c.DirectorPassword = bp.DirectorPassword
+	c.DirectorCACert = bp.DirectorCACert
+	return c
+}
+
 func stripVersion(tags []string) []string {
 	output := []string{}
 	for _, tag := range tags {
@@ -54,6 +70,15 @@ func (client *Client) Deploy() error {
 		return fmt.Errorf("error getting initial config before deploy: [%v]", err)
 	}
 
+	err = client.deploy(conf, isDomainUpdated)
+	// Self-updates are reported by the pipeline, which also sees failures before deploy starts
+	if client.deployArgs.SelfUpdate {
+		return err
+	}
+	return client.notify(conf, "deploy", err)
+}
+
+func (client *Client) deploy(conf config.Config, isDomainUpdated bool) error {
 	r, err := client.checkPreTerraformConfigRequirements(conf, client.deployArgs.SelfUpdate)
 	if err != nil {
 		return err
@@ -107,17 +132,7 @@ func (client *Client) Deploy() error {
 		bp, err = client.deployBoshAndPipeline(conf, tfOutputs)
 	}
 
-	conf.CredhubPassword = bp.CredhubPassword
-	conf.CredhubAdminClientSecret = bp.CredhubAdminClientSecret
-	conf.CredhubCACert = bp.CredhubCACert
-	conf.CredhubURL = bp.CredhubURL
-	conf.CredhubUsername = bp.CredhubUsername
-	conf.ConcourseUsername = bp.ConcourseUsername
-	conf.ConcoursePassword = bp.ConcoursePassword
-	conf.GrafanaPassword = bp.GrafanaPassword
-	conf.DirectorUsername = bp.DirectorUsername
-	conf.DirectorPassword = bp.DirectorPassword
-	conf.DirectorCACert = bp.DirectorCACert
+	conf = bp.applyTo(conf)
 
 	err1 := client.configClient.Update(conf)
 	if err == nil {
@@ -149,6 +164,10 @@ func (client *Client) deployBoshAndPipeline(c config.Config, tfOutputs terraform
 		return bp, err
 	}
 
+	flyVersionFile, err := client.flyVersionFile(c)
+	if err != nil {
+		return bp, err
+	}
 	flyClient, err := client.flyClientFactory(client.provider, fly.Credentials{
 		Target:   c.Deployment,
 		API:      fmt.Sprintf("https://%s", c.Domain),
@@ -157,7 +176,7 @@ func (client *Client) deployBoshAndPipeline(c config.Config, tfOutputs terraform
 	},
 		client.stdout,
 		client.stderr,
-		client.versionFile,
+		flyVersionFile,
 	)
 	if err != nil {
 		return bp, err
@@ -195,6 +214,10 @@ func (client *Client) updateBoshAndPipeline(c config.Config, tfOutputs terraform
 		DirectorCACert:           c.DirectorCACert,
 	}
 
+	flyVersionFile, err := client.flyVersionFile(c)
+	if err != nil {
+		return bp, err
+	}
 	flyClient, err := client.flyClientFactory(client.provider, fly.Credentials{
 		Target:   c.Deployment,
 		API:      fmt.Sprintf("https://%s", c.Domain),
@@ -203,7 +226,7 @@ func (client *Client) updateBoshAndPipeline(c config.Config, tfOutputs terraform
 	},
 		client.stdout,
 		client.stderr,
-		client.versionFile,
+		flyVersionFile,
 	)
 	if err != nil {
 		return bp, err
@@ -492,11 +515,19 @@ func (client *Client) deployBosh(config config.Config, tfOutputs terraform.Outpu
 		return bp, err
 	}
 
-	bp.CredhubPassword = cc.CredhubPassword
-	bp.CredhubAdminClientSecret = cc.CredhubAdminClientSecret
-	bp.CredhubCACert = cc.InternalTLS.CA
-	bp.CredhubURL = fmt.Sprintf("https://%s:8844/", config.Domain)
-	bp.CredhubUsername = "credhub-cli"
+	if usesCredhub(config) {
+		bp.CredhubPassword = cc.CredhubPassword
+		bp.CredhubAdminClientSecret = cc.CredhubAdminClientSecret
+		bp.CredhubCACert = cc.InternalTLS.CA
+		bp.CredhubURL = fmt.Sprintf("https://%s:8844/", config.Domain)
+		bp.CredhubUsername = "credhub-cli"
+	} else {
+		bp.CredhubPassword = ""
+		bp.CredhubAdminClientSecret = ""
+		bp.CredhubCACert = ""
+		bp.CredhubURL = ""
+		bp.CredhubUsername = ""
+	}
 	bp.ConcourseUsername = "admin"
 	if len(cc.AtcPassword) > 0 {
 		bp.ConcoursePassword = cc.AtcPassword
@@ -4,17 +4,31 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/EngineerBetter/concourse-up/commands/destroy"
+	"github.com/EngineerBetter/concourse-up/config"
 	"github.com/EngineerBetter/concourse-up/iaas"
 )
 
 // Destroy destroys a concourse instance
-func (client *Client) Destroy() error {
+func (client *Client) Destroy(args destroy.Args) error {
 
 	conf, err := client.configClient.Load()
 	if err != nil {
 		return err
 	}
 
+	return client.notify(conf, "destroy", client.destroy(conf, args))
+}
+
+func (client *Client) destroy(conf config.Config, args destroy.Args) error {
+	if !args.SkipFinalSnapshot {
+		var err error
+		conf, err = client.takeFinalSnapshot(conf)
+		if err != nil {
+			return err
+		}
+	}
+
 	tfInputVars := client.tfInputVarsFactory.NewInputVars(conf)
 
 	var volumesToDelete []string
@@ -47,7 +61,7 @@ func (client *Client) Destroy() error {
 		}
 	}
 
-	err = client.tfCLI.Destroy(tfInputVars)
+	err := client.tfCLI.Destroy(tfInputVars)
 	if err != nil {
 		return err
 	}
@@ -61,7 +75,7 @@ func (client *Client) Destroy() error {
 		}
 	}
 
-	if err = client.configClient.DeleteAll(conf); err != nil {
+	if err := client.configClient.DeleteAll(conf); err != nil {
 		return err
 	}
 
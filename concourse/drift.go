@@ -0,0 +1,67 @@
+package concourse
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Drift is how a deployment has been changed outside of concourse-up since it was deployed
+type Drift struct {
+	Infrastructure bool     `json:"infrastructure"`
+	CloudConfig    []string `json:"cloud_config"`
+}
+
+// Found returns true when anything has drifted
+func (d *Drift) Found() bool {
+	return d.Infrastructure || len(d.CloudConfig) > 0
+}
+
+// FetchDrift runs terraform plan with the stored config, which prints any changes, and
+// compares the director's cloud config with the one deploy would upload
+func (client *Client) FetchDrift() (*Drift, error) {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return nil, err
+	}
+	tfInputVars := client.tfInputVarsFactory.NewInputVars(conf)
+
+	var drift Drift
+	drift.Infrastructure, err = client.tfCLI.Plan(tfInputVars)
+	if err != nil {
+		return nil, err
+	}
+
+	tfOutputs, err := client.tfCLI.BuildOutput(tfInputVars)
+	if err != nil {
+		return nil, err
+	}
+	boshClient, err := client.buildBoshClient(conf, tfOutputs)
+	if err != nil {
+		return nil, err
+	}
+	defer boshClient.Cleanup()
+	drift.CloudConfig, err = boshClient.CloudConfigDrift()
+	if err != nil {
+		return nil, err
+	}
+	return &drift, nil
+}
+
+// String summarises the drift, the infrastructure changes having been printed by terraform
+func (d *Drift) String() string {
+	var buf bytes.Buffer
+	if d.Infrastructure {
+		buf.WriteString("Infrastructure has drifted, see the terraform plan above\n")
+	} else {
+		buf.WriteString("Infrastructure matches the config\n")
+	}
+	if len(d.CloudConfig) == 0 {
+		buf.WriteString("Cloud config matches the config\n")
+		return buf.String()
+	}
+	buf.WriteString("Cloud config has drifted:\n")
+	for _, line := range d.CloudConfig {
+		fmt.Fprintf(&buf, "  %s\n", line)
+	}
+	return buf.String()
+}
@@ -0,0 +1,59 @@
+package concourse
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/EngineerBetter/concourse-up/terraform"
+)
+
+// TerraformConfigFilename is where Export writes the terraform config, under its own directory
+// so terraform can be run there
+const TerraformConfigFilename = "terraform/main.tf"
+
+// Export writes the terraform config, including its backend, and everything bosh deploys the
+// director and Concourse with to dir, so the deployment can be operated with plain terraform
+// and bosh. Secrets are replaced with REDACTED when redact is set
+func (client *Client) Export(dir string, redact bool) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	tfInputVars := client.tfInputVarsFactory.NewInputVars(conf)
+
+	tfConfig, err := client.tfCLI.Config(tfInputVars)
+	if err != nil {
+		return err
+	}
+	if redact {
+		tfConfig = terraform.RedactConfig(tfConfig)
+	}
+	tfConfigPath := filepath.Join(dir, TerraformConfigFilename)
+	if err = os.MkdirAll(filepath.Dir(tfConfigPath), 0700); err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(tfConfigPath, []byte(tfConfig), 0600); err != nil {
+		return err
+	}
+
+	tfOutputs, err := client.tfCLI.BuildOutput(tfInputVars)
+	if err != nil {
+		return err
+	}
+	boshClient, err := client.buildBoshClient(conf, tfOutputs)
+	if err != nil {
+		return err
+	}
+	defer boshClient.Cleanup()
+
+	state, err := loadDirectorState(client.configClient)
+	if err != nil {
+		return err
+	}
+	creds, err := loadDirectorCreds(client.configClient)
+	if err != nil {
+		return err
+	}
+	return boshClient.Export(dir, state, creds, redact)
+}
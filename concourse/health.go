@@ -0,0 +1,121 @@
+package concourse
+
+import (
+	"fmt"
+
+	"github.com/EngineerBetter/concourse-up/atc"
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/credhub"
+)
+
+// Health reports whether Concourse, its workers and the services it depends on are working
+type Health struct {
+	Healthy   bool            `json:"healthy"`
+	Concourse ConcourseHealth `json:"concourse"`
+	Workers   []atc.Worker    `json:"workers"`
+	DB        ServiceHealth   `json:"db"`
+	CredHub   ServiceHealth   `json:"credhub"`
+	BOSH      ServiceHealth   `json:"bosh"`
+	Instances []bosh.Instance `json:"instances"`
+}
+
+// ServiceHealth is whether a service could be reached and, if not, why. Services the deployment
+// doesn't use are marked as such rather than checked
+type ServiceHealth struct {
+	Reachable bool   `json:"reachable"`
+	NotUsed   bool   `json:"not_used,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ConcourseHealth is whether the Concourse API could be reached and the versions it reports
+type ConcourseHealth struct {
+	ServiceHealth
+	atc.Info
+}
+
+func serviceHealth(err error) ServiceHealth {
+	if err != nil {
+		return ServiceHealth{Error: err.Error()}
+	}
+	return ServiceHealth{Reachable: true}
+}
+
+// FetchHealth checks the Concourse API, CredHub and the VMs BOSH deployed. Nothing is
+// retried, so a deployment that is still starting up will be reported as unhealthy
+func (client *Client) FetchHealth() (*Health, error) {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return nil, err
+	}
+	tfOutputs, err := client.tfCLI.BuildOutput(client.tfInputVarsFactory.NewInputVars(conf))
+	if err != nil {
+		return nil, err
+	}
+
+	var health Health
+	health.Concourse, health.Workers, health.DB = concourseHealth(conf)
+	health.CredHub = credhubHealth(conf)
+
+	boshClient, err := client.buildBoshClient(conf, tfOutputs)
+	if err != nil {
+		return nil, err
+	}
+	defer boshClient.Cleanup()
+	health.Instances, err = boshClient.Vitals()
+	health.BOSH = serviceHealth(err)
+
+	health.Healthy = health.evaluate()
+	return &health, nil
+}
+
+// concourseHealth asks the ATC for its version and workers. The ATC has no endpoint to report
+// on its database, but it reads workers from it, so listing them shows the database is up
+func concourseHealth(conf config.Config) (ConcourseHealth, []atc.Worker, ServiceHealth) {
+	api, err := atc.New(fmt.Sprintf("https://%s", conf.Domain), conf.ConcourseCACert, conf.ConcourseUsername, conf.ConcoursePassword)
+	if err != nil {
+		return ConcourseHealth{ServiceHealth: serviceHealth(err)}, nil, ServiceHealth{Error: "not checked, Concourse API is unreachable"}
+	}
+	info, err := api.Info()
+	if err != nil {
+		return ConcourseHealth{ServiceHealth: serviceHealth(err)}, nil, ServiceHealth{Error: "not checked, Concourse API is unreachable"}
+	}
+	workers, err := api.Workers()
+	return ConcourseHealth{ServiceHealth: serviceHealth(nil), Info: info}, workers, serviceHealth(err)
+}
+
+// credhubHealth checks CredHub, unless Concourse uses another credential manager and CredHub was never deployed
+func credhubHealth(conf config.Config) ServiceHealth {
+	if !usesCredhub(conf) {
+		return ServiceHealth{NotUsed: true}
+	}
+	client, err := credhub.New(conf.CredhubURL, conf.CredhubCACert, credhub.AdminClient, conf.CredhubAdminClientSecret)
+	if err != nil {
+		return serviceHealth(err)
+	}
+	return serviceHealth(client.Health())
+}
+
+// evaluate returns true when every service in use is reachable, there is at least one worker
+// and all workers and VMs are running
+func (h *Health) evaluate() bool {
+	for _, s := range []ServiceHealth{h.Concourse.ServiceHealth, h.DB, h.CredHub, h.BOSH} {
+		if !s.Reachable && !s.NotUsed {
+			return false
+		}
+	}
+	if len(h.Workers) == 0 {
+		return false
+	}
+	for _, w := range h.Workers {
+		if w.State != "running" {
+			return false
+		}
+	}
+	for _, i := range h.Instances {
+		if i.State != "running" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+package concourse
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Import adopts a director deployed with bosh create-env, along with the terraform state of
+// the infrastructure it runs on and the Concourse deployment on the director, storing them
+// with a new config so the next deploy manages them. The director keeps its own database and
+// blobstore, so it still knows the deployment. Resources bosh-bootloader created are moved
+// to the addresses concourse-up uses; the terraform state may hold no others, as deploy would
+// destroy them
+func (client *Client) Import(state, creds, tfState []byte, deployment string) error {
+	exists, err := client.configClient.ConfigExists()
+	if err != nil {
+		return fmt.Errorf("error determining if config already exists [%v]", err)
+	}
+	if exists {
+		return errors.New("this deployment is already managed by concourse-up")
+	}
+
+	tfState, err = terraform.RenameBBLResources(client.provider.IAAS(), tfState)
+	if err != nil {
+		return err
+	}
+	unmanaged, err := terraform.UnmanagedResources(client.provider.IAAS(), tfState)
+	if err != nil {
+		return err
+	}
+	if len(unmanaged) > 0 {
+		return fmt.Errorf("the next deploy would destroy these resources, as concourse-up's terraform config doesn't declare them. Rename them to the resources concourse-up uses with `terraform state mv`, or stop tracking them with `terraform state rm`, before importing:\n  %s", strings.Join(unmanaged, "\n  "))
+	}
+
+	var directorCreds struct {
+		AdminPassword string `yaml:"admin_password"`
+	}
+	if err = yaml.Unmarshal(creds, &directorCreds); err != nil {
+		return fmt.Errorf("failed to parse director creds: [%v]", err)
+	}
+	if directorCreds.AdminPassword == "" {
+		return errors.New("director creds must have the admin_password generated by bosh create-env")
+	}
+
+	conf, err := newConfig(client.configClient, client.deployArgs, client.provider, client.passwordGenerator, client.eightRandomLetters, client.sshGenerator)
+	if err != nil {
+		return fmt.Errorf("error generating new config: [%v]", err)
+	}
+	// The director certificate is left for deploy to generate, as the director's IP
+	// changes unless its elastic IP is kept in the terraform state
+	conf.DirectorPassword = directorCreds.AdminPassword
+	conf.ConcourseDeployment = deployment
+	conf.ImportedDirector = true
+
+	for filename, contents := range map[string][]byte{
+		conf.TFStatePath:   tfState,
+		bosh.StateFilename: state,
+		bosh.CredsFilename: creds,
+	} {
+		if err = client.configClient.StoreAsset(filename, contents); err != nil {
+			return fmt.Errorf("failed to store %s: [%v]", filename, err)
+		}
+	}
+	// The config is stored last, as it marks the deployment as managed by concourse-up
+	return client.configClient.Update(conf)
+}
@@ -2,28 +2,32 @@ package concourse
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"github.com/EngineerBetter/concourse-up/iaas"
 	"io/ioutil"
 	"os"
-	"os/exec"
-	"regexp"
+	"sort"
 	"strings"
+	"text/tabwriter"
 	"text/template"
+	"time"
 
 	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/certs"
 	"github.com/EngineerBetter/concourse-up/config"
-	"github.com/EngineerBetter/concourse-up/util/yaml"
 	"github.com/fatih/color"
 )
 
 // Info represents the compound fields for info templates
 type Info struct {
-	Terraform   TerraformInfo   `json:"terraform"`
-	Config      config.Config   `json:"config"`
-	Instances   []bosh.Instance `json:"instances"`
-	CertExpiry  string          `json:"cert_expiry"`
-	GatewayUser string
+	Terraform    TerraformInfo       `json:"terraform"`
+	Config       config.Config       `json:"config"`
+	Instances    []bosh.Instance     `json:"instances"`
+	Access       []AccessEntry       `json:"access"`
+	CertExpiry   string              `json:"cert_expiry"`
+	Certificates []certs.Certificate `json:"certificates"`
+	GatewayUser  string
 }
 
 // TerraformInfo represents the terraform output fields needed for the info templates
@@ -40,35 +44,11 @@ func (client *Client) FetchInfo() (*Info, error) {
 		return nil, err
 	}
 
-	directorCredsBytes, err := loadDirectorCreds(client.configClient)
+	certificates, err := client.fetchCertificates(conf)
 	if err != nil {
 		return nil, err
 	}
 
-	var certExpiry string
-	if len(directorCredsBytes) > 0 {
-		natsCA, err1 := yaml.Path(directorCredsBytes, "nats_server_tls/ca")
-		if err1 != nil {
-			return nil, err1
-		}
-
-		var re = regexp.MustCompile(`\n\s*`)
-
-		openSSL := exec.Command("openssl", "x509", "-noout", "-dates")
-		openSSL.Stdin = strings.NewReader(re.ReplaceAllString(natsCA, "\n"))
-		var out bytes.Buffer
-		openSSL.Stdout = &out
-		err1 = openSSL.Run()
-		if err1 != nil {
-			return nil, err1
-		}
-		if strings.Contains(out.String(), "notAfter=") {
-			certExpiry = strings.Split(out.String(), "notAfter=")[1]
-		} else {
-			return nil, fmt.Errorf("openssl output is not as expected. got: %s", out.String())
-		}
-	}
-
 	tfInputVars := client.tfInputVarsFactory.NewInputVars(conf)
 
 	switch client.provider.IAAS() {
@@ -117,6 +97,15 @@ func (client *Client) FetchInfo() (*Info, error) {
 		return nil, err1
 	}
 
+	var access []AccessEntry
+	for _, cidr := range conf.AccessCIDRs {
+		present, err1 := client.provider.CheckForWhitelistedIP(cidr, directorSecurityGroupID)
+		if err1 != nil {
+			return nil, err1
+		}
+		access = append(access, AccessEntry{CIDR: cidr, Whitelisted: present})
+	}
+
 	boshClient, err := client.buildBoshClient(conf, tfOutputs)
 	if err != nil {
 		return nil, err
@@ -129,14 +118,86 @@ func (client *Client) FetchInfo() (*Info, error) {
 	}
 
 	return &Info{
-		Terraform:   terraformInfo,
-		Config:      conf,
-		Instances:   instances,
-		GatewayUser: gatewayUser,
-		CertExpiry:  certExpiry,
+		Terraform:    terraformInfo,
+		Config:       conf,
+		Instances:    instances,
+		Access:       access,
+		GatewayUser:  gatewayUser,
+		CertExpiry:   NATSCertExpiry(certificates),
+		Certificates: certificates,
 	}, nil
 }
 
+// FetchCertificates describes every certificate concourse-up manages. Unlike FetchInfo it only reads
+// the config bucket, so it also works from places that can't reach the director, such as the workers
+func (client *Client) FetchCertificates() ([]certs.Certificate, error) {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return nil, err
+	}
+	return client.fetchCertificates(conf)
+}
+
+func (client *Client) fetchCertificates(conf config.Config) ([]certs.Certificate, error) {
+	directorCredsBytes, err := loadDirectorCreds(client.configClient)
+	if err != nil {
+		return nil, err
+	}
+	return inspectCertificates(conf, directorCredsBytes)
+}
+
+// NATSCertExpiry returns when the director's NATS CA expires, or nothing if it can't be read
+func NATSCertExpiry(certificates []certs.Certificate) string {
+	for _, c := range certificates {
+		if c.Source == bosh.CredsFilename && c.Name == "nats_server_tls/ca" && c.Error == "" {
+			return c.NotAfter.UTC().Format(opensslDateFormat) + "\n"
+		}
+	}
+	return ""
+}
+
+// CertificatesTable lists certificates in a table, soonest to expire first, with the reason
+// any that can't be read were skipped in place of their details
+func CertificatesTable(certificates []certs.Certificate) string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "SOURCE\tNAME\tSUBJECT\tISSUER\tSANS\tEXPIRES\tDAYS REMAINING")
+	for _, c := range certificates {
+		if c.Error != "" {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", c.Source, c.Name, c.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\n", c.Source, c.Name, c.Subject, c.Issuer, strings.Join(c.SANs, ","), c.NotAfter.UTC().Format(time.RFC3339), c.DaysRemaining)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// opensslDateFormat is how openssl prints certificate dates, which CertExpiry has always used
+const opensslDateFormat = "Jan _2 15:04:05 2006 GMT"
+
+// inspectCertificates describes every certificate in the config and director-creds.yml, soonest to expire first
+func inspectCertificates(conf config.Config, directorCreds []byte) ([]certs.Certificate, error) {
+	now := time.Now()
+	configBytes, err := json.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+	certificates, err := certs.Inspect("config.json", configBytes, now)
+	if err != nil {
+		return nil, err
+	}
+	fromCreds, err := certs.Inspect(bosh.CredsFilename, directorCreds, now)
+	if err != nil {
+		return nil, err
+	}
+	certificates = append(certificates, fromCreds...)
+	sort.SliceStable(certificates, func(i, j int) bool {
+		return certificates[i].NotAfter.Before(certificates[j].NotAfter)
+	})
+	return certificates, nil
+}
+
 const infoTemplate = `Deployment:
 	Namespace: {{.Config.Namespace}}
 	IAAS:      {{.Config.IAAS}}
@@ -144,25 +205,39 @@ const infoTemplate = `Deployment:
 
 Workers:
 	Count:              {{.Config.ConcourseWorkerCount}}
-	Size:               {{.Config.ConcourseWorkerSize}}
+	Size:               {{if .Config.WorkerInstanceType}}{{.Config.WorkerInstanceType}}{{else}}{{.Config.ConcourseWorkerSize}}{{end}}
 	Outbound Public IP: {{.Terraform.NatGatewayIP}}
 
 Instances:
 {{range .Instances}}
-	{{.Name}} {{.IP | replace "\n" ","}} {{.State}}
+	{{.Name}} {{.IP | replace "\n" ","}} {{.State}} {{.Stemcell}}
 {{end}}
 
+Director access:
+	Deployer IP: {{.Config.SourceAccessIP}}
+{{- range .Access}}
+	{{.CIDR}} {{if .Whitelisted}}present{{else}}missing from director firewall{{end}}
+{{- end}}
+
 Concourse credentials:
 	username: {{.Config.ConcourseUsername}}
 	password: {{.Config.ConcoursePassword}}
 	URL:      https://{{.Config.Domain}}
 
+{{if .Config.CredhubURL -}}
 Credhub credentials:
 	username: {{.Config.CredhubUsername}}
 	password: {{.Config.CredhubPassword}}
 	URL:      {{.Config.CredhubURL}}
 	CA Cert:
 		{{ .Config.CredhubCACert | replace "\n" "\n\t\t"}}
+{{- else -}}
+Credential manager: {{.Config.CredentialManager}}
+{{- if .Config.VaultURL}}
+	URL: {{.Config.VaultURL}}
+	path prefix: {{.Config.VaultPathPrefix}}
+{{- end}}
+{{- end}}
 
 Grafana credentials:
 	username: {{.Config.ConcourseUsername}}
@@ -225,10 +300,12 @@ export BOSH_CLIENT={{.Config.DirectorUsername}}
 export BOSH_CLIENT_SECRET={{.Config.DirectorPassword}}
 export BOSH_GW_USER={{.GatewayUser}}
 export BOSH_GW_PRIVATE_KEY={{.Config.PrivateKey | to_file}}
+{{- if .Config.CredhubURL}}
 export CREDHUB_SERVER={{.Config.CredhubURL}}
 export CREDHUB_CA_CERT='{{.Config.CredhubCACert}}'
 export CREDHUB_CLIENT=credhub_admin
 export CREDHUB_SECRET={{.Config.CredhubAdminClientSecret}}
+{{- end}}
 export NAMESPACE={{.Config.Namespace}}
 `))
 
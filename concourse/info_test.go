@@ -13,6 +13,7 @@ func TestInfo_String(t *testing.T) {
 		Terraform   TerraformInfo
 		Config      config.Config
 		Instances   []bosh.Instance
+		Access      []AccessEntry
 		CertExpiry  string
 		GatewayUser string
 	}
@@ -49,6 +50,34 @@ func TestInfo_String(t *testing.T) {
 			},
 			want: "IAAS:      aCloudProvider",
 		},
+		{
+			name:   "access list templating",
+			fields: defaultFields,
+			init: func(f fields) fields {
+				f.Access = []AccessEntry{{CIDR: "203.0.113.0/24", Whitelisted: true}}
+				return f
+			},
+			want: "203.0.113.0/24 present",
+		},
+		{
+			name:   "missing access entry templating",
+			fields: defaultFields,
+			init: func(f fields) fields {
+				f.Access = []AccessEntry{{CIDR: "198.51.100.7/32", Whitelisted: false}}
+				return f
+			},
+			want: "198.51.100.7/32 missing from director firewall",
+		},
+		{
+			name:   "vault credential manager templating",
+			fields: defaultFields,
+			init: func(f fields) fields {
+				f.Config.CredentialManager = "vault"
+				f.Config.VaultURL = "https://vault.example.com:8200"
+				return f
+			},
+			want: "Credential manager: vault\n\tURL: https://vault.example.com:8200",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -57,6 +86,7 @@ func TestInfo_String(t *testing.T) {
 				Terraform:   tt.fields.Terraform,
 				Config:      tt.fields.Config,
 				Instances:   tt.fields.Instances,
+				Access:      tt.fields.Access,
 				CertExpiry:  tt.fields.CertExpiry,
 				GatewayUser: tt.fields.GatewayUser,
 			}
@@ -21,9 +21,18 @@ type tasks struct {
 
 // Maintenance is a struct representing values used by the maintenance command
 type Maintenance struct {
-	StatusIndex int `json:"status_index"`
+	Operation   string `json:"operation,omitempty"`
+	StatusIndex int    `json:"status_index"`
 }
 
+// Maintenance operations that run in resumable stages
+const (
+	renewNatsCertOperation       = "renew-nats-cert"
+	rotateCredentialsOperation   = "rotate-credentials"
+	rotateDirectorCertsOperation = "rotate-director-certs"
+	upgradeDBOperation           = "upgrade-db"
+)
+
 // Tables represents the output of bosh locks
 type Tables struct {
 	Tables []Table `json:"Tables"`
@@ -41,12 +50,53 @@ const maintenanceFilename = "maintenance.json"
 func (client *Client) Maintain(m maintain.Args) error {
 	switch {
 	case m.RenewNatsCertIsSet:
-		return client.renewCert(m)
+		conf, err := client.configClient.Load()
+		if err != nil {
+			return err
+		}
+		return client.notify(conf, "maintain", client.renewCert(m))
+	case m.RotateCredentialsIsSet:
+		conf, err := client.configClient.Load()
+		if err != nil {
+			return err
+		}
+		return client.notify(conf, "maintain", client.rotateCredentials(m))
+	case m.RotateDirectorCertsIsSet:
+		conf, err := client.configClient.Load()
+		if err != nil {
+			return err
+		}
+		return client.notify(conf, "maintain", client.rotateDirectorCerts(m))
+	case m.UpgradeStemcellIsSet:
+		conf, err := client.configClient.Load()
+		if err != nil {
+			return err
+		}
+		return client.notify(conf, "maintain", client.upgradeStemcell(m))
+	case m.UpgradeDBIsSet:
+		conf, err := client.configClient.Load()
+		if err != nil {
+			return err
+		}
+		return client.notify(conf, "maintain", client.upgradeDB(m))
 	}
 	return nil
 }
 
 func (client *Client) renewCert(m maintain.Args) error {
+	return client.runTasks(renewNatsCertOperation, m, []tasks{
+		{"Adding new CA", resource.AddNewCa, client.createEnv},
+		{"Recreating VMs for the first time", "first", client.recreate},
+		{"Removing old CA", resource.RemoveOldCa, client.createEnv},
+		{"Recreating VMs for the second time", "second", client.recreate},
+		{"Cleaning up director-creds.yml", "", client.cleanup},
+	})
+}
+
+// runTasks runs the stages of a maintenance operation in order, starting from the stage
+// after the last one to complete unless a stage is given. Progress is stored after each
+// stage so that a failed operation can be resumed
+func (client *Client) runTasks(operation string, m maintain.Args, tasks []tasks) error {
 
 	_ = client.waitForBOSHLocks(10 * time.Minute)
 
@@ -59,24 +109,20 @@ func (client *Client) renewCert(m maintain.Args) error {
 	if m.StageIsSet {
 		stageIndex = m.Stage
 	} else {
+		if maintenance.StatusIndex != -1 && maintenance.Operation != operation {
+			return fmt.Errorf("%s has not finished, run it again to complete it or pass --stage to start %s anyway", maintenance.Operation, operation)
+		}
 		stageIndex, err = client.determineStage(maintenance)
 		if err != nil {
 			return err
 		}
 	}
 
-	tasks := []tasks{
-		{"Adding new CA", resource.AddNewCa, client.createEnv},
-		{"Recreating VMs for the first time", "first", client.recreate},
-		{"Removing old CA", resource.RemoveOldCa, client.createEnv},
-		{"Recreating VMs for the second time", "second", client.recreate},
-		{"Cleaning up director-creds.yml", "", client.cleanup},
-	}
-
-	if stageIndex >= len(tasks) {
+	if stageIndex < 0 || stageIndex >= len(tasks) {
 		return fmt.Errorf("Invalid stage index")
 	}
 
+	maintenance.Operation = operation
 	for i := stageIndex; i < len(tasks); i++ {
 		fmt.Printf("current action: %s\n", tasks[i].description)
 		err1 := tasks[i].action(tasks[i].description, tasks[i].operation)
@@ -178,6 +224,10 @@ func (client *Client) retrieveStage() (*Maintenance, error) {
 	} else {
 		maintenance = Maintenance{StatusIndex: -1}
 	}
+	if maintenance.Operation == "" && maintenance.StatusIndex != -1 {
+		// Written before there was more than one operation
+		maintenance.Operation = renewNatsCertOperation
+	}
 	return &maintenance, nil
 }
 
@@ -0,0 +1,235 @@
+package concourse
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/commands/destroy"
+	"github.com/EngineerBetter/concourse-up/commands/maintain"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
+const migrateOperation = "migrate"
+
+// migrationSnapshotName names the snapshot of the databases the new deployment is restored from
+func migrationSnapshotName(deployment string) string {
+	return fmt.Sprintf("%s-migrate-%s", deployment, time.Now().UTC().Format("20060102150405"))
+}
+
+// Migrate moves the deployment to the region, namespace or name target deploys to. The databases
+// are carried across by the same snapshots and exports that destroy and deploy --restore-from-snapshot
+// use. The domain is moved once the new deployment is up, and only then is this one destroyed
+func (client *Client) Migrate(target *Client) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	return client.notify(conf, "migrate", client.migrate(conf, target))
+}
+
+func (client *Client) migrate(conf config.Config, target *Client) error {
+	finished, err := client.migrationFinished(conf, target)
+	if err != nil {
+		return err
+	}
+
+	if !finished {
+		target.deployArgs.AllowIPs = strings.Replace(conf.AllowIPs, `"`, "", -1)
+		err = client.runTasks(migrateOperation, maintain.Args{}, []tasks{
+			{"Snapshotting the databases", "", func(string, string) error { return client.snapshotForMigration(target) }},
+			{"Archiving the config for the new deployment", "", func(string, string) error { return client.archiveForMigration(target) }},
+			{"Deploying the new deployment", "", func(string, string) error { return client.deployMigration(target) }},
+			{"Moving the domain to the new deployment", "", func(string, string) error { return client.moveDomain(target) }},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// The stage can't be recorded once the config bucket has gone, so destroying the old
+	// deployment is left until the new one is complete
+	conf, err = client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	return client.destroy(conf, destroy.Args{})
+}
+
+// migrationFinished returns true when a previous migrate got as far as destroying this deployment
+func (client *Client) migrationFinished(conf config.Config, target *Client) (bool, error) {
+	maintenance, err := client.retrieveStage()
+	if err != nil {
+		return false, err
+	}
+	if maintenance.Operation == migrateOperation && maintenance.StatusIndex != -1 {
+		return false, nil
+	}
+	exists, err := target.configClient.ConfigExists()
+	if err != nil || !exists || conf.DBUpgradeSnapshot == "" {
+		return false, err
+	}
+	targetConf, err := target.configClient.Load()
+	if err != nil {
+		return false, err
+	}
+	return targetConf.RestoredFromSnapshot == conf.DBUpgradeSnapshot, nil
+}
+
+// checkMigrationTarget refuses a target that is this deployment, or whose resources would clash
+// with this deployment's while both exist. On GCP the network and service accounts are named after
+// the deployment across the whole project. On AWS the IAM users and blobstore bucket are named
+// after the deployment and its region or namespace
+func checkMigrationTarget(from, to config.Config, iaasName iaas.Name) error {
+	if from.ConfigBucket == to.ConfigBucket {
+		return errors.New("the deployment to migrate to is this deployment, pass a new region, namespace or name")
+	}
+	if from.Deployment != to.Deployment {
+		return nil
+	}
+	switch iaasName {
+	case iaas.AWS: // nolint
+		if from.Region == to.Region || from.Namespace == to.Namespace {
+			return errors.New("a deployment keeping its name on AWS must move to both a new region and a new namespace, or pass a new name")
+		}
+	case iaas.GCP: // nolint
+		return errors.New("a deployment on GCP must be given a new name to migrate, as its network and service accounts are named after it across the whole project")
+	}
+	return nil
+}
+
+// migratedConfig makes the config archived for the new deployment. The director and Concourse
+// get new addresses, so their certificates are generated again, and the domain stays with this
+// deployment until moveDomain hands it over
+func migratedConfig(conf, newConf config.Config, zone string) config.Config {
+	conf = withoutAddresses(conf)
+	conf.ConfigBucket = newConf.ConfigBucket
+	conf.Deployment = newConf.Deployment
+	conf.Namespace = newConf.Namespace
+	conf.Project = newConf.Project
+	conf.Region = newConf.Region
+	conf.AvailabilityZone = zone
+	return conf
+}
+
+// snapshotForMigration snapshots RDS with terraform, the same way as before an upgrade, or
+// exports the Cloud SQL databases to the new deployment's archive bucket
+func (client *Client) snapshotForMigration(target *Client) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	newConf := target.configClient.NewConfig()
+	if err = checkMigrationTarget(conf, newConf, client.provider.IAAS()); err != nil {
+		return err
+	}
+	exists, err := target.configClient.ConfigExists()
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("cannot migrate to %s as a deployment already exists there", newConf.ConfigBucket)
+	}
+
+	conf.DBUpgradeSnapshot = migrationSnapshotName(conf.Deployment)
+	err = client.configClient.Update(conf)
+	if err != nil {
+		return err
+	}
+
+	switch client.provider.IAAS() {
+	case iaas.AWS: // nolint
+		return client.tfCLI.Apply(client.tfInputVarsFactory.NewInputVars(conf))
+	case iaas.GCP: // nolint
+		err = target.ensureArchiveBucket(newConf)
+		if err != nil {
+			return err
+		}
+		return client.provider.ExportDatabases(conf.RDSDefaultDatabaseName, config.ArchiveBucket(newConf), conf.DBUpgradeSnapshot, bosh.ConcourseDatabases)
+	}
+	return fmt.Errorf("IAAS not supported [%s]", client.provider.IAAS())
+}
+
+// archiveForMigration archives the config and director creds for the new deployment, copying
+// the RDS snapshot first when the new deployment is in another region
+func (client *Client) archiveForMigration(target *Client) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	if client.provider.IAAS() == iaas.AWS && target.provider.Region() != client.provider.Region() {
+		err = target.provider.CopyDBSnapshot(client.provider.Region(), conf.DBUpgradeSnapshot)
+		if err != nil {
+			return err
+		}
+	}
+
+	zone := conf.AvailabilityZone
+	if target.provider.Region() != client.provider.Region() {
+		if conf.WorkerInstanceType != "" {
+			target.provider.WorkerType(conf.WorkerInstanceType)
+		} else {
+			target.provider.WorkerType(conf.ConcourseWorkerSize)
+		}
+		zone = target.provider.Zone("")
+	}
+
+	directorCreds, err := loadDirectorCreds(client.configClient)
+	if err != nil {
+		return err
+	}
+	return target.writeArchive(migratedConfig(conf, target.configClient.NewConfig(), zone), conf.DBUpgradeSnapshot, directorCreds)
+}
+
+// deployMigration deploys the new deployment from the archive, or carries on deploying it
+// when a previous attempt got as far as creating its config
+func (client *Client) deployMigration(target *Client) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	exists, err := target.configClient.ConfigExists()
+	if err != nil {
+		return err
+	}
+	target.deployArgs.RestoreFromSnapshot = conf.DBUpgradeSnapshot
+	target.deployArgs.RestoreFromSnapshotIsSet = !exists
+	return target.Deploy()
+}
+
+// moveDomain removes this deployment's DNS record and deploys the new one with the domain,
+// which adds its own record and certificate. Concourse is unreachable by name in between
+func (client *Client) moveDomain(target *Client) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	if conf.Domain == "" {
+		return nil
+	}
+
+	if conf.HostedZoneID != "" {
+		conf.HostedZoneID = ""
+		conf.HostedZoneRecordPrefix = ""
+		err = client.configClient.Update(conf)
+		if err != nil {
+			return err
+		}
+		err = client.tfCLI.Apply(client.tfInputVarsFactory.NewInputVars(conf))
+		if err != nil {
+			return err
+		}
+	}
+
+	target.deployArgs.RestoreFromSnapshotIsSet = false
+	target.deployArgs.Domain = conf.Domain
+	target.deployArgs.DomainIsSet = true
+	if conf.ConcourseUserProvidedCert {
+		target.deployArgs.TLSCert = conf.ConcourseCert
+		target.deployArgs.TLSKey = conf.ConcourseKey
+	}
+	return target.Deploy()
+}
@@ -0,0 +1,146 @@
+package concourse
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
+func TestCheckMigrationTarget(t *testing.T) {
+	from := config.Config{
+		ConfigBucket: "concourse-up-happymeal-eu-west-1-config",
+		Deployment:   "concourse-up-happymeal",
+		Namespace:    "eu-west-1",
+		Region:       "eu-west-1",
+	}
+	tests := []struct {
+		name    string
+		to      config.Config
+		iaas    iaas.Name
+		wantErr bool
+	}{
+		{
+			name:    "same deployment",
+			to:      from,
+			iaas:    iaas.AWS,
+			wantErr: true,
+		},
+		{
+			name: "AWS new region and namespace",
+			to: config.Config{
+				ConfigBucket: "concourse-up-happymeal-us-east-1-config",
+				Deployment:   "concourse-up-happymeal",
+				Namespace:    "us-east-1",
+				Region:       "us-east-1",
+			},
+			iaas: iaas.AWS,
+		},
+		{
+			name: "AWS new region keeping the namespace",
+			to: config.Config{
+				ConfigBucket: "concourse-up-happymeal-eu-west-1-config-us-east-1",
+				Deployment:   "concourse-up-happymeal",
+				Namespace:    "eu-west-1",
+				Region:       "us-east-1",
+			},
+			iaas:    iaas.AWS,
+			wantErr: true,
+		},
+		{
+			name: "AWS new namespace in the same region",
+			to: config.Config{
+				ConfigBucket: "concourse-up-happymeal-team-config",
+				Deployment:   "concourse-up-happymeal",
+				Namespace:    "team",
+				Region:       "eu-west-1",
+			},
+			iaas:    iaas.AWS,
+			wantErr: true,
+		},
+		{
+			name: "AWS new name in the same region",
+			to: config.Config{
+				ConfigBucket: "concourse-up-happiermeal-eu-west-1-config",
+				Deployment:   "concourse-up-happiermeal",
+				Namespace:    "eu-west-1",
+				Region:       "eu-west-1",
+			},
+			iaas: iaas.AWS,
+		},
+		{
+			name: "GCP keeping the name",
+			to: config.Config{
+				ConfigBucket: "concourse-up-happymeal-europe-west2-config",
+				Deployment:   "concourse-up-happymeal",
+				Namespace:    "europe-west2",
+				Region:       "europe-west2",
+			},
+			iaas:    iaas.GCP,
+			wantErr: true,
+		},
+		{
+			name: "GCP new name",
+			to: config.Config{
+				ConfigBucket: "concourse-up-happiermeal-europe-west2-config",
+				Deployment:   "concourse-up-happiermeal",
+				Namespace:    "europe-west2",
+				Region:       "europe-west2",
+			},
+			iaas: iaas.GCP,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkMigrationTarget(from, tt.to, tt.iaas)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkMigrationTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMigratedConfig(t *testing.T) {
+	conf := config.Config{
+		AvailabilityZone:          "eu-west-1a",
+		ConcourseCert:             "concourse-cert",
+		ConcourseKey:              "concourse-key",
+		ConcoursePassword:         "concourse-password",
+		ConcourseUserProvidedCert: true,
+		ConfigBucket:              "concourse-up-happymeal-eu-west-1-config",
+		Deployment:                "concourse-up-happymeal",
+		DirectorCACert:            "director-ca",
+		DirectorPublicIP:          "1.2.3.4",
+		Domain:                    "ci.example.com",
+		HostedZoneID:              "Z1234",
+		HostedZoneRecordPrefix:    "ci",
+		Namespace:                 "eu-west-1",
+		Project:                   "happymeal",
+		RDSPassword:               "rds-password",
+		Region:                    "eu-west-1",
+	}
+	newConf := config.Config{
+		ConfigBucket: "concourse-up-happymeal-us-east-1-config",
+		Deployment:   "concourse-up-happymeal",
+		Namespace:    "us-east-1",
+		Project:      "happymeal",
+		Region:       "us-east-1",
+	}
+
+	got := migratedConfig(conf, newConf, "us-east-1b")
+
+	want := config.Config{
+		AvailabilityZone:  "us-east-1b",
+		ConcoursePassword: "concourse-password",
+		ConfigBucket:      "concourse-up-happymeal-us-east-1-config",
+		Deployment:        "concourse-up-happymeal",
+		Namespace:         "us-east-1",
+		Project:           "happymeal",
+		RDSPassword:       "rds-password",
+		Region:            "us-east-1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("migratedConfig() = %+v, want %+v", got, want)
+	}
+}
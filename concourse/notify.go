@@ -0,0 +1,39 @@
+package concourse
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/notify"
+)
+
+// notify tells c's notification targets how action went and returns err unchanged.
+// Failing to notify is only a warning, it shouldn't mask the outcome of the action itself
+func (client *Client) notify(c config.Config, action string, err error) error {
+	targets := notify.Targets{
+		SlackWebhook: c.NotifySlackWebhook,
+		Webhook:      c.NotifyWebhook,
+		Email:        c.NotifyEmail,
+		SMTPServer:   c.NotifySMTPServer,
+		SMTPUsername: c.NotifySMTPUsername,
+		SMTPPassword: c.NotifySMTPPassword,
+	}
+	if !targets.IsSet() {
+		return err
+	}
+
+	event := notify.Event{
+		Deployment: strings.TrimPrefix(c.Deployment, "concourse-up-"),
+		Action:     action,
+		Outcome:    notify.Succeeded,
+	}
+	if err != nil {
+		event.Outcome = notify.Failed
+		event.Error = err.Error()
+	}
+	if notifyErr := notify.Send(targets, event); notifyErr != nil {
+		fmt.Fprintf(client.stderr, "\nWARNING: %v\n\n", notifyErr)
+	}
+	return err
+}
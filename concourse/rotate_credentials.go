@@ -0,0 +1,105 @@
+package concourse
+
+import (
+	"strings"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/commands/maintain"
+	"github.com/EngineerBetter/concourse-up/resource"
+	"github.com/EngineerBetter/concourse-up/util/yaml"
+)
+
+// rotateCredentials replaces the passwords, encryption key and SSH keypair generated by
+// concourse-up. The ATC keeps the old encryption key until it has re-encrypted its data
+func (client *Client) rotateCredentials(m maintain.Args) error {
+	return client.runTasks(rotateCredentialsOperation, m, []tasks{
+		{"Generating new credentials", "", client.generateCredentials},
+		{"Applying the new database password and SSH key", "", client.applyInfrastructure},
+		{"Updating the director with new credentials", "", client.createEnv},
+		{"Deploying Concourse with new credentials", "", client.redeploy},
+		{"Recreating VMs", "", client.recreate},
+		{"Removing the old encryption key", "", client.removeOldEncryptionKey},
+	})
+}
+
+// generateCredentials stores new credentials in the config and removes the generated
+// ones from director-creds.yml so that BOSH generates them again
+func (client *Client) generateCredentials(description, operation string) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	directorCredsBytes, err := loadDirectorCreds(client.configClient)
+	if err != nil {
+		return err
+	}
+	rotatedCreds, err := yaml.Interpolate(string(directorCredsBytes), resource.RotateCredentials, nil)
+	if err != nil {
+		return err
+	}
+	privateKey, publicKey, _, err := client.sshGenerator()
+	if err != nil {
+		return err
+	}
+
+	// Keep the key the ATC data is encrypted with if this stage is run again
+	if conf.OldEncryptionKey == "" {
+		conf.OldEncryptionKey = conf.EncryptionKey
+	}
+	conf.EncryptionKey = client.passwordGenerator(32)
+	conf.DirectorPassword = client.passwordGenerator(defaultPasswordLength)
+	conf.RDSPassword = client.passwordGenerator(defaultPasswordLength)
+	conf.PrivateKey = strings.TrimSpace(string(privateKey))
+	conf.PublicKey = strings.TrimSpace(string(publicKey))
+	conf.ConcoursePassword = ""
+
+	err = client.configClient.StoreAsset("director-creds-backup.yml", directorCredsBytes)
+	if err != nil {
+		return err
+	}
+	err = client.configClient.StoreAsset(bosh.CredsFilename, []byte(rotatedCreds))
+	if err != nil {
+		return err
+	}
+	return client.configClient.Update(conf)
+}
+
+// applyInfrastructure runs terraform to change the database password and SSH key
+func (client *Client) applyInfrastructure(description, operation string) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	return client.tfCLI.Apply(client.tfInputVarsFactory.NewInputVars(conf))
+}
+
+// redeploy deploys Concourse with the current config and stores the credentials it generates
+func (client *Client) redeploy(description, operation string) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	tfOutputs, err := client.tfCLI.BuildOutput(client.tfInputVarsFactory.NewInputVars(conf))
+	if err != nil {
+		return err
+	}
+	bp, err := client.deployBosh(conf, tfOutputs, false)
+	if err != nil {
+		return err
+	}
+	return client.configClient.Update(bp.applyTo(conf))
+}
+
+// removeOldEncryptionKey redeploys Concourse without the old encryption key
+func (client *Client) removeOldEncryptionKey(description, operation string) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	conf.OldEncryptionKey = ""
+	err = client.configClient.Update(conf)
+	if err != nil {
+		return err
+	}
+	return client.redeploy(description, operation)
+}
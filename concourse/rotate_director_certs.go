@@ -0,0 +1,95 @@
+package concourse
+
+import (
+	"fmt"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/commands/maintain"
+	"github.com/EngineerBetter/concourse-up/resource"
+	"gopkg.in/yaml.v2"
+)
+
+// directorCAs are the director-creds.yml certificates that are replaced by the _2 versions
+// generated while rotating
+var directorCAs = []string{"default_ca", "mbus_bootstrap_ssl", "blobstore_ca", "blobstore_server_tls"}
+
+// concourseCA is the director-creds.yml CA that signs Concourse's internal certificates
+const concourseCA = "ca"
+
+// rotateDirectorCerts replaces the director TLS certificate, the CAs used by the director's
+// agent and blobstore, and the Concourse internal CA. Agents are recreated while they trust
+// both the old and new CAs so that none of them lose contact with the director
+func (client *Client) rotateDirectorCerts(m maintain.Args) error {
+	return client.runTasks(rotateDirectorCertsOperation, m, []tasks{
+		{"Generating a new director certificate", "", client.generateDirectorCert},
+		{"Adding new director CAs", resource.AddNewDirectorCa, client.createEnv},
+		{"Recreating VMs for the first time", "first", client.recreate},
+		{"Removing old director CAs", resource.RemoveOldDirectorCa, client.createEnv},
+		{"Recreating VMs for the second time", "second", client.recreate},
+		{"Cleaning up director-creds.yml", "", client.promoteDirectorCAs},
+		{"Deploying Concourse with a new internal CA", "", client.redeploy},
+	})
+}
+
+// generateDirectorCert stores a new director CA, certificate and key in the config
+func (client *Client) generateDirectorCert(description, operation string) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	tfOutputs, err := client.tfCLI.BuildOutput(client.tfInputVarsFactory.NewInputVars(conf))
+	if err != nil {
+		return err
+	}
+	dc, err := client.ensureDirectorCerts(client.acmeClientConstructor, DirectorCerts{}, conf.Deployment, tfOutputs, conf.PublicCIDR)
+	if err != nil {
+		return err
+	}
+	conf.DirectorCACert = dc.DirectorCACert
+	conf.DirectorCert = dc.DirectorCert
+	conf.DirectorKey = dc.DirectorKey
+	return client.configClient.Update(conf)
+}
+
+// promoteDirectorCAs moves the rotated director CAs to their original names in
+// director-creds.yml, and removes the Concourse CA and everything it signed so that they
+// are generated again on the next deploy
+func (client *Client) promoteDirectorCAs(description, operation string) error {
+	directorCredsBytes, err := loadDirectorCreds(client.configClient)
+	if err != nil {
+		return err
+	}
+	var creds map[string]interface{}
+	err = yaml.Unmarshal(directorCredsBytes, &creds)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range directorCAs {
+		renewed, ok := creds[name+"_2"]
+		if !ok {
+			return fmt.Errorf("%s_2 is missing from %s", name, bosh.CredsFilename)
+		}
+		creds[name] = renewed
+		delete(creds, name+"_2")
+	}
+
+	if ca, ok := creds[concourseCA].(map[interface{}]interface{}); ok {
+		for name, value := range creds {
+			if cert, ok := value.(map[interface{}]interface{}); ok && cert["ca"] == ca["certificate"] {
+				delete(creds, name)
+			}
+		}
+		delete(creds, concourseCA)
+	}
+
+	rotatedCreds, err := yaml.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	err = client.configClient.StoreAsset("director-creds-backup.yml", directorCredsBytes)
+	if err != nil {
+		return err
+	}
+	return client.configClient.StoreAsset(bosh.CredsFilename, rotatedCreds)
+}
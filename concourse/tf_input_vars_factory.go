@@ -2,6 +2,8 @@ package concourse
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/EngineerBetter/concourse-up/config"
 	"github.com/EngineerBetter/concourse-up/iaas"
@@ -42,13 +44,19 @@ type AWSInputVarsFactory struct{}
 
 func (f *AWSInputVarsFactory) NewInputVars(c config.Config) terraform.InputVars {
 	return &terraform.AWSInputVars{
+		AccessCIDRs:            quoteTerraformList(c.AccessCIDRs),
 		NetworkCIDR:            c.NetworkCIDR,
 		PublicCIDR:             c.PublicCIDR,
 		PrivateCIDR:            c.PrivateCIDR,
 		AllowIPs:               c.AllowIPs,
 		AvailabilityZone:       c.AvailabilityZone,
 		ConfigBucket:           c.ConfigBucket,
+		CredentialManager:      c.CredentialManager,
+		DBEngineVersion:        rdsEngineVersions[dbVersion(c)],
+		DBStorage:              dbStorage(c),
+		DBUpgradeSnapshot:      c.DBUpgradeSnapshot,
 		Deployment:             c.Deployment,
+		FinalSnapshot:          c.FinalSnapshot,
 		HostedZoneID:           c.HostedZoneID,
 		HostedZoneRecordPrefix: c.HostedZoneRecordPrefix,
 		Namespace:              c.Namespace,
@@ -61,8 +69,11 @@ func (f *AWSInputVarsFactory) NewInputVars(c config.Config) terraform.InputVars
 		RDS1CIDR:               c.RDS1CIDR,
 		RDS2CIDR:               c.RDS2CIDR,
 		Region:                 c.Region,
+		RestoreSnapshot:        c.RestoredFromSnapshot,
+		SelfUpdateRoleARN:      c.SelfUpdateRoleARN,
 		SourceAccessIP:         c.SourceAccessIP,
 		TFStatePath:            c.TFStatePath,
+		WorkerIAMPolicies:      quoteTerraformList(c.WorkerIAMPolicies),
 	}
 }
 
@@ -75,23 +86,36 @@ type GCPInputVarsFactory struct {
 
 func (f *GCPInputVarsFactory) NewInputVars(c config.Config) terraform.InputVars {
 	return &terraform.GCPInputVars{
-		AllowIPs:           c.AllowIPs,
-		ConfigBucket:       c.ConfigBucket,
-		DBName:             c.RDSDefaultDatabaseName,
-		DBPassword:         c.RDSPassword,
-		DBTier:             c.RDSInstanceClass,
-		DBUsername:         c.RDSUsername,
-		Deployment:         c.Deployment,
-		DNSManagedZoneName: c.HostedZoneID,
-		DNSRecordSetPrefix: c.HostedZoneRecordPrefix,
-		ExternalIP:         c.SourceAccessIP,
-		GCPCredentialsJSON: f.credentialsPath,
-		Namespace:          c.Namespace,
-		Project:            f.project,
-		Region:             f.region,
-		Tags:               "",
-		Zone:               f.zone,
-		PublicCIDR:         c.PublicCIDR,
-		PrivateCIDR:        c.PrivateCIDR,
+		AccessCIDRs:               quoteTerraformList(c.AccessCIDRs),
+		AllowIPs:                  c.AllowIPs,
+		ConfigBucket:              c.ConfigBucket,
+		DBName:                    c.RDSDefaultDatabaseName,
+		DBPassword:                c.RDSPassword,
+		DBStorage:                 dbStorage(c),
+		DBTier:                    c.RDSInstanceClass,
+		DBUsername:                c.RDSUsername,
+		DBVersion:                 cloudSQLVersions[dbVersion(c)],
+		Deployment:                c.Deployment,
+		DNSManagedZoneName:        c.HostedZoneID,
+		DNSRecordSetPrefix:        c.HostedZoneRecordPrefix,
+		ExternalIP:                c.SourceAccessIP,
+		GCPCredentialsJSON:        f.credentialsPath,
+		Namespace:                 c.Namespace,
+		Project:                   f.project,
+		Region:                    f.region,
+		Tags:                      "",
+		Zone:                      f.zone,
+		PublicCIDR:                c.PublicCIDR,
+		PrivateCIDR:               c.PrivateCIDR,
+		WorkerServiceAccountRoles: quoteTerraformList(c.WorkerServiceAccountRoles),
 	}
 }
+
+// quoteTerraformList renders a list of strings as terraform list elements
+func quoteTerraformList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = strconv.Quote(item)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,228 @@
+package concourse
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/commands/maintain"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
+// postgresVersions are the major versions of PostgreSQL the database can run, oldest first
+var postgresVersions = []string{"9.6", "10", "11"}
+
+// rdsEngineVersions are the RDS engine versions used for each major version
+var rdsEngineVersions = map[string]string{
+	"9.6": "9.6.11",
+	"10":  "10.6",
+	"11":  "11.1",
+}
+
+// cloudSQLVersions are the Cloud SQL database versions used for each major version
+var cloudSQLVersions = map[string]string{
+	"9.6": "POSTGRES_9_6",
+	"10":  "POSTGRES_10",
+	"11":  "POSTGRES_11",
+}
+
+// dbEngineVersions are the versions each IAAS names the major versions by
+var dbEngineVersions = map[iaas.Name]map[string]string{
+	iaas.AWS: rdsEngineVersions,
+	iaas.GCP: cloudSQLVersions,
+}
+
+const defaultDBStorage = 10
+
+// dbVersion is the major version of PostgreSQL the database runs. Deployments from before
+// it was recorded are on the oldest version
+func dbVersion(c config.Config) string {
+	if c.DBVersion == "" {
+		return postgresVersions[0]
+	}
+	return c.DBVersion
+}
+
+// dbStorage is the storage, in GB, the database was created with
+func dbStorage(c config.Config) int {
+	if c.DBStorage == 0 {
+		return defaultDBStorage
+	}
+	return c.DBStorage
+}
+
+// postgresMajorVersion returns the major part of a PostgreSQL version, which is the first two
+// numbers before PostgreSQL 10 and the first number after
+func postgresMajorVersion(version string) string {
+	parts := strings.Split(version, ".")
+	if len(parts) > 1 && parts[0] == "9" {
+		return parts[0] + "." + parts[1]
+	}
+	return parts[0]
+}
+
+func postgresVersionIndex(version string) int {
+	for i, v := range postgresVersions {
+		if v == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// upgradeDB moves the database to a newer major version of PostgreSQL, keeping a copy of it
+// from before the upgrade. RDS upgrades in place. Cloud SQL can't, so the databases are
+// exported, the instance is replaced and the exports are imported into the new one
+func (client *Client) upgradeDB(m maintain.Args) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+
+	target := m.DBVersion
+	if target == "" {
+		target = postgresVersions[len(postgresVersions)-1]
+	}
+	if postgresVersionIndex(target) == -1 {
+		return fmt.Errorf("PostgreSQL %s is not supported, use one of %s", target, strings.Join(postgresVersions, ", "))
+	}
+
+	maintenance, err := client.retrieveStage()
+	if err != nil {
+		return err
+	}
+	// The recorded version changes part way through, so only compare when starting afresh
+	resuming := m.StageIsSet || (maintenance.Operation == upgradeDBOperation && maintenance.StatusIndex != -1)
+	if !resuming {
+		current := dbVersion(conf)
+		switch c := postgresVersionIndex(target) - postgresVersionIndex(current); {
+		case c == 0:
+			fmt.Fprintf(client.stdout, "The database is already on PostgreSQL %s\n", current)
+			return nil
+		case c < 0:
+			return fmt.Errorf("refusing to downgrade the database from PostgreSQL %s to %s", current, target)
+		}
+	}
+
+	switch client.provider.IAAS() {
+	case iaas.AWS: // nolint
+		return client.runTasks(upgradeDBOperation, m, []tasks{
+			{"Snapshotting the database", "", client.snapshotDB},
+			{fmt.Sprintf("Upgrading the database to PostgreSQL %s", target), target, client.upgradeRDS},
+			{"Checking the database through the director", target, client.checkDBVersion},
+		})
+	case iaas.GCP: // nolint
+		return client.runTasks(upgradeDBOperation, m, []tasks{
+			{"Exporting the databases", "", client.exportDBs},
+			{fmt.Sprintf("Replacing the database instance with one on PostgreSQL %s", target), target, client.replaceCloudSQLInstance},
+			{"Importing the databases", "", client.importDBs},
+			{"Deploying Concourse with the new database", "", client.redeploy},
+			{"Checking the database", target, client.checkDBVersion},
+		})
+	}
+	return fmt.Errorf("IAAS not supported [%s]", client.provider.IAAS())
+}
+
+// upgradeSnapshotName names the copy of the database kept from before an upgrade
+func upgradeSnapshotName(deployment string) string {
+	return fmt.Sprintf("%s-pre-upgrade-%s", deployment, time.Now().UTC().Format("20060102150405"))
+}
+
+// snapshotDB has terraform snapshot RDS. The snapshot is kept until the next upgrade
+// replaces it, or the deployment is destroyed
+func (client *Client) snapshotDB(description, operation string) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	conf.DBUpgradeSnapshot = upgradeSnapshotName(conf.Deployment)
+	err = client.configClient.Update(conf)
+	if err != nil {
+		return err
+	}
+	return client.tfCLI.Apply(client.tfInputVarsFactory.NewInputVars(conf))
+}
+
+// upgradeRDS records the new version and has terraform upgrade RDS, which returns once the
+// instance is available again
+func (client *Client) upgradeRDS(description, version string) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	conf.DBVersion = version
+	err = client.configClient.Update(conf)
+	if err != nil {
+		return err
+	}
+	return client.tfCLI.Apply(client.tfInputVarsFactory.NewInputVars(conf))
+}
+
+// exportDBs exports the Concourse databases to the archive bucket
+func (client *Client) exportDBs(description, operation string) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	conf.DBUpgradeSnapshot = upgradeSnapshotName(conf.Deployment)
+	err = client.configClient.Update(conf)
+	if err != nil {
+		return err
+	}
+	err = client.ensureArchiveBucket(conf)
+	if err != nil {
+		return err
+	}
+	return client.provider.ExportDatabases(conf.RDSDefaultDatabaseName, config.ArchiveBucket(conf), conf.DBUpgradeSnapshot, bosh.ConcourseDatabases)
+}
+
+// replaceCloudSQLInstance has terraform replace the Cloud SQL instance with one on the new
+// version. Instance names can't be reused for a week, so the new one gets a new name
+func (client *Client) replaceCloudSQLInstance(description, version string) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	conf.DBVersion = version
+	conf.RDSDefaultDatabaseName = fmt.Sprintf("bosh-%s", client.eightRandomLetters())
+	err = client.configClient.Update(conf)
+	if err != nil {
+		return err
+	}
+	return client.tfCLI.Apply(client.tfInputVarsFactory.NewInputVars(conf))
+}
+
+// importDBs creates the Concourse databases on the new Cloud SQL instance and imports the exports into them
+func (client *Client) importDBs(description, operation string) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+	err = client.provider.CreateDatabases(conf.RDSDefaultDatabaseName, conf.RDSUsername, conf.RDSPassword)
+	if err != nil {
+		return err
+	}
+	return client.provider.ImportDatabases(conf.RDSDefaultDatabaseName, conf.RDSUsername, config.ArchiveBucket(conf), conf.DBUpgradeSnapshot, bosh.ConcourseDatabases)
+}
+
+// checkDBVersion connects to the database and checks it reports the version it was upgraded to
+func (client *Client) checkDBVersion(description, version string) error {
+	boshClientPointer, err := client.constructBoshClient()
+	if err != nil {
+		return err
+	}
+	boshClient := *boshClientPointer
+	defer boshClient.Cleanup()
+
+	reported, err := boshClient.DatabaseVersion()
+	if err != nil {
+		return fmt.Errorf("error connecting to the database: [%v]", err)
+	}
+	if postgresMajorVersion(reported) != version {
+		return fmt.Errorf("the database reports PostgreSQL %s, expected %s", reported, version)
+	}
+	fmt.Fprintf(client.stdout, "The database is on PostgreSQL %s\n", reported)
+	return nil
+}
@@ -0,0 +1,83 @@
+package concourse
+
+import (
+	"fmt"
+
+	"github.com/EngineerBetter/concourse-up/bosh"
+	"github.com/EngineerBetter/concourse-up/commands/maintain"
+)
+
+// upgradeStemcell redeploys Concourse onto a newer stemcell, either the version given or the
+// latest in the line Concourse is currently on. BOSH updates the canaries first, as set in
+// the update block of the manifest, and stops if they fail
+func (client *Client) upgradeStemcell(m maintain.Args) error {
+	conf, err := client.configClient.Load()
+	if err != nil {
+		return err
+	}
+
+	current, err := bosh.ConcourseStemcellVersion(conf, client.provider.IAAS())
+	if err != nil {
+		return err
+	}
+	target := m.StemcellVersion
+	if target == "" {
+		target, err = bosh.LatestStemcellVersion(client.provider.IAAS(), current)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch c := bosh.CompareStemcellVersions(target, current); {
+	case c == 0:
+		fmt.Fprintf(client.stdout, "Concourse is already on stemcell %s\n", current)
+		return nil
+	case c < 0:
+		return fmt.Errorf("refusing to downgrade the stemcell from %s to %s", current, target)
+	}
+
+	tfOutputs, err := client.tfCLI.BuildOutput(client.tfInputVarsFactory.NewInputVars(conf))
+	if err != nil {
+		return err
+	}
+	instances, err := client.instances()
+	if err != nil {
+		return err
+	}
+	before := make(map[string]string, len(instances))
+	for _, instance := range instances {
+		before[instance.Name] = instance.Stemcell
+	}
+
+	fmt.Fprintf(client.stdout, "Upgrading the stemcell from %s to %s\n", current, target)
+	conf.StemcellVersion = target
+	bp, err := client.deployBosh(conf, tfOutputs, false)
+	if err != nil {
+		return err
+	}
+	err = client.configClient.Update(bp.applyTo(conf))
+	if err != nil {
+		return err
+	}
+
+	instances, err = client.instances()
+	if err != nil {
+		return err
+	}
+	for _, instance := range instances {
+		fmt.Fprintf(client.stdout, "%s: %s -> %s\n", instance.Name, before[instance.Name], instance.Stemcell)
+	}
+	return nil
+}
+
+// instances lists the VMs deployed by BOSH
+func (client *Client) instances() ([]bosh.Instance, error) {
+	boshClientPointer, err := client.constructBoshClient()
+	if err != nil {
+		return nil, err
+	}
+	boshClient := *boshClientPointer
+	defer boshClient.Cleanup()
+
+	return boshClient.Instances()
+}
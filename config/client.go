@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/EngineerBetter/concourse-up/iaas"
+	"strings"
 )
 
 const terraformStateFileName = "terraform.tfstate"
@@ -148,6 +149,12 @@ func createBucketName(deployment, extension string) string {
 	return fmt.Sprintf("%s-%s-config", deployment, extension)
 }
 
+// ArchiveBucket is where destroy keeps a copy of a deployment's config and database, and
+// outlives the config bucket
+func ArchiveBucket(c Config) string {
+	return strings.TrimSuffix(c.ConfigBucket, "-config") + "-archive"
+}
+
 func determineBucketName(iaas iaas.Provider, namespace, project string) (string, bool, error) {
 	regionBucketName := createBucketName(deployment(project), iaas.Region())
 	namespaceBucketName := createBucketName(deployment(project), namespace)
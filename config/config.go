@@ -2,23 +2,36 @@ package config
 
 // Config represents a concourse-up configuration file
 type Config struct {
+	AccessCIDRs               []string `json:"access_cidrs"`
 	AllowIPs                  string   `json:"allow_ips"`
+	ARMConcourseReleaseURL    string   `json:"arm_concourse_release_url"`
+	ARMGardenRunCReleaseURL   string   `json:"arm_garden_runc_release_url"`
+	ARMStemcellURL            string   `json:"arm_stemcell_url"`
+	ARMWorkerCount            int      `json:"arm_worker_count"`
+	ARMWorkerInstanceType     string   `json:"arm_worker_instance_type"`
 	AvailabilityZone          string   `json:"availability_zone"`
 	ConcourseCACert           string   `json:"concourse_ca_cert"`
 	ConcourseCert             string   `json:"concourse_cert"`
+	ConcourseDeployment       string   `json:"concourse_deployment"`
 	ConcourseKey              string   `json:"concourse_key"`
 	ConcoursePassword         string   `json:"concourse_password"`
 	ConcourseUsername         string   `json:"concourse_username"`
 	ConcourseUserProvidedCert bool     `json:"concourse_user_provided_cert"`
+	ConcourseVersion          string   `json:"concourse_version"`
 	ConcourseWebSize          string   `json:"concourse_web_size"`
 	ConcourseWorkerCount      int      `json:"concourse_worker_count"`
 	ConcourseWorkerSize       string   `json:"concourse_worker_size"`
 	ConfigBucket              string   `json:"config_bucket"`
+	CredentialManager         string   `json:"credential_manager"`
 	CredhubAdminClientSecret  string   `json:"credhub_admin_client_secret"`
 	CredhubCACert             string   `json:"credhub_ca_cert"`
 	CredhubPassword           string   `json:"credhub_password"`
 	CredhubURL                string   `json:"credhub_url"`
 	CredhubUsername           string   `json:"credhub_username"`
+	DBMaxStorage              int      `json:"db_max_storage"`
+	DBStorage                 int      `json:"db_storage"`
+	DBUpgradeSnapshot         string   `json:"db_upgrade_snapshot"`
+	DBVersion                 string   `json:"db_version"`
 	Deployment                string   `json:"deployment"`
 	DirectorCACert            string   `json:"director_ca_cert"`
 	DirectorCert              string   `json:"director_cert"`
@@ -32,6 +45,7 @@ type Config struct {
 	DirectorUsername          string   `json:"director_username"`
 	Domain                    string   `json:"domain"`
 	EncryptionKey             string   `json:"encryption_key"`
+	FinalSnapshot             string   `json:"final_snapshot"`
 	GithubAuthIsSet           bool     `json:"github_auth_is_set"`
 	GithubClientID            string   `json:"github_client_id"`
 	GithubClientSecret        string   `json:"github_client_secret"`
@@ -39,7 +53,16 @@ type Config struct {
 	HostedZoneID              string   `json:"hosted_zone_id"`
 	HostedZoneRecordPrefix    string   `json:"hosted_zone_record_prefix"`
 	IAAS                      string   `json:"iaas"`
+	ImportedDirector          bool     `json:"imported_director"`
+	MaintenanceWindow         string   `json:"maintenance_window"`
 	Namespace                 string   `json:"namespace"`
+	NotifyEmail               string   `json:"notify_email"`
+	NotifySlackWebhook        string   `json:"notify_slack_webhook"`
+	NotifySMTPPassword        string   `json:"notify_smtp_password"`
+	NotifySMTPServer          string   `json:"notify_smtp_server"`
+	NotifySMTPUsername        string   `json:"notify_smtp_username"`
+	NotifyWebhook             string   `json:"notify_webhook"`
+	OldEncryptionKey          string   `json:"old_encryption_key"`
 	PrivateKey                string   `json:"private_key"`
 	Project                   string   `json:"project"`
 	PublicKey                 string   `json:"public_key"`
@@ -48,11 +71,32 @@ type Config struct {
 	RDSPassword               string   `json:"rds_password"`
 	RDSUsername               string   `json:"rds_username"`
 	Region                    string   `json:"region"`
+	RestoredFromSnapshot      string   `json:"restored_from_snapshot"`
+	SelfUpdateApproval        bool     `json:"self_update_approval"`
+	SelfUpdateChannel         string   `json:"self_update_channel"`
+	SelfUpdateExternalID      string   `json:"self_update_external_id"`
+	SelfUpdateRoleARN         string   `json:"self_update_role_arn"`
+	SelfUpdateVersionRange    string   `json:"self_update_version_range"`
 	SourceAccessIP            string   `json:"source_access_ip"`
 	Spot                      bool     `json:"spot"`
+	StemcellVersion           string   `json:"stemcell_version"`
 	Tags                      []string `json:"tags"`
 	TFStatePath               string   `json:"tf_state_path"`
+	VaultCACert               string   `json:"vault_ca_cert"`
+	VaultPathPrefix           string   `json:"vault_path_prefix"`
+	VaultToken                string   `json:"vault_token"`
+	VaultURL                  string   `json:"vault_url"`
 	Version                   string   `json:"version"`
+	WebInstanceType           string   `json:"web_instance_type"`
+	WindowsWorkerCount        int      `json:"windows_worker_count"`
+	WindowsWorkerInstanceType string   `json:"windows_worker_instance_type"`
+	WorkerDiskIOPS            int      `json:"worker_disk_iops"`
+	WorkerDiskSize            int      `json:"worker_disk_size"`
+	WorkerDiskType            string   `json:"worker_disk_type"`
+	WorkerIAMPolicies         []string `json:"worker_iam_policies"`
+	WorkerInstanceType        string   `json:"worker_instance_type"`
+	WorkerPersistentDiskSize  int      `json:"worker_persistent_disk_size"`
+	WorkerServiceAccountRoles []string `json:"worker_service_account_roles"`
 	WorkerType                string   `json:"worker_type"`
 	PrivateCIDR               string   `json:"private_cidr"`
 	PublicCIDR                string   `json:"public_cidr"`
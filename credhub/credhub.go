@@ -0,0 +1,234 @@
+package credhub
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// AdminClient is the UAA client concourse-up configures for CredHub administration
+const AdminClient = "credhub_admin"
+
+// Credential is a single credential as stored in CredHub
+type Credential struct {
+	Name  string          `json:"name"`
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Client talks to the CredHub API on behalf of the credhub_admin UAA client
+type Client struct {
+	url          string
+	clientID     string
+	clientSecret string
+	httpClient   *http.Client
+	token        string
+}
+
+// New returns a CredHub client which trusts only the given CA certificate
+func New(credhubURL, caCert, clientID, clientSecret string) (*Client, error) {
+	if credhubURL == "" {
+		return nil, errors.New("no CredHub URL found in config, has this deployment finished deploying?")
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(caCert)) {
+		return nil, errors.New("could not parse CredHub CA certificate")
+	}
+
+	return &Client{
+		url:          strings.TrimSuffix(credhubURL, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: pool},
+			},
+		},
+	}, nil
+}
+
+// Path scopes a credential name to a Concourse team and, optionally, a pipeline
+// in the same way Concourse looks credentials up
+func Path(team, pipeline, name string) string {
+	if strings.HasPrefix(name, "/") {
+		return name
+	}
+	return path.Join("/concourse", team, pipeline, name)
+}
+
+// Set writes a new version of a credential
+func (c *Client) Set(name, credType string, value interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"name":  name,
+		"type":  credType,
+		"value": value,
+	})
+	if err != nil {
+		return err
+	}
+	return c.do("PUT", "/api/v1/data", nil, bytes.NewReader(body), nil)
+}
+
+// Get fetches the current version of a credential
+func (c *Client) Get(name string) (Credential, error) {
+	var response struct {
+		Data []Credential `json:"data"`
+	}
+	err := c.do("GET", "/api/v1/data", url.Values{"name": {name}, "current": {"true"}}, nil, &response)
+	if err != nil {
+		return Credential{}, err
+	}
+	if len(response.Data) == 0 {
+		return Credential{}, fmt.Errorf("credential %s not found", name)
+	}
+	return response.Data[0], nil
+}
+
+// List returns the names of all credentials under a path
+func (c *Client) List(credPath string) ([]string, error) {
+	var response struct {
+		Credentials []struct {
+			Name string `json:"name"`
+		} `json:"credentials"`
+	}
+	err := c.do("GET", "/api/v1/data", url.Values{"path": {credPath}}, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(response.Credentials))
+	for i, cred := range response.Credentials {
+		names[i] = cred.Name
+	}
+	return names, nil
+}
+
+// Delete removes all versions of a credential
+func (c *Client) Delete(name string) error {
+	return c.do("DELETE", "/api/v1/data", url.Values{"name": {name}}, nil, nil)
+}
+
+// Health checks that CredHub reports itself as up, which doesn't need a token
+func (c *Client) Health() error {
+	resp, err := c.httpClient.Get(c.url + "/health")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+
+	var health struct {
+		Status string `json:"status"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return fmt.Errorf("error decoding CredHub health: [%v]", err)
+	}
+	if health.Status != "UP" {
+		return fmt.Errorf("CredHub status is %q", health.Status)
+	}
+	return nil
+}
+
+func (c *Client) do(method, endpoint string, query url.Values, body io.Reader, out interface{}) error {
+	if c.token == "" {
+		err := c.authenticate()
+		if err != nil {
+			return err
+		}
+	}
+
+	u := c.url + endpoint
+	if query != nil {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return responseError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// authenticate discovers the UAA server from CredHub and fetches a client credentials token
+func (c *Client) authenticate() error {
+	resp, err := c.httpClient.Get(c.url + "/info")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+
+	var info struct {
+		AuthServer struct {
+			URL string `json:"url"`
+		} `json:"auth-server"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return fmt.Errorf("error decoding CredHub info: [%v]", err)
+	}
+
+	tokenResp, err := c.httpClient.PostForm(strings.TrimSuffix(info.AuthServer.URL, "/")+"/oauth/token", url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {c.clientID},
+		"client_secret": {c.clientSecret},
+	})
+	if err != nil {
+		return err
+	}
+	defer tokenResp.Body.Close()
+	if tokenResp.StatusCode != http.StatusOK {
+		return responseError(tokenResp)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err = json.NewDecoder(tokenResp.Body).Decode(&token); err != nil {
+		return fmt.Errorf("error decoding UAA token: [%v]", err)
+	}
+	if token.AccessToken == "" {
+		return errors.New("UAA did not return an access token")
+	}
+	c.token = token.AccessToken
+	return nil
+}
+
+func responseError(resp *http.Response) error {
+	body, _ := ioutil.ReadAll(resp.Body)
+	var credhubErr struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &credhubErr) == nil && credhubErr.Error != "" {
+		return fmt.Errorf("%s %s: %s", resp.Request.Method, resp.Request.URL.Path, credhubErr.Error)
+	}
+	return fmt.Errorf("%s %s: unexpected status %s", resp.Request.Method, resp.Request.URL.Path, resp.Status)
+}
@@ -0,0 +1,178 @@
+package credhub_test
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/EngineerBetter/concourse-up/credhub"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCredHub is an in-memory stand-in for the CredHub data API and the UAA token endpoint
+type fakeCredHub struct {
+	mu    sync.Mutex
+	creds map[string]credhub.Credential
+}
+
+func newFakeCredHub(t *testing.T) (*httptest.Server, *fakeCredHub) {
+	f := &fakeCredHub{creds: map[string]credhub.Credential{}}
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/info", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"auth-server": map[string]string{"url": server.URL},
+		})
+	})
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"status": "UP"})
+	})
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("client_id") != credhub.AdminClient || r.FormValue("client_secret") != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "token"})
+	})
+	mux.HandleFunc("/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		switch r.Method {
+		case "PUT":
+			var c credhub.Credential
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&c))
+			f.creds[c.Name] = c
+			json.NewEncoder(w).Encode(c)
+		case "GET":
+			if name := r.URL.Query().Get("name"); name != "" {
+				c, ok := f.creds[name]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					json.NewEncoder(w).Encode(map[string]string{"error": "The request could not be completed because the credential does not exist or you do not have sufficient authorization."})
+					return
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"data": []credhub.Credential{c}})
+				return
+			}
+			var found []map[string]string
+			for name := range f.creds {
+				if strings.HasPrefix(name, r.URL.Query().Get("path")+"/") {
+					found = append(found, map[string]string{"name": name})
+				}
+			}
+			sort.Slice(found, func(i, j int) bool { return found[i]["name"] < found[j]["name"] })
+			json.NewEncoder(w).Encode(map[string]interface{}{"credentials": found})
+		case "DELETE":
+			delete(f.creds, r.URL.Query().Get("name"))
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	server = httptest.NewTLSServer(mux)
+	return server, f
+}
+
+func newClient(t *testing.T, server *httptest.Server) *credhub.Client {
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	c, err := credhub.New(server.URL, string(caCert), credhub.AdminClient, "s3cret")
+	require.NoError(t, err)
+	return c
+}
+
+func TestPath(t *testing.T) {
+	require.Equal(t, "/concourse/main/my-pipeline/password", credhub.Path("main", "my-pipeline", "password"))
+	require.Equal(t, "/concourse/main/password", credhub.Path("main", "", "password"))
+	require.Equal(t, "/custom/password", credhub.Path("main", "my-pipeline", "/custom/password"))
+}
+
+func TestClient_SetGetListDelete(t *testing.T) {
+	server, _ := newFakeCredHub(t)
+	defer server.Close()
+	c := newClient(t, server)
+
+	require.NoError(t, c.Set("/concourse/main/p/password", "value", "hunter2"))
+	require.NoError(t, c.Set("/concourse/main/p/token", "value", "abc"))
+
+	cred, err := c.Get("/concourse/main/p/password")
+	require.NoError(t, err)
+	require.Equal(t, "value", cred.Type)
+	require.JSONEq(t, `"hunter2"`, string(cred.Value))
+
+	names, err := c.List("/concourse/main/p")
+	require.NoError(t, err)
+	require.Equal(t, []string{"/concourse/main/p/password", "/concourse/main/p/token"}, names)
+
+	require.NoError(t, c.Delete("/concourse/main/p/password"))
+	_, err = c.Get("/concourse/main/p/password")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "credential does not exist")
+}
+
+func TestClient_Health(t *testing.T) {
+	server, _ := newFakeCredHub(t)
+	defer server.Close()
+	c := newClient(t, server)
+
+	require.NoError(t, c.Health())
+
+	server.Close()
+	require.Error(t, c.Health())
+}
+
+func TestClient_RejectsBadSecret(t *testing.T) {
+	server, _ := newFakeCredHub(t)
+	defer server.Close()
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	c, err := credhub.New(server.URL, string(caCert), credhub.AdminClient, "wrong")
+	require.NoError(t, err)
+
+	err = c.Set("/concourse/main/password", "value", "x")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "401")
+}
+
+func TestNew_RequiresCACert(t *testing.T) {
+	_, err := credhub.New("https://credhub.example.com:8844", "not a cert", credhub.AdminClient, "s3cret")
+	require.EqualError(t, err, "could not parse CredHub CA certificate")
+}
+
+func TestClient_Import(t *testing.T) {
+	server, fake := newFakeCredHub(t)
+	defer server.Close()
+	c := newClient(t, server)
+
+	names, err := c.Import("main", "deploy-app", []byte(`
+password: hunter2
+port: 8080
+aws:
+  access_key_id: AKIA
+  secret_access_key: xyz
+`))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"/concourse/main/deploy-app/aws",
+		"/concourse/main/deploy-app/password",
+		"/concourse/main/deploy-app/port",
+	}, names)
+
+	require.Equal(t, "json", fake.creds["/concourse/main/deploy-app/aws"].Type)
+	require.JSONEq(t, `{"access_key_id":"AKIA","secret_access_key":"xyz"}`, string(fake.creds["/concourse/main/deploy-app/aws"].Value))
+	require.JSONEq(t, `"8080"`, string(fake.creds["/concourse/main/deploy-app/port"].Value))
+}
+
+func TestClient_ImportRejectsLists(t *testing.T) {
+	server, _ := newFakeCredHub(t)
+	defer server.Close()
+	c := newClient(t, server)
+
+	_, err := c.Import("main", "", []byte("hosts: [a, b]\n"))
+	require.EqualError(t, err, "hosts: lists are not supported, use a map instead")
+}
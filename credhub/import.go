@@ -0,0 +1,84 @@
+package credhub
+
+import (
+	"fmt"
+	"sort"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Import sets every top-level key of a YAML document as a credential scoped to the
+// given team and pipeline. Scalars are stored as value credentials and maps as json
+// credentials. It returns the names of the credentials it set.
+func (c *Client) Import(team, pipeline string, contents []byte) ([]string, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(contents, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing secrets file: [%v]", err)
+	}
+
+	keys := make([]string, 0, len(doc))
+	for k := range doc {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var names []string
+	for _, k := range keys {
+		credType, value, err := credentialFromYAML(doc[k])
+		if err != nil {
+			return names, fmt.Errorf("%s: %v", k, err)
+		}
+		name := Path(team, pipeline, k)
+		if err = c.Set(name, credType, value); err != nil {
+			return names, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func credentialFromYAML(v interface{}) (string, interface{}, error) {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		value, err := stringKeys(t)
+		return "json", value, err
+	case []interface{}:
+		return "", nil, fmt.Errorf("lists are not supported, use a map instead")
+	case nil:
+		return "", nil, fmt.Errorf("value is empty")
+	default:
+		return "value", fmt.Sprint(t), nil
+	}
+}
+
+// stringKeys converts the maps produced by yaml.v2 into ones encoding/json can marshal
+func stringKeys(v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("non-string key %v", k)
+			}
+			converted, err := stringKeys(val)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = converted
+		}
+		return m, nil
+	case []interface{}:
+		l := make([]interface{}, len(t))
+		for i, val := range t {
+			converted, err := stringKeys(val)
+			if err != nil {
+				return nil, err
+			}
+			l[i] = converted
+		}
+		return l, nil
+	default:
+		return t, nil
+	}
+}
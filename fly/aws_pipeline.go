@@ -1,9 +1,8 @@
 package fly
 
 import (
-	"strings"
-
-	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/EngineerBetter/concourse-up/config"
+	"github.com/EngineerBetter/concourse-up/iaas"
 )
 
 // AWSPipeline is AWS specific implementation of Pipeline interface
@@ -11,7 +10,9 @@ type AWSPipeline struct {
 	PipelineTemplateParams
 	AWSAccessKeyID     string
 	AWSSecretAccessKey string
-	credsGetter AWSCredsGetter
+	AWSAssumeRoleARN   string
+	AWSExternalID      string
+	credsGetter        AWSCredsGetter
 }
 
 // NewAWSPipeline return AWSPipeline
@@ -19,38 +20,30 @@ func NewAWSPipeline(getter AWSCredsGetter) Pipeline {
 	return AWSPipeline{credsGetter: getter}
 }
 
-type AWSCredsGetter = func()(string, string, error)
-var getCredsFromSession = func() (string, string, error) {
-	sess, err := session.NewSession()
-	if err != nil {
-		return "", "", err
-	}
-
-	creds, err := sess.Config.Credentials.Get()
-	if err != nil {
-		return "", "", err
-	}
+// AWSCredsGetter returns the credentials the self update pipeline runs with
+type AWSCredsGetter = func() (iaas.AWSCredentials, error)
 
-	return creds.AccessKeyID, creds.SecretAccessKey, nil
-}
+var getCredsFromSession = iaas.AWSSelfUpdateCredentials
 
 //BuildPipelineParams builds params for AWS concourse-up self update pipeline
-func (a AWSPipeline) BuildPipelineParams(deployment, namespace, region, domain string) (Pipeline, error) {
-	accessKeyID, secretAccessKey, err := a.credsGetter()
-	if err != nil {
-		return nil, err
+// When the workers have an instance profile the self update jobs rely on it, assuming
+// the deployer's role from it if there was one, rather than on keys copied from the local session
+func (a AWSPipeline) BuildPipelineParams(config config.Config) (Pipeline, error) {
+	var creds iaas.AWSCredentials
+	if len(config.WorkerIAMPolicies) == 0 && config.SelfUpdateRoleARN == "" {
+		var err error
+		creds, err = a.credsGetter()
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return AWSPipeline{
-		PipelineTemplateParams: PipelineTemplateParams{
-			ConcourseUpVersion: ConcourseUpVersion,
-			Deployment:         strings.TrimPrefix(deployment, "concourse-up-"),
-			Domain:             domain,
-			Namespace:          namespace,
-			Region:             region,
-		},
-		AWSAccessKeyID:     accessKeyID,
-		AWSSecretAccessKey: secretAccessKey,
+		PipelineTemplateParams: newPipelineTemplateParams(config),
+		AWSAccessKeyID:         creds.AccessKeyID,
+		AWSSecretAccessKey:     creds.SecretAccessKey,
+		AWSAssumeRoleARN:       config.SelfUpdateRoleARN,
+		AWSExternalID:          config.SelfUpdateExternalID,
 	}, nil
 }
 
@@ -62,19 +55,23 @@ func (a AWSPipeline) GetConfigTemplate() string {
 
 const awsPipelineTemplate = `
 ---` + selfUpdateResources + `
-jobs:
+jobs:` + approvalJob + `
 - name: self-update
   serial_groups: [cup]
   serial: true
-  plan:
-  - get: concourse-up-release
-    trigger: true
+  plan:` + selfUpdateGet + `
   - task: update
     params:
       AWS_REGION: "{{ .Region }}"
       DEPLOYMENT: "{{ .Deployment }}"
+{{- if .AWSAccessKeyID }}
       AWS_ACCESS_KEY_ID: "{{ .AWSAccessKeyID }}"
       AWS_SECRET_ACCESS_KEY: "{{ .AWSSecretAccessKey }}"
+{{- end }}
+{{- if .AWSAssumeRoleARN }}
+      ASSUME_ROLE_ARN: "{{ .AWSAssumeRoleARN }}"
+      EXTERNAL_ID: "{{ .AWSExternalID }}"
+{{- end }}
       SELF_UPDATE: true
       NAMESPACE: {{ .Namespace }}
     config:
@@ -94,7 +91,7 @@ jobs:
 
           cd concourse-up-release
           chmod +x concourse-up-linux-amd64
-          ./concourse-up-linux-amd64 deploy $DEPLOYMENT
+          ./concourse-up-linux-amd64 deploy $DEPLOYMENT` + selfUpdateNotifyHooks + `
 - name: renew-https-cert
   serial_groups: [cup]
   serial: true
@@ -107,8 +104,14 @@ jobs:
     params:
       AWS_REGION: "{{ .Region }}"
       DEPLOYMENT: "{{ .Deployment }}"
+{{- if .AWSAccessKeyID }}
       AWS_ACCESS_KEY_ID: "{{ .AWSAccessKeyID }}"
       AWS_SECRET_ACCESS_KEY: "{{ .AWSSecretAccessKey }}"
+{{- end }}
+{{- if .AWSAssumeRoleARN }}
+      ASSUME_ROLE_ARN: "{{ .AWSAssumeRoleARN }}"
+      EXTERNAL_ID: "{{ .AWSExternalID }}"
+{{- end }}
       SELF_UPDATE: true
       NAMESPACE: {{ .Namespace }}
     config:
@@ -129,5 +132,5 @@ jobs:
           chmod +x concourse-up-linux-amd64
 ` + renewCertsDateCheck + `
           echo Certificates expire in $days_until_expiry days, redeploying to renew them
-          ./concourse-up-linux-amd64 deploy $DEPLOYMENT
+          ./concourse-up-linux-amd64 deploy $DEPLOYMENT` + renewCertNotifyHooks + `
 `
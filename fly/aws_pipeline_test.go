@@ -1,7 +1,9 @@
 package fly_test
 
 import (
+	"github.com/EngineerBetter/concourse-up/config"
 	. "github.com/EngineerBetter/concourse-up/fly"
+	"github.com/EngineerBetter/concourse-up/iaas"
 	"github.com/EngineerBetter/concourse-up/util"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -88,12 +90,12 @@ jobs:
           cd concourse-up-release
           chmod +x concourse-up-linux-amd64
 
-          now_seconds=$(date +%s)
-          not_after=$(echo | openssl s_client -connect ci.engineerbetter.com:443 2>/dev/null | openssl x509 -noout -enddate)
-          expires_on=${not_after#'notAfter='}
-          expires_on_seconds=$(date --date="$expires_on" +%s)
-          let "seconds_until_expiry = $expires_on_seconds - $now_seconds"
-          let "days_until_expiry = $seconds_until_expiry / 60 / 60 / 24"
+          days_until_expiry=$(./concourse-up-linux-amd64 info --cert-expiry --json $DEPLOYMENT |
+            jq '[.[] | select(.source == "config.json" and (.name == "concourse_cert" or (.name | startswith("concourse_cert[")))) | .days_remaining] | min // empty')
+          if [ -z "$days_until_expiry" ]; then
+            echo Not renewing HTTPS cert, as there is no Concourse cert in the config.
+            exit 0
+          fi
           if [ $days_until_expiry -gt 2 ]; then
             echo Not renewing HTTPS cert, as they do not expire in the next two days.
             exit 0
@@ -104,13 +106,18 @@ jobs:
 `
 
 		It("Generates something sensible", func() {
-			fakeCredsGetter := func()(string, string, error) {
-				return "access-key", "secret-key", nil
+			fakeCredsGetter := func() (iaas.AWSCredentials, error) {
+				return iaas.AWSCredentials{AccessKeyID: "access-key", SecretAccessKey: "secret-key"}, nil
 			}
 
 			pipeline := NewAWSPipeline(fakeCredsGetter)
 
-			params, err := pipeline.BuildPipelineParams("my-deployment", "prod", "eu-west-1", "ci.engineerbetter.com")
+			params, err := pipeline.BuildPipelineParams(config.Config{
+				Deployment: "my-deployment",
+				Namespace:  "prod",
+				Region:     "eu-west-1",
+				Domain:     "ci.engineerbetter.com",
+			})
 			Expect(err).ToNot(HaveOccurred())
 
 			yamlBytes, err := util.RenderTemplate("self-update pipeline", pipeline.GetConfigTemplate(), params)
@@ -119,6 +126,107 @@ jobs:
 			actual := string(yamlBytes)
 			Expect(actual).To(Equal(expected))
 		})
+
+		It("Assumes the deployer's role from the worker instance profile instead of passing static keys", func() {
+			credsGetterCalled := false
+			fakeCredsGetter := func() (iaas.AWSCredentials, error) {
+				credsGetterCalled = true
+				return iaas.AWSCredentials{AccessKeyID: "access-key", SecretAccessKey: "secret-key"}, nil
+			}
+
+			pipeline := NewAWSPipeline(fakeCredsGetter)
+
+			params, err := pipeline.BuildPipelineParams(config.Config{
+				Deployment:           "my-deployment",
+				Namespace:            "prod",
+				Region:               "eu-west-1",
+				Domain:               "ci.engineerbetter.com",
+				SelfUpdateRoleARN:    "arn:aws:iam::123456789012:role/concourse-up",
+				SelfUpdateExternalID: "external-id",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			yamlBytes, err := util.RenderTemplate("self-update pipeline", pipeline.GetConfigTemplate(), params)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(credsGetterCalled).To(BeFalse())
+			Expect(string(yamlBytes)).ToNot(ContainSubstring("AWS_SECRET_ACCESS_KEY"))
+			Expect(string(yamlBytes)).To(ContainSubstring(`      DEPLOYMENT: "my-deployment"
+      ASSUME_ROLE_ARN: "arn:aws:iam::123456789012:role/concourse-up"
+      EXTERNAL_ID: "external-id"
+      SELF_UPDATE: true`))
+		})
+
+		It("Relies on the worker instance profile instead of static keys when one is configured", func() {
+			credsGetterCalled := false
+			fakeCredsGetter := func() (iaas.AWSCredentials, error) {
+				credsGetterCalled = true
+				return iaas.AWSCredentials{AccessKeyID: "access-key", SecretAccessKey: "secret-key"}, nil
+			}
+
+			pipeline := NewAWSPipeline(fakeCredsGetter)
+
+			params, err := pipeline.BuildPipelineParams(config.Config{
+				Deployment:        "my-deployment",
+				Namespace:         "prod",
+				Region:            "eu-west-1",
+				Domain:            "ci.engineerbetter.com",
+				WorkerIAMPolicies: []string{"arn:aws:iam::aws:policy/AdministratorAccess"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			yamlBytes, err := util.RenderTemplate("self-update pipeline", pipeline.GetConfigTemplate(), params)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(credsGetterCalled).To(BeFalse())
+			Expect(string(yamlBytes)).ToNot(ContainSubstring("AWS_ACCESS_KEY_ID"))
+			Expect(string(yamlBytes)).ToNot(ContainSubstring("AWS_SECRET_ACCESS_KEY"))
+			Expect(string(yamlBytes)).To(ContainSubstring(`DEPLOYMENT: "my-deployment"
+      SELF_UPDATE: true`))
+		})
+
+		It("Applies the self-update channel, version range, approval gate and maintenance window", func() {
+			fakeCredsGetter := func() (iaas.AWSCredentials, error) {
+				return iaas.AWSCredentials{AccessKeyID: "access-key", SecretAccessKey: "secret-key"}, nil
+			}
+
+			pipeline := NewAWSPipeline(fakeCredsGetter)
+
+			params, err := pipeline.BuildPipelineParams(config.Config{
+				Deployment:             "my-deployment",
+				Namespace:              "prod",
+				Region:                 "eu-west-1",
+				Domain:                 "ci.engineerbetter.com",
+				SelfUpdateChannel:      "stable",
+				SelfUpdateVersionRange: ">=0.20.0 <0.21.0",
+				SelfUpdateApproval:     true,
+				MaintenanceWindow:      "02:00-04:00",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			yamlBytes, err := util.RenderTemplate("self-update pipeline", pipeline.GetConfigTemplate(), params)
+			Expect(err).ToNot(HaveOccurred())
+
+			actual := string(yamlBytes)
+			Expect(actual).To(ContainSubstring(`    pre_release: false
+    semver_constraint: ">=0.20.0 <0.21.0"
+- name: every-day
+  type: time
+  source: {interval: 24h, start: "02:00", stop: "04:00", location: UTC}
+`))
+			Expect(actual).To(ContainSubstring(`jobs:
+- name: approve-self-update
+  plan:
+  - get: concourse-up-release
+- name: self-update
+  serial_groups: [cup]
+  serial: true
+  plan:
+  - get: concourse-up-release
+    trigger: true
+    passed: [approve-self-update]
+  - task: update`))
+		})
 	})
 })
 
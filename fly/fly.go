@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"net/http"
 	"os"
 	"os/exec"
 	"runtime"
@@ -18,6 +17,7 @@ import (
 
 	"github.com/EngineerBetter/concourse-up/config"
 	"github.com/EngineerBetter/concourse-up/util"
+	"github.com/EngineerBetter/concourse-up/util/bincache"
 )
 
 // ConcourseUpVersion is a compile-time variable set with -ldflags
@@ -56,32 +56,21 @@ func New(provider iaas.Provider, creds Credentials, stdout, stderr io.Writer, ve
 		return nil, err
 	}
 
-	fileHandler, err := os.Create(tempDir.Path("fly"))
+	url, sha256sum, err := getFly(versionFile, runtime.GOOS)
 	if err != nil {
 		return nil, err
 	}
-	defer fileHandler.Close()
 
-	url, err := getFlyURL(versionFile)
+	// bincache verifies the download before it is cached, so the copy made executable here is trusted
+	cachedPath, err := bincache.Download(url, sha256sum)
 	if err != nil {
 		return nil, err
 	}
-
-	resp, err := http.Get(url)
-	if err != nil {
+	if err = copyFile(cachedPath, tempDir.Path("fly")); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if _, err := io.Copy(fileHandler, resp.Body); err != nil {
-		return nil, err
-	}
-
-	if err := fileHandler.Sync(); err != nil {
-		return nil, err
-	}
-
-	if err := os.Chmod(fileHandler.Name(), 0700); err != nil {
+	if err := os.Chmod(tempDir.Path("fly"), 0700); err != nil {
 		return nil, err
 	}
 
@@ -192,8 +181,11 @@ func (client *Client) SetDefaultPipeline(config config.Config, allowFlyVersionDi
 		return err
 	}
 
-	if err := client.run("pause-job", "--job", pipelineName+"/self-update"); err != nil {
-		return err
+	// With an approval gate the self-update job only runs once someone triggers approve-self-update
+	if !config.SelfUpdateApproval {
+		if err := client.run("pause-job", "--job", pipelineName+"/self-update"); err != nil {
+			return err
+		}
 	}
 
 	return client.run("unpause-pipeline", "--pipeline", pipelineName)
@@ -206,7 +198,7 @@ func (client *Client) writePipelineConfig(pipelinePath string, config config.Con
 	}
 	defer fileHandler.Close()
 
-	params, err := client.pipeline.BuildPipelineParams(config.Deployment, config.Namespace, config.Region, config.Domain)
+	params, err := client.pipeline.BuildPipelineParams(config)
 	if err != nil {
 		return err
 	}
@@ -267,18 +259,41 @@ func (client *Client) run(args ...string) error {
 	return cmd.Run()
 }
 
-func getFlyURL(versionFile []byte) (string, error) {
+func getFly(versionFile []byte, goos string) (string, string, error) {
 	var x map[string]map[string]string
 	err := json.Unmarshal(versionFile, &x)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
-	switch runtime.GOOS {
+	switch goos {
 	case "darwin":
-		return x["fly"]["mac"], nil
+		return x["fly"]["mac"], x["fly"]["mac_sha256"], nil
 	case "linux":
-		return x["fly"]["linux"], nil
+		return x["fly"]["linux"], x["fly"]["linux_sha256"], nil
 	default:
-		return "", fmt.Errorf("unknown os: `%s`", runtime.GOOS)
+		return "", "", fmt.Errorf("unknown os: `%s`", goos)
+	}
+}
+
+// FlyURL returns the fly download URL for goos from a version file
+func FlyURL(versionFile []byte, goos string) (string, error) {
+	url, _, err := getFly(versionFile, goos)
+	return url, err
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
 	}
+	return out.Close()
 }
@@ -82,3 +82,30 @@ func TestClient_runFly(t *testing.T) {
 		})
 	}
 }
+
+func Test_getFly(t *testing.T) {
+	versionFile := []byte(`{"fly": {"mac": "https://example.com/fly_darwin_amd64", "mac_sha256": "abc", "linux": "https://example.com/fly_linux_amd64", "linux_sha256": "def"}}`)
+	tests := []struct {
+		name       string
+		goos       string
+		wantURL    string
+		wantSHA256 string
+		wantErr    bool
+	}{
+		{name: "linux", goos: "linux", wantURL: "https://example.com/fly_linux_amd64", wantSHA256: "def"},
+		{name: "mac", goos: "darwin", wantURL: "https://example.com/fly_darwin_amd64", wantSHA256: "abc"},
+		{name: "unsupported", goos: "windows", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, sha256sum, err := getFly(versionFile, tt.goos)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getFly() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if url != tt.wantURL || sha256sum != tt.wantSHA256 {
+				t.Errorf("getFly() = %v, %v, want %v, %v", url, sha256sum, tt.wantURL, tt.wantSHA256)
+			}
+		})
+	}
+}
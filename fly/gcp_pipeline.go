@@ -2,7 +2,8 @@ package fly
 
 import (
 	"io/ioutil"
-	"strings"
+
+	"github.com/EngineerBetter/concourse-up/config"
 )
 
 // GCPPipeline is GCP specific implementation of Pipeline interface
@@ -23,16 +24,10 @@ func NewGCPPipeline(credsPath string) (Pipeline, error) {
 }
 
 //BuildPipelineParams builds params for AWS concourse-up self update pipeline
-func (a GCPPipeline) BuildPipelineParams(deployment, namespace, region, domain string) (Pipeline, error) {
+func (a GCPPipeline) BuildPipelineParams(config config.Config) (Pipeline, error) {
 	return GCPPipeline{
-		PipelineTemplateParams: PipelineTemplateParams{
-			ConcourseUpVersion: ConcourseUpVersion,
-			Deployment:         strings.TrimPrefix(deployment, "concourse-up-"),
-			Domain:             domain,
-			Namespace:          namespace,
-			Region:             region,
-		},
-		GCPCreds: a.GCPCreds,
+		PipelineTemplateParams: newPipelineTemplateParams(config),
+		GCPCreds:               a.GCPCreds,
 	}, nil
 }
 
@@ -52,13 +47,11 @@ func readFileContents(path string) (string, error) {
 
 const gcpPipelineTemplate = `
 ---` + selfUpdateResources + `
-jobs:
+jobs:` + approvalJob + `
 - name: self-update
   serial_groups: [cup]
   serial: true
-  plan:
-  - get: concourse-up-release
-    trigger: true
+  plan:` + selfUpdateGet + `
   - task: update
     params:
       AWS_REGION: "{{ .Region }}"
@@ -85,7 +78,7 @@ jobs:
           export GOOGLE_APPLICATION_CREDENTIALS=$PWD/googlecreds.json
           set -eux
           chmod +x concourse-up-linux-amd64
-          ./concourse-up-linux-amd64 deploy $DEPLOYMENT
+          ./concourse-up-linux-amd64 deploy $DEPLOYMENT` + selfUpdateNotifyHooks + `
 - name: renew-https-cert
   serial_groups: [cup]
   serial: true
@@ -122,5 +115,5 @@ jobs:
           chmod +x concourse-up-linux-amd64
 ` + renewCertsDateCheck + `
           echo Certificates expire in $days_until_expiry days, redeploying to renew them
-          ./concourse-up-linux-amd64 deploy $DEPLOYMENT
+          ./concourse-up-linux-amd64 deploy $DEPLOYMENT` + renewCertNotifyHooks + `
 `
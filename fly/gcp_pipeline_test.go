@@ -4,10 +4,12 @@ import (
 	"io/ioutil"
 	"os"
 
+	"github.com/EngineerBetter/concourse-up/config"
 	. "github.com/EngineerBetter/concourse-up/fly"
 	"github.com/EngineerBetter/concourse-up/util"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
 )
 
 var _ = Describe("GCPPipeline", func() {
@@ -94,12 +96,12 @@ jobs:
           cd concourse-up-release
           chmod +x concourse-up-linux-amd64
 
-          now_seconds=$(date +%s)
-          not_after=$(echo | openssl s_client -connect ci.engineerbetter.com:443 2>/dev/null | openssl x509 -noout -enddate)
-          expires_on=${not_after#'notAfter='}
-          expires_on_seconds=$(date --date="$expires_on" +%s)
-          let "seconds_until_expiry = $expires_on_seconds - $now_seconds"
-          let "days_until_expiry = $seconds_until_expiry / 60 / 60 / 24"
+          days_until_expiry=$(./concourse-up-linux-amd64 info --cert-expiry --json $DEPLOYMENT |
+            jq '[.[] | select(.source == "config.json" and (.name == "concourse_cert" or (.name | startswith("concourse_cert[")))) | .days_remaining] | min // empty')
+          if [ -z "$days_until_expiry" ]; then
+            echo Not renewing HTTPS cert, as there is no Concourse cert in the config.
+            exit 0
+          fi
           if [ $days_until_expiry -gt 2 ]; then
             echo Not renewing HTTPS cert, as they do not expire in the next two days.
             exit 0
@@ -121,7 +123,12 @@ jobs:
 			pipeline, err := NewGCPPipeline(tempFile.Name())
 			Expect(err).ToNot(HaveOccurred())
 
-			params, err := pipeline.BuildPipelineParams("my-deployment", "prod", "europe-west1", "ci.engineerbetter.com")
+			params, err := pipeline.BuildPipelineParams(config.Config{
+				Deployment: "my-deployment",
+				Namespace:  "prod",
+				Region:     "europe-west1",
+				Domain:     "ci.engineerbetter.com",
+			})
 			Expect(err).ToNot(HaveOccurred())
 
 			yamlBytes, err := util.RenderTemplate("self-update pipeline", pipeline.GetConfigTemplate(), params)
@@ -130,5 +137,55 @@ jobs:
 			actual := string(yamlBytes)
 			Expect(actual).To(Equal(expected))
 		})
+
+		It("Reports self-update outcomes and cert renewal failures when notifications are configured", func() {
+			tempFile, err := ioutil.TempFile("", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			defer os.Remove(tempFile.Name()) // clean up
+
+			pipeline, err := NewGCPPipeline(tempFile.Name())
+			Expect(err).ToNot(HaveOccurred())
+
+			params, err := pipeline.BuildPipelineParams(config.Config{
+				Deployment:       "concourse-up-my-deployment",
+				Namespace:        "prod",
+				Region:           "europe-west1",
+				Domain:           "ci.engineerbetter.com",
+				NotifyWebhook:    "https://hooks.example.com/concourse",
+				NotifyEmail:      "ops@example.com",
+				NotifySMTPServer: "smtp.example.com:587",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			yamlBytes, err := util.RenderTemplate("self-update pipeline", pipeline.GetConfigTemplate(), params)
+			Expect(err).ToNot(HaveOccurred())
+
+			var parsed struct {
+				Jobs []struct {
+					Name      string
+					OnSuccess map[string]interface{} `yaml:"on_success"`
+					OnFailure struct {
+						Task   string
+						Params map[string]string
+					} `yaml:"on_failure"`
+				}
+			}
+			Expect(yaml.Unmarshal(yamlBytes, &parsed)).To(Succeed())
+			Expect(parsed.Jobs).To(HaveLen(2))
+
+			selfUpdate, renewCert := parsed.Jobs[0], parsed.Jobs[1]
+			Expect(selfUpdate.Name).To(Equal("self-update"))
+			Expect(selfUpdate.OnSuccess).To(HaveKeyWithValue("task", "notify"))
+			Expect(selfUpdate.OnFailure.Params).To(HaveKeyWithValue("OUTCOME", "failed"))
+			Expect(selfUpdate.OnFailure.Params).To(HaveKeyWithValue("DEPLOYMENT", "my-deployment"))
+			Expect(selfUpdate.OnFailure.Params).To(HaveKeyWithValue("WEBHOOK", "https://hooks.example.com/concourse"))
+			Expect(selfUpdate.OnFailure.Params).To(HaveKeyWithValue("SMTP_SERVER", "smtp.example.com:587"))
+
+			Expect(renewCert.Name).To(Equal("renew-https-cert"))
+			Expect(renewCert.OnSuccess).To(BeNil())
+			Expect(renewCert.OnFailure.Task).To(Equal("notify"))
+			Expect(renewCert.OnFailure.Params).To(HaveKeyWithValue("JOB", "renew-https-cert"))
+		})
 	})
 })
@@ -1,8 +1,14 @@
 package fly
 
+import (
+	"strings"
+
+	"github.com/EngineerBetter/concourse-up/config"
+)
+
 // Pipeline is interface for self update pipeline
 type Pipeline interface {
-	BuildPipelineParams(deployment, namespace, region, domain string) (Pipeline, error)
+	BuildPipelineParams(config config.Config) (Pipeline, error)
 	GetConfigTemplate() string
 }
 
@@ -12,6 +18,44 @@ type PipelineTemplateParams struct {
 	Domain             string
 	Namespace          string
 	Region             string
+	PreRelease         bool
+	SemverConstraint   string
+	Approval           bool
+	WindowStart        string
+	WindowStop         string
+	NotifySlackWebhook string
+	NotifyWebhook      string
+	NotifyEmail        string
+	NotifySMTPServer   string
+	NotifySMTPUsername string
+	NotifySMTPPassword string
+}
+
+func newPipelineTemplateParams(config config.Config) PipelineTemplateParams {
+	var windowStart, windowStop string
+	if window := strings.SplitN(config.MaintenanceWindow, "-", 2); len(window) == 2 {
+		windowStart, windowStop = window[0], window[1]
+	}
+
+	return PipelineTemplateParams{
+		ConcourseUpVersion: ConcourseUpVersion,
+		Deployment:         strings.TrimPrefix(config.Deployment, "concourse-up-"),
+		Domain:             config.Domain,
+		Namespace:          config.Namespace,
+		Region:             config.Region,
+		// Deployments from before channels existed have always followed pre-releases
+		PreRelease:         config.SelfUpdateChannel != "stable",
+		SemverConstraint:   config.SelfUpdateVersionRange,
+		Approval:           config.SelfUpdateApproval,
+		WindowStart:        windowStart,
+		WindowStop:         windowStop,
+		NotifySlackWebhook: config.NotifySlackWebhook,
+		NotifyWebhook:      config.NotifyWebhook,
+		NotifyEmail:        config.NotifyEmail,
+		NotifySMTPServer:   config.NotifySMTPServer,
+		NotifySMTPUsername: config.NotifySMTPUsername,
+		NotifySMTPPassword: config.NotifySMTPPassword,
+	}
 }
 
 const selfUpdateResources = `
@@ -21,19 +65,103 @@ resources:
   source:
     user: engineerbetter
     repository: concourse-up
-    pre_release: true
+    pre_release: {{ .PreRelease }}
+{{- if .SemverConstraint }}
+    semver_constraint: "{{ .SemverConstraint }}"
+{{- end }}
 - name: every-day
   type: time
-  source: {interval: 24h}
+  source: {interval: 24h{{ if .WindowStart }}, start: "{{ .WindowStart }}", stop: "{{ .WindowStop }}", location: UTC{{ end }}}
 `
 
+const approvalJob = `
+{{- if .Approval }}
+- name: approve-self-update
+  plan:
+  - get: concourse-up-release
+{{- end }}`
+
+const selfUpdateGet = `
+  - get: concourse-up-release
+    trigger: true
+{{- if .Approval }}
+    passed: [approve-self-update]
+{{- end }}`
+
+// Daily cert checks that don't need a renewal also succeed, so renew-https-cert only reports failures
+const selfUpdateNotifyHooks = `
+{{- if or .NotifySlackWebhook .NotifyWebhook .NotifyEmail }}
+  on_success:
+    task: notify
+    params:
+      JOB: self-update
+      OUTCOME: succeeded` + notifyTask + `
+  on_failure:
+    task: notify
+    params:
+      JOB: self-update
+      OUTCOME: failed` + notifyTask + `
+{{- end }}`
+
+const renewCertNotifyHooks = `
+{{- if or .NotifySlackWebhook .NotifyWebhook .NotifyEmail }}
+  on_failure:
+    task: notify
+    params:
+      JOB: renew-https-cert
+      OUTCOME: failed` + notifyTask + `
+{{- end }}`
+
+// notifyTask sends the same messages and payloads as the CLI's notify package
+const notifyTask = `
+      DEPLOYMENT: "{{ .Deployment }}"
+      SLACK_WEBHOOK: "{{ .NotifySlackWebhook }}"
+      WEBHOOK: "{{ .NotifyWebhook }}"
+      EMAIL: "{{ .NotifyEmail }}"
+      SMTP_SERVER: "{{ .NotifySMTPServer }}"
+      SMTP_USERNAME: "{{ .NotifySMTPUsername }}"
+      SMTP_PASSWORD: "{{ .NotifySMTPPassword }}"
+    config:
+      platform: linux
+      image_resource:
+        type: docker-image
+        source:
+          repository: engineerbetter/pcf-ops
+      run:
+        path: bash
+        args:
+        - -c
+        - |
+          set -euo pipefail
+          summary="concourse-up $JOB of $DEPLOYMENT $OUTCOME"
+
+          if [ -n "$SLACK_WEBHOOK" ]; then
+            jq -n --arg text "$summary" '{text: $text}' |
+              curl -fsS -H 'Content-Type: application/json' --data @- "$SLACK_WEBHOOK"
+          fi
+
+          if [ -n "$WEBHOOK" ]; then
+            jq -n --arg text "$summary" --arg deployment "$DEPLOYMENT" --arg action "$JOB" --arg outcome "$OUTCOME" \
+              '{text: $text, deployment: $deployment, action: $action, outcome: $outcome}' |
+              curl -fsS -H 'Content-Type: application/json' --data @- "$WEBHOOK"
+          fi
+
+          if [ -n "$EMAIL" ]; then
+            printf 'From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n' "$EMAIL" "$EMAIL" "$summary" "$summary" > mail.txt
+            if [ -n "$SMTP_USERNAME" ]; then
+              curl -fsS --ssl "smtp://$SMTP_SERVER" --mail-from "$EMAIL" --mail-rcpt "$EMAIL" --upload-file mail.txt --user "$SMTP_USERNAME:$SMTP_PASSWORD"
+            else
+              curl -fsS --ssl "smtp://$SMTP_SERVER" --mail-from "$EMAIL" --mail-rcpt "$EMAIL" --upload-file mail.txt
+            fi
+          fi`
+
 const renewCertsDateCheck = `
-          now_seconds=$(date +%s)
-          not_after=$(echo | openssl s_client -connect {{.Domain}}:443 2>/dev/null | openssl x509 -noout -enddate)
-          expires_on=${not_after#'notAfter='}
-          expires_on_seconds=$(date --date="$expires_on" +%s)
-          let "seconds_until_expiry = $expires_on_seconds - $now_seconds"
-          let "days_until_expiry = $seconds_until_expiry / 60 / 60 / 24"
+          days_until_expiry=$(./concourse-up-linux-amd64 info --cert-expiry --json $DEPLOYMENT |
+            jq '[.[] | select(.source == "config.json" and (.name == "concourse_cert" or (.name | startswith("concourse_cert[")))) | .days_remaining] | min // empty')
+          if [ -z "$days_until_expiry" ]; then
+            echo Not renewing HTTPS cert, as there is no Concourse cert in the config.
+            exit 0
+          fi
           if [ $days_until_expiry -gt 2 ]; then
             echo Not renewing HTTPS cert, as they do not expire in the next two days.
             exit 0
@@ -1,14 +1,19 @@
 package iaas
 
 import (
+	"encoding/xml"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/rds"
 	"github.com/aws/aws-sdk-go/service/route53"
 )
 
@@ -36,6 +41,9 @@ type IEC2 interface {
 }
 
 func newAWS(region string) (Provider, error) {
+	if err := resolveAWSAuth(); err != nil {
+		return nil, err
+	}
 	sess, err := session.NewSession(&aws.Config{
 		Region: aws.String(region),
 	})
@@ -130,10 +138,13 @@ func (a *AWSProvider) listZones() ([]string, error) {
 	return zones, nil
 }
 
-// CheckForWhitelistedIP checks if the specified IP is whitelisted in the security group
+// CheckForWhitelistedIP checks if the specified IP or CIDR range is whitelisted in the security group
 func (a *AWSProvider) CheckForWhitelistedIP(ip, securityGroup string) (bool, error) {
 
-	parsedIP := net.ParseIP(ip)
+	parsedIP, err := parseIPOrCIDR(ip)
+	if err != nil {
+		return false, err
+	}
 
 	ec2Client := ec2.New(a.sess)
 
@@ -166,8 +177,8 @@ func (a *AWSProvider) CheckForWhitelistedIP(ip, securityGroup string) (bool, err
 	return false, nil
 }
 
-func checkPorts(cidr *net.IPNet, ip net.IP, port22, port6868, port25555 *bool, fromPort int64) {
-	if cidr.Contains(ip) {
+func checkPorts(cidr *net.IPNet, ip *net.IPNet, port22, port6868, port25555 *bool, fromPort int64) {
+	if cidrContains(cidr, ip) {
 		switch fromPort {
 		case 22:
 			*port22 = true
@@ -376,3 +387,146 @@ func (a *AWSProvider) FindLongestMatchingHostedZone(subdomain string) (string, s
 func (a *AWSProvider) CreateDatabases(name, username, password string) error {
 	return fmt.Errorf("Not implemented yet")
 }
+
+// ExportDatabases is not needed on AWS, where RDS takes snapshots of the whole instance
+func (a *AWSProvider) ExportDatabases(instance, bucket, path string, databases []string) error {
+	return fmt.Errorf("Not implemented yet")
+}
+
+// ImportDatabases is not needed on AWS, where RDS restores the whole instance from a snapshot
+func (a *AWSProvider) ImportDatabases(instance, user, bucket, path string, databases []string) error {
+	return fmt.Errorf("Not implemented yet")
+}
+
+// SetDBMaxStorage turns on RDS storage autoscaling up to gb, or turns it off with 0 by setting
+// the maximum to the storage the instance has. The terraform provider and SDK concourse-up use
+// predate MaxAllocatedStorage, so it is read from the raw response and added to the request by hand
+func (a *AWSProvider) SetDBMaxStorage(instance string, gb int) error {
+	svc := rds.New(a.sess)
+	var current struct {
+		AllocatedStorage    int `xml:"DescribeDBInstancesResult>DBInstances>DBInstance>AllocatedStorage"`
+		MaxAllocatedStorage int `xml:"DescribeDBInstancesResult>DBInstances>DBInstance>MaxAllocatedStorage"`
+	}
+	describe, _ := svc.DescribeDBInstancesRequest(&rds.DescribeDBInstancesInput{
+		DBInstanceIdentifier: aws.String(instance),
+	})
+	describe.Handlers.Unmarshal.Clear()
+	describe.Handlers.Unmarshal.PushBack(func(r *request.Request) {
+		defer r.HTTPResponse.Body.Close()
+		r.Error = xml.NewDecoder(r.HTTPResponse.Body).Decode(&current)
+	})
+	if err := describe.Send(); err != nil {
+		return fmt.Errorf("error finding RDS instance %s: [%v]", instance, err)
+	}
+
+	want := gb
+	if gb == 0 {
+		if current.MaxAllocatedStorage == 0 {
+			return nil
+		}
+		want = current.AllocatedStorage
+	}
+	if current.MaxAllocatedStorage == want {
+		return nil
+	}
+
+	modify, _ := svc.ModifyDBInstanceRequest(&rds.ModifyDBInstanceInput{
+		ApplyImmediately:     aws.Bool(true),
+		DBInstanceIdentifier: aws.String(instance),
+	})
+	modify.Handlers.Build.PushBack(func(r *request.Request) {
+		if r.Error != nil {
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			r.Error = err
+			return
+		}
+		r.SetBufferBody(append(body, fmt.Sprintf("&MaxAllocatedStorage=%d", want)...))
+	})
+	if err := modify.Send(); err != nil {
+		return fmt.Errorf("failed to set the maximum storage of %s: [%v]", instance, err)
+	}
+	return nil
+}
+
+// CopyDBSnapshot copies an RDS snapshot from another region into this one, keeping its name,
+// and returns once the copy can be restored from. A copy left by an earlier, interrupted run
+// is only waited on
+func (a *AWSProvider) CopyDBSnapshot(sourceRegion, snapshot string) error {
+	svc := rds.New(a.sess)
+	wait := &rds.DescribeDBSnapshotsInput{
+		DBSnapshotIdentifier: aws.String(snapshot),
+	}
+	target, err := svc.DescribeDBSnapshots(wait)
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == rds.ErrCodeDBSnapshotNotFoundFault {
+		err = nil
+	}
+	if err != nil {
+		return fmt.Errorf("error finding snapshot %s in %s: [%v]", snapshot, a.Region(), err)
+	}
+	if len(target.DBSnapshots) > 0 {
+		return svc.WaitUntilDBSnapshotAvailable(wait)
+	}
+
+	source, err := rds.New(a.sess, aws.NewConfig().WithRegion(sourceRegion)).DescribeDBSnapshots(&rds.DescribeDBSnapshotsInput{
+		DBSnapshotIdentifier: aws.String(snapshot),
+	})
+	if err != nil {
+		return fmt.Errorf("error finding snapshot %s in %s: [%v]", snapshot, sourceRegion, err)
+	}
+	if len(source.DBSnapshots) == 0 {
+		return fmt.Errorf("snapshot %s not found in %s", snapshot, sourceRegion)
+	}
+
+	_, err = svc.CopyDBSnapshot(&rds.CopyDBSnapshotInput{
+		CopyTags:                   aws.Bool(true),
+		SourceDBSnapshotIdentifier: source.DBSnapshots[0].DBSnapshotArn,
+		SourceRegion:               aws.String(sourceRegion),
+		TargetDBSnapshotIdentifier: aws.String(snapshot),
+	})
+	if err != nil {
+		return fmt.Errorf("error copying snapshot %s from %s: [%v]", snapshot, sourceRegion, err)
+	}
+	return svc.WaitUntilDBSnapshotAvailable(wait)
+}
+
+// DatabaseVersion is not needed on AWS, where RDS can be queried through the director
+func (a *AWSProvider) DatabaseVersion(instance string) (string, error) {
+	return "", fmt.Errorf("Not implemented yet")
+}
+
+// ValidateInstanceType checks EC2 offers the instance type in the zone. Every type a zone runs
+// has reserved instance offerings, which is also how Zone finds a zone for the workers
+func (a *AWSProvider) ValidateInstanceType(instanceType, zone string) error {
+	o, err := ec2.New(a.sess).DescribeReservedInstancesOfferings(&ec2.DescribeReservedInstancesOfferingsInput{
+		AvailabilityZone:   aws.String(zone),
+		IncludeMarketplace: aws.Bool(false),
+		InstanceType:       aws.String(instanceType),
+	})
+	if err != nil {
+		return fmt.Errorf("error checking instance type %s: [%v]", instanceType, err)
+	}
+	if len(o.ReservedInstancesOfferings) == 0 {
+		return fmt.Errorf("instance type %s is not offered in %s", instanceType, zone)
+	}
+	return nil
+}
+
+// ValidateDBInstanceType checks RDS offers the instance class for the version of PostgreSQL in the region
+func (a *AWSProvider) ValidateDBInstanceType(instanceClass, engineVersion string) error {
+	o, err := rds.New(a.sess).DescribeOrderableDBInstanceOptions(&rds.DescribeOrderableDBInstanceOptionsInput{
+		DBInstanceClass: aws.String(instanceClass),
+		Engine:          aws.String("postgres"),
+		EngineVersion:   aws.String(engineVersion),
+		MaxRecords:      aws.Int64(20),
+	})
+	if err != nil {
+		return fmt.Errorf("error checking RDS instance class %s: [%v]", instanceClass, err)
+	}
+	if len(o.OrderableDBInstanceOptions) == 0 {
+		return fmt.Errorf("RDS instance class %s is not offered for PostgreSQL %s in %s", instanceClass, engineVersion, a.Region())
+	}
+	return nil
+}
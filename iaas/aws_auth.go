@@ -0,0 +1,215 @@
+package iaas
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// AWSAuth describes how to obtain AWS credentials when the default chain isn't enough
+type AWSAuth struct {
+	Profile    string
+	RoleARN    string
+	ExternalID string
+	MFASerial  string
+	MFAToken   string
+}
+
+// AWSCredentials are the credentials the self-update pipeline uses to act as the deployer
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// awsSessionDuration is how long assumed role and MFA credentials are requested for. They're
+// exported once and not refreshed, so they must outlast a whole deploy
+const awsSessionDuration = 12 * time.Hour
+
+// awsDefaultMaxSessionDuration is the longest a role can be assumed for unless its maximum
+// session duration has been raised, and the most AWS allows when chaining roles
+const awsDefaultMaxSessionDuration = time.Hour
+
+var (
+	awsAuth         AWSAuth
+	awsAuthOnce     sync.Once
+	awsAuthErr      error
+	awsBaseCreds    credentials.Value
+	awsMFATokenFunc = stscreds.StdinTokenProvider
+)
+
+// UseAWSAuth sets the profile, role and MFA device used the first time an AWS provider is created
+func UseAWSAuth(auth AWSAuth) {
+	awsAuth = auth
+}
+
+func (a AWSAuth) isSet() bool {
+	return a.Profile != "" || a.RoleARN != "" || a.ExternalID != "" || a.MFASerial != ""
+}
+
+// resolveAWSAuth exchanges the configured profile, role and MFA device for a single set of
+// credentials and exports them in the standard AWS environment variables, so that terraform,
+// bosh and lego's Route53 provider act as the same identity as the SDK clients in this package
+func resolveAWSAuth() error {
+	awsAuthOnce.Do(func() {
+		if !awsAuth.isSet() {
+			return
+		}
+		if awsAuth.ExternalID != "" && awsAuth.RoleARN == "" {
+			awsAuthErr = errors.New("--external-id requires --assume-role-arn to also be provided")
+			return
+		}
+
+		sess, err := session.NewSessionWithOptions(session.Options{
+			Profile:                 awsAuth.Profile,
+			SharedConfigState:       session.SharedConfigEnable,
+			AssumeRoleTokenProvider: awsMFATokenFunc,
+		})
+		if err != nil {
+			awsAuthErr = err
+			return
+		}
+		awsBaseCreds, err = sess.Config.Credentials.Get()
+		if err != nil {
+			awsAuthErr = fmt.Errorf("error loading AWS credentials: [%v]", err)
+			return
+		}
+
+		creds, err := assumeAWSIdentity(sess)
+		if err != nil {
+			awsAuthErr = err
+			return
+		}
+		awsAuthErr = exportAWSCredentials(creds)
+	})
+	return awsAuthErr
+}
+
+func assumeAWSIdentity(sess *session.Session) (credentials.Value, error) {
+	if awsAuth.RoleARN != "" {
+		creds, err := assumeAWSRole(sess, awsSessionDuration)
+		if isDurationTooLong(err) {
+			creds, err = assumeAWSRole(sess, awsDefaultMaxSessionDuration)
+		}
+		if err != nil {
+			return creds, fmt.Errorf("error assuming role %s: [%v]", awsAuth.RoleARN, err)
+		}
+		return creds, nil
+	}
+
+	if awsAuth.MFASerial != "" {
+		token := awsAuth.MFAToken
+		if token == "" {
+			var err error
+			if token, err = awsMFATokenFunc(); err != nil {
+				return credentials.Value{}, err
+			}
+		}
+		out, err := sts.New(sess).GetSessionToken(&sts.GetSessionTokenInput{
+			DurationSeconds: aws.Int64(int64(awsSessionDuration.Seconds())),
+			SerialNumber:    aws.String(awsAuth.MFASerial),
+			TokenCode:       aws.String(token),
+		})
+		if err != nil {
+			return credentials.Value{}, fmt.Errorf("error getting MFA session token: [%v]", err)
+		}
+		return credentials.Value{
+			AccessKeyID:     aws.StringValue(out.Credentials.AccessKeyId),
+			SecretAccessKey: aws.StringValue(out.Credentials.SecretAccessKey),
+			SessionToken:    aws.StringValue(out.Credentials.SessionToken),
+		}, nil
+	}
+
+	return awsBaseCreds, nil
+}
+
+// isDurationTooLong returns true when STS refused a session because the role's maximum
+// session duration is shorter than the one requested
+func isDurationTooLong(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == "ValidationError" && strings.Contains(aerr.Message(), "DurationSeconds")
+	}
+	return false
+}
+
+func assumeAWSRole(sess *session.Session, duration time.Duration) (credentials.Value, error) {
+	return stscreds.NewCredentials(sess, awsAuth.RoleARN, func(p *stscreds.AssumeRoleProvider) {
+		p.Duration = duration
+		p.RoleSessionName = "concourse-up"
+		if awsAuth.ExternalID != "" {
+			p.ExternalID = aws.String(awsAuth.ExternalID)
+		}
+		if awsAuth.MFASerial != "" {
+			p.SerialNumber = aws.String(awsAuth.MFASerial)
+			if awsAuth.MFAToken != "" {
+				p.TokenCode = aws.String(awsAuth.MFAToken)
+			} else {
+				p.TokenProvider = awsMFATokenFunc
+			}
+		}
+	}).Get()
+}
+
+func exportAWSCredentials(creds credentials.Value) error {
+	env := map[string]string{
+		"AWS_ACCESS_KEY_ID":     creds.AccessKeyID,
+		"AWS_SECRET_ACCESS_KEY": creds.SecretAccessKey,
+	}
+	for k, v := range env {
+		if err := os.Setenv(k, v); err != nil {
+			return err
+		}
+	}
+	if creds.SessionToken == "" {
+		return os.Unsetenv("AWS_SESSION_TOKEN")
+	}
+	return os.Setenv("AWS_SESSION_TOKEN", creds.SessionToken)
+}
+
+// AWSSelfUpdateCredentials returns the long-lived credentials of the profile in use, for a
+// self-update pipeline whose workers have no instance profile to act through
+func AWSSelfUpdateCredentials() (AWSCredentials, error) {
+	if err := resolveAWSAuth(); err != nil {
+		return AWSCredentials{}, err
+	}
+
+	if !awsAuth.isSet() {
+		sess, err := session.NewSession()
+		if err != nil {
+			return AWSCredentials{}, err
+		}
+		creds, err := sess.Config.Credentials.Get()
+		if err != nil {
+			return AWSCredentials{}, err
+		}
+		return selfUpdateCredentials(creds)
+	}
+
+	return selfUpdateCredentials(awsBaseCreds)
+}
+
+// selfUpdateCredentials refuses temporary credentials, which would expire long before the
+// pipeline next runs
+func selfUpdateCredentials(creds credentials.Value) (AWSCredentials, error) {
+	if creds.SessionToken != "" {
+		return AWSCredentials{}, errors.New("the self-update pipeline can't use temporary AWS credentials, as they expire before it runs. Deploy with --assume-role-arn so that it assumes that role from the workers' instance profile, or with long-lived access keys")
+	}
+	return AWSCredentials{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+	}, nil
+}
+
+// AWSAssumedRole returns the role and external ID given with --assume-role-arn and --external-id
+func AWSAssumedRole() (string, string) {
+	return awsAuth.RoleARN, awsAuth.ExternalID
+}
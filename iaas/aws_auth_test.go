@@ -0,0 +1,79 @@
+package iaas
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+func TestResolveAWSAuth_ExternalIDRequiresRole(t *testing.T) {
+	defer func() { awsAuth, awsAuthOnce, awsAuthErr = AWSAuth{}, sync.Once{}, nil }()
+	UseAWSAuth(AWSAuth{ExternalID: "external-id"})
+	awsAuthOnce = sync.Once{}
+
+	err := resolveAWSAuth()
+	if err == nil || err.Error() != "--external-id requires --assume-role-arn to also be provided" {
+		t.Errorf("resolveAWSAuth() error = %v", err)
+	}
+}
+
+func TestExportAWSCredentials(t *testing.T) {
+	for _, k := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN"} {
+		defer os.Setenv(k, os.Getenv(k))
+	}
+
+	err := exportAWSCredentials(credentials.Value{AccessKeyID: "id", SecretAccessKey: "secret", SessionToken: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if os.Getenv("AWS_ACCESS_KEY_ID") != "id" || os.Getenv("AWS_SECRET_ACCESS_KEY") != "secret" || os.Getenv("AWS_SESSION_TOKEN") != "token" {
+		t.Errorf("assumed credentials were not exported")
+	}
+
+	err = exportAWSCredentials(credentials.Value{AccessKeyID: "id", SecretAccessKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := os.LookupEnv("AWS_SESSION_TOKEN"); ok {
+		t.Errorf("a stale session token was left behind for long-lived credentials")
+	}
+}
+
+func TestSelfUpdateCredentials(t *testing.T) {
+	creds, err := selfUpdateCredentials(credentials.Value{AccessKeyID: "id", SecretAccessKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds != (AWSCredentials{AccessKeyID: "id", SecretAccessKey: "secret"}) {
+		t.Errorf("selfUpdateCredentials() = %+v", creds)
+	}
+
+	_, err = selfUpdateCredentials(credentials.Value{AccessKeyID: "id", SecretAccessKey: "secret", SessionToken: "token"})
+	if err == nil || !strings.Contains(err.Error(), "--assume-role-arn") {
+		t.Errorf("selfUpdateCredentials() error = %v, want temporary credentials refused", err)
+	}
+}
+
+func TestIsDurationTooLong(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"no error", nil, false},
+		{"role maximum exceeded", awserr.New("ValidationError", "The requested DurationSeconds exceeds the MaxSessionDuration set for this role.", nil), true},
+		{"other validation error", awserr.New("ValidationError", "1 validation error detected", nil), false},
+		{"access denied", awserr.New("AccessDenied", "not authorized to perform sts:AssumeRole", nil), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDurationTooLong(tt.err); got != tt.want {
+				t.Errorf("isDurationTooLong() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
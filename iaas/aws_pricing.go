@@ -0,0 +1,237 @@
+package iaas
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// awsPricingURL is the AWS Price List API. It is only served from us-east-1 and ap-south-1,
+// whichever region is being priced
+var awsPricingURL = "https://api.pricing.us-east-1.amazonaws.com/"
+
+const awsPricingRegion = "us-east-1"
+
+// spotBidMargin is how far above the highest recent spot price bids are made when the
+// on-demand price can't be found
+const spotBidMargin = 2
+
+type awsPriceFilter struct {
+	Type  string
+	Field string
+	Value string
+}
+
+type awsGetProductsInput struct {
+	ServiceCode   string
+	Filters       []awsPriceFilter
+	FormatVersion string
+	MaxResults    int
+	NextToken     string `json:",omitempty"`
+}
+
+type awsGetProductsOutput struct {
+	NextToken *string
+	PriceList []string
+}
+
+// awsPriceListItem is the part of a price list entry needed to read on-demand prices
+type awsPriceListItem struct {
+	Terms struct {
+		OnDemand map[string]struct {
+			PriceDimensions map[string]struct {
+				Unit         string
+				PricePerUnit map[string]string
+			}
+		}
+	}
+}
+
+// awsPrice finds the on-demand USD price per unit of the first product in a service matching
+// all of the filters. The Price List API has no SDK in the vendored aws-sdk-go, so requests
+// are signed and sent by hand
+func (a *AWSProvider) awsPrice(serviceCode, unit string, filters map[string]string) (float64, error) {
+	input := awsGetProductsInput{
+		ServiceCode:   serviceCode,
+		FormatVersion: "aws_v1",
+		MaxResults:    100,
+	}
+	fields := make([]string, 0, len(filters))
+	for field := range filters {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		input.Filters = append(input.Filters, awsPriceFilter{Type: "TERM_MATCH", Field: field, Value: filters[field]})
+	}
+
+	signer := v4.NewSigner(a.sess.Config.Credentials)
+	for {
+		body, err := json.Marshal(input)
+		if err != nil {
+			return 0, err
+		}
+		req, err := http.NewRequest("POST", awsPricingURL, nil)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+		req.Header.Set("X-Amz-Target", "AWSPriceListService.GetProducts")
+		if _, err = signer.Sign(req, bytes.NewReader(body), "pricing", awsPricingRegion, time.Now()); err != nil {
+			return 0, err
+		}
+		req.ContentLength = int64(len(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		respBody, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return 0, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return 0, fmt.Errorf("error fetching %s prices: %s", serviceCode, respBody)
+		}
+		var output awsGetProductsOutput
+		if err = json.Unmarshal(respBody, &output); err != nil {
+			return 0, err
+		}
+		for _, entry := range output.PriceList {
+			var item awsPriceListItem
+			if err = json.Unmarshal([]byte(entry), &item); err != nil {
+				return 0, err
+			}
+			for _, term := range item.Terms.OnDemand {
+				for _, dimension := range term.PriceDimensions {
+					if dimension.Unit != unit {
+						continue
+					}
+					price, err := strconv.ParseFloat(dimension.PricePerUnit["USD"], 64)
+					if err == nil && price > 0 {
+						return price, nil
+					}
+				}
+			}
+		}
+		if aws.StringValue(output.NextToken) == "" {
+			return 0, fmt.Errorf("no %s price found for %v", serviceCode, filters)
+		}
+		input.NextToken = aws.StringValue(output.NextToken)
+	}
+}
+
+// spotPrices returns the most recent and the highest spot prices for an instance type in the
+// zone since a point in time
+func (a *AWSProvider) spotPrices(instanceType, zone string, since time.Time) (latest, highest float64, err error) {
+	var latestTime time.Time
+	err = ec2.New(a.sess).DescribeSpotPriceHistoryPages(&ec2.DescribeSpotPriceHistoryInput{
+		AvailabilityZone:    aws.String(zone),
+		InstanceTypes:       []*string{aws.String(instanceType)},
+		ProductDescriptions: []*string{aws.String("Linux/UNIX")},
+		StartTime:           aws.Time(since),
+	}, func(page *ec2.DescribeSpotPriceHistoryOutput, lastPage bool) bool {
+		for _, p := range page.SpotPriceHistory {
+			price, err := strconv.ParseFloat(aws.StringValue(p.SpotPrice), 64)
+			if err != nil {
+				continue
+			}
+			if price > highest {
+				highest = price
+			}
+			if t := aws.TimeValue(p.Timestamp); t.After(latestTime) {
+				latestTime = t
+				latest = price
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("error fetching spot prices for %s: [%v]", instanceType, err)
+	}
+	if highest == 0 {
+		return 0, 0, fmt.Errorf("no spot prices found for %s in %s", instanceType, zone)
+	}
+	return latest, highest, nil
+}
+
+// SpotBidPrice returns a bid for spot instances of a type in the zone. Spot instances never
+// cost more than on-demand ones, so bidding the on-demand price only loses an instance when
+// capacity runs out. If the Price List API can't be used, the bid is twice the highest spot
+// price there over the last week. Either is rounded up to the cent
+func (a *AWSProvider) SpotBidPrice(instanceType, zone string) (float64, error) {
+	price, err := a.InstancePrice(instanceType, zone, false)
+	if err != nil {
+		_, highest, spotErr := a.spotPrices(instanceType, zone, time.Now().Add(-7*24*time.Hour))
+		if spotErr != nil {
+			return 0, spotErr
+		}
+		price = highest * spotBidMargin
+	}
+	return math.Ceil(price*100) / 100, nil
+}
+
+// InstancePrice returns the hourly price of a Linux instance in the zone, either on-demand
+// or the current spot price
+func (a *AWSProvider) InstancePrice(instanceType, zone string, spot bool) (float64, error) {
+	if spot {
+		latest, _, err := a.spotPrices(instanceType, zone, time.Now().Add(-time.Hour))
+		return latest, err
+	}
+	return a.awsPrice("AmazonEC2", "Hrs", map[string]string{
+		"capacitystatus":  "Used",
+		"instanceType":    instanceType,
+		"operatingSystem": "Linux",
+		"preInstalledSw":  "NA",
+		"regionCode":      a.Region(),
+		"tenancy":         "Shared",
+	})
+}
+
+// DBInstancePrice returns the hourly price of a single-AZ PostgreSQL RDS instance
+func (a *AWSProvider) DBInstancePrice(instanceClass string) (float64, error) {
+	return a.awsPrice("AmazonRDS", "Hrs", map[string]string{
+		"databaseEngine":   "PostgreSQL",
+		"deploymentOption": "Single-AZ",
+		"instanceType":     instanceClass,
+		"regionCode":       a.Region(),
+	})
+}
+
+// DBStoragePrice returns the monthly price of a GB of general purpose RDS storage
+func (a *AWSProvider) DBStoragePrice() (float64, error) {
+	return a.awsPrice("AmazonRDS", "GB-Mo", map[string]string{
+		"databaseEngine":   "PostgreSQL",
+		"deploymentOption": "Single-AZ",
+		"productFamily":    "Database Storage",
+		"regionCode":       a.Region(),
+		"volumeType":       "General Purpose",
+	})
+}
+
+// DiskPrice returns the monthly price of a GB of EBS volume of a type such as gp2
+func (a *AWSProvider) DiskPrice(diskType string) (float64, error) {
+	return a.awsPrice("AmazonEC2", "GB-Mo", map[string]string{
+		"productFamily": "Storage",
+		"regionCode":    a.Region(),
+		"volumeApiName": diskType,
+	})
+}
+
+// NATGatewayPrice returns the hourly price of a NAT gateway, not counting the data it processes
+func (a *AWSProvider) NATGatewayPrice() (float64, error) {
+	return a.awsPrice("AmazonEC2", "Hrs", map[string]string{
+		"productFamily": "NAT Gateway",
+		"regionCode":    a.Region(),
+	})
+}
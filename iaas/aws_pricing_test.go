@@ -0,0 +1,160 @@
+package iaas
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// newPricingTestProvider serves recorded Price List and EC2 responses for eu-west-1. The Price
+// List API answers with pricingFixture, or a 400 when it is empty
+func newPricingTestProvider(t *testing.T, pricingFixture string) (*AWSProvider, *[]awsGetProductsInput, func()) {
+	var requests []awsGetProductsInput
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") == "AWSPriceListService.GetProducts" {
+			if !strings.Contains(r.Header.Get("Authorization"), "/us-east-1/pricing/aws4_request") {
+				t.Errorf("expected the request to be signed for pricing in us-east-1, got %q", r.Header.Get("Authorization"))
+			}
+			var input awsGetProductsInput
+			if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+				t.Fatal(err)
+			}
+			requests = append(requests, input)
+			if pricingFixture == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"__type":"AccessDeniedException","Message":"User is not authorized to perform: pricing:GetProducts"}`))
+				return
+			}
+			w.Write(loadFixture(t, pricingFixture))
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.Form.Get("Action") != "DescribeSpotPriceHistory" {
+			t.Fatalf("unexpected EC2 action %q", r.Form.Get("Action"))
+		}
+		w.Write(loadFixture(t, "aws_spot_price_history.xml"))
+	}))
+
+	oldURL := awsPricingURL
+	awsPricingURL = server.URL
+	sess := session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("eu-west-1"),
+		Endpoint:    aws.String(server.URL),
+		Credentials: credentials.NewStaticCredentials("access-key-id", "secret-access-key", ""),
+	}))
+	return &AWSProvider{sess: sess}, &requests, func() {
+		awsPricingURL = oldURL
+		server.Close()
+	}
+}
+
+func loadFixture(t *testing.T, name string) []byte {
+	contents, err := ioutil.ReadFile("fixtures/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return contents
+}
+
+func filterValue(input awsGetProductsInput, field string) string {
+	for _, f := range input.Filters {
+		if f.Field == field {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+func TestAWSProvider_InstancePrice(t *testing.T) {
+	t.Run("returns the on-demand price", func(t *testing.T) {
+		a, requests, cleanup := newPricingTestProvider(t, "aws_pricing_m4_xlarge.json")
+		defer cleanup()
+
+		price, err := a.InstancePrice("m4.xlarge", "eu-west-1a", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if price != 0.222 {
+			t.Errorf("expected 0.222, got %v", price)
+		}
+		input := (*requests)[0]
+		if input.ServiceCode != "AmazonEC2" || filterValue(input, "instanceType") != "m4.xlarge" || filterValue(input, "regionCode") != "eu-west-1" {
+			t.Errorf("unexpected query %+v", input)
+		}
+	})
+
+	t.Run("returns the latest spot price", func(t *testing.T) {
+		a, _, cleanup := newPricingTestProvider(t, "")
+		defer cleanup()
+
+		price, err := a.InstancePrice("m4.xlarge", "eu-west-1a", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if price != 0.0671 {
+			t.Errorf("expected 0.0671, got %v", price)
+		}
+	})
+}
+
+func TestAWSProvider_SpotBidPrice(t *testing.T) {
+	t.Run("bids the on-demand price rounded up to the cent", func(t *testing.T) {
+		a, _, cleanup := newPricingTestProvider(t, "aws_pricing_m4_xlarge.json")
+		defer cleanup()
+
+		bid, err := a.SpotBidPrice("m4.xlarge", "eu-west-1a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bid != 0.23 {
+			t.Errorf("expected 0.23, got %v", bid)
+		}
+	})
+
+	t.Run("falls back to twice the highest spot price when prices can't be looked up", func(t *testing.T) {
+		a, _, cleanup := newPricingTestProvider(t, "")
+		defer cleanup()
+
+		bid, err := a.SpotBidPrice("m4.xlarge", "eu-west-1a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bid != 0.17 {
+			t.Errorf("expected 0.17, got %v", bid)
+		}
+	})
+}
+
+func TestAWSProvider_NATGatewayPrice(t *testing.T) {
+	a, requests, cleanup := newPricingTestProvider(t, "aws_pricing_nat_gateway.json")
+	defer cleanup()
+
+	price, err := a.NATGatewayPrice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price != 0.048 {
+		t.Errorf("expected 0.048, got %v", price)
+	}
+	if filterValue((*requests)[0], "productFamily") != "NAT Gateway" {
+		t.Errorf("unexpected query %+v", (*requests)[0])
+	}
+}
+
+func TestAWSProvider_awsPrice(t *testing.T) {
+	a, _, cleanup := newPricingTestProvider(t, "aws_pricing_nat_gateway.json")
+	defer cleanup()
+
+	if _, err := a.awsPrice("AmazonEC2", "GB-Mo", map[string]string{"productFamily": "NAT Gateway"}); err == nil {
+		t.Error("expected an error when no price is in the requested unit")
+	}
+}
@@ -0,0 +1,64 @@
+package iaas
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+func TestAWSProvider_SetDBMaxStorage(t *testing.T) {
+	tests := []struct {
+		name       string
+		gb         int
+		currentMax string
+		wantModify string
+	}{
+		{name: "turns autoscaling on", gb: 100, wantModify: "100"},
+		{name: "leaves the same maximum alone", gb: 100, currentMax: "<MaxAllocatedStorage>100</MaxAllocatedStorage>"},
+		{name: "raises the maximum", gb: 200, currentMax: "<MaxAllocatedStorage>100</MaxAllocatedStorage>", wantModify: "200"},
+		{name: "turns autoscaling off", gb: 0, currentMax: "<MaxAllocatedStorage>100</MaxAllocatedStorage>", wantModify: "20"},
+		{name: "leaves autoscaling off", gb: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var modified string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseForm(); err != nil {
+					t.Fatal(err)
+				}
+				if r.Form.Get("DBInstanceIdentifier") != "terraform-123" {
+					t.Errorf("unexpected DB instance %q", r.Form.Get("DBInstanceIdentifier"))
+				}
+				switch r.Form.Get("Action") {
+				case "DescribeDBInstances":
+					fmt.Fprintf(w, `<DescribeDBInstancesResponse><DescribeDBInstancesResult><DBInstances><DBInstance>
+<AllocatedStorage>20</AllocatedStorage>%s
+</DBInstance></DBInstances></DescribeDBInstancesResult></DescribeDBInstancesResponse>`, tt.currentMax)
+				case "ModifyDBInstance":
+					modified = r.Form.Get("MaxAllocatedStorage")
+					fmt.Fprint(w, `<ModifyDBInstanceResponse><ModifyDBInstanceResult><DBInstance/></ModifyDBInstanceResult></ModifyDBInstanceResponse>`)
+				default:
+					t.Fatalf("unexpected RDS action %q", r.Form.Get("Action"))
+				}
+			}))
+			defer server.Close()
+
+			a := &AWSProvider{sess: session.Must(session.NewSession(&aws.Config{
+				Region:      aws.String("eu-west-1"),
+				Endpoint:    aws.String(server.URL),
+				Credentials: credentials.NewStaticCredentials("access-key-id", "secret-access-key", ""),
+			}))}
+			if err := a.SetDBMaxStorage("terraform-123", tt.gb); err != nil {
+				t.Fatal(err)
+			}
+			if modified != tt.wantModify {
+				t.Errorf("MaxAllocatedStorage = %q, want %q", modified, tt.wantModify)
+			}
+		})
+	}
+}
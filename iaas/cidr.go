@@ -0,0 +1,32 @@
+package iaas
+
+import (
+	"fmt"
+	"net"
+)
+
+// parseIPOrCIDR parses either a bare IP address or a CIDR range. A bare IP
+// is treated as a single-address range.
+func parseIPOrCIDR(s string) (*net.IPNet, error) {
+	if _, ipNet, err := net.ParseCIDR(s); err == nil {
+		return ipNet, nil
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("could not parse %q as an IP address or CIDR range", s)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// cidrContains reports whether every address in inner is also in outer
+func cidrContains(outer, inner *net.IPNet) bool {
+	outerOnes, outerBits := outer.Mask.Size()
+	innerOnes, innerBits := inner.Mask.Size()
+	if outerBits != innerBits || outerOnes > innerOnes {
+		return false
+	}
+	return outer.Contains(inner.IP)
+}
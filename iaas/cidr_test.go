@@ -0,0 +1,48 @@
+package iaas
+
+import "testing"
+
+func TestCIDRContains(t *testing.T) {
+	tests := []struct {
+		name  string
+		outer string
+		inner string
+		want  bool
+	}{
+		{"ip inside range", "10.0.0.0/8", "10.1.2.3", true},
+		{"ip outside range", "10.0.0.0/8", "192.168.0.1", false},
+		{"exact ip", "192.0.2.1/32", "192.0.2.1", true},
+		{"narrower range inside", "10.0.0.0/8", "10.1.0.0/16", true},
+		{"wider range not contained", "10.1.0.0/16", "10.0.0.0/8", false},
+		{"disjoint ranges", "10.0.0.0/16", "10.1.0.0/16", false},
+		{"everything", "0.0.0.0/0", "203.0.113.0/24", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outer, err := parseIPOrCIDR(tt.outer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			inner, err := parseIPOrCIDR(tt.inner)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cidrContains(outer, inner); got != tt.want {
+				t.Errorf("cidrContains(%s, %s) = %v, want %v", tt.outer, tt.inner, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIPOrCIDR(t *testing.T) {
+	if _, err := parseIPOrCIDR("not-an-ip"); err == nil {
+		t.Error("expected an error parsing an invalid address")
+	}
+	ipNet, err := parseIPOrCIDR("192.0.2.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ipNet.String(); got != "192.0.2.7/32" {
+		t.Errorf("got %s, want 192.0.2.7/32", got)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"log"
 	"net"
 	"os"
+	"regexp"
 	"strings"
 	"time"
 
@@ -18,6 +19,7 @@ import (
 	"google.golang.org/api/compute/v1"
 	clouddns "google.golang.org/api/dns/v1"
 	"google.golang.org/api/iterator"
+	sqladmin "google.golang.org/api/sqladmin/v1beta4"
 
 	// PostgreSQL driver required at runtime
 	_ "github.com/GoogleCloudPlatform/cloudsql-proxy/proxy/dialers/postgres"
@@ -29,6 +31,7 @@ type GCPProvider struct {
 	storage GCPStorageClient
 	region  string
 	attrs   map[string]string
+	skus    map[string][]gcpSKU
 }
 
 // GCPOption is the signature of the option function
@@ -63,7 +66,7 @@ func newGCP(region string, ops ...GCPOption) (Provider, error) {
 
 	ctx := context.Background()
 
-	g := &GCPProvider{ctx, &storage.Client{}, region, attrs}
+	g := &GCPProvider{ctx, &storage.Client{}, region, attrs, nil}
 	for _, op := range ops {
 		if err := op(g); err != nil {
 			return nil, err
@@ -273,10 +276,13 @@ func (g *GCPProvider) DeleteVolumes(volumesToDelete []string, deleteVolume func(
 	return errors.New("DeleteVolumes Not Implemented Yet")
 }
 
-// CheckForWhitelistedIP checks if the specified IP is whitelisted in the security group
+// CheckForWhitelistedIP checks if the specified IP or CIDR range is whitelisted in the firewall
 func (g *GCPProvider) CheckForWhitelistedIP(ip, firewallName string) (bool, error) {
 
-	parsedIP := net.ParseIP(ip)
+	parsedIP, err := parseIPOrCIDR(ip)
+	if err != nil {
+		return false, err
+	}
 
 	c, err := google.DefaultClient(g.ctx, compute.CloudPlatformScope)
 	if err != nil {
@@ -311,7 +317,7 @@ func (g *GCPProvider) CheckForWhitelistedIP(ip, firewallName string) (bool, erro
 		if err != nil {
 			return false, err
 		}
-		if parsedCIDR.Contains(parsedIP) {
+		if cidrContains(parsedCIDR, parsedIP) {
 			return true, nil
 		}
 	}
@@ -474,3 +480,213 @@ func (g *GCPProvider) CreateDatabases(name, username, password string) error {
 	}
 	return nil
 }
+
+// CopyDBSnapshot is not needed on GCP, where databases are exported to buckets any region can read
+func (g *GCPProvider) CopyDBSnapshot(sourceRegion, snapshot string) error {
+	return fmt.Errorf("Not implemented yet")
+}
+
+// DatabaseExportURI is where ExportDatabases writes a database, and ImportDatabases reads it from
+func DatabaseExportURI(bucket, path, database string) string {
+	return fmt.Sprintf("gs://%s/%s/%s.sql.gz", bucket, path, database)
+}
+
+// ExportDatabases exports each database on a Cloud SQL instance to a bucket, which outlives
+// the backups Cloud SQL deletes along with the instance
+func (g *GCPProvider) ExportDatabases(instance, bucket, path string, databases []string) error {
+	svc, project, err := g.sqlAdmin()
+	if err != nil {
+		return err
+	}
+	inst, err := svc.Instances.Get(project, instance).Do()
+	if err != nil {
+		return err
+	}
+	// The instance writes the exports itself
+	err = g.storage.Bucket(bucket).ACL().Set(g.ctx, storage.ACLEntity("user-"+inst.ServiceAccountEmailAddress), storage.RoleWriter)
+	if err != nil {
+		return fmt.Errorf("failed to let %s write to %s: [%v]", instance, bucket, err)
+	}
+
+	for _, database := range databases {
+		op, err := svc.Instances.Export(project, instance, &sqladmin.InstancesExportRequest{
+			ExportContext: &sqladmin.ExportContext{
+				Databases: []string{database},
+				FileType:  "SQL",
+				Uri:       DatabaseExportURI(bucket, path, database),
+			},
+		}).Do()
+		if err != nil {
+			return fmt.Errorf("failed to export database %s: [%v]", database, err)
+		}
+		if err = waitForSQLOperation(svc, project, op); err != nil {
+			return fmt.Errorf("failed to export database %s: [%v]", database, err)
+		}
+	}
+	return nil
+}
+
+// ImportDatabases imports databases exported by ExportDatabases into a Cloud SQL instance,
+// as user so that they keep the same owner
+func (g *GCPProvider) ImportDatabases(instance, user, bucket, path string, databases []string) error {
+	svc, project, err := g.sqlAdmin()
+	if err != nil {
+		return err
+	}
+	inst, err := svc.Instances.Get(project, instance).Do()
+	if err != nil {
+		return err
+	}
+
+	for _, database := range databases {
+		object := fmt.Sprintf("%s/%s.sql.gz", path, database)
+		// The instance reads the export itself
+		err = g.storage.Bucket(bucket).Object(object).ACL().Set(g.ctx, storage.ACLEntity("user-"+inst.ServiceAccountEmailAddress), storage.RoleReader)
+		if err != nil {
+			return fmt.Errorf("failed to let %s read %s: [%v]", instance, object, err)
+		}
+		op, err := svc.Instances.Import(project, instance, &sqladmin.InstancesImportRequest{
+			ImportContext: &sqladmin.ImportContext{
+				Database:   database,
+				FileType:   "SQL",
+				ImportUser: user,
+				Uri:        DatabaseExportURI(bucket, path, database),
+			},
+		}).Do()
+		if err != nil {
+			return fmt.Errorf("failed to import database %s: [%v]", database, err)
+		}
+		if err = waitForSQLOperation(svc, project, op); err != nil {
+			return fmt.Errorf("failed to import database %s: [%v]", database, err)
+		}
+	}
+	return nil
+}
+
+// DatabaseVersion returns the major version of PostgreSQL a Cloud SQL instance runs, such as 9.6,
+// as long as the instance is running
+func (g *GCPProvider) DatabaseVersion(instance string) (string, error) {
+	svc, project, err := g.sqlAdmin()
+	if err != nil {
+		return "", err
+	}
+	inst, err := svc.Instances.Get(project, instance).Do()
+	if err != nil {
+		return "", err
+	}
+	if inst.State != "RUNNABLE" {
+		return "", fmt.Errorf("Cloud SQL instance %s is %s", instance, inst.State)
+	}
+	return strings.Replace(strings.TrimPrefix(inst.DatabaseVersion, "POSTGRES_"), "_", ".", -1), nil
+}
+
+// SetDBMaxStorage limits how far Cloud SQL grows the instance's disk by itself, with 0 meaning no
+// limit. The google terraform provider concourse-up uses predates disk_autoresize_limit
+func (g *GCPProvider) SetDBMaxStorage(instance string, gb int) error {
+	svc, project, err := g.sqlAdmin()
+	if err != nil {
+		return err
+	}
+	inst, err := svc.Instances.Get(project, instance).Do()
+	if err != nil {
+		return err
+	}
+	if inst.Settings != nil && inst.Settings.StorageAutoResizeLimit == int64(gb) {
+		return nil
+	}
+	op, err := svc.Instances.Patch(project, instance, &sqladmin.DatabaseInstance{
+		Settings: &sqladmin.Settings{
+			StorageAutoResizeLimit: int64(gb),
+			ForceSendFields:        []string{"StorageAutoResizeLimit"},
+		},
+	}).Do()
+	if err != nil {
+		return fmt.Errorf("failed to set the maximum storage of %s: [%v]", instance, err)
+	}
+	return waitForSQLOperation(svc, project, op)
+}
+
+func (g *GCPProvider) sqlAdmin() (*sqladmin.Service, string, error) {
+	project, err := g.Attr("project")
+	if err != nil {
+		return nil, "", err
+	}
+	c, err := google.DefaultClient(g.ctx, sqladmin.CloudPlatformScope)
+	if err != nil {
+		return nil, "", err
+	}
+	svc, err := sqladmin.New(c)
+	if err != nil {
+		return nil, "", err
+	}
+	return svc, project, nil
+}
+
+// waitForSQLOperation polls a Cloud SQL operation until it has finished
+func waitForSQLOperation(svc *sqladmin.Service, project string, op *sqladmin.Operation) error {
+	var err error
+	for op.Status != "DONE" {
+		time.Sleep(5 * time.Second)
+		op, err = svc.Operations.Get(project, op.Name).Do()
+		if err != nil {
+			return err
+		}
+	}
+	if op.Error != nil && len(op.Error.Errors) > 0 {
+		messages := make([]string, len(op.Error.Errors))
+		for i, e := range op.Error.Errors {
+			messages[i] = e.Message
+		}
+		return errors.New(strings.Join(messages, ", "))
+	}
+	return nil
+}
+
+// ValidateInstanceType checks Compute Engine offers the machine type in the zone
+func (g *GCPProvider) ValidateInstanceType(machineType, zone string) error {
+	c, err := google.DefaultClient(g.ctx, compute.CloudPlatformScope)
+	if err != nil {
+		return err
+	}
+	computeService, err := compute.New(c)
+	if err != nil {
+		return err
+	}
+	project, err := g.Attr("project")
+	if err != nil {
+		return err
+	}
+	if _, err = computeService.MachineTypes.Get(project, zone, machineType).Do(); err != nil {
+		return fmt.Errorf("machine type %s is not offered in %s: [%v]", machineType, zone, err)
+	}
+	return nil
+}
+
+var cloudSQLCustomTierRegex = regexp.MustCompile(`^db-custom-\d+-\d+$`)
+
+// ValidateDBInstanceType checks Cloud SQL offers the tier. Custom tiers aren't listed, so only
+// their format is checked. Tiers don't depend on the version of PostgreSQL
+func (g *GCPProvider) ValidateDBInstanceType(tier, databaseVersion string) error {
+	if cloudSQLCustomTierRegex.MatchString(tier) {
+		return nil
+	}
+	svc, project, err := g.sqlAdmin()
+	if err != nil {
+		return err
+	}
+	tiers, err := svc.Tiers.List(project).Do()
+	if err != nil {
+		return fmt.Errorf("error listing Cloud SQL tiers: [%v]", err)
+	}
+	for _, t := range tiers.Items {
+		if t.Tier == tier {
+			return nil
+		}
+	}
+	return fmt.Errorf("`%s` is not a Cloud SQL tier, e.g. db-custom-2-8192", tier)
+}
+
+// SpotBidPrice is not needed on GCP, where preemptible VMs have a fixed price
+func (g *GCPProvider) SpotBidPrice(machineType, zone string) (float64, error) {
+	return 0, fmt.Errorf("Not implemented yet")
+}
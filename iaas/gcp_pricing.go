@@ -0,0 +1,239 @@
+package iaas
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/context"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/compute/v1"
+)
+
+// gcpBillingURL is the Cloud Billing Catalog API, which has no client in the vendored
+// google.golang.org/api
+var gcpBillingURL = "https://cloudbilling.googleapis.com/v1/"
+
+// gcpBillingClient returns the HTTP client used to query the billing catalogue
+var gcpBillingClient = func(ctx context.Context) (*http.Client, error) {
+	return google.DefaultClient(ctx, compute.CloudPlatformScope)
+}
+
+// Billing catalogue service IDs
+const (
+	gcpComputeServiceID  = "6F81-5844-456A"
+	gcpCloudSQLServiceID = "9662-B51E-5089"
+)
+
+type gcpSKU struct {
+	Description string
+	Category    struct {
+		UsageType string
+	}
+	ServiceRegions []string
+	PricingInfo    []struct {
+		PricingExpression struct {
+			UsageUnit   string
+			TieredRates []struct {
+				UnitPrice struct {
+					CurrencyCode string
+					Units        string
+					Nanos        int64
+				}
+			}
+		}
+	}
+}
+
+type gcpListSKUsOutput struct {
+	SKUs          []gcpSKU `json:"skus"`
+	NextPageToken string
+}
+
+// gcpSKUs lists every SKU of a billing catalogue service, fetching each service only once
+func (g *GCPProvider) gcpSKUs(serviceID string) ([]gcpSKU, error) {
+	if skus, ok := g.skus[serviceID]; ok {
+		return skus, nil
+	}
+	client, err := gcpBillingClient(g.ctx)
+	if err != nil {
+		return nil, err
+	}
+	var skus []gcpSKU
+	pageToken := ""
+	for {
+		query := url.Values{"currencyCode": {"USD"}, "pageSize": {"5000"}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		resp, err := client.Get(fmt.Sprintf("%sservices/%s/skus?%s", gcpBillingURL, serviceID, query.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("error fetching billing catalogue: %s", body)
+		}
+		var output gcpListSKUsOutput
+		if err = json.Unmarshal(body, &output); err != nil {
+			return nil, err
+		}
+		skus = append(skus, output.SKUs...)
+		if output.NextPageToken == "" {
+			break
+		}
+		pageToken = output.NextPageToken
+	}
+	if g.skus == nil {
+		g.skus = make(map[string][]gcpSKU)
+	}
+	g.skus[serviceID] = skus
+	return skus, nil
+}
+
+// gcpPrice finds the USD price per unit of the SKU in the provider's region whose description
+// starts with a prefix. Where a SKU has tiers, the last one is used, as earlier tiers are
+// free allowances
+func (g *GCPProvider) gcpPrice(serviceID, usageType, descriptionPrefix string) (float64, error) {
+	skus, err := g.gcpSKUs(serviceID)
+	if err != nil {
+		return 0, err
+	}
+	for _, sku := range skus {
+		if sku.Category.UsageType != usageType || !strings.HasPrefix(sku.Description, descriptionPrefix) || !containsString(sku.ServiceRegions, g.region) {
+			continue
+		}
+		if len(sku.PricingInfo) == 0 || len(sku.PricingInfo[0].PricingExpression.TieredRates) == 0 {
+			continue
+		}
+		rates := sku.PricingInfo[0].PricingExpression.TieredRates
+		price := rates[len(rates)-1].UnitPrice
+		units, err := strconv.ParseFloat(price.Units, 64)
+		if err != nil {
+			return 0, err
+		}
+		return units + float64(price.Nanos)/1e9, nil
+	}
+	return 0, fmt.Errorf("no price found for `%s` in %s", descriptionPrefix, g.region)
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	gcpPredefinedMachineTypeRegex = regexp.MustCompile(`^n1-(standard|highmem|highcpu)-(\d+)$`)
+	gcpCustomMachineTypeRegex     = regexp.MustCompile(`^custom-(\d+)-(\d+)$`)
+)
+
+// gcpN1MemoryPerCPU is the GB of memory per vCPU of each predefined N1 machine type
+var gcpN1MemoryPerCPU = map[string]float64{
+	"standard": 3.75,
+	"highmem":  6.5,
+	"highcpu":  0.9,
+}
+
+// gcpMachineResources returns the SKU description prefix for a machine type's cores and
+// memory, and how many cores and GB of memory it has
+func gcpMachineResources(machineType string) (prefix string, cpus, memory float64, err error) {
+	if m := gcpPredefinedMachineTypeRegex.FindStringSubmatch(machineType); m != nil {
+		cpus, _ = strconv.ParseFloat(m[2], 64)
+		return "N1 Predefined Instance", cpus, cpus * gcpN1MemoryPerCPU[m[1]], nil
+	}
+	if m := gcpCustomMachineTypeRegex.FindStringSubmatch(machineType); m != nil {
+		cpus, _ = strconv.ParseFloat(m[1], 64)
+		memory, _ = strconv.ParseFloat(m[2], 64)
+		return "Custom Instance", cpus, memory / 1024, nil
+	}
+	return "", 0, 0, fmt.Errorf("can't price machine type %s, only N1 and custom machine types are supported", machineType)
+}
+
+// InstancePrice returns the hourly price of a machine type from its cores and memory, either
+// on-demand or preemptible. Prices are the same in every zone of a region
+func (g *GCPProvider) InstancePrice(machineType, zone string, spot bool) (float64, error) {
+	prefix, cpus, memory, err := gcpMachineResources(machineType)
+	if err != nil {
+		return 0, err
+	}
+	usageType := "OnDemand"
+	if spot {
+		usageType = "Preemptible"
+		prefix = "Preemptible " + prefix
+	}
+	cpuPrice, err := g.gcpPrice(gcpComputeServiceID, usageType, prefix+" Core")
+	if err != nil {
+		return 0, err
+	}
+	memoryPrice, err := g.gcpPrice(gcpComputeServiceID, usageType, prefix+" Ram")
+	if err != nil {
+		return 0, err
+	}
+	return cpus*cpuPrice + memory*memoryPrice, nil
+}
+
+// gcpSharedCoreTiers are the SKU description prefixes of Cloud SQL tiers with a fixed price
+var gcpSharedCoreTiers = map[string]string{
+	"db-f1-micro": "DB generic Micro instance",
+	"db-g1-small": "DB generic Small instance",
+}
+
+// DBInstancePrice returns the hourly price of a zonal Cloud SQL instance
+func (g *GCPProvider) DBInstancePrice(tier string) (float64, error) {
+	if prefix, ok := gcpSharedCoreTiers[tier]; ok {
+		return g.gcpPrice(gcpCloudSQLServiceID, "OnDemand", prefix)
+	}
+	if !cloudSQLCustomTierRegex.MatchString(tier) {
+		return 0, fmt.Errorf("can't price Cloud SQL tier %s, only custom and shared core tiers are supported", tier)
+	}
+	parts := strings.Split(tier, "-")
+	cpus, _ := strconv.ParseFloat(parts[2], 64)
+	memory, _ := strconv.ParseFloat(parts[3], 64)
+	cpuPrice, err := g.gcpPrice(gcpCloudSQLServiceID, "OnDemand", "DB custom CORE")
+	if err != nil {
+		return 0, err
+	}
+	memoryPrice, err := g.gcpPrice(gcpCloudSQLServiceID, "OnDemand", "DB custom RAM")
+	if err != nil {
+		return 0, err
+	}
+	return cpus*cpuPrice + memory/1024*memoryPrice, nil
+}
+
+// DBStoragePrice returns the monthly price of a GB of Cloud SQL SSD storage
+func (g *GCPProvider) DBStoragePrice() (float64, error) {
+	return g.gcpPrice(gcpCloudSQLServiceID, "OnDemand", "Storage PD SSD for DB")
+}
+
+// gcpDiskSKUs are the SKU description prefixes of persistent disk types
+var gcpDiskSKUs = map[string]string{
+	"pd-ssd":      "SSD backed PD Capacity",
+	"pd-standard": "Storage PD Capacity",
+}
+
+// DiskPrice returns the monthly price of a GB of persistent disk of a type such as pd-ssd
+func (g *GCPProvider) DiskPrice(diskType string) (float64, error) {
+	prefix, ok := gcpDiskSKUs[diskType]
+	if !ok {
+		return 0, fmt.Errorf("can't price disk type %s", diskType)
+	}
+	return g.gcpPrice(gcpComputeServiceID, "OnDemand", prefix)
+}
+
+// NATGatewayPrice is not needed on GCP, where NAT is done by an instance
+func (g *GCPProvider) NATGatewayPrice() (float64, error) {
+	return 0, fmt.Errorf("Not implemented yet")
+}
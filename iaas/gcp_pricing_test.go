@@ -0,0 +1,140 @@
+package iaas
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+// newBillingTestProvider serves the recorded billing catalogue, counting requests so caching
+// can be checked
+func newBillingTestProvider(t *testing.T) (*GCPProvider, *int, func()) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("currencyCode") != "USD" {
+			t.Errorf("expected prices in USD, got %q", r.URL.RawQuery)
+		}
+		switch {
+		case r.URL.Path == "/services/"+gcpCloudSQLServiceID+"/skus":
+			w.Write(loadFixture(t, "gcp_billing_cloud_sql.json"))
+		case r.URL.Path == "/services/"+gcpComputeServiceID+"/skus" && r.URL.Query().Get("pageToken") == "page2":
+			w.Write(loadFixture(t, "gcp_billing_compute_page2.json"))
+		case r.URL.Path == "/services/"+gcpComputeServiceID+"/skus":
+			w.Write(loadFixture(t, "gcp_billing_compute_page1.json"))
+		default:
+			t.Fatalf("unexpected request %s", r.URL)
+		}
+	}))
+
+	oldURL, oldClient := gcpBillingURL, gcpBillingClient
+	gcpBillingURL = server.URL + "/"
+	gcpBillingClient = func(context.Context) (*http.Client, error) {
+		return server.Client(), nil
+	}
+	return &GCPProvider{ctx: context.Background(), region: "europe-west1"}, &requests, func() {
+		gcpBillingURL, gcpBillingClient = oldURL, oldClient
+		server.Close()
+	}
+}
+
+func assertPrice(t *testing.T, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestGCPProvider_InstancePrice(t *testing.T) {
+	tests := []struct {
+		name        string
+		machineType string
+		spot        bool
+		want        float64
+		wantErr     bool
+	}{
+		{
+			name:        "prices a predefined machine type from its cores and memory",
+			machineType: "n1-standard-4",
+			want:        4*0.0347731 + 15*0.004661,
+		},
+		{
+			name:        "prices a preemptible machine type",
+			machineType: "n1-standard-4",
+			spot:        true,
+			want:        4*0.00732 + 15*0.000981,
+		},
+		{
+			name:        "doesn't price other machine families",
+			machineType: "e2-standard-4",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _, cleanup := newBillingTestProvider(t)
+			defer cleanup()
+
+			got, err := g.InstancePrice(tt.machineType, "europe-west1-b", tt.spot)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GCPProvider.InstancePrice() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assertPrice(t, got, tt.want)
+		})
+	}
+}
+
+func TestGCPProvider_DBInstancePrice(t *testing.T) {
+	tests := []struct {
+		name string
+		tier string
+		want float64
+	}{
+		{
+			name: "prices a custom tier from its cores and memory",
+			tier: "db-custom-2-8192",
+			want: 2*0.0413 + 8*0.007,
+		},
+		{
+			name: "prices a shared core tier",
+			tier: "db-g1-small",
+			want: 0.0275,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _, cleanup := newBillingTestProvider(t)
+			defer cleanup()
+
+			got, err := g.DBInstancePrice(tt.tier)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertPrice(t, got, tt.want)
+		})
+	}
+}
+
+func TestGCPProvider_DiskPrice(t *testing.T) {
+	g, requests, cleanup := newBillingTestProvider(t)
+	defer cleanup()
+
+	ssd, err := g.DiskPrice("pd-ssd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPrice(t, ssd, 0.17)
+
+	standard, err := g.DiskPrice("pd-standard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPrice(t, standard, 0.04)
+
+	if *requests != 2 {
+		t.Errorf("expected both pages of the catalogue to be fetched once, got %d requests", *requests)
+	}
+}
@@ -46,20 +46,33 @@ type Provider interface {
 	Attr(string) (string, error)
 	BucketExists(name string) (bool, error)
 	CheckForWhitelistedIP(ip, securityGroup string) (bool, error)
+	CopyDBSnapshot(sourceRegion, snapshot string) error
 	CreateBucket(name string) error
 	CreateDatabases(name, username, password string) error
+	DatabaseVersion(instance string) (string, error)
+	DBInstancePrice(instanceType string) (float64, error)
+	DBStoragePrice() (float64, error)
 	DeleteFile(bucket, path string) error
 	DeleteVersionedBucket(name string) error
 	DeleteVMsInDeployment(zone, project, deployment string) error
 	DeleteVMsInVPC(vpcID string) ([]string, error)
 	DeleteVolumes(volumesToDelete []string, deleteVolume func(ec2Client IEC2, volumeID *string) error) error
+	DiskPrice(diskType string) (float64, error)
 	EnsureFileExists(bucket, path string, defaultContents []byte) ([]byte, bool, error)
+	ExportDatabases(instance, bucket, path string, databases []string) error
 	FindLongestMatchingHostedZone(subdomain string) (string, string, error)
 	HasFile(bucket, path string) (bool, error)
 	DBType(name string) string
 	IAAS() Name
+	InstancePrice(instanceType, zone string, spot bool) (float64, error)
+	ImportDatabases(instance, user, bucket, path string, databases []string) error
 	LoadFile(bucket, path string) ([]byte, error)
+	NATGatewayPrice() (float64, error)
 	Region() string
+	SetDBMaxStorage(instance string, gb int) error
+	SpotBidPrice(instanceType, zone string) (float64, error)
+	ValidateDBInstanceType(instanceType, engineVersion string) error
+	ValidateInstanceType(instanceType, zone string) error
 	WorkerType(string)
 	WriteFile(bucket, path string, contents []byte) error
 	Zone(string) string
@@ -59,6 +59,18 @@ type FakeProvider struct {
 	chooseReturnsOnCall map[int]struct {
 		result1 interface{}
 	}
+	CopyDBSnapshotStub        func(string, string) error
+	copyDBSnapshotMutex       sync.RWMutex
+	copyDBSnapshotArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	copyDBSnapshotReturns struct {
+		result1 error
+	}
+	copyDBSnapshotReturnsOnCall map[int]struct {
+		result1 error
+	}
 	CreateBucketStub        func(string) error
 	createBucketMutex       sync.RWMutex
 	createBucketArgsForCall []struct {
@@ -83,6 +95,31 @@ type FakeProvider struct {
 	createDatabasesReturnsOnCall map[int]struct {
 		result1 error
 	}
+	DBInstancePriceStub        func(string) (float64, error)
+	dBInstancePriceMutex       sync.RWMutex
+	dBInstancePriceArgsForCall []struct {
+		arg1 string
+	}
+	dBInstancePriceReturns struct {
+		result1 float64
+		result2 error
+	}
+	dBInstancePriceReturnsOnCall map[int]struct {
+		result1 float64
+		result2 error
+	}
+	DBStoragePriceStub        func() (float64, error)
+	dBStoragePriceMutex       sync.RWMutex
+	dBStoragePriceArgsForCall []struct {
+	}
+	dBStoragePriceReturns struct {
+		result1 float64
+		result2 error
+	}
+	dBStoragePriceReturnsOnCall map[int]struct {
+		result1 float64
+		result2 error
+	}
 	DBTypeStub        func(string) string
 	dBTypeMutex       sync.RWMutex
 	dBTypeArgsForCall []struct {
@@ -94,6 +131,19 @@ type FakeProvider struct {
 	dBTypeReturnsOnCall map[int]struct {
 		result1 string
 	}
+	DatabaseVersionStub        func(string) (string, error)
+	databaseVersionMutex       sync.RWMutex
+	databaseVersionArgsForCall []struct {
+		arg1 string
+	}
+	databaseVersionReturns struct {
+		result1 string
+		result2 error
+	}
+	databaseVersionReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
 	DeleteFileStub        func(string, string) error
 	deleteFileMutex       sync.RWMutex
 	deleteFileArgsForCall []struct {
@@ -155,6 +205,19 @@ type FakeProvider struct {
 	deleteVolumesReturnsOnCall map[int]struct {
 		result1 error
 	}
+	DiskPriceStub        func(string) (float64, error)
+	diskPriceMutex       sync.RWMutex
+	diskPriceArgsForCall []struct {
+		arg1 string
+	}
+	diskPriceReturns struct {
+		result1 float64
+		result2 error
+	}
+	diskPriceReturnsOnCall map[int]struct {
+		result1 float64
+		result2 error
+	}
 	EnsureFileExistsStub        func(string, string, []byte) ([]byte, bool, error)
 	ensureFileExistsMutex       sync.RWMutex
 	ensureFileExistsArgsForCall []struct {
@@ -172,6 +235,20 @@ type FakeProvider struct {
 		result2 bool
 		result3 error
 	}
+	ExportDatabasesStub        func(string, string, string, []string) error
+	exportDatabasesMutex       sync.RWMutex
+	exportDatabasesArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 []string
+	}
+	exportDatabasesReturns struct {
+		result1 error
+	}
+	exportDatabasesReturnsOnCall map[int]struct {
+		result1 error
+	}
 	FindLongestMatchingHostedZoneStub        func(string) (string, string, error)
 	findLongestMatchingHostedZoneMutex       sync.RWMutex
 	findLongestMatchingHostedZoneArgsForCall []struct {
@@ -211,6 +288,36 @@ type FakeProvider struct {
 	iAASReturnsOnCall map[int]struct {
 		result1 iaas.Name
 	}
+	ImportDatabasesStub        func(string, string, string, string, []string) error
+	importDatabasesMutex       sync.RWMutex
+	importDatabasesArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 []string
+	}
+	importDatabasesReturns struct {
+		result1 error
+	}
+	importDatabasesReturnsOnCall map[int]struct {
+		result1 error
+	}
+	InstancePriceStub        func(string, string, bool) (float64, error)
+	instancePriceMutex       sync.RWMutex
+	instancePriceArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 bool
+	}
+	instancePriceReturns struct {
+		result1 float64
+		result2 error
+	}
+	instancePriceReturnsOnCall map[int]struct {
+		result1 float64
+		result2 error
+	}
 	LoadFileStub        func(string, string) ([]byte, error)
 	loadFileMutex       sync.RWMutex
 	loadFileArgsForCall []struct {
@@ -225,6 +332,18 @@ type FakeProvider struct {
 		result1 []byte
 		result2 error
 	}
+	NATGatewayPriceStub        func() (float64, error)
+	nATGatewayPriceMutex       sync.RWMutex
+	nATGatewayPriceArgsForCall []struct {
+	}
+	nATGatewayPriceReturns struct {
+		result1 float64
+		result2 error
+	}
+	nATGatewayPriceReturnsOnCall map[int]struct {
+		result1 float64
+		result2 error
+	}
 	RegionStub        func() string
 	regionMutex       sync.RWMutex
 	regionArgsForCall []struct {
@@ -235,6 +354,56 @@ type FakeProvider struct {
 	regionReturnsOnCall map[int]struct {
 		result1 string
 	}
+	SetDBMaxStorageStub        func(string, int) error
+	setDBMaxStorageMutex       sync.RWMutex
+	setDBMaxStorageArgsForCall []struct {
+		arg1 string
+		arg2 int
+	}
+	setDBMaxStorageReturns struct {
+		result1 error
+	}
+	setDBMaxStorageReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SpotBidPriceStub        func(string, string) (float64, error)
+	spotBidPriceMutex       sync.RWMutex
+	spotBidPriceArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	spotBidPriceReturns struct {
+		result1 float64
+		result2 error
+	}
+	spotBidPriceReturnsOnCall map[int]struct {
+		result1 float64
+		result2 error
+	}
+	ValidateDBInstanceTypeStub        func(string, string) error
+	validateDBInstanceTypeMutex       sync.RWMutex
+	validateDBInstanceTypeArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	validateDBInstanceTypeReturns struct {
+		result1 error
+	}
+	validateDBInstanceTypeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ValidateInstanceTypeStub        func(string, string) error
+	validateInstanceTypeMutex       sync.RWMutex
+	validateInstanceTypeArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	validateInstanceTypeReturns struct {
+		result1 error
+	}
+	validateInstanceTypeReturnsOnCall map[int]struct {
+		result1 error
+	}
 	WorkerTypeStub        func(string)
 	workerTypeMutex       sync.RWMutex
 	workerTypeArgsForCall []struct {
@@ -518,6 +687,67 @@ func (fake *FakeProvider) ChooseReturnsOnCall(i int, result1 interface{}) {
 	}{result1}
 }
 
+func (fake *FakeProvider) CopyDBSnapshot(arg1 string, arg2 string) error {
+	fake.copyDBSnapshotMutex.Lock()
+	ret, specificReturn := fake.copyDBSnapshotReturnsOnCall[len(fake.copyDBSnapshotArgsForCall)]
+	fake.copyDBSnapshotArgsForCall = append(fake.copyDBSnapshotArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("CopyDBSnapshot", []interface{}{arg1, arg2})
+	fake.copyDBSnapshotMutex.Unlock()
+	if fake.CopyDBSnapshotStub != nil {
+		return fake.CopyDBSnapshotStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.copyDBSnapshotReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeProvider) CopyDBSnapshotCallCount() int {
+	fake.copyDBSnapshotMutex.RLock()
+	defer fake.copyDBSnapshotMutex.RUnlock()
+	return len(fake.copyDBSnapshotArgsForCall)
+}
+
+func (fake *FakeProvider) CopyDBSnapshotCalls(stub func(string, string) error) {
+	fake.copyDBSnapshotMutex.Lock()
+	defer fake.copyDBSnapshotMutex.Unlock()
+	fake.CopyDBSnapshotStub = stub
+}
+
+func (fake *FakeProvider) CopyDBSnapshotArgsForCall(i int) (string, string) {
+	fake.copyDBSnapshotMutex.RLock()
+	defer fake.copyDBSnapshotMutex.RUnlock()
+	argsForCall := fake.copyDBSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeProvider) CopyDBSnapshotReturns(result1 error) {
+	fake.copyDBSnapshotMutex.Lock()
+	defer fake.copyDBSnapshotMutex.Unlock()
+	fake.CopyDBSnapshotStub = nil
+	fake.copyDBSnapshotReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) CopyDBSnapshotReturnsOnCall(i int, result1 error) {
+	fake.copyDBSnapshotMutex.Lock()
+	defer fake.copyDBSnapshotMutex.Unlock()
+	fake.CopyDBSnapshotStub = nil
+	if fake.copyDBSnapshotReturnsOnCall == nil {
+		fake.copyDBSnapshotReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.copyDBSnapshotReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeProvider) CreateBucket(arg1 string) error {
 	fake.createBucketMutex.Lock()
 	ret, specificReturn := fake.createBucketReturnsOnCall[len(fake.createBucketArgsForCall)]
@@ -640,6 +870,124 @@ func (fake *FakeProvider) CreateDatabasesReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeProvider) DBInstancePrice(arg1 string) (float64, error) {
+	fake.dBInstancePriceMutex.Lock()
+	ret, specificReturn := fake.dBInstancePriceReturnsOnCall[len(fake.dBInstancePriceArgsForCall)]
+	fake.dBInstancePriceArgsForCall = append(fake.dBInstancePriceArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("DBInstancePrice", []interface{}{arg1})
+	fake.dBInstancePriceMutex.Unlock()
+	if fake.DBInstancePriceStub != nil {
+		return fake.DBInstancePriceStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.dBInstancePriceReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) DBInstancePriceCallCount() int {
+	fake.dBInstancePriceMutex.RLock()
+	defer fake.dBInstancePriceMutex.RUnlock()
+	return len(fake.dBInstancePriceArgsForCall)
+}
+
+func (fake *FakeProvider) DBInstancePriceCalls(stub func(string) (float64, error)) {
+	fake.dBInstancePriceMutex.Lock()
+	defer fake.dBInstancePriceMutex.Unlock()
+	fake.DBInstancePriceStub = stub
+}
+
+func (fake *FakeProvider) DBInstancePriceArgsForCall(i int) string {
+	fake.dBInstancePriceMutex.RLock()
+	defer fake.dBInstancePriceMutex.RUnlock()
+	argsForCall := fake.dBInstancePriceArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeProvider) DBInstancePriceReturns(result1 float64, result2 error) {
+	fake.dBInstancePriceMutex.Lock()
+	defer fake.dBInstancePriceMutex.Unlock()
+	fake.DBInstancePriceStub = nil
+	fake.dBInstancePriceReturns = struct {
+		result1 float64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) DBInstancePriceReturnsOnCall(i int, result1 float64, result2 error) {
+	fake.dBInstancePriceMutex.Lock()
+	defer fake.dBInstancePriceMutex.Unlock()
+	fake.DBInstancePriceStub = nil
+	if fake.dBInstancePriceReturnsOnCall == nil {
+		fake.dBInstancePriceReturnsOnCall = make(map[int]struct {
+			result1 float64
+			result2 error
+		})
+	}
+	fake.dBInstancePriceReturnsOnCall[i] = struct {
+		result1 float64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) DBStoragePrice() (float64, error) {
+	fake.dBStoragePriceMutex.Lock()
+	ret, specificReturn := fake.dBStoragePriceReturnsOnCall[len(fake.dBStoragePriceArgsForCall)]
+	fake.dBStoragePriceArgsForCall = append(fake.dBStoragePriceArgsForCall, struct {
+	}{})
+	fake.recordInvocation("DBStoragePrice", []interface{}{})
+	fake.dBStoragePriceMutex.Unlock()
+	if fake.DBStoragePriceStub != nil {
+		return fake.DBStoragePriceStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.dBStoragePriceReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) DBStoragePriceCallCount() int {
+	fake.dBStoragePriceMutex.RLock()
+	defer fake.dBStoragePriceMutex.RUnlock()
+	return len(fake.dBStoragePriceArgsForCall)
+}
+
+func (fake *FakeProvider) DBStoragePriceCalls(stub func() (float64, error)) {
+	fake.dBStoragePriceMutex.Lock()
+	defer fake.dBStoragePriceMutex.Unlock()
+	fake.DBStoragePriceStub = stub
+}
+
+func (fake *FakeProvider) DBStoragePriceReturns(result1 float64, result2 error) {
+	fake.dBStoragePriceMutex.Lock()
+	defer fake.dBStoragePriceMutex.Unlock()
+	fake.DBStoragePriceStub = nil
+	fake.dBStoragePriceReturns = struct {
+		result1 float64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) DBStoragePriceReturnsOnCall(i int, result1 float64, result2 error) {
+	fake.dBStoragePriceMutex.Lock()
+	defer fake.dBStoragePriceMutex.Unlock()
+	fake.DBStoragePriceStub = nil
+	if fake.dBStoragePriceReturnsOnCall == nil {
+		fake.dBStoragePriceReturnsOnCall = make(map[int]struct {
+			result1 float64
+			result2 error
+		})
+	}
+	fake.dBStoragePriceReturnsOnCall[i] = struct {
+		result1 float64
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeProvider) DBType(arg1 string) string {
 	fake.dBTypeMutex.Lock()
 	ret, specificReturn := fake.dBTypeReturnsOnCall[len(fake.dBTypeArgsForCall)]
@@ -700,6 +1048,69 @@ func (fake *FakeProvider) DBTypeReturnsOnCall(i int, result1 string) {
 	}{result1}
 }
 
+func (fake *FakeProvider) DatabaseVersion(arg1 string) (string, error) {
+	fake.databaseVersionMutex.Lock()
+	ret, specificReturn := fake.databaseVersionReturnsOnCall[len(fake.databaseVersionArgsForCall)]
+	fake.databaseVersionArgsForCall = append(fake.databaseVersionArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("DatabaseVersion", []interface{}{arg1})
+	fake.databaseVersionMutex.Unlock()
+	if fake.DatabaseVersionStub != nil {
+		return fake.DatabaseVersionStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.databaseVersionReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) DatabaseVersionCallCount() int {
+	fake.databaseVersionMutex.RLock()
+	defer fake.databaseVersionMutex.RUnlock()
+	return len(fake.databaseVersionArgsForCall)
+}
+
+func (fake *FakeProvider) DatabaseVersionCalls(stub func(string) (string, error)) {
+	fake.databaseVersionMutex.Lock()
+	defer fake.databaseVersionMutex.Unlock()
+	fake.DatabaseVersionStub = stub
+}
+
+func (fake *FakeProvider) DatabaseVersionArgsForCall(i int) string {
+	fake.databaseVersionMutex.RLock()
+	defer fake.databaseVersionMutex.RUnlock()
+	argsForCall := fake.databaseVersionArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeProvider) DatabaseVersionReturns(result1 string, result2 error) {
+	fake.databaseVersionMutex.Lock()
+	defer fake.databaseVersionMutex.Unlock()
+	fake.DatabaseVersionStub = nil
+	fake.databaseVersionReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) DatabaseVersionReturnsOnCall(i int, result1 string, result2 error) {
+	fake.databaseVersionMutex.Lock()
+	defer fake.databaseVersionMutex.Unlock()
+	fake.DatabaseVersionStub = nil
+	if fake.databaseVersionReturnsOnCall == nil {
+		fake.databaseVersionReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.databaseVersionReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeProvider) DeleteFile(arg1 string, arg2 string) error {
 	fake.deleteFileMutex.Lock()
 	ret, specificReturn := fake.deleteFileReturnsOnCall[len(fake.deleteFileArgsForCall)]
@@ -1012,6 +1423,69 @@ func (fake *FakeProvider) DeleteVolumesReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeProvider) DiskPrice(arg1 string) (float64, error) {
+	fake.diskPriceMutex.Lock()
+	ret, specificReturn := fake.diskPriceReturnsOnCall[len(fake.diskPriceArgsForCall)]
+	fake.diskPriceArgsForCall = append(fake.diskPriceArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("DiskPrice", []interface{}{arg1})
+	fake.diskPriceMutex.Unlock()
+	if fake.DiskPriceStub != nil {
+		return fake.DiskPriceStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.diskPriceReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) DiskPriceCallCount() int {
+	fake.diskPriceMutex.RLock()
+	defer fake.diskPriceMutex.RUnlock()
+	return len(fake.diskPriceArgsForCall)
+}
+
+func (fake *FakeProvider) DiskPriceCalls(stub func(string) (float64, error)) {
+	fake.diskPriceMutex.Lock()
+	defer fake.diskPriceMutex.Unlock()
+	fake.DiskPriceStub = stub
+}
+
+func (fake *FakeProvider) DiskPriceArgsForCall(i int) string {
+	fake.diskPriceMutex.RLock()
+	defer fake.diskPriceMutex.RUnlock()
+	argsForCall := fake.diskPriceArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeProvider) DiskPriceReturns(result1 float64, result2 error) {
+	fake.diskPriceMutex.Lock()
+	defer fake.diskPriceMutex.Unlock()
+	fake.DiskPriceStub = nil
+	fake.diskPriceReturns = struct {
+		result1 float64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) DiskPriceReturnsOnCall(i int, result1 float64, result2 error) {
+	fake.diskPriceMutex.Lock()
+	defer fake.diskPriceMutex.Unlock()
+	fake.DiskPriceStub = nil
+	if fake.diskPriceReturnsOnCall == nil {
+		fake.diskPriceReturnsOnCall = make(map[int]struct {
+			result1 float64
+			result2 error
+		})
+	}
+	fake.diskPriceReturnsOnCall[i] = struct {
+		result1 float64
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeProvider) EnsureFileExists(arg1 string, arg2 string, arg3 []byte) ([]byte, bool, error) {
 	var arg3Copy []byte
 	if arg3 != nil {
@@ -1085,6 +1559,74 @@ func (fake *FakeProvider) EnsureFileExistsReturnsOnCall(i int, result1 []byte, r
 	}{result1, result2, result3}
 }
 
+func (fake *FakeProvider) ExportDatabases(arg1 string, arg2 string, arg3 string, arg4 []string) error {
+	var arg4Copy []string
+	if arg4 != nil {
+		arg4Copy = make([]string, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.exportDatabasesMutex.Lock()
+	ret, specificReturn := fake.exportDatabasesReturnsOnCall[len(fake.exportDatabasesArgsForCall)]
+	fake.exportDatabasesArgsForCall = append(fake.exportDatabasesArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 []string
+	}{arg1, arg2, arg3, arg4Copy})
+	fake.recordInvocation("ExportDatabases", []interface{}{arg1, arg2, arg3, arg4Copy})
+	fake.exportDatabasesMutex.Unlock()
+	if fake.ExportDatabasesStub != nil {
+		return fake.ExportDatabasesStub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.exportDatabasesReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeProvider) ExportDatabasesCallCount() int {
+	fake.exportDatabasesMutex.RLock()
+	defer fake.exportDatabasesMutex.RUnlock()
+	return len(fake.exportDatabasesArgsForCall)
+}
+
+func (fake *FakeProvider) ExportDatabasesCalls(stub func(string, string, string, []string) error) {
+	fake.exportDatabasesMutex.Lock()
+	defer fake.exportDatabasesMutex.Unlock()
+	fake.ExportDatabasesStub = stub
+}
+
+func (fake *FakeProvider) ExportDatabasesArgsForCall(i int) (string, string, string, []string) {
+	fake.exportDatabasesMutex.RLock()
+	defer fake.exportDatabasesMutex.RUnlock()
+	argsForCall := fake.exportDatabasesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeProvider) ExportDatabasesReturns(result1 error) {
+	fake.exportDatabasesMutex.Lock()
+	defer fake.exportDatabasesMutex.Unlock()
+	fake.ExportDatabasesStub = nil
+	fake.exportDatabasesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) ExportDatabasesReturnsOnCall(i int, result1 error) {
+	fake.exportDatabasesMutex.Lock()
+	defer fake.exportDatabasesMutex.Unlock()
+	fake.ExportDatabasesStub = nil
+	if fake.exportDatabasesReturnsOnCall == nil {
+		fake.exportDatabasesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.exportDatabasesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeProvider) FindLongestMatchingHostedZone(arg1 string) (string, string, error) {
 	fake.findLongestMatchingHostedZoneMutex.Lock()
 	ret, specificReturn := fake.findLongestMatchingHostedZoneReturnsOnCall[len(fake.findLongestMatchingHostedZoneArgsForCall)]
@@ -1267,6 +1809,140 @@ func (fake *FakeProvider) IAASReturnsOnCall(i int, result1 iaas.Name) {
 	}{result1}
 }
 
+func (fake *FakeProvider) ImportDatabases(arg1 string, arg2 string, arg3 string, arg4 string, arg5 []string) error {
+	var arg5Copy []string
+	if arg5 != nil {
+		arg5Copy = make([]string, len(arg5))
+		copy(arg5Copy, arg5)
+	}
+	fake.importDatabasesMutex.Lock()
+	ret, specificReturn := fake.importDatabasesReturnsOnCall[len(fake.importDatabasesArgsForCall)]
+	fake.importDatabasesArgsForCall = append(fake.importDatabasesArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 []string
+	}{arg1, arg2, arg3, arg4, arg5Copy})
+	fake.recordInvocation("ImportDatabases", []interface{}{arg1, arg2, arg3, arg4, arg5Copy})
+	fake.importDatabasesMutex.Unlock()
+	if fake.ImportDatabasesStub != nil {
+		return fake.ImportDatabasesStub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.importDatabasesReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeProvider) ImportDatabasesCallCount() int {
+	fake.importDatabasesMutex.RLock()
+	defer fake.importDatabasesMutex.RUnlock()
+	return len(fake.importDatabasesArgsForCall)
+}
+
+func (fake *FakeProvider) ImportDatabasesCalls(stub func(string, string, string, string, []string) error) {
+	fake.importDatabasesMutex.Lock()
+	defer fake.importDatabasesMutex.Unlock()
+	fake.ImportDatabasesStub = stub
+}
+
+func (fake *FakeProvider) ImportDatabasesArgsForCall(i int) (string, string, string, string, []string) {
+	fake.importDatabasesMutex.RLock()
+	defer fake.importDatabasesMutex.RUnlock()
+	argsForCall := fake.importDatabasesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeProvider) ImportDatabasesReturns(result1 error) {
+	fake.importDatabasesMutex.Lock()
+	defer fake.importDatabasesMutex.Unlock()
+	fake.ImportDatabasesStub = nil
+	fake.importDatabasesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) ImportDatabasesReturnsOnCall(i int, result1 error) {
+	fake.importDatabasesMutex.Lock()
+	defer fake.importDatabasesMutex.Unlock()
+	fake.ImportDatabasesStub = nil
+	if fake.importDatabasesReturnsOnCall == nil {
+		fake.importDatabasesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.importDatabasesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) InstancePrice(arg1 string, arg2 string, arg3 bool) (float64, error) {
+	fake.instancePriceMutex.Lock()
+	ret, specificReturn := fake.instancePriceReturnsOnCall[len(fake.instancePriceArgsForCall)]
+	fake.instancePriceArgsForCall = append(fake.instancePriceArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 bool
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("InstancePrice", []interface{}{arg1, arg2, arg3})
+	fake.instancePriceMutex.Unlock()
+	if fake.InstancePriceStub != nil {
+		return fake.InstancePriceStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.instancePriceReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) InstancePriceCallCount() int {
+	fake.instancePriceMutex.RLock()
+	defer fake.instancePriceMutex.RUnlock()
+	return len(fake.instancePriceArgsForCall)
+}
+
+func (fake *FakeProvider) InstancePriceCalls(stub func(string, string, bool) (float64, error)) {
+	fake.instancePriceMutex.Lock()
+	defer fake.instancePriceMutex.Unlock()
+	fake.InstancePriceStub = stub
+}
+
+func (fake *FakeProvider) InstancePriceArgsForCall(i int) (string, string, bool) {
+	fake.instancePriceMutex.RLock()
+	defer fake.instancePriceMutex.RUnlock()
+	argsForCall := fake.instancePriceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeProvider) InstancePriceReturns(result1 float64, result2 error) {
+	fake.instancePriceMutex.Lock()
+	defer fake.instancePriceMutex.Unlock()
+	fake.InstancePriceStub = nil
+	fake.instancePriceReturns = struct {
+		result1 float64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) InstancePriceReturnsOnCall(i int, result1 float64, result2 error) {
+	fake.instancePriceMutex.Lock()
+	defer fake.instancePriceMutex.Unlock()
+	fake.InstancePriceStub = nil
+	if fake.instancePriceReturnsOnCall == nil {
+		fake.instancePriceReturnsOnCall = make(map[int]struct {
+			result1 float64
+			result2 error
+		})
+	}
+	fake.instancePriceReturnsOnCall[i] = struct {
+		result1 float64
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeProvider) LoadFile(arg1 string, arg2 string) ([]byte, error) {
 	fake.loadFileMutex.Lock()
 	ret, specificReturn := fake.loadFileReturnsOnCall[len(fake.loadFileArgsForCall)]
@@ -1331,6 +2007,61 @@ func (fake *FakeProvider) LoadFileReturnsOnCall(i int, result1 []byte, result2 e
 	}{result1, result2}
 }
 
+func (fake *FakeProvider) NATGatewayPrice() (float64, error) {
+	fake.nATGatewayPriceMutex.Lock()
+	ret, specificReturn := fake.nATGatewayPriceReturnsOnCall[len(fake.nATGatewayPriceArgsForCall)]
+	fake.nATGatewayPriceArgsForCall = append(fake.nATGatewayPriceArgsForCall, struct {
+	}{})
+	fake.recordInvocation("NATGatewayPrice", []interface{}{})
+	fake.nATGatewayPriceMutex.Unlock()
+	if fake.NATGatewayPriceStub != nil {
+		return fake.NATGatewayPriceStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.nATGatewayPriceReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) NATGatewayPriceCallCount() int {
+	fake.nATGatewayPriceMutex.RLock()
+	defer fake.nATGatewayPriceMutex.RUnlock()
+	return len(fake.nATGatewayPriceArgsForCall)
+}
+
+func (fake *FakeProvider) NATGatewayPriceCalls(stub func() (float64, error)) {
+	fake.nATGatewayPriceMutex.Lock()
+	defer fake.nATGatewayPriceMutex.Unlock()
+	fake.NATGatewayPriceStub = stub
+}
+
+func (fake *FakeProvider) NATGatewayPriceReturns(result1 float64, result2 error) {
+	fake.nATGatewayPriceMutex.Lock()
+	defer fake.nATGatewayPriceMutex.Unlock()
+	fake.NATGatewayPriceStub = nil
+	fake.nATGatewayPriceReturns = struct {
+		result1 float64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) NATGatewayPriceReturnsOnCall(i int, result1 float64, result2 error) {
+	fake.nATGatewayPriceMutex.Lock()
+	defer fake.nATGatewayPriceMutex.Unlock()
+	fake.NATGatewayPriceStub = nil
+	if fake.nATGatewayPriceReturnsOnCall == nil {
+		fake.nATGatewayPriceReturnsOnCall = make(map[int]struct {
+			result1 float64
+			result2 error
+		})
+	}
+	fake.nATGatewayPriceReturnsOnCall[i] = struct {
+		result1 float64
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeProvider) Region() string {
 	fake.regionMutex.Lock()
 	ret, specificReturn := fake.regionReturnsOnCall[len(fake.regionArgsForCall)]
@@ -1383,6 +2114,253 @@ func (fake *FakeProvider) RegionReturnsOnCall(i int, result1 string) {
 	}{result1}
 }
 
+func (fake *FakeProvider) SetDBMaxStorage(arg1 string, arg2 int) error {
+	fake.setDBMaxStorageMutex.Lock()
+	ret, specificReturn := fake.setDBMaxStorageReturnsOnCall[len(fake.setDBMaxStorageArgsForCall)]
+	fake.setDBMaxStorageArgsForCall = append(fake.setDBMaxStorageArgsForCall, struct {
+		arg1 string
+		arg2 int
+	}{arg1, arg2})
+	fake.recordInvocation("SetDBMaxStorage", []interface{}{arg1, arg2})
+	fake.setDBMaxStorageMutex.Unlock()
+	if fake.SetDBMaxStorageStub != nil {
+		return fake.SetDBMaxStorageStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.setDBMaxStorageReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeProvider) SetDBMaxStorageCallCount() int {
+	fake.setDBMaxStorageMutex.RLock()
+	defer fake.setDBMaxStorageMutex.RUnlock()
+	return len(fake.setDBMaxStorageArgsForCall)
+}
+
+func (fake *FakeProvider) SetDBMaxStorageCalls(stub func(string, int) error) {
+	fake.setDBMaxStorageMutex.Lock()
+	defer fake.setDBMaxStorageMutex.Unlock()
+	fake.SetDBMaxStorageStub = stub
+}
+
+func (fake *FakeProvider) SetDBMaxStorageArgsForCall(i int) (string, int) {
+	fake.setDBMaxStorageMutex.RLock()
+	defer fake.setDBMaxStorageMutex.RUnlock()
+	argsForCall := fake.setDBMaxStorageArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeProvider) SetDBMaxStorageReturns(result1 error) {
+	fake.setDBMaxStorageMutex.Lock()
+	defer fake.setDBMaxStorageMutex.Unlock()
+	fake.SetDBMaxStorageStub = nil
+	fake.setDBMaxStorageReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) SetDBMaxStorageReturnsOnCall(i int, result1 error) {
+	fake.setDBMaxStorageMutex.Lock()
+	defer fake.setDBMaxStorageMutex.Unlock()
+	fake.SetDBMaxStorageStub = nil
+	if fake.setDBMaxStorageReturnsOnCall == nil {
+		fake.setDBMaxStorageReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setDBMaxStorageReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) SpotBidPrice(arg1 string, arg2 string) (float64, error) {
+	fake.spotBidPriceMutex.Lock()
+	ret, specificReturn := fake.spotBidPriceReturnsOnCall[len(fake.spotBidPriceArgsForCall)]
+	fake.spotBidPriceArgsForCall = append(fake.spotBidPriceArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("SpotBidPrice", []interface{}{arg1, arg2})
+	fake.spotBidPriceMutex.Unlock()
+	if fake.SpotBidPriceStub != nil {
+		return fake.SpotBidPriceStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.spotBidPriceReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeProvider) SpotBidPriceCallCount() int {
+	fake.spotBidPriceMutex.RLock()
+	defer fake.spotBidPriceMutex.RUnlock()
+	return len(fake.spotBidPriceArgsForCall)
+}
+
+func (fake *FakeProvider) SpotBidPriceCalls(stub func(string, string) (float64, error)) {
+	fake.spotBidPriceMutex.Lock()
+	defer fake.spotBidPriceMutex.Unlock()
+	fake.SpotBidPriceStub = stub
+}
+
+func (fake *FakeProvider) SpotBidPriceArgsForCall(i int) (string, string) {
+	fake.spotBidPriceMutex.RLock()
+	defer fake.spotBidPriceMutex.RUnlock()
+	argsForCall := fake.spotBidPriceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeProvider) SpotBidPriceReturns(result1 float64, result2 error) {
+	fake.spotBidPriceMutex.Lock()
+	defer fake.spotBidPriceMutex.Unlock()
+	fake.SpotBidPriceStub = nil
+	fake.spotBidPriceReturns = struct {
+		result1 float64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) SpotBidPriceReturnsOnCall(i int, result1 float64, result2 error) {
+	fake.spotBidPriceMutex.Lock()
+	defer fake.spotBidPriceMutex.Unlock()
+	fake.SpotBidPriceStub = nil
+	if fake.spotBidPriceReturnsOnCall == nil {
+		fake.spotBidPriceReturnsOnCall = make(map[int]struct {
+			result1 float64
+			result2 error
+		})
+	}
+	fake.spotBidPriceReturnsOnCall[i] = struct {
+		result1 float64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeProvider) ValidateDBInstanceType(arg1 string, arg2 string) error {
+	fake.validateDBInstanceTypeMutex.Lock()
+	ret, specificReturn := fake.validateDBInstanceTypeReturnsOnCall[len(fake.validateDBInstanceTypeArgsForCall)]
+	fake.validateDBInstanceTypeArgsForCall = append(fake.validateDBInstanceTypeArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("ValidateDBInstanceType", []interface{}{arg1, arg2})
+	fake.validateDBInstanceTypeMutex.Unlock()
+	if fake.ValidateDBInstanceTypeStub != nil {
+		return fake.ValidateDBInstanceTypeStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.validateDBInstanceTypeReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeProvider) ValidateDBInstanceTypeCallCount() int {
+	fake.validateDBInstanceTypeMutex.RLock()
+	defer fake.validateDBInstanceTypeMutex.RUnlock()
+	return len(fake.validateDBInstanceTypeArgsForCall)
+}
+
+func (fake *FakeProvider) ValidateDBInstanceTypeCalls(stub func(string, string) error) {
+	fake.validateDBInstanceTypeMutex.Lock()
+	defer fake.validateDBInstanceTypeMutex.Unlock()
+	fake.ValidateDBInstanceTypeStub = stub
+}
+
+func (fake *FakeProvider) ValidateDBInstanceTypeArgsForCall(i int) (string, string) {
+	fake.validateDBInstanceTypeMutex.RLock()
+	defer fake.validateDBInstanceTypeMutex.RUnlock()
+	argsForCall := fake.validateDBInstanceTypeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeProvider) ValidateDBInstanceTypeReturns(result1 error) {
+	fake.validateDBInstanceTypeMutex.Lock()
+	defer fake.validateDBInstanceTypeMutex.Unlock()
+	fake.ValidateDBInstanceTypeStub = nil
+	fake.validateDBInstanceTypeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) ValidateDBInstanceTypeReturnsOnCall(i int, result1 error) {
+	fake.validateDBInstanceTypeMutex.Lock()
+	defer fake.validateDBInstanceTypeMutex.Unlock()
+	fake.ValidateDBInstanceTypeStub = nil
+	if fake.validateDBInstanceTypeReturnsOnCall == nil {
+		fake.validateDBInstanceTypeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.validateDBInstanceTypeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) ValidateInstanceType(arg1 string, arg2 string) error {
+	fake.validateInstanceTypeMutex.Lock()
+	ret, specificReturn := fake.validateInstanceTypeReturnsOnCall[len(fake.validateInstanceTypeArgsForCall)]
+	fake.validateInstanceTypeArgsForCall = append(fake.validateInstanceTypeArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("ValidateInstanceType", []interface{}{arg1, arg2})
+	fake.validateInstanceTypeMutex.Unlock()
+	if fake.ValidateInstanceTypeStub != nil {
+		return fake.ValidateInstanceTypeStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.validateInstanceTypeReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeProvider) ValidateInstanceTypeCallCount() int {
+	fake.validateInstanceTypeMutex.RLock()
+	defer fake.validateInstanceTypeMutex.RUnlock()
+	return len(fake.validateInstanceTypeArgsForCall)
+}
+
+func (fake *FakeProvider) ValidateInstanceTypeCalls(stub func(string, string) error) {
+	fake.validateInstanceTypeMutex.Lock()
+	defer fake.validateInstanceTypeMutex.Unlock()
+	fake.ValidateInstanceTypeStub = stub
+}
+
+func (fake *FakeProvider) ValidateInstanceTypeArgsForCall(i int) (string, string) {
+	fake.validateInstanceTypeMutex.RLock()
+	defer fake.validateInstanceTypeMutex.RUnlock()
+	argsForCall := fake.validateInstanceTypeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeProvider) ValidateInstanceTypeReturns(result1 error) {
+	fake.validateInstanceTypeMutex.Lock()
+	defer fake.validateInstanceTypeMutex.Unlock()
+	fake.ValidateInstanceTypeStub = nil
+	fake.validateInstanceTypeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeProvider) ValidateInstanceTypeReturnsOnCall(i int, result1 error) {
+	fake.validateInstanceTypeMutex.Lock()
+	defer fake.validateInstanceTypeMutex.Unlock()
+	fake.ValidateInstanceTypeStub = nil
+	if fake.validateInstanceTypeReturnsOnCall == nil {
+		fake.validateInstanceTypeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.validateInstanceTypeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeProvider) WorkerType(arg1 string) {
 	fake.workerTypeMutex.Lock()
 	fake.workerTypeArgsForCall = append(fake.workerTypeArgsForCall, struct {
@@ -1552,12 +2530,20 @@ func (fake *FakeProvider) Invocations() map[string][][]interface{} {
 	defer fake.checkForWhitelistedIPMutex.RUnlock()
 	fake.chooseMutex.RLock()
 	defer fake.chooseMutex.RUnlock()
+	fake.copyDBSnapshotMutex.RLock()
+	defer fake.copyDBSnapshotMutex.RUnlock()
 	fake.createBucketMutex.RLock()
 	defer fake.createBucketMutex.RUnlock()
 	fake.createDatabasesMutex.RLock()
 	defer fake.createDatabasesMutex.RUnlock()
+	fake.dBInstancePriceMutex.RLock()
+	defer fake.dBInstancePriceMutex.RUnlock()
+	fake.dBStoragePriceMutex.RLock()
+	defer fake.dBStoragePriceMutex.RUnlock()
 	fake.dBTypeMutex.RLock()
 	defer fake.dBTypeMutex.RUnlock()
+	fake.databaseVersionMutex.RLock()
+	defer fake.databaseVersionMutex.RUnlock()
 	fake.deleteFileMutex.RLock()
 	defer fake.deleteFileMutex.RUnlock()
 	fake.deleteVMsInDeploymentMutex.RLock()
@@ -1568,18 +2554,36 @@ func (fake *FakeProvider) Invocations() map[string][][]interface{} {
 	defer fake.deleteVersionedBucketMutex.RUnlock()
 	fake.deleteVolumesMutex.RLock()
 	defer fake.deleteVolumesMutex.RUnlock()
+	fake.diskPriceMutex.RLock()
+	defer fake.diskPriceMutex.RUnlock()
 	fake.ensureFileExistsMutex.RLock()
 	defer fake.ensureFileExistsMutex.RUnlock()
+	fake.exportDatabasesMutex.RLock()
+	defer fake.exportDatabasesMutex.RUnlock()
 	fake.findLongestMatchingHostedZoneMutex.RLock()
 	defer fake.findLongestMatchingHostedZoneMutex.RUnlock()
 	fake.hasFileMutex.RLock()
 	defer fake.hasFileMutex.RUnlock()
 	fake.iAASMutex.RLock()
 	defer fake.iAASMutex.RUnlock()
+	fake.importDatabasesMutex.RLock()
+	defer fake.importDatabasesMutex.RUnlock()
+	fake.instancePriceMutex.RLock()
+	defer fake.instancePriceMutex.RUnlock()
 	fake.loadFileMutex.RLock()
 	defer fake.loadFileMutex.RUnlock()
+	fake.nATGatewayPriceMutex.RLock()
+	defer fake.nATGatewayPriceMutex.RUnlock()
 	fake.regionMutex.RLock()
 	defer fake.regionMutex.RUnlock()
+	fake.setDBMaxStorageMutex.RLock()
+	defer fake.setDBMaxStorageMutex.RUnlock()
+	fake.spotBidPriceMutex.RLock()
+	defer fake.spotBidPriceMutex.RUnlock()
+	fake.validateDBInstanceTypeMutex.RLock()
+	defer fake.validateDBInstanceTypeMutex.RUnlock()
+	fake.validateInstanceTypeMutex.RLock()
+	defer fake.validateInstanceTypeMutex.RUnlock()
 	fake.workerTypeMutex.RLock()
 	defer fake.workerTypeMutex.RUnlock()
 	fake.writeFileMutex.RLock()
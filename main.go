@@ -21,6 +21,7 @@ func main() {
 	app.Version = ConcourseUpVersion
 	app.Commands = commands.Commands
 	app.Flags = commands.GlobalFlags
+	app.Before = commands.Before
 	cli.AppHelpTemplate = fmt.Sprintf(`%s
 
 See 'concourse-up help <command>' to read about a specific command.
@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Outcomes of an action
+const (
+	Succeeded = "succeeded"
+	Failed    = "failed"
+)
+
+// Targets are where outcomes are reported. Any of them may be empty
+type Targets struct {
+	SlackWebhook string
+	Webhook      string
+	Email        string
+	SMTPServer   string
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// IsSet is true when there is at least one target to notify
+func (t Targets) IsSet() bool {
+	return t.SlackWebhook != "" || t.Webhook != "" || t.Email != ""
+}
+
+// Event is the outcome of a concourse-up action on a deployment
+type Event struct {
+	Deployment string `json:"deployment"`
+	Action     string `json:"action"`
+	Outcome    string `json:"outcome"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Summary describes the event in a single line
+func (e Event) Summary() string {
+	return fmt.Sprintf("concourse-up %s of %s %s", e.Action, e.Deployment, e.Outcome)
+}
+
+// Message is the summary followed by the error, if there was one
+func (e Event) Message() string {
+	if e.Error == "" {
+		return e.Summary()
+	}
+	return e.Summary() + ": " + e.Error
+}
+
+var client = &http.Client{Timeout: 30 * time.Second}
+
+// Send reports e to every target, trying them all even if some fail
+func Send(t Targets, e Event) error {
+	var errs []string
+	if t.SlackWebhook != "" {
+		if err := post(t.SlackWebhook, struct {
+			Text string `json:"text"`
+		}{e.Message()}); err != nil {
+			errs = append(errs, fmt.Sprintf("slack webhook: %v", err))
+		}
+	}
+	if t.Webhook != "" {
+		if err := post(t.Webhook, struct {
+			Text string `json:"text"`
+			Event
+		}{e.Message(), e}); err != nil {
+			errs = append(errs, fmt.Sprintf("webhook: %v", err))
+		}
+	}
+	if t.Email != "" {
+		if err := mail(t, e); err != nil {
+			errs = append(errs, fmt.Sprintf("email: %v", err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to send notifications: [%s]", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func post(url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// mail sends e to t.Email from the same address, upgrading to TLS when the server offers it
+func mail(t Targets, e Event) error {
+	host, _, err := net.SplitHostPort(t.SMTPServer)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if t.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", t.SMTPUsername, t.SMTPPassword, host)
+	}
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n", t.Email, t.Email, e.Summary(), e.Message())
+	return smtp.SendMail(t.SMTPServer, auth, t.Email, []string{t.Email}, []byte(msg))
+}
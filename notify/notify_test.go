@@ -0,0 +1,145 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var event = Event{Deployment: "my-deployment", Action: "deploy", Outcome: Failed, Error: "boom"}
+
+func TestSendWebhooks(t *testing.T) {
+	received := map[string]map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected content type %q", r.Header.Get("Content-Type"))
+		}
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+		received[r.URL.Path] = payload
+	}))
+	defer server.Close()
+
+	err := Send(Targets{SlackWebhook: server.URL + "/slack", Webhook: server.URL + "/hook"}, event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := received["/slack"], map[string]string{"text": "concourse-up deploy of my-deployment failed: boom"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("slack payload = %v, want %v", got, want)
+	}
+	want := map[string]string{
+		"text":       "concourse-up deploy of my-deployment failed: boom",
+		"deployment": "my-deployment",
+		"action":     "deploy",
+		"outcome":    "failed",
+		"error":      "boom",
+	}
+	if got := received["/hook"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("webhook payload = %v, want %v", got, want)
+	}
+}
+
+func TestSendReportsEveryFailure(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := Send(Targets{SlackWebhook: server.URL, Webhook: server.URL}, event)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if hits != 2 {
+		t.Errorf("expected both webhooks to be tried, got %d requests", hits)
+	}
+	if !strings.Contains(err.Error(), "slack webhook: unexpected status 500") || !strings.Contains(err.Error(), "webhook: unexpected status 500") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSendNoTargets(t *testing.T) {
+	if (Targets{}).IsSet() {
+		t.Error("expected empty targets not to be set")
+	}
+	if err := Send(Targets{}, event); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSendEmail(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	transcript := make(chan string, 1)
+	go fakeSMTPServer(t, l, transcript)
+
+	err = Send(Targets{Email: "ops@example.com", SMTPServer: l.Addr().String()}, event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := <-transcript
+	for _, want := range []string{
+		"MAIL FROM:<ops@example.com>",
+		"RCPT TO:<ops@example.com>",
+		"Subject: concourse-up deploy of my-deployment failed\r\n",
+		"\r\n\r\nconcourse-up deploy of my-deployment failed: boom\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in SMTP transcript:\n%s", want, got)
+		}
+	}
+}
+
+// fakeSMTPServer accepts a single message and sends everything the client said to transcript
+func fakeSMTPServer(t *testing.T, l net.Listener, transcript chan<- string) {
+	conn, err := l.Accept()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	var said strings.Builder
+	reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+
+	reply("220 localhost ESMTP")
+	inData := false
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			transcript <- said.String()
+			return
+		}
+		said.WriteString(line)
+		switch {
+		case inData && line == ".\r\n":
+			inData = false
+			reply("250 OK")
+		case inData:
+		case strings.HasPrefix(line, "EHLO"):
+			reply("250 localhost")
+		case strings.HasPrefix(line, "DATA"):
+			inData = true
+			reply("354 go ahead")
+		case strings.HasPrefix(line, "QUIT"):
+			reply("221 bye")
+			transcript <- said.String()
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
@@ -174,12 +174,136 @@ resource "aws_iam_user_policy" "bosh" {
       ],
       "Effect": "Allow",
       "Resource": "*"
+    }{{if or (eq .CredentialManager "aws-secretsmanager") (eq .CredentialManager "aws-ssm")}},
+    {
+      "Action": [
+        "iam:PassRole"
+      ],
+      "Effect": "Allow",
+      "Resource": "${aws_iam_role.web.arn}"
+    }{{end}}{{if or .WorkerIAMPolicies .SelfUpdateRoleARN}},
+    {
+      "Action": [
+        "iam:PassRole"
+      ],
+      "Effect": "Allow",
+      "Resource": "${aws_iam_role.worker.arn}"
+    }{{end}}
+  ]
+}
+EOF
+}
+{{if or (eq .CredentialManager "aws-secretsmanager") (eq .CredentialManager "aws-ssm")}}
+resource "aws_iam_role" "web" {
+  name = "${var.deployment}-{{ .Namespace }}-web"
+
+  assume_role_policy = <<EOF
+{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Action": "sts:AssumeRole",
+      "Effect": "Allow",
+      "Principal": {
+        "Service": "ec2.amazonaws.com"
+      }
+    }
+  ]
+}
+EOF
+}
+
+resource "aws_iam_role_policy" "web" {
+  name = "${var.deployment}-{{ .Namespace }}-web"
+  role = "${aws_iam_role.web.id}"
+
+  policy = <<EOF
+{
+  "Version": "2012-10-17",
+  "Statement": [{{if eq .CredentialManager "aws-secretsmanager"}}
+    {
+      "Action": [
+        "secretsmanager:DescribeSecret",
+        "secretsmanager:GetSecretValue"
+      ],
+      "Effect": "Allow",
+      "Resource": "arn:aws:secretsmanager:${var.region}:*:secret:/concourse/*"
+    }{{else}}
+    {
+      "Action": [
+        "ssm:GetParameter",
+        "ssm:GetParametersByPath"
+      ],
+      "Effect": "Allow",
+      "Resource": "arn:aws:ssm:${var.region}:*:parameter/concourse/*"
+    }{{end}}
+  ]
+}
+EOF
+}
+
+resource "aws_iam_instance_profile" "web" {
+  name = "${var.deployment}-{{ .Namespace }}-web"
+  role = "${aws_iam_role.web.name}"
+}
+{{end}}
+{{if .WorkerIAMPolicies}}
+variable "worker_iam_policies" {
+  type = "list"
+  default = [{{ .WorkerIAMPolicies }}]
+}
+
+resource "aws_iam_role_policy_attachment" "worker" {
+  count      = "${length(var.worker_iam_policies)}"
+  role       = "${aws_iam_role.worker.name}"
+  policy_arn = "${element(var.worker_iam_policies, count.index)}"
+}
+{{end}}
+{{if .SelfUpdateRoleARN}}
+resource "aws_iam_role_policy" "worker_self_update" {
+  name = "${var.deployment}-{{ .Namespace }}-self-update"
+  role = "${aws_iam_role.worker.id}"
+
+  policy = <<EOF
+{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Action": "sts:AssumeRole",
+      "Effect": "Allow",
+      "Resource": "{{ .SelfUpdateRoleARN }}"
+    }
+  ]
+}
+EOF
+}
+{{end}}
+{{if or .WorkerIAMPolicies .SelfUpdateRoleARN}}
+resource "aws_iam_role" "worker" {
+  name = "${var.deployment}-{{ .Namespace }}-worker"
+
+  assume_role_policy = <<EOF
+{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Action": "sts:AssumeRole",
+      "Effect": "Allow",
+      "Principal": {
+        "Service": "ec2.amazonaws.com"
+      }
     }
   ]
 }
 EOF
 }
 
+resource "aws_iam_instance_profile" "worker" {
+  name = "${var.deployment}-{{ .Namespace }}-worker"
+  role = "${aws_iam_role.worker.name}"
+}
+{{end}}
+
 resource "aws_vpc" "default" {
   cidr_block = "${var.network_cidr}"
 
@@ -319,21 +443,21 @@ resource "aws_security_group" "director" {
     from_port   = 6868
     to_port     = 6868
     protocol    = "tcp"
-    cidr_blocks = ["${var.source_access_ip}/32", "${aws_nat_gateway.default.public_ip}/32"]
+    cidr_blocks = ["${var.source_access_ip}/32", "${aws_nat_gateway.default.public_ip}/32"{{if .AccessCIDRs}}, {{ .AccessCIDRs }}{{end}}]
   }
 
   ingress {
     from_port   = 25555
     to_port     = 25555
     protocol    = "tcp"
-    cidr_blocks = ["${var.source_access_ip}/32", "${aws_nat_gateway.default.public_ip}/32"]
+    cidr_blocks = ["${var.source_access_ip}/32", "${aws_nat_gateway.default.public_ip}/32"{{if .AccessCIDRs}}, {{ .AccessCIDRs }}{{end}}]
   }
 
   ingress {
     from_port   = 22
     to_port     = 22
     protocol    = "tcp"
-    cidr_blocks = ["${var.source_access_ip}/32", "${aws_nat_gateway.default.public_ip}/32"]
+    cidr_blocks = ["${var.source_access_ip}/32", "${aws_nat_gateway.default.public_ip}/32"{{if .AccessCIDRs}}, {{ .AccessCIDRs }}{{end}}]
   }
 
   egress {
@@ -585,12 +709,13 @@ resource "aws_db_subnet_group" "default" {
 }
 
 resource "aws_db_instance" "default" {
-  allocated_storage      = 10
+  allocated_storage      = {{.DBStorage}}
+  allow_major_version_upgrade = true
   apply_immediately      = true
   port                   = 5432
   engine                 = "postgres"
   instance_class         = "${var.rds_instance_class}"
-  engine_version         = "9.6.11"
+  engine_version         = "{{.DBEngineVersion}}"
   name                   = "${var.rds_default_database_name}"
   username               = "${var.rds_instance_username}"
   password               = "${var.rds_instance_password}"
@@ -598,7 +723,9 @@ resource "aws_db_instance" "default" {
   multi_az               = false
   vpc_security_group_ids = ["${aws_security_group.rds.id}"]
   db_subnet_group_name   = "${aws_db_subnet_group.default.name}"
-  skip_final_snapshot    = true
+  skip_final_snapshot    = {{if .FinalSnapshot}}false{{else}}true{{end}}
+  {{if .FinalSnapshot}}final_snapshot_identifier = "{{.FinalSnapshot}}"{{end}}
+  {{if .RestoreSnapshot}}snapshot_identifier = "{{.RestoreSnapshot}}"{{end}}
   storage_type           = "gp2"
   lifecycle {
     ignore_changes = ["allocated_storage"]
@@ -610,6 +737,23 @@ resource "aws_db_instance" "default" {
   }
 }
 
+{{if .DBUpgradeSnapshot}}
+resource "aws_db_snapshot" "upgrade" {
+  db_instance_identifier = "${aws_db_instance.default.id}"
+  db_snapshot_identifier = "{{.DBUpgradeSnapshot}}"
+}
+{{end}}
+
+{{if or (eq .CredentialManager "aws-secretsmanager") (eq .CredentialManager "aws-ssm")}}
+output "web_instance_profile" {
+  value = "${aws_iam_instance_profile.web.name}"
+}
+{{end}}
+{{if or .WorkerIAMPolicies .SelfUpdateRoleARN}}
+output "worker_instance_profile" {
+  value = "${aws_iam_instance_profile.worker.name}"
+}
+{{end}}
 output "vpc_id" {
   value = "${aws_vpc.default.id}"
 }
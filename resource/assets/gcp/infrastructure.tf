@@ -182,7 +182,7 @@ resource "google_compute_firewall" "director" {
   description = "Firewall for external access to BOSH director"
   network     = "${google_compute_network.default.self_link}"
   target_tags = ["external"]
-  source_ranges = ["${var.source_access_ip}/32", "${google_compute_instance.nat-instance.network_interface.0.access_config.0.nat_ip}/32"]
+  source_ranges = ["${var.source_access_ip}/32", "${google_compute_instance.nat-instance.network_interface.0.access_config.0.nat_ip}/32"{{if .AccessCIDRs}}, {{ .AccessCIDRs }}{{end}}]
   allow {
     protocol = "tcp"
     ports = ["6868", "25555", "22"]
@@ -322,6 +322,24 @@ resource "google_project_iam_member" "bosh" {
   role    = "roles/owner"
   member  = "serviceAccount:${google_service_account.bosh.email}"
 }
+{{if .WorkerServiceAccountRoles}}
+variable "worker_service_account_roles" {
+  type = "list"
+  default = [{{ .WorkerServiceAccountRoles }}]
+}
+
+resource "google_service_account" "worker" {
+  account_id   = "${var.deployment}-worker"
+  display_name = "concourse worker"
+}
+
+resource "google_project_iam_member" "worker" {
+  count   = "${length(var.worker_service_account_roles)}"
+  project = "${var.project}"
+  role    = "${element(var.worker_service_account_roles, count.index)}"
+  member  = "serviceAccount:${google_service_account.worker.email}"
+}
+{{end}}
 resource "google_compute_address" "atc_ip" {
   name = "${var.deployment}-atc-ip"
 }
@@ -332,11 +350,13 @@ resource "google_compute_address" "director" {
 
 resource "google_sql_database_instance" "director" {
   name = "${var.db_name}"
-  database_version = "POSTGRES_9_6"
+  database_version = "{{.DBVersion}}"
   region       = "${var.region}"
 
   settings {
     tier = "${var.db_tier}"
+    disk_size = {{.DBStorage}}
+    disk_autoresize = true
     user_labels {
       deployment = "${var.deployment}"
     }
@@ -352,6 +372,10 @@ resource "google_sql_database_instance" "director" {
     }
     }
   }
+
+  lifecycle {
+    ignore_changes = ["settings.0.disk_size"]
+  }
 }
 
 resource "google_sql_database" "director" {
@@ -417,3 +441,8 @@ output "nat_gateway_ip" {
 output "server_ca_cert" {
   value = "${google_sql_database_instance.director.server_ca_cert.0.cert}"
 }
+{{if .WorkerServiceAccountRoles}}
+output "worker_service_account" {
+  value = "${google_service_account.worker.email}"
+}
+{{end}}
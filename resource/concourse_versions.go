@@ -0,0 +1,53 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/EngineerBetter/concourse-up/resource/internal/file"
+)
+
+// ConcourseRelease is a version of Concourse from the catalogue this release of concourse-up can deploy
+type ConcourseRelease struct {
+	Version string `json:"version"`
+	URL     string `json:"url"`
+	SHA1    string `json:"sha1"`
+	// CompatibilityOps names the ops file that adapts the upstream manifest to concourse-up for this version
+	CompatibilityOps string            `json:"compatibility_ops"`
+	Fly              map[string]string `json:"fly"`
+}
+
+var concourseReleases []ConcourseRelease
+
+func init() {
+	p := file.MustAsset("../../concourse-up-ops/concourse-versions.json")
+	err := json.Unmarshal(p, &concourseReleases)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// ConcourseVersions lists the versions of Concourse in the catalogue
+func ConcourseVersions() []string {
+	versions := make([]string, len(concourseReleases))
+	for i, r := range concourseReleases {
+		versions[i] = r.Version
+	}
+	return versions
+}
+
+// GetConcourseRelease returns the catalogue entry for version
+func GetConcourseRelease(version string) (ConcourseRelease, error) {
+	for _, r := range concourseReleases {
+		if r.Version == version {
+			return r, nil
+		}
+	}
+	return ConcourseRelease{}, fmt.Errorf("Concourse %s is not supported by this version of concourse-up, choose one of: %s", version, strings.Join(ConcourseVersions(), ", "))
+}
+
+// FlyVersionFile returns the fly download URLs for r in the same format as director-versions-<iaas>.json
+func (r ConcourseRelease) FlyVersionFile() ([]byte, error) {
+	return json.Marshal(map[string]map[string]string{"fly": r.Fly})
+}
@@ -64,6 +64,10 @@ var (
 	ExternalIPOps = mustAssetString("assets/external-ip.yml")
 	// AWSDirectorCustomOps statically defines custom-ops.yml contents
 	AWSDirectorCustomOps = mustAssetString("assets/aws/custom-ops.yml")
+	// AWSExternalDBOps statically defines aws external-db.yml contents
+	AWSExternalDBOps = mustAssetString("assets/aws/external-db.yml")
+	// AWSS3BlobstoreOps statically defines aws s3-blobstore.yml contents
+	AWSS3BlobstoreOps = mustAssetString("assets/aws/s3-blobstore.yml")
 
 	// AWSReleaseVersions carries all versions of releases
 	AWSReleaseVersions = mustAssetString("../../concourse-up-ops/ops/versions-aws.json")
@@ -79,6 +83,15 @@ var (
 
 	// CleanupCerts moves renewed values of certs to old keys in director vars store
 	CleanupCerts = mustAssetString("assets/maintenance/cleanup-certs.yml")
+
+	// AddNewDirectorCa carries the ops file that adds new director CAs trusted alongside the old ones
+	AddNewDirectorCa = mustAssetString("assets/maintenance/add-new-director-ca.yml")
+
+	// RemoveOldDirectorCa carries the ops file that stops trusting the old director CAs
+	RemoveOldDirectorCa = mustAssetString("assets/maintenance/remove-old-director-ca.yml")
+
+	// RotateCredentials removes generated passwords from the vars store so that they are regenerated
+	RotateCredentials = mustAssetString("assets/maintenance/rotate-credentials.yml")
 )
 
 // NOTE(px) remove this in a later version of github.com/mattn/go-bindata
@@ -110,16 +123,23 @@ func init() {
 var binaries map[string]binaryPaths
 
 type binaryPaths struct {
-	Mac   string `json:"mac"`
-	Linux string `json:"linux"`
+	Mac         string `json:"mac"`
+	MacSHA256   string `json:"mac_sha256"`
+	Linux       string `json:"linux"`
+	LinuxSHA256 string `json:"linux_sha256"`
 }
 
-func (p binaryPaths) path() string {
-	switch runtime.GOOS {
+func (p binaryPaths) path() (string, string) {
+	return p.pathFor(runtime.GOOS)
+}
+
+// pathFor returns the download URL for goos along with its SHA-256 digest
+func (p binaryPaths) pathFor(goos string) (string, string) {
+	switch goos {
 	case "darwin":
-		return p.Mac
+		return p.Mac, p.MacSHA256
 	case "linux":
-		return p.Linux
+		return p.Linux, p.LinuxSHA256
 	default:
 		panic("OS not supported")
 	}
@@ -127,12 +147,17 @@ func (p binaryPaths) path() string {
 
 // BOSHCLIPath returns the path of the downloaded bosh-cli
 func BOSHCLIPath() (string, error) {
-	p := binaries["bosh-cli"].path()
-	return bincache.Download(p)
+	return bincache.Download(binaries["bosh-cli"].path())
 }
 
 // TerraformCLIPath returns the path of the downloaded terraform-cli
 func TerraformCLIPath() (string, error) {
-	p := binaries["terraform"].path()
-	return bincache.Download(p)
+	return bincache.Download(binaries["terraform"].path())
+}
+
+// BinaryURLs returns the download URLs of the bosh-cli and terraform binaries for goos
+func BinaryURLs(goos string) []string {
+	boshCLI, _ := binaries["bosh-cli"].pathFor(goos)
+	terraform, _ := binaries["terraform"].pathFor(goos)
+	return []string{boshCLI, terraform}
 }
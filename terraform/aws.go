@@ -12,10 +12,16 @@ import (
 
 // InputVars holds all the parameters AWS IAAS needs
 type AWSInputVars struct {
+	AccessCIDRs            string
 	AllowIPs               string
 	AvailabilityZone       string
 	ConfigBucket           string
+	CredentialManager      string
+	DBEngineVersion        string
+	DBStorage              int
+	DBUpgradeSnapshot      string
 	Deployment             string
+	FinalSnapshot          string
 	HostedZoneID           string
 	HostedZoneRecordPrefix string
 	Namespace              string
@@ -31,8 +37,11 @@ type AWSInputVars struct {
 	RDS1CIDR               string
 	RDS2CIDR               string
 	Region                 string
+	RestoreSnapshot        string
+	SelfUpdateRoleARN      string
 	SourceAccessIP         string
 	TFStatePath            string
+	WorkerIAMPolicies      string
 }
 
 // ConfigureTerraform interpolates terraform contents and returns terraform config
@@ -69,6 +78,8 @@ type AWSOutputs struct {
 	SourceAccessIP           MetadataStringValue `json:"source_access_ip"`
 	VMsSecurityGroupID       MetadataStringValue `json:"vms_security_group_id" valid:"required"`
 	VPCID                    MetadataStringValue `json:"vpc_id" valid:"required"`
+	WebInstanceProfile       MetadataStringValue `json:"web_instance_profile"`
+	WorkerInstanceProfile    MetadataStringValue `json:"worker_instance_profile"`
 }
 
 // AssertValid returns an error if the struct contains any missing fields
@@ -12,24 +12,28 @@ import (
 
 // InputVars holds all the parameters GCP IAAS needs
 type GCPInputVars struct {
-	AllowIPs           string
-	ConfigBucket       string
-	DBName             string
-	DBPassword         string
-	DBTier             string
-	DBUsername         string
-	Deployment         string
-	DNSManagedZoneName string
-	DNSRecordSetPrefix string
-	ExternalIP         string
-	GCPCredentialsJSON string
-	Namespace          string
-	PrivateCIDR        string
-	Project            string
-	PublicCIDR         string
-	Region             string
-	Tags               string
-	Zone               string
+	AccessCIDRs               string
+	AllowIPs                  string
+	ConfigBucket              string
+	DBName                    string
+	DBPassword                string
+	DBStorage                 int
+	DBTier                    string
+	DBUsername                string
+	DBVersion                 string
+	Deployment                string
+	DNSManagedZoneName        string
+	DNSRecordSetPrefix        string
+	ExternalIP                string
+	GCPCredentialsJSON        string
+	Namespace                 string
+	PrivateCIDR               string
+	Project                   string
+	PublicCIDR                string
+	Region                    string
+	Tags                      string
+	Zone                      string
+	WorkerServiceAccountRoles string
 }
 
 // ConfigureTerraform interpolates terraform contents and returns terraform config
@@ -43,19 +47,20 @@ func (v *GCPInputVars) ConfigureTerraform(terraformContents string) (string, err
 
 // Metadata represents output from terraform on GCP or GCP
 type GCPOutputs struct {
-	Network                    MetadataStringValue `json:"network" valid:"required"`
-	PrivateSubnetworkName      MetadataStringValue `json:"private_subnetwork_name" valid:"required"`
-	PublicSubnetworkName       MetadataStringValue `json:"public_subnetwork_name" valid:"required"`
+	Network                     MetadataStringValue `json:"network" valid:"required"`
+	PrivateSubnetworkName       MetadataStringValue `json:"private_subnetwork_name" valid:"required"`
+	PublicSubnetworkName        MetadataStringValue `json:"public_subnetwork_name" valid:"required"`
 	PrivateSubnetworkInternalGw MetadataStringValue `json:"private_subnetwork_internal_gw" valid:"required"`
 	PublicSubnetworkInternalGw  MetadataStringValue `json:"public_subnetwork_internal_gw" valid:"required"`
-	ATCPublicIP                MetadataStringValue `json:"atc_public_ip" valid:"required"`
-	DirectorAccountCreds       MetadataStringValue `json:"director_account_creds" valid:"required"`
-	DirectorPublicIP           MetadataStringValue `json:"director_public_ip" valid:"required"`
-	BoshDBAddress              MetadataStringValue `json:"bosh_db_address" valid:"required"`
-	DBName                     MetadataStringValue `json:"db_name" valid:"required"`
-	NatGatewayIP               MetadataStringValue `json:"nat_gateway_ip" valid:"required"`
-	SQLServerCert              MetadataStringValue `json:"server_ca_cert" valid:"required"`
-	DirectorSecurityGroupID    MetadataStringValue `json:"director_firewall_name" valid:"required"`
+	ATCPublicIP                 MetadataStringValue `json:"atc_public_ip" valid:"required"`
+	DirectorAccountCreds        MetadataStringValue `json:"director_account_creds" valid:"required"`
+	DirectorPublicIP            MetadataStringValue `json:"director_public_ip" valid:"required"`
+	BoshDBAddress               MetadataStringValue `json:"bosh_db_address" valid:"required"`
+	DBName                      MetadataStringValue `json:"db_name" valid:"required"`
+	NatGatewayIP                MetadataStringValue `json:"nat_gateway_ip" valid:"required"`
+	SQLServerCert               MetadataStringValue `json:"server_ca_cert" valid:"required"`
+	DirectorSecurityGroupID     MetadataStringValue `json:"director_firewall_name" valid:"required"`
+	WorkerServiceAccount        MetadataStringValue `json:"worker_service_account"`
 }
 
 // AssertValid returns an error if the struct contains any missing fields
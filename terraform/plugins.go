@@ -0,0 +1,108 @@
+package terraform
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/EngineerBetter/concourse-up/bundle"
+	"github.com/EngineerBetter/concourse-up/iaas"
+)
+
+// providers pins the version of the terraform provider bundled for each IAAS. It has to satisfy
+// the version constraint on the provider block in the IAAS's infrastructure.tf
+var providers = map[iaas.Name]struct{ name, version string }{
+	iaas.AWS: {"aws", "1.60.0"},
+	iaas.GCP: {"google", "1.20.0"},
+}
+
+// ProviderURL returns the download URL of the terraform provider name uses on goos
+func ProviderURL(name iaas.Name, goos string) (string, error) {
+	p, ok := providers[name]
+	if !ok {
+		return "", fmt.Errorf("IAAS not supported: [%s]", name)
+	}
+	return fmt.Sprintf("https://releases.hashicorp.com/terraform-provider-%[1]s/%[2]s/terraform-provider-%[1]s_%[2]s_%[3]s_amd64.zip", p.name, p.version, goos), nil
+}
+
+// pluginDir unzips the provider from the active bundle, once, into a directory to pass to
+// terraform init with -plugin-dir so that it doesn't download providers from the internet.
+// Nothing is returned when no bundle is in use
+func pluginDir(name iaas.Name, goos string) (string, error) {
+	if !bundle.Active() {
+		return "", nil
+	}
+	u, err := ProviderURL(name, goos)
+	if err != nil {
+		return "", err
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(u))
+	dir := filepath.Join(cacheDir, "concourse-up", "terraform-plugins", hex.EncodeToString(sum[:8]))
+	if files, err := ioutil.ReadDir(dir); err == nil && len(files) > 0 {
+		return dir, nil
+	}
+
+	resp, err := bundle.Get(u)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error fetching %s from the bundle: unexpected status %s", u, resp.Status)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	r, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return "", fmt.Errorf("error unzipping %s: [%v]", u, err)
+	}
+
+	tmpDir := dir + ".tmp"
+	os.RemoveAll(tmpDir)
+	if err = os.MkdirAll(tmpDir, 0700); err != nil {
+		return "", err
+	}
+	for _, f := range r.File {
+		if strings.ContainsAny(f.Name, `/\`) {
+			continue
+		}
+		if err = unzipFile(f, filepath.Join(tmpDir, f.Name)); err != nil {
+			return "", err
+		}
+	}
+	if err = os.Rename(tmpDir, dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+func unzipFile(f *zip.File, name string) error {
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0700)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
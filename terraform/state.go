@@ -0,0 +1,189 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/resource"
+)
+
+var resourceDeclaration = regexp.MustCompile(`(?m)^\s*resource "([^"]+)" "([^"]+)"`)
+
+// countIndex is the index terraform 0.11 puts on the address of resources with a count
+var countIndex = regexp.MustCompile(`\.\d+$`)
+
+// UnmanagedResources lists the resources in a terraform state that concourse-up's config
+// doesn't declare, which terraform would destroy the next time it is applied. Both the 0.11
+// and 0.12 state formats are understood
+func UnmanagedResources(name iaas.Name, state []byte) ([]string, error) {
+	var tfConfig string
+	switch name {
+	case iaas.AWS: // nolint
+		tfConfig = resource.AWSTerraformConfig
+	case iaas.GCP: // nolint
+		tfConfig = resource.GCPTerraformConfig
+	default:
+		return nil, fmt.Errorf("terraform: %s not a valid iaas provider", name)
+	}
+	declared := map[string]bool{}
+	for _, match := range resourceDeclaration.FindAllStringSubmatch(tfConfig, -1) {
+		declared[match[1]+"."+match[2]] = true
+	}
+
+	addresses, err := stateAddresses(state)
+	if err != nil {
+		return nil, err
+	}
+	var unmanaged []string
+	for _, address := range addresses {
+		if !declared[address] {
+			unmanaged = append(unmanaged, address)
+		}
+	}
+	return unmanaged, nil
+}
+
+// bblResources maps the addresses bosh-bootloader gives resources to the ones concourse-up
+// declares for them. Only AWS is mapped: on GCP bbl's subnet spans the whole network, leaving
+// no room for the subnets concourse-up adds
+var bblResources = map[iaas.Name]map[string]string{
+	iaas.AWS: {
+		"aws_vpc.vpc":                                "aws_vpc.default",
+		"aws_internet_gateway.ig":                    "aws_internet_gateway.default",
+		"aws_subnet.bosh_subnet":                     "aws_subnet.public",
+		"aws_eip.bosh_eip":                           "aws_eip.director",
+		"aws_security_group.bosh_security_group":     "aws_security_group.director",
+		"aws_security_group.internal_security_group": "aws_security_group.vms",
+	},
+}
+
+// RenameBBLResources moves the resources bosh-bootloader created to the addresses
+// concourse-up declares for them, as `terraform state mv` would, so that the next apply
+// updates rather than destroys them. Resources already at those addresses are left alone
+func RenameBBLResources(name iaas.Name, state []byte) ([]byte, error) {
+	renames := bblResources[name]
+	if len(renames) == 0 {
+		return state, nil
+	}
+	var s map[string]interface{}
+	if err := json.Unmarshal(state, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse terraform state: [%v]", err)
+	}
+
+	renamed := false
+	switch version, _ := s["version"].(float64); version {
+	case 3:
+		modules, _ := s["modules"].([]interface{})
+		for _, m := range modules {
+			module, _ := m.(map[string]interface{})
+			if path, _ := module["path"].([]interface{}); len(path) != 1 {
+				continue
+			}
+			resources, _ := module["resources"].(map[string]interface{})
+			for from, to := range renames {
+				r, ok := resources[from]
+				if _, taken := resources[to]; !ok || taken {
+					continue
+				}
+				delete(resources, from)
+				resources[to] = r
+				renamed = true
+			}
+		}
+	case 4:
+		resources, _ := s["resources"].([]interface{})
+		taken := map[string]bool{}
+		for _, r := range resources {
+			resource, _ := r.(map[string]interface{})
+			if resource["module"] == nil && resource["mode"] == "managed" {
+				taken[fmt.Sprintf("%s.%s", resource["type"], resource["name"])] = true
+			}
+		}
+		for _, r := range resources {
+			resource, _ := r.(map[string]interface{})
+			if resource["module"] != nil || resource["mode"] != "managed" {
+				continue
+			}
+			to, ok := renames[fmt.Sprintf("%s.%s", resource["type"], resource["name"])]
+			if !ok || taken[to] {
+				continue
+			}
+			resource["name"] = strings.SplitN(to, ".", 2)[1]
+			taken[to] = true
+			renamed = true
+		}
+	default:
+		return nil, fmt.Errorf("terraform state version %v is not supported", s["version"])
+	}
+	if !renamed {
+		return state, nil
+	}
+	// terraform refuses a state that changed without its serial going up
+	serial, _ := s["serial"].(float64)
+	s["serial"] = serial + 1
+	return json.MarshalIndent(s, "", "    ")
+}
+
+// stateAddresses lists the addresses of the managed resources in a terraform state, leaving
+// out data sources and count indexes
+func stateAddresses(state []byte) ([]string, error) {
+	var s struct {
+		Version int `json:"version"`
+		Modules []struct {
+			Path      []string                   `json:"path"`
+			Resources map[string]json.RawMessage `json:"resources"`
+		} `json:"modules"`
+		Resources []struct {
+			Module string `json:"module"`
+			Mode   string `json:"mode"`
+			Type   string `json:"type"`
+			Name   string `json:"name"`
+		} `json:"resources"`
+	}
+	if err := json.Unmarshal(state, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse terraform state: [%v]", err)
+	}
+
+	seen := map[string]bool{}
+	switch s.Version {
+	case 3:
+		for _, module := range s.Modules {
+			var prefix string
+			for i, name := range module.Path {
+				if i > 0 {
+					prefix += "module." + name + "."
+				}
+			}
+			for key := range module.Resources {
+				if strings.HasPrefix(key, "data.") {
+					continue
+				}
+				seen[prefix+countIndex.ReplaceAllString(key, "")] = true
+			}
+		}
+	case 4:
+		for _, r := range s.Resources {
+			if r.Mode != "managed" {
+				continue
+			}
+			var prefix string
+			if r.Module != "" {
+				prefix = r.Module + "."
+			}
+			seen[prefix+r.Type+"."+r.Name] = true
+		}
+	default:
+		return nil, fmt.Errorf("terraform state version %d is not supported", s.Version)
+	}
+
+	addresses := make([]string, 0, len(seen))
+	for address := range seen {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses, nil
+}
@@ -0,0 +1,137 @@
+package terraform_test
+
+import (
+	"testing"
+
+	"github.com/EngineerBetter/concourse-up/iaas"
+	"github.com/EngineerBetter/concourse-up/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmanagedResources(t *testing.T) {
+	tests := []struct {
+		name    string
+		iaas    iaas.Name
+		state   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "0.11 state with only declared resources",
+			iaas: iaas.AWS,
+			state: `{"version":3,"modules":[{"path":["root"],"resources":{
+				"aws_vpc.default":{},
+				"aws_eip.director":{},
+				"data.aws_availability_zones.available":{}
+			}}]}`,
+		},
+		{
+			name: "0.11 state with other resources",
+			iaas: iaas.AWS,
+			state: `{"version":3,"modules":[{"path":["root"],"resources":{
+				"aws_vpc.default":{},
+				"aws_instance.nat.0":{},
+				"aws_instance.nat.1":{}
+			}},{"path":["root","vpc"],"resources":{"aws_subnet.internal":{}}}]}`,
+			want: []string{"aws_instance.nat", "module.vpc.aws_subnet.internal"},
+		},
+		{
+			name: "0.12 state",
+			iaas: iaas.GCP,
+			state: `{"version":4,"resources":[
+				{"mode":"managed","type":"google_compute_network","name":"default"},
+				{"mode":"managed","type":"google_compute_network","name":"bbl-network"},
+				{"mode":"data","type":"google_compute_zones","name":"available"}
+			]}`,
+			want: []string{"google_compute_network.bbl-network"},
+		},
+		{
+			name:    "unsupported state version",
+			iaas:    iaas.AWS,
+			state:   `{"version":1}`,
+			wantErr: true,
+		},
+		{
+			name:    "not a state file",
+			iaas:    iaas.AWS,
+			state:   `director_ssl: {}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := terraform.UnmanagedResources(tt.iaas, []byte(tt.state))
+			require.Equal(t, tt.wantErr, err != nil, "error: %v", err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRenameBBLResources(t *testing.T) {
+	tests := []struct {
+		name    string
+		iaas    iaas.Name
+		state   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "0.11 bbl state",
+			iaas: iaas.AWS,
+			state: `{"version":3,"serial":7,"modules":[{"path":["root"],"resources":{
+				"aws_vpc.vpc":{},
+				"aws_eip.bosh_eip":{},
+				"aws_subnet.bosh_subnet":{},
+				"aws_security_group.internal_security_group":{},
+				"aws_instance.nat":{}
+			}}]}`,
+			want: []string{"aws_instance.nat"},
+		},
+		{
+			name: "0.12 bbl state",
+			iaas: iaas.AWS,
+			state: `{"version":4,"serial":7,"resources":[
+				{"mode":"managed","type":"aws_vpc","name":"vpc"},
+				{"mode":"managed","type":"aws_internet_gateway","name":"ig"},
+				{"mode":"managed","type":"aws_security_group","name":"bosh_security_group"},
+				{"mode":"managed","type":"aws_security_group_rule","name":"bosh_ssh"}
+			]}`,
+			want: []string{"aws_security_group_rule.bosh_ssh"},
+		},
+		{
+			name: "resource already at the concourse-up address",
+			iaas: iaas.AWS,
+			state: `{"version":3,"serial":7,"modules":[{"path":["root"],"resources":{
+				"aws_vpc.vpc":{},
+				"aws_vpc.default":{}
+			}}]}`,
+			want: []string{"aws_vpc.vpc"},
+		},
+		{
+			name: "GCP state is left as it is",
+			iaas: iaas.GCP,
+			state: `{"version":4,"resources":[
+				{"mode":"managed","type":"google_compute_network","name":"bbl-network"}
+			]}`,
+			want: []string{"google_compute_network.bbl-network"},
+		},
+		{
+			name:    "not a state file",
+			iaas:    iaas.AWS,
+			state:   `director_ssl: {}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := terraform.RenameBBLResources(tt.iaas, []byte(tt.state))
+			require.Equal(t, tt.wantErr, err != nil, "error: %v", err)
+			if tt.wantErr {
+				return
+			}
+			got, err := terraform.UnmanagedResources(tt.iaas, state)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
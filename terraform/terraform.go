@@ -9,6 +9,8 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"regexp"
+	"runtime"
 
 	"github.com/EngineerBetter/concourse-up/iaas"
 	"github.com/EngineerBetter/concourse-up/resource"
@@ -33,6 +35,8 @@ type CLIInterface interface {
 	Apply(InputVars) error
 	Destroy(InputVars) error
 	BuildOutput(InputVars) (Outputs, error)
+	Plan(InputVars) (bool, error)
+	Config(InputVars) (string, error)
 }
 
 // CLI struct holds the abstraction of execCmd
@@ -104,29 +108,44 @@ func (n *NullOutputs) Init(*bytes.Buffer) error { return nil }
 
 func (n *NullOutputs) Get(string) (string, error) { return "", nil }
 
-func (c *CLI) init(config InputVars) (string, error) {
-	var (
-		tfConfig string
-		err      error
-	)
+// Config renders the terraform config, including its backend, for a given set of input vars
+func (c *CLI) Config(config InputVars) (string, error) {
 	switch c.iaas {
 	case iaas.AWS: // nolint
-		tfConfig, err = config.ConfigureTerraform(resource.AWSTerraformConfig)
-		if err != nil {
-			return "", err
-		}
+		return config.ConfigureTerraform(resource.AWSTerraformConfig)
 	case iaas.GCP: // nolint
-		tfConfig, err = config.ConfigureTerraform(resource.GCPTerraformConfig)
-		if err != nil {
-			return "", err
-		}
+		return config.ConfigureTerraform(resource.GCPTerraformConfig)
+	}
+	return "", nil
+}
+
+var passwordVariable = regexp.MustCompile(`(variable "[^"]*password[^"]*" \{[^}]*default\s*=\s*)"[^"]*"`)
+
+// RedactConfig replaces the defaults of password variables in a rendered config
+func RedactConfig(tfConfig string) string {
+	return passwordVariable.ReplaceAllString(tfConfig, `${1}"REDACTED"`)
+}
+
+func (c *CLI) init(config InputVars) (string, error) {
+	tfConfig, err := c.Config(config)
+	if err != nil {
+		return "", err
 	}
 
 	terraformConfigPath, err := writeTempFile([]byte(tfConfig))
 	if err != nil {
 		return "", err
 	}
-	cmd := c.execCmd(c.Path, "init")
+	plugins, err := pluginDir(c.iaas, runtime.GOOS)
+	if err != nil {
+		os.RemoveAll(terraformConfigPath)
+		return "", err
+	}
+	args := []string{"init"}
+	if plugins != "" {
+		args = append(args, "-plugin-dir="+plugins)
+	}
+	cmd := c.execCmd(c.Path, args...)
 	cmd.Dir = terraformConfigPath
 	cmd.Stderr = os.Stderr
 	err = cmd.Run()
@@ -171,6 +190,28 @@ func (c *CLI) Destroy(config InputVars) error {
 	return cmd.Run()
 }
 
+// Plan runs terraform plan for a given config, returning true when the infrastructure
+// differs from it
+func (c *CLI) Plan(config InputVars) (bool, error) {
+	terraformConfigPath, err := c.init(config)
+	if err != nil {
+		return false, err
+	}
+
+	defer os.RemoveAll(terraformConfigPath)
+
+	cmd := c.execCmd(c.Path, "plan", "-input=false", "-detailed-exitcode")
+	cmd.Dir = terraformConfigPath
+	cmd.Stderr = os.Stderr
+	cmd.Stdout = os.Stdout
+	err = cmd.Run()
+	// -detailed-exitcode exits 2 when there are changes, and 1 on errors
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 2 {
+		return true, nil
+	}
+	return false, err
+}
+
 // BuildOutput builds the terraform output
 func (c *CLI) BuildOutput(config InputVars) (Outputs, error) {
 	terraformConfigPath, err := c.init(config)
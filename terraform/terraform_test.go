@@ -1,15 +1,35 @@
 package terraform_test
 
 import (
+	"archive/zip"
 	"bytes"
+	"fmt"
 	"github.com/EngineerBetter/concourse-up/iaas"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/EngineerBetter/concourse-up/bundle"
 	"github.com/EngineerBetter/concourse-up/internal/fakeexec"
 	"github.com/EngineerBetter/concourse-up/terraform"
 	"github.com/stretchr/testify/require"
 )
 
+func TestExecCommandHelper(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	fmt.Print(os.Getenv("STDOUT"))
+	i, _ := strconv.Atoi(os.Getenv("EXIT_STATUS"))
+	os.Exit(i)
+}
+
 type mockTerraformInputVars struct{}
 type mockOutputs struct{}
 
@@ -97,3 +117,130 @@ func TestCLI_Destroy(t *testing.T) {
 	err = mockCLIent.Destroy(config)
 	require.NoError(t, err)
 }
+
+func TestCLI_ApplyFromBundle(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "terraform-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(cacheDir)
+	defer os.Setenv("XDG_CACHE_HOME", os.Getenv("XDG_CACHE_HOME"))
+	os.Setenv("XDG_CACHE_HOME", cacheDir)
+
+	var provider bytes.Buffer
+	zw := zip.NewWriter(&provider)
+	w, err := zw.Create("terraform-provider-aws_v1.60.0_x4")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("provider"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	providerURL, err := terraform.ProviderURL(iaas.AWS, runtime.GOOS)
+	require.NoError(t, err)
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/index.json" {
+			fmt.Fprintf(w, `{"artifacts":[{"url":%q,"path":"artifacts/provider.zip"}]}`, providerURL)
+			return
+		}
+		w.Write(provider.Bytes())
+	}))
+	defer mirror.Close()
+	b, err := bundle.Open(mirror.URL)
+	require.NoError(t, err)
+	bundle.Use(b)
+	defer bundle.Use(nil)
+
+	e := fakeexec.New(t)
+	defer e.Finish()
+	mockCLIent, err := terraform.New(iaas.AWS, terraform.FakeExec(e.Cmd()))
+	require.NoError(t, err)
+
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "terraform", command)
+		require.Len(t, args, 2)
+		require.Equal(t, "init", args[0])
+		require.True(t, strings.HasPrefix(args[1], "-plugin-dir="))
+		_, err := os.Stat(filepath.Join(strings.TrimPrefix(args[1], "-plugin-dir="), "terraform-provider-aws_v1.60.0_x4"))
+		require.NoError(t, err)
+	})
+	e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+		require.Equal(t, "apply", args[0])
+	})
+	err = mockCLIent.Apply(&mockTerraformInputVars{})
+	require.NoError(t, err)
+}
+
+func TestCLI_Plan(t *testing.T) {
+	tests := []struct {
+		name      string
+		exitCode  int
+		wantDrift bool
+		wantErr   bool
+	}{
+		{name: "no changes", exitCode: 0},
+		{name: "changes", exitCode: 2, wantDrift: true},
+		{name: "error", exitCode: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := fakeexec.New(t)
+			defer e.Finish()
+			mockCLIent, err := terraform.New(iaas.AWS, terraform.FakeExec(e.Cmd()))
+			require.NoError(t, err)
+
+			e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+				require.Equal(t, "terraform", command)
+				require.Equal(t, args[0], "init")
+			})
+			e.ExpectFunc(func(t testing.TB, command string, args ...string) {
+				require.Equal(t, "terraform", command)
+				require.Equal(t, []string{"plan", "-input=false", "-detailed-exitcode"}, args)
+			}).Exits(tt.exitCode)
+
+			drift, err := mockCLIent.Plan(&mockTerraformInputVars{})
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.wantDrift, drift)
+		})
+	}
+}
+
+func TestCLI_Config(t *testing.T) {
+	mockCLIent, err := terraform.New(iaas.GCP)
+	require.NoError(t, err)
+
+	tfConfig, err := mockCLIent.Config(&mockTerraformInputVars{})
+	require.NoError(t, err)
+	require.Equal(t, "", tfConfig)
+}
+
+func TestRedactConfig(t *testing.T) {
+	tfConfig := `variable "rds_instance_username" {
+  type = "string"
+	default = "admin"
+}
+
+variable "rds_instance_password" {
+  type = "string"
+	default = "s3cret"
+}
+
+variable "db_password" {
+	type = "string"
+	default = "an0ther"
+}
+`
+	want := `variable "rds_instance_username" {
+  type = "string"
+	default = "admin"
+}
+
+variable "rds_instance_password" {
+  type = "string"
+	default = "REDACTED"
+}
+
+variable "db_password" {
+	type = "string"
+	default = "REDACTED"
+}
+`
+	require.Equal(t, want, terraform.RedactConfig(tfConfig))
+}
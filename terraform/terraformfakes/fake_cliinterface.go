@@ -32,6 +32,19 @@ type FakeCLIInterface struct {
 		result1 terraform.Outputs
 		result2 error
 	}
+	ConfigStub        func(terraform.InputVars) (string, error)
+	configMutex       sync.RWMutex
+	configArgsForCall []struct {
+		arg1 terraform.InputVars
+	}
+	configReturns struct {
+		result1 string
+		result2 error
+	}
+	configReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
 	DestroyStub        func(terraform.InputVars) error
 	destroyMutex       sync.RWMutex
 	destroyArgsForCall []struct {
@@ -43,6 +56,19 @@ type FakeCLIInterface struct {
 	destroyReturnsOnCall map[int]struct {
 		result1 error
 	}
+	PlanStub        func(terraform.InputVars) (bool, error)
+	planMutex       sync.RWMutex
+	planArgsForCall []struct {
+		arg1 terraform.InputVars
+	}
+	planReturns struct {
+		result1 bool
+		result2 error
+	}
+	planReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -170,6 +196,69 @@ func (fake *FakeCLIInterface) BuildOutputReturnsOnCall(i int, result1 terraform.
 	}{result1, result2}
 }
 
+func (fake *FakeCLIInterface) Config(arg1 terraform.InputVars) (string, error) {
+	fake.configMutex.Lock()
+	ret, specificReturn := fake.configReturnsOnCall[len(fake.configArgsForCall)]
+	fake.configArgsForCall = append(fake.configArgsForCall, struct {
+		arg1 terraform.InputVars
+	}{arg1})
+	fake.recordInvocation("Config", []interface{}{arg1})
+	fake.configMutex.Unlock()
+	if fake.ConfigStub != nil {
+		return fake.ConfigStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.configReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCLIInterface) ConfigCallCount() int {
+	fake.configMutex.RLock()
+	defer fake.configMutex.RUnlock()
+	return len(fake.configArgsForCall)
+}
+
+func (fake *FakeCLIInterface) ConfigCalls(stub func(terraform.InputVars) (string, error)) {
+	fake.configMutex.Lock()
+	defer fake.configMutex.Unlock()
+	fake.ConfigStub = stub
+}
+
+func (fake *FakeCLIInterface) ConfigArgsForCall(i int) terraform.InputVars {
+	fake.configMutex.RLock()
+	defer fake.configMutex.RUnlock()
+	argsForCall := fake.configArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCLIInterface) ConfigReturns(result1 string, result2 error) {
+	fake.configMutex.Lock()
+	defer fake.configMutex.Unlock()
+	fake.ConfigStub = nil
+	fake.configReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCLIInterface) ConfigReturnsOnCall(i int, result1 string, result2 error) {
+	fake.configMutex.Lock()
+	defer fake.configMutex.Unlock()
+	fake.ConfigStub = nil
+	if fake.configReturnsOnCall == nil {
+		fake.configReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.configReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeCLIInterface) Destroy(arg1 terraform.InputVars) error {
 	fake.destroyMutex.Lock()
 	ret, specificReturn := fake.destroyReturnsOnCall[len(fake.destroyArgsForCall)]
@@ -230,6 +319,69 @@ func (fake *FakeCLIInterface) DestroyReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeCLIInterface) Plan(arg1 terraform.InputVars) (bool, error) {
+	fake.planMutex.Lock()
+	ret, specificReturn := fake.planReturnsOnCall[len(fake.planArgsForCall)]
+	fake.planArgsForCall = append(fake.planArgsForCall, struct {
+		arg1 terraform.InputVars
+	}{arg1})
+	fake.recordInvocation("Plan", []interface{}{arg1})
+	fake.planMutex.Unlock()
+	if fake.PlanStub != nil {
+		return fake.PlanStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.planReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCLIInterface) PlanCallCount() int {
+	fake.planMutex.RLock()
+	defer fake.planMutex.RUnlock()
+	return len(fake.planArgsForCall)
+}
+
+func (fake *FakeCLIInterface) PlanCalls(stub func(terraform.InputVars) (bool, error)) {
+	fake.planMutex.Lock()
+	defer fake.planMutex.Unlock()
+	fake.PlanStub = stub
+}
+
+func (fake *FakeCLIInterface) PlanArgsForCall(i int) terraform.InputVars {
+	fake.planMutex.RLock()
+	defer fake.planMutex.RUnlock()
+	argsForCall := fake.planArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCLIInterface) PlanReturns(result1 bool, result2 error) {
+	fake.planMutex.Lock()
+	defer fake.planMutex.Unlock()
+	fake.PlanStub = nil
+	fake.planReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCLIInterface) PlanReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.planMutex.Lock()
+	defer fake.planMutex.Unlock()
+	fake.PlanStub = nil
+	if fake.planReturnsOnCall == nil {
+		fake.planReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.planReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeCLIInterface) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -237,8 +389,12 @@ func (fake *FakeCLIInterface) Invocations() map[string][][]interface{} {
 	defer fake.applyMutex.RUnlock()
 	fake.buildOutputMutex.RLock()
 	defer fake.buildOutputMutex.RUnlock()
+	fake.configMutex.RLock()
+	defer fake.configMutex.RUnlock()
 	fake.destroyMutex.RLock()
 	defer fake.destroyMutex.RUnlock()
+	fake.planMutex.RLock()
+	defer fake.planMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -5,83 +5,114 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
-	"io"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/EngineerBetter/concourse-up/bundle"
 )
 
-// Download a file from url and check with a sha1
-func Download(url string) (string, error) {
-	path, err := os.UserCacheDir()
+// Download a file from url, verify it against its expected SHA-256 digest and cache it.
+// Cached files whose contents no longer match what was verified are evicted and downloaded again
+func Download(url, sha256sum string) (string, error) {
+	if sha256sum == "" {
+		return "", fmt.Errorf("no sha256 recorded for %s", url)
+	}
+	dir, err := os.UserCacheDir()
 	if err != nil {
 		return "", err
 	}
-	path = filepath.Join(path, "concourse-up", "bin")
-	err = os.MkdirAll(path, 0700)
+	dir = filepath.Join(dir, "concourse-up", "bin")
+	err = os.MkdirAll(dir, 0700)
 	if err != nil {
 		return "", err
 	}
-	path = filepath.Join(path, hash(url))
-	if _, err = os.Stat(path); !os.IsNotExist(err) {
+	path := filepath.Join(dir, hash(url))
+	if verifyCached(path) {
 		return path, nil
 	}
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0700)
+
+	resp, err := bundle.Get(url)
 	if err != nil {
 		return "", err
 	}
-	defer f.Close()
-	resp, err := http.Get(url)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error downloading %s: unexpected status %s", url, resp.Status)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		os.Remove(path)
 		return "", err
 	}
-	defer resp.Body.Close()
-
-	var closer io.ReadCloser
-
-	// if strings.HasSuffix(url, ".zip") {
-	// 	closer, err = handleZipFile(resp)
-	// 	if err != nil {
-	// 		return "", nil
-	// 	}
-	// } else {
-	// 	closer = resp.Body
-	// }
+	if got := hashBytes(body); got != strings.ToLower(sha256sum) {
+		return "", fmt.Errorf("sha256 mismatch for %s: expected %s, got %s", url, sha256sum, got)
+	}
 
+	contents := body
 	if isZip(url, resp) {
-		closer, err = handleZipFile(resp)
+		contents, err = handleZipFile(body)
 		if err != nil {
-			return "", nil
+			return "", err
 		}
-	} else {
-		closer = resp.Body
 	}
-	_, err = io.Copy(f, closer)
+
+	tmp, err := ioutil.TempFile(dir, ".download")
 	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(contents); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err = tmp.Close(); err != nil {
+		return "", err
+	}
+	if err = os.Chmod(tmp.Name(), 0700); err != nil {
+		return "", err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	if err = ioutil.WriteFile(path+".sha256", []byte(hashBytes(contents)), 0600); err != nil {
 		os.Remove(path)
 		return "", err
 	}
 	return path, nil
 }
 
-func handleZipFile(resp *http.Response) (io.ReadCloser, error) {
-	body, errz := ioutil.ReadAll(resp.Body)
-	if errz != nil {
-		return nil, errz
+// verifyCached reports whether path holds the same contents as when it was cached,
+// evicting it if not
+func verifyCached(path string) bool {
+	want, err := ioutil.ReadFile(path + ".sha256")
+	if err == nil {
+		contents, err := ioutil.ReadFile(path)
+		if err == nil && hashBytes(contents) == string(want) {
+			return true
+		}
 	}
-	r, errz := zip.NewReader(bytes.NewReader(body), resp.ContentLength)
-	if errz != nil {
-		return nil, errz
+	os.Remove(path)
+	os.Remove(path + ".sha256")
+	return false
+}
+
+func handleZipFile(body []byte) ([]byte, error) {
+	r, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return nil, err
 	}
-	firstFile, errz := r.File[0].Open()
-	if errz != nil {
-		return nil, errz
+	if len(r.File) == 0 {
+		return nil, fmt.Errorf("zip file is empty")
 	}
-	return firstFile, nil
-
+	firstFile, err := r.File[0].Open()
+	if err != nil {
+		return nil, err
+	}
+	defer firstFile.Close()
+	return ioutil.ReadAll(firstFile)
 }
 
 func isZip(url string, resp *http.Response) bool {
@@ -95,6 +126,10 @@ func isZip(url string, resp *http.Response) bool {
 }
 
 func hash(s string) string {
-	sum := sha256.Sum256([]byte(s))
+	return hashBytes([]byte(s))
+}
+
+func hashBytes(b []byte) string {
+	sum := sha256.Sum256(b)
 	return hex.EncodeToString(sum[:])
 }
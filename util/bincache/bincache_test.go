@@ -3,6 +3,8 @@ package bincache_test
 import (
 	"archive/zip"
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -16,13 +18,18 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
 func TestDownload(t *testing.T) {
 	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		io.WriteString(w, "#!/bin/bash\necho hi")
 	}))
 
 	defer s.Close()
-	path, err := bincache.Download(s.URL)
+	path, err := bincache.Download(s.URL, sha256Hex([]byte("#!/bin/bash\necho hi")))
 	require.NoError(t, err)
 	defer os.Remove(path)
 	out, err := exec.Command(path).Output()
@@ -31,7 +38,7 @@ func TestDownload(t *testing.T) {
 
 	// check download does not happen if file already exists
 	s.Close()
-	path1, err := bincache.Download(s.URL)
+	path1, err := bincache.Download(s.URL, sha256Hex([]byte("#!/bin/bash\necho hi")))
 	require.NoError(t, err)
 	require.Equal(t, path, path1)
 }
@@ -57,7 +64,7 @@ func TestDownloadZip(t *testing.T) {
 		w.Write(buf.Bytes())
 	}))
 
-	path, err := bincache.Download(s.URL)
+	path, err := bincache.Download(s.URL, sha256Hex(buf.Bytes()))
 	require.NoError(t, err)
 	defer os.Remove(path)
 	out, err := exec.Command(path).Output()
@@ -65,3 +72,50 @@ func TestDownloadZip(t *testing.T) {
 	require.Equal(t, "HELLO\n", string(out))
 	s.Close()
 }
+
+func TestDownloadChecksumMismatch(t *testing.T) {
+	requests := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		io.WriteString(w, "#!/bin/bash\necho tampered")
+	}))
+	defer s.Close()
+
+	_, err := bincache.Download(s.URL, sha256Hex([]byte("#!/bin/bash\necho hi")))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "sha256 mismatch")
+
+	// check nothing was cached, so the next attempt downloads again
+	_, err = bincache.Download(s.URL, sha256Hex([]byte("#!/bin/bash\necho hi")))
+	require.Error(t, err)
+	require.Equal(t, 2, requests)
+}
+
+func TestDownloadNoChecksum(t *testing.T) {
+	_, err := bincache.Download("https://example.com/bosh-cli", "")
+	require.EqualError(t, err, "no sha256 recorded for https://example.com/bosh-cli")
+}
+
+// check that a cached binary which has changed since it was verified is evicted
+func TestDownloadEvictsCorruptCache(t *testing.T) {
+	requests := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		io.WriteString(w, "#!/bin/bash\necho hi")
+	}))
+	defer s.Close()
+
+	path, err := bincache.Download(s.URL, sha256Hex([]byte("#!/bin/bash\necho hi")))
+	require.NoError(t, err)
+	defer os.Remove(path)
+	defer os.Remove(path + ".sha256")
+	require.NoError(t, ioutil.WriteFile(path, []byte("#!/bin/bash\necho corrupt"), 0700))
+
+	path1, err := bincache.Download(s.URL, sha256Hex([]byte("#!/bin/bash\necho hi")))
+	require.NoError(t, err)
+	require.Equal(t, path, path1)
+	require.Equal(t, 2, requests)
+	out, err := exec.Command(path1).Output()
+	require.NoError(t, err)
+	require.Equal(t, "hi\n", string(out))
+}
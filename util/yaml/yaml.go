@@ -6,6 +6,7 @@ import (
 	"github.com/cloudfoundry/bosh-cli/director/template"
 	"github.com/cppforlife/go-patch/patch"
 	yamlenc "github.com/ghodss/yaml"
+	yamlv2 "gopkg.in/yaml.v2"
 )
 
 func newOpsFromString(ops string) (patch.Op, error) {
@@ -51,3 +52,43 @@ func Path(b []byte, path string) (string, error) {
 	})
 	return string(x), err
 }
+
+// Redacted replaces values removed by Redact
+const Redacted = "REDACTED"
+
+// Redact replaces the scalar values of keys picked out by isSecret, including those in
+// lists under such keys, keeping the order of everything else. References to variables
+// such as ((admin_password)) are left for bosh to fill in
+func Redact(b []byte, isSecret func(key string) bool) ([]byte, error) {
+	var doc yamlv2.MapSlice
+	if err := yamlv2.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc) == 0 {
+		return b, nil
+	}
+	return yamlv2.Marshal(redact("", doc, isSecret))
+}
+
+func redact(key string, v interface{}, isSecret func(string) bool) interface{} {
+	switch v := v.(type) {
+	case yamlv2.MapSlice:
+		for i, item := range v {
+			k, _ := item.Key.(string)
+			v[i].Value = redact(k, item.Value, isSecret)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redact(key, item, isSecret)
+		}
+		return v
+	}
+	if s, ok := v.(string); ok && strings.HasPrefix(s, "((") && strings.HasSuffix(s, "))") {
+		return v
+	}
+	if v != nil && isSecret(key) {
+		return Redacted
+	}
+	return v
+}
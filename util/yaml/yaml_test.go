@@ -2,6 +2,7 @@ package yaml_test
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/EngineerBetter/concourse-up/util/yaml"
@@ -148,3 +149,49 @@ a:
 		})
 	}
 }
+
+func TestRedact(t *testing.T) {
+	isPassword := func(key string) bool { return strings.Contains(key, "password") }
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "nested values",
+			in:   "name: bosh\nproperties:\n  admin_password: s3cret\n  port: 25555\n",
+			want: "name: bosh\nproperties:\n  admin_password: REDACTED\n  port: 25555\n",
+		},
+		{
+			name: "values in lists",
+			in:   "users:\n- name: admin\n  password: s3cret\npasswords:\n- one\n- two\n",
+			want: "users:\n- name: admin\n  password: REDACTED\npasswords:\n- REDACTED\n- REDACTED\n",
+		},
+		{
+			name: "variable references",
+			in:   "password: ((admin_password))\n",
+			want: "password: ((admin_password))\n",
+		},
+		{
+			name: "empty values",
+			in:   "password:\n",
+			want: "password: null\n",
+		},
+		{
+			name: "empty document",
+			in:   "",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := yaml.Redact([]byte(tt.in), isPassword)
+			if err != nil {
+				t.Fatalf("Redact() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Redact() = '%s', want '%s'", got, tt.want)
+			}
+		})
+	}
+}